@@ -37,13 +37,19 @@ func ClientByCLIContext(cliCtx *cli.Context) (*dblabapi.Client, error) {
 		remoteURL.Host = BuildHostname(remoteURL.Hostname(), cliCtx.String(FwLocalPortKey))
 	}
 
-	options := dblabapi.Options{
-		Host:              remoteURL.String(),
+	options := ClientOptions(cliCtx)
+	options.Host = remoteURL.String()
+
+	// TODO(akartasov): Init and use logger.
+	return dblabapi.NewClient(options)
+}
+
+// ClientOptions returns options of the Database Lab API client defined by CLI flags.
+func ClientOptions(cliCtx *cli.Context) dblabapi.Options {
+	return dblabapi.Options{
+		Host:              cliCtx.String(URLKey),
 		VerificationToken: cliCtx.String(TokenKey),
 		Insecure:          cliCtx.Bool(InsecureKey),
 		RequestTimeout:    cliCtx.Duration(RequestTimeoutKey),
 	}
-
-	// TODO(akartasov): Init and use logger.
-	return dblabapi.NewClient(options)
 }
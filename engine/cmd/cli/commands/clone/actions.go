@@ -32,31 +32,48 @@ func list(cliCtx *cli.Context) error {
 		return err
 	}
 
-	body, err := dblabClient.ListClonesRaw(cliCtx.Context)
-	if err != nil {
-		return err
-	}
+	listRequest := types.CloneListRequest{
+		Selector: strings.Join(cliCtx.StringSlice("selector"), ","),
+		Status:   cliCtx.StringSlice("status"),
+		Snapshot: cliCtx.String("snapshot-id"),
+		Pool:     cliCtx.String("pool"),
+		Creator:  cliCtx.String("creator"),
+		MinAge:   cliCtx.Duration("min-age"),
+		MaxAge:   cliCtx.Duration("max-age"),
+		Sort:     cliCtx.String("sort"),
+		Limit:    cliCtx.Int("limit"),
+		Offset:   cliCtx.Int("offset"),
+	}
+
+	clones := []*models.Clone{}
+
+	// Fetch all pages unless the limit is specified.
+	for {
+		page, err := dblabClient.ListClonesPage(cliCtx.Context, listRequest)
+		if err != nil {
+			return err
+		}
 
-	defer func() { _ = body.Close() }()
+		clones = append(clones, page.Clones...)
+		listRequest.Offset += len(page.Clones)
 
-	viewCloneList := &models.CloneListView{
-		Cloning: models.CloningView{
-			Clones: make([]*models.CloneView, 0),
-		},
+		if cliCtx.IsSet("limit") || len(page.Clones) == 0 || listRequest.Offset >= page.Total {
+			break
+		}
 	}
 
-	if err := json.NewDecoder(body).Decode(&viewCloneList); err != nil {
-		return err
-	}
+	viewClones := make([]*models.CloneView, 0, len(clones))
 
-	commandResponse, err := json.MarshalIndent(viewCloneList.Cloning.Clones, "", "    ")
-	if err != nil {
-		return err
-	}
+	for _, clone := range clones {
+		viewClone, err := convertCloneView(clone)
+		if err != nil {
+			return err
+		}
 
-	_, err = fmt.Fprintln(cliCtx.App.Writer, string(commandResponse))
+		viewClones = append(viewClones, viewClone)
+	}
 
-	return err
+	return commands.PrintTable(cliCtx, viewClones, cloneTable(viewClones...))
 }
 
 // status runs a request to get clone info.
@@ -79,14 +96,7 @@ func status(cliCtx *cli.Context) error {
 		return err
 	}
 
-	commandResponse, err := json.MarshalIndent(cloneView, "", "    ")
-	if err != nil {
-		return err
-	}
-
-	_, err = fmt.Fprintln(cliCtx.App.Writer, string(commandResponse))
-
-	return err
+	return commands.PrintTable(cliCtx, cloneView, cloneTable(cloneView))
 }
 
 // create runs a request to create a new clone.
@@ -105,6 +115,9 @@ func create(cliCtx *cli.Context) error {
 			Restricted: cliCtx.Bool("restricted"),
 			DBName:     cliCtx.String("db-name"),
 		},
+		DockerImage:  cliCtx.String("docker-image"),
+		AllowedCIDRs: allowedCIDRs(cliCtx),
+		InitProfile:  cliCtx.String(cloneInitProfileFlag),
 	}
 
 	if cliCtx.IsSet("snapshot-id") {
@@ -113,6 +126,10 @@ func create(cliCtx *cli.Context) error {
 
 	cloneRequest.ExtraConf = splitFlags(cliCtx.StringSlice("extra-config"))
 
+	if cloneRequest.Labels, err = commands.ParseLabels(cliCtx.StringSlice(cloneLabelFlag)); err != nil {
+		return err
+	}
+
 	var clone *models.Clone
 
 	if cliCtx.Bool("async") {
@@ -122,22 +139,19 @@ func create(cliCtx *cli.Context) error {
 	}
 
 	if err != nil {
-		return err
-	}
+		if clone != nil && clone.ID != "" {
+			return errors.Wrapf(err, "clone %s has status %s, destroy it if it is not needed", clone.ID, clone.Status.Code)
+		}
 
-	viewClone, err := convertCloneView(clone)
-	if err != nil {
 		return err
 	}
 
-	commandResponse, err := json.MarshalIndent(viewClone, "", "    ")
+	viewClone, err := convertCloneView(clone)
 	if err != nil {
 		return err
 	}
 
-	_, err = fmt.Fprintln(cliCtx.App.Writer, string(commandResponse))
-
-	return err
+	return commands.PrintTable(cliCtx, viewClone, cloneTable(viewClone))
 }
 
 // update runs a request to update an existing clone.
@@ -147,30 +161,49 @@ func update(cliCtx *cli.Context) error {
 		return err
 	}
 
-	updateRequest := types.CloneUpdateRequest{
-		Protected: cliCtx.Bool("protected"),
+	patchRequest := types.ClonePatchRequest{}
+
+	if cliCtx.IsSet(cloneProtectedFlag) {
+		protected := cliCtx.Bool(cloneProtectedFlag)
+		patchRequest.Protected = &protected
 	}
 
 	cloneID := cliCtx.Args().First()
 
-	clone, err := dblabClient.UpdateClone(cliCtx.Context, cloneID, updateRequest)
-	if err != nil {
-		return err
+	if cliCtx.IsSet(cloneAllowedCIDRFlag) {
+		patchRequest.AllowedCIDRs = allowedCIDRs(cliCtx)
 	}
 
-	viewClone, err := convertCloneView(clone)
+	if cliCtx.IsSet(cloneLabelFlag) {
+		if patchRequest.Labels, err = commands.ParseLabels(cliCtx.StringSlice(cloneLabelFlag)); err != nil {
+			return err
+		}
+	}
+
+	clone, err := dblabClient.PatchClone(cliCtx.Context, cloneID, patchRequest)
 	if err != nil {
 		return err
 	}
 
-	commandResponse, err := json.MarshalIndent(viewClone, "", "    ")
+	viewClone, err := convertCloneView(clone)
 	if err != nil {
 		return err
 	}
 
-	_, err = fmt.Fprintln(cliCtx.App.Writer, string(commandResponse))
+	return commands.PrintTable(cliCtx, viewClone, cloneTable(viewClone))
+}
 
-	return err
+// allowedCIDRs returns client CIDRs of the flag skipping empty values, so an empty value allows all clients.
+func allowedCIDRs(cliCtx *cli.Context) []string {
+	cidrs := []string{}
+
+	for _, cidr := range cliCtx.StringSlice(cloneAllowedCIDRFlag) {
+		if cidr != "" {
+			cidrs = append(cidrs, cidr)
+		}
+	}
+
+	return cidrs
 }
 
 func convertCloneView(clone *models.Clone) (*models.CloneView, error) {
@@ -200,6 +233,23 @@ func reset(cliCtx *cli.Context) error {
 		SnapshotID: cliCtx.String(cloneResetSnapshotIDFlag),
 	}
 
+	if cliCtx.IsSet(cloneInitProfileFlag) {
+		initProfile := cliCtx.String(cloneInitProfileFlag)
+		resetOptions.InitProfile = &initProfile
+	}
+
+	if hasBulkFilters(cliCtx) {
+		result, err := dblabClient.ResetClones(cliCtx.Context, types.BulkResetRequest{
+			BulkCloneRequest:  bulkRequest(cliCtx),
+			ResetCloneRequest: resetOptions,
+		})
+		if err != nil {
+			return err
+		}
+
+		return printBulkResult(cliCtx, result)
+	}
+
 	if cliCtx.Bool("async") {
 		err = dblabClient.ResetCloneAsync(cliCtx.Context, cloneID, resetOptions)
 	} else {
@@ -222,6 +272,15 @@ func destroy(cliCtx *cli.Context) error {
 		return err
 	}
 
+	if hasBulkFilters(cliCtx) {
+		result, err := dblabClient.DestroyClones(cliCtx.Context, bulkRequest(cliCtx))
+		if err != nil {
+			return err
+		}
+
+		return printBulkResult(cliCtx, result)
+	}
+
 	cloneID := cliCtx.Args().First()
 
 	if cliCtx.Bool("async") {
@@ -239,6 +298,255 @@ func destroy(cliCtx *cli.Context) error {
 	return err
 }
 
+// stop runs a request to stop the clone container keeping its data.
+func stop(cliCtx *cli.Context) error {
+	dblabClient, err := commands.ClientByCLIContext(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	cloneID := cliCtx.Args().First()
+
+	if err := dblabClient.StopClone(cliCtx.Context, cloneID); err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(cliCtx.App.Writer, "The clone has been successfully stopped: %s\n", cloneID)
+
+	return err
+}
+
+// start runs a request to start a stopped clone.
+func start(cliCtx *cli.Context) error {
+	dblabClient, err := commands.ClientByCLIContext(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	cloneID := cliCtx.Args().First()
+
+	if cliCtx.Bool("async") {
+		if err := dblabClient.StartCloneAsync(cliCtx.Context, cloneID); err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintf(cliCtx.App.Writer, "The clone is being started: %s\n", cloneID)
+
+		return err
+	}
+
+	clone, err := dblabClient.StartClone(cliCtx.Context, cloneID)
+	if err != nil {
+		return err
+	}
+
+	viewClone, err := convertCloneView(clone)
+	if err != nil {
+		return err
+	}
+
+	return commands.PrintTable(cliCtx, viewClone, cloneTable(viewClone))
+}
+
+// createSavepoint runs a request to save the current state of the clone data.
+func createSavepoint(cliCtx *cli.Context) error {
+	dblabClient, err := commands.ClientByCLIContext(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	savepoint, err := dblabClient.CreateSavepoint(cliCtx.Context, cliCtx.Args().First(), types.SavepointCreateRequest{
+		Name: cliCtx.String("name"),
+	})
+	if err != nil {
+		return err
+	}
+
+	return commands.PrintTable(cliCtx, savepoint, savepointTable(*savepoint))
+}
+
+// rollbackSavepoint runs a request to roll the clone back to the savepoint.
+func rollbackSavepoint(cliCtx *cli.Context) error {
+	dblabClient, err := commands.ClientByCLIContext(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	cloneID := cliCtx.Args().First()
+	rollbackRequest := types.SavepointRollbackRequest{Name: cliCtx.String("name")}
+
+	if cliCtx.Bool("async") {
+		if err := dblabClient.RollbackSavepointAsync(cliCtx.Context, cloneID, rollbackRequest); err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintf(cliCtx.App.Writer, "The clone is being rolled back to savepoint %s: %s\n", rollbackRequest.Name, cloneID)
+
+		return err
+	}
+
+	clone, err := dblabClient.RollbackSavepoint(cliCtx.Context, cloneID, rollbackRequest)
+	if err != nil {
+		return err
+	}
+
+	viewClone, err := convertCloneView(clone)
+	if err != nil {
+		return err
+	}
+
+	return commands.PrintTable(cliCtx, viewClone, cloneTable(viewClone))
+}
+
+// upgrade runs a request to upgrade the clone to a new Postgres major version.
+func upgrade(cliCtx *cli.Context) error {
+	dblabClient, err := commands.ClientByCLIContext(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	cloneID := cliCtx.Args().First()
+	upgradeRequest := types.CloneUpgradeRequest{DockerImage: cliCtx.String("docker-image")}
+
+	if cliCtx.Bool("async") {
+		if err := dblabClient.UpgradeCloneAsync(cliCtx.Context, cloneID, upgradeRequest); err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintf(cliCtx.App.Writer, "The clone is being upgraded using image %s: %s\n", upgradeRequest.DockerImage, cloneID)
+
+		return err
+	}
+
+	cloneUpgrade, err := dblabClient.UpgradeClone(cliCtx.Context, cloneID, upgradeRequest)
+	if err != nil {
+		if cloneUpgrade == nil {
+			return err
+		}
+
+		if printErr := commands.PrintTable(cliCtx, cloneUpgrade, upgradeTable(cloneUpgrade)); printErr != nil {
+			return printErr
+		}
+
+		return err
+	}
+
+	return commands.PrintTable(cliCtx, cloneUpgrade, upgradeTable(cloneUpgrade))
+}
+
+// upgradeReport runs a request to get the report of the last clone upgrade.
+func upgradeReport(cliCtx *cli.Context) error {
+	dblabClient, err := commands.ClientByCLIContext(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	cloneUpgrade, err := dblabClient.GetCloneUpgrade(cliCtx.Context, cliCtx.Args().First())
+	if err != nil {
+		return err
+	}
+
+	return commands.PrintTable(cliCtx, cloneUpgrade, upgradeTable(cloneUpgrade))
+}
+
+// listSavepoints runs a request to list savepoints of the clone.
+func listSavepoints(cliCtx *cli.Context) error {
+	dblabClient, err := commands.ClientByCLIContext(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	savepoints, err := dblabClient.ListSavepoints(cliCtx.Context, cliCtx.Args().First())
+	if err != nil {
+		return err
+	}
+
+	return commands.PrintTable(cliCtx, savepoints, savepointTable(savepoints...))
+}
+
+// addUser runs a request to add a database user to the clone.
+func addUser(cliCtx *cli.Context) error {
+	dblabClient, err := commands.ClientByCLIContext(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	user, err := dblabClient.CreateCloneUser(cliCtx.Context, cliCtx.Args().First(), types.CloneUserRequest{
+		Username: cliCtx.String("username"),
+		Password: cliCtx.String("password"),
+		Role:     models.UserRole(cliCtx.String(cloneUserRoleFlag)),
+		DBName:   cliCtx.String("db-name"),
+		Grants:   cliCtx.StringSlice(cloneUserGrantFlag),
+	})
+	if err != nil {
+		return err
+	}
+
+	return commands.PrintTable(cliCtx, user, userTable(*user))
+}
+
+// listUsers runs a request to list database users of the clone.
+func listUsers(cliCtx *cli.Context) error {
+	dblabClient, err := commands.ClientByCLIContext(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	users, err := dblabClient.ListCloneUsers(cliCtx.Context, cliCtx.Args().First())
+	if err != nil {
+		return err
+	}
+
+	return commands.PrintTable(cliCtx, users, userTable(users...))
+}
+
+// deleteUser runs a request to drop a database user added to the clone.
+func deleteUser(cliCtx *cli.Context) error {
+	dblabClient, err := commands.ClientByCLIContext(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	cloneID := cliCtx.Args().First()
+	username := cliCtx.String("username")
+
+	if err := dblabClient.DeleteCloneUser(cliCtx.Context, cloneID, username); err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(cliCtx.App.Writer, "User %s has been deleted from clone %s\n", username, cloneID)
+
+	return err
+}
+
+func bulkRequest(cliCtx *cli.Context) types.BulkCloneRequest {
+	bulkRequest := types.BulkCloneRequest{
+		Selector: strings.Join(cliCtx.StringSlice(cloneSelectorFlag), ","),
+		Status:   cliCtx.StringSlice(cloneStatusFlag),
+		Force:    cliCtx.Bool("force"),
+		DryRun:   cliCtx.Bool("dry-run"),
+	}
+
+	if cliCtx.IsSet(cloneOlderThanFlag) {
+		bulkRequest.OlderThan = cliCtx.Duration(cloneOlderThanFlag).String()
+	}
+
+	return bulkRequest
+}
+
+// printBulkResult prints results of a bulk operation and fails if the operation has failed on any clone.
+func printBulkResult(cliCtx *cli.Context, result *models.BulkResult) error {
+	if err := commands.Print(cliCtx, result); err != nil {
+		return err
+	}
+
+	if result.Failed > 0 {
+		return commands.NewActionError(fmt.Sprintf("operation failed on %d clone(s)", result.Failed))
+	}
+
+	return nil
+}
+
 // startObservation runs a request to startObservation clone.
 func startObservation(cliCtx *cli.Context) error {
 	dblabClient, err := commands.ClientByCLIContext(cliCtx)
@@ -266,14 +574,7 @@ func startObservation(cliCtx *cli.Context) error {
 		return err
 	}
 
-	commandResponse, err := json.MarshalIndent(session, "", "    ")
-	if err != nil {
-		return err
-	}
-
-	_, err = fmt.Fprintln(cliCtx.App.Writer, string(commandResponse))
-
-	return err
+	return commands.Print(cliCtx, session)
 }
 
 // stopObservation shows observing summary and check satisfaction of performance requirements.
@@ -290,14 +591,7 @@ func stopObservation(cliCtx *cli.Context) error {
 		return err
 	}
 
-	commandResponse, err := json.MarshalIndent(result, "", "    ")
-	if err != nil {
-		return err
-	}
-
-	_, err = fmt.Fprintln(cliCtx.App.Writer, string(commandResponse))
-
-	return err
+	return commands.Print(cliCtx, result)
 }
 
 // summaryObservation returns the observing summary artifact.
@@ -315,14 +609,7 @@ func summaryObservation(cliCtx *cli.Context) error {
 		return err
 	}
 
-	commandResponse, err := json.MarshalIndent(result, "", "    ")
-	if err != nil {
-		return err
-	}
-
-	_, err = fmt.Fprintln(cliCtx.App.Writer, string(commandResponse))
-
-	return err
+	return commands.Print(cliCtx, result)
 }
 
 func downloadArtifact(cliCtx *cli.Context) error {
@@ -334,7 +621,7 @@ func downloadArtifact(cliCtx *cli.Context) error {
 	cloneID := cliCtx.String("clone-id")
 	sessionID := cliCtx.String("session-id")
 	artifactType := cliCtx.String("artifact-type")
-	outputPath := cliCtx.String("output")
+	outputPath := cliCtx.String(artifactOutputFileFlag)
 
 	body, err := dblabClient.DownloadArtifact(cliCtx.Context, cloneID, sessionID, artifactType)
 	if err != nil {
@@ -380,16 +667,16 @@ func forward(cliCtx *cli.Context) error {
 
 	wg := &sync.WaitGroup{}
 
-	port, err := retrieveClonePort(cliCtx, wg, remoteURL)
+	clone, err := retrieveClone(cliCtx, wg, remoteURL)
 	if err != nil {
 		return err
 	}
 
 	wg.Wait()
 
-	log.Dbg(fmt.Sprintf("The clone port has been retrieved: %s", port))
+	log.Dbg(fmt.Sprintf("The clone port has been retrieved: %s", clone.DB.Port))
 
-	remoteURL.Host = commands.BuildHostname(remoteURL.Hostname(), port)
+	remoteURL.Host = commands.BuildHostname(remoteURL.Hostname(), clone.DB.Port)
 
 	tunnel, err := commands.BuildTunnel(cliCtx, remoteURL)
 	if err != nil {
@@ -405,14 +692,15 @@ func forward(cliCtx *cli.Context) error {
 	return tunnel.Listen(cliCtx.Context)
 }
 
-func retrieveClonePort(cliCtx *cli.Context, wg *sync.WaitGroup, remoteHost *url.URL) (string, error) {
+// retrieveClone gets the clone info through an SSH tunnel to the Database Lab API.
+func retrieveClone(cliCtx *cli.Context, wg *sync.WaitGroup, remoteHost *url.URL) (*models.Clone, error) {
 	tunnel, err := commands.BuildTunnel(cliCtx, remoteHost)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	if err := tunnel.Open(); err != nil {
-		return "", err
+		return nil, err
 	}
 
 	const goroutineCount = 1
@@ -435,19 +723,14 @@ func retrieveClonePort(cliCtx *cli.Context, wg *sync.WaitGroup, remoteHost *url.
 		}
 	}()
 
-	log.Dbg("Retrieving clone port")
+	log.Dbg("Retrieving clone info")
 
 	dblabClient, err := commands.ClientByCLIContext(cliCtx)
 	if err != nil {
-		return "", err
-	}
-
-	clone, err := dblabClient.GetClone(cliCtx.Context, cliCtx.Args().First())
-	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return clone.DB.Port, nil
+	return dblabClient.GetClone(cliCtx.Context, cliCtx.Args().First())
 }
 
 func splitFlags(flags []string) map[string]string {
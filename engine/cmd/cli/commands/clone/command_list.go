@@ -13,6 +13,16 @@ import (
 const (
 	cloneResetLatestFlag     = "latest"
 	cloneResetSnapshotIDFlag = "snapshot-id"
+	cloneLabelFlag           = "label"
+	cloneAllowedCIDRFlag     = "allowed-cidr"
+	cloneProtectedFlag       = "protected"
+	cloneSelectorFlag        = "selector"
+	cloneStatusFlag          = "status"
+	cloneOlderThanFlag       = "older-than"
+	cloneUserRoleFlag        = "role"
+	cloneUserGrantFlag       = "grant"
+	cloneInitProfileFlag     = "init-profile"
+	artifactOutputFileFlag   = "output-file"
 )
 
 // CommandList returns available commands for a clones management.
@@ -23,8 +33,51 @@ func CommandList() []*cli.Command {
 		Subcommands: []*cli.Command{
 			{
 				Name:   "list",
-				Usage:  "list all existing clones",
+				Usage:  "list existing clones",
 				Action: list,
+				Flags: []cli.Flag{
+					&cli.StringSliceFlag{
+						Name:    "selector",
+						Usage:   "filter clones by labels. An example: team=billing,env!=production",
+						Aliases: []string{"l"},
+					},
+					&cli.StringSliceFlag{
+						Name:  "status",
+						Usage: "filter clones by status. An example: OK",
+					},
+					&cli.StringFlag{
+						Name:  "snapshot-id",
+						Usage: "filter clones by snapshot ID",
+					},
+					&cli.StringFlag{
+						Name:  "pool",
+						Usage: "filter clones by pool",
+					},
+					&cli.StringFlag{
+						Name:  "creator",
+						Usage: "filter clones by database user",
+					},
+					&cli.DurationFlag{
+						Name:  "min-age",
+						Usage: "show clones older than the duration. An example: 24h",
+					},
+					&cli.DurationFlag{
+						Name:  "max-age",
+						Usage: "show clones younger than the duration. An example: 1h",
+					},
+					&cli.StringFlag{
+						Name:  "sort",
+						Usage: "sort clones by field: created_at, id, status, snapshot, diff_size. Prefix it with '-' for descending order",
+					},
+					&cli.IntFlag{
+						Name:  "limit",
+						Usage: "maximum number of clones to show (by default, all matching clones are shown)",
+					},
+					&cli.IntFlag{
+						Name:  "offset",
+						Usage: "number of clones to skip",
+					},
+				},
 			},
 			{
 				Name:      "status",
@@ -78,6 +131,22 @@ func CommandList() []*cli.Command {
 						Name:  "extra-config",
 						Usage: "set an extra database configuration for the clone. An example: statement_timeout='1s'",
 					},
+					&cli.StringSliceFlag{
+						Name:  cloneLabelFlag,
+						Usage: "set a label of the clone. An example: team=billing",
+					},
+					&cli.StringFlag{
+						Name:  "docker-image",
+						Usage: "Docker image of the clone allowed by the server configuration (optional)",
+					},
+					&cli.StringSliceFlag{
+						Name:  cloneAllowedCIDRFlag,
+						Usage: "allow connections to the clone only from the loopback client CIDR. An example: 127.0.0.1/32",
+					},
+					&cli.StringFlag{
+						Name:  cloneInitProfileFlag,
+						Usage: "init profile of the server configuration run after the clone starts and after each reset (optional)",
+					},
 				},
 			},
 			{
@@ -88,19 +157,27 @@ func CommandList() []*cli.Command {
 				Action:    update,
 				Flags: []cli.Flag{
 					&cli.BoolFlag{
-						Name:    "protected",
+						Name:    cloneProtectedFlag,
 						Usage:   "mark instance as protected from deletion",
 						Aliases: []string{"p"},
 					},
+					&cli.StringSliceFlag{
+						Name:  cloneLabelFlag,
+						Usage: "replace labels of the clone. An example: team=billing",
+					},
+					&cli.StringSliceFlag{
+						Name:  cloneAllowedCIDRFlag,
+						Usage: `replace client CIDRs allowed to connect to the clone. Use --allowed-cidr="" to allow all clients`,
+					},
 				},
 			},
 			{
 				Name:      "reset",
-				Usage:     "reset clone's state",
-				ArgsUsage: "CLONE_ID",
-				Before:    checkCloneIDBefore,
+				Usage:     "reset clone's state, or reset all clones matching the filters",
+				ArgsUsage: "[CLONE_ID]",
+				Before:    checkCloneIDOrFiltersBefore,
 				Action:    reset,
-				Flags: []cli.Flag{
+				Flags: append(bulkFlags(),
 					&cli.BoolFlag{
 						Name:    "async",
 						Usage:   "run the command asynchronously",
@@ -114,15 +191,137 @@ func CommandList() []*cli.Command {
 						Name:  cloneResetSnapshotIDFlag,
 						Usage: "snapshot ID used when resetting clone's state",
 					},
+					&cli.StringFlag{
+						Name:  cloneInitProfileFlag,
+						Usage: "init profile replacing the profile of the clone, an empty value removes the profile; the current profile runs by default",
+					},
+				),
+			},
+			{
+				Name:      "stop",
+				Usage:     "stop clone's container keeping its data and port, the clone can be started later",
+				ArgsUsage: "CLONE_ID",
+				Before:    checkCloneIDBefore,
+				Action:    stop,
+			},
+			{
+				Name:      "start",
+				Usage:     "start a stopped clone",
+				ArgsUsage: "CLONE_ID",
+				Before:    checkCloneIDBefore,
+				Action:    start,
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:    "async",
+						Usage:   "run the command asynchronously",
+						Aliases: []string{"a"},
+					},
 				},
 			},
 			{
-				Name:      "destroy",
-				Usage:     "destroy clone",
+				Name:      "savepoint",
+				Usage:     "save the current state of the clone data",
 				ArgsUsage: "CLONE_ID",
 				Before:    checkCloneIDBefore,
-				Action:    destroy,
+				Action:    createSavepoint,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "name",
+						Usage: "savepoint name, the current time is used by default",
+					},
+				},
+			},
+			{
+				Name:      "rollback",
+				Usage:     "roll the clone back to a savepoint, savepoints created after it are destroyed",
+				ArgsUsage: "CLONE_ID",
+				Before:    checkCloneIDBefore,
+				Action:    rollbackSavepoint,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "name",
+						Usage:    "savepoint name",
+						Required: true,
+					},
+					&cli.BoolFlag{
+						Name:    "async",
+						Usage:   "run the command asynchronously",
+						Aliases: []string{"a"},
+					},
+				},
+			},
+			{
+				Name:      "savepoints",
+				Usage:     "list savepoints of the clone",
+				ArgsUsage: "CLONE_ID",
+				Before:    checkCloneIDBefore,
+				Action:    listSavepoints,
+			},
+			{
+				Name:      "add-user",
+				Usage:     "add a database user to the clone, the user is recreated after the clone reset",
+				ArgsUsage: "CLONE_ID",
+				Before:    checkCloneIDBefore,
+				Action:    addUser,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "username",
+						Usage:    "database username",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:     "password",
+						Usage:    "database password",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:  cloneUserRoleFlag,
+						Usage: "role preset: superuser, restricted, readonly or custom",
+						Value: "readonly",
+					},
+					&cli.StringFlag{
+						Name:  "db-name",
+						Usage: "database the user connects to, grants of the custom role are executed in it",
+					},
+					&cli.StringSliceFlag{
+						Name:  cloneUserGrantFlag,
+						Usage: "SQL statement granting privileges to the user with the custom role, can be repeated",
+					},
+				},
+			},
+			{
+				Name:      "users",
+				Usage:     "list database users of the clone",
+				ArgsUsage: "CLONE_ID",
+				Before:    checkCloneIDBefore,
+				Action:    listUsers,
+			},
+			{
+				Name:      "delete-user",
+				Usage:     "drop a database user added to the clone",
+				ArgsUsage: "CLONE_ID",
+				Before:    checkCloneIDBefore,
+				Action:    deleteUser,
 				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "username",
+						Usage:    "database username",
+						Required: true,
+					},
+				},
+			},
+			{
+				Name:      "upgrade",
+				Usage:     "upgrade the clone to a new Postgres major version with pg_upgrade",
+				ArgsUsage: "CLONE_ID",
+				Before:    checkCloneIDBefore,
+				Action:    upgrade,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "docker-image",
+						Usage:    "Docker image providing the new Postgres major version",
+						Required: true,
+					},
 					&cli.BoolFlag{
 						Name:    "async",
 						Usage:   "run the command asynchronously",
@@ -130,6 +329,27 @@ func CommandList() []*cli.Command {
 					},
 				},
 			},
+			{
+				Name:      "upgrade-report",
+				Usage:     "show timings and pg_upgrade --check output of the last clone upgrade",
+				ArgsUsage: "CLONE_ID",
+				Before:    checkCloneIDBefore,
+				Action:    upgradeReport,
+			},
+			{
+				Name:      "destroy",
+				Usage:     "destroy clone, or destroy all clones matching the filters",
+				ArgsUsage: "[CLONE_ID]",
+				Before:    checkCloneIDOrFiltersBefore,
+				Action:    destroy,
+				Flags: append(bulkFlags(),
+					&cli.BoolFlag{
+						Name:    "async",
+						Usage:   "run the command asynchronously",
+						Aliases: []string{"a"},
+					},
+				),
+			},
 			{
 				Name:      "start-observation",
 				Usage:     "[EXPERIMENTAL] start clone state monitoring",
@@ -211,9 +431,47 @@ func CommandList() []*cli.Command {
 						Required: true,
 					},
 					&cli.StringFlag{
-						Name:    "output",
-						Usage:   "write an artifact to file (optional)",
-						Aliases: []string{"o"},
+						Name:  artifactOutputFileFlag,
+						Usage: "write an artifact to file (optional)",
+					},
+				},
+			},
+			{
+				Name:      "connect",
+				Aliases:   []string{"psql"},
+				Usage:     "connect to clone with psql, using an SSH tunnel if the forwarding server is configured",
+				ArgsUsage: "CLONE_ID [-- PSQL_ARGS]",
+				Before:    checkCloneIDBefore,
+				Action:    connect,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "username",
+						Usage: "database username (by default, the user of the clone)",
+					},
+					&cli.StringFlag{
+						Name:    "password",
+						Usage:   "database password",
+						EnvVars: []string{"PGPASSWORD"},
+					},
+					&cli.StringFlag{
+						Name:  "db-name",
+						Usage: "database name (by default, the database of the clone or postgres)",
+					},
+					&cli.BoolFlag{
+						Name:  connectDSNFlag,
+						Usage: "print the connection URI instead of running psql",
+					},
+					&cli.BoolFlag{
+						Name:  connectPgpassFlag,
+						Usage: "write the connection password to the password file (PGPASSFILE or ~/.pgpass)",
+					},
+					&cli.StringFlag{
+						Name:  connectServiceFlag,
+						Usage: "write the connection to the service file (PGSERVICEFILE or ~/.pg_service.conf) with the given service name",
+					},
+					&cli.StringFlag{
+						Name:  connectLocalPortFlag,
+						Usage: "local port of the SSH tunnel (by default, a random free port)",
 					},
 				},
 			},
@@ -233,6 +491,33 @@ func CommandList() []*cli.Command {
 	}}
 }
 
+// bulkFlags returns flags selecting clones of bulk operations.
+func bulkFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:    cloneSelectorFlag,
+			Usage:   "select clones by labels. An example: team=billing,env!=production",
+			Aliases: []string{"l"},
+		},
+		&cli.StringSliceFlag{
+			Name:  cloneStatusFlag,
+			Usage: "select clones by status. An example: FATAL",
+		},
+		&cli.DurationFlag{
+			Name:  cloneOlderThanFlag,
+			Usage: "select clones created earlier than the duration ago. An example: 2h",
+		},
+		&cli.BoolFlag{
+			Name:  "force",
+			Usage: "include protected clones",
+		},
+		&cli.BoolFlag{
+			Name:  "dry-run",
+			Usage: "show selected clones without running the operation",
+		},
+	}
+}
+
 func checkCloneIDBefore(c *cli.Context) error {
 	if c.NArg() == 0 {
 		return commands.NewActionError("CLONE_ID argument is required")
@@ -240,3 +525,19 @@ func checkCloneIDBefore(c *cli.Context) error {
 
 	return nil
 }
+
+func checkCloneIDOrFiltersBefore(c *cli.Context) error {
+	if hasBulkFilters(c) {
+		if c.NArg() > 0 {
+			return commands.NewActionError("CLONE_ID argument must not be used together with clone filters")
+		}
+
+		return nil
+	}
+
+	return checkCloneIDBefore(c)
+}
+
+func hasBulkFilters(c *cli.Context) bool {
+	return c.IsSet(cloneSelectorFlag) || c.IsSet(cloneStatusFlag) || c.IsSet(cloneOlderThanFlag)
+}
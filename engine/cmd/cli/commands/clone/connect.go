@@ -0,0 +1,460 @@
+package clone
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+
+	"gitlab.com/postgres-ai/database-lab/v3/cmd/cli/commands"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/portfwd"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+const (
+	connectDSNFlag       = "dsn"
+	connectPgpassFlag    = "pgpass"
+	connectServiceFlag   = "service"
+	connectLocalPortFlag = "local-port"
+
+	psqlBinary        = "psql"
+	defaultDBName     = "postgres"
+	pgpassFilename    = ".pgpass"
+	pgServiceFilename = ".pg_service.conf"
+	pgpassFileMode    = 0600
+	pgServiceFileMode = 0644
+	randomLocalPort   = "0"
+)
+
+// connParams describes the connection to a clone.
+type connParams struct {
+	host     string
+	port     string
+	username string
+	password string
+	dbName   string
+}
+
+// DSN returns the connection URI.
+func (p connParams) DSN() string {
+	dsn := url.URL{
+		Scheme: "postgresql",
+		Host:   commands.BuildHostname(p.host, p.port),
+		Path:   "/" + p.dbName,
+	}
+
+	if p.password != "" {
+		dsn.User = url.UserPassword(p.username, p.password)
+	} else {
+		dsn.User = url.User(p.username)
+	}
+
+	return dsn.String()
+}
+
+func (p connParams) withoutPassword() connParams {
+	p.password = ""
+
+	return p
+}
+
+// connect opens a connection to a clone: runs psql, prints DSN or writes the connection to the libpq files.
+func connect(cliCtx *cli.Context) error {
+	if err := checkConnectModes(cliCtx); err != nil {
+		return err
+	}
+
+	clone, err := getCloneToConnect(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	params := connParams{
+		host:     clone.DB.Host,
+		port:     clone.DB.Port,
+		username: clone.DB.Username,
+		password: cliCtx.String("password"),
+		dbName:   cliCtx.String("db-name"),
+	}
+
+	if params.dbName == "" {
+		params.dbName = clone.DB.DBName
+	}
+
+	if params.dbName == "" {
+		params.dbName = defaultDBName
+	}
+
+	if cliCtx.String("username") != "" {
+		params.username = cliCtx.String("username")
+	}
+
+	if params.host == "" {
+		remoteURL, err := url.Parse(cliCtx.String(commands.URLKey))
+		if err != nil {
+			return err
+		}
+
+		params.host = remoteURL.Hostname()
+	}
+
+	if cliCtx.String(commands.FwServerURLKey) == "" {
+		return connectClone(cliCtx, params)
+	}
+
+	tunnel, err := openCloneTunnel(cliCtx, params)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(cliCtx.Context)
+	wg := &sync.WaitGroup{}
+
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		if err := tunnel.Listen(ctx); err != nil && ctx.Err() == nil {
+			log.Dbg("The tunnel has been closed: ", err)
+		}
+	}()
+
+	defer func() {
+		cancel()
+
+		if err := tunnel.Stop(); err != nil {
+			log.Err("Failed to stop the tunnel: ", err)
+		}
+
+		wg.Wait()
+	}()
+
+	localHost, localPort, err := net.SplitHostPort(tunnel.Endpoints.Local)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse the tunnel address")
+	}
+
+	params.host = localHost
+	params.port = localPort
+
+	if err := connectClone(cliCtx, params); err != nil {
+		return err
+	}
+
+	if isPsqlMode(cliCtx) {
+		return nil
+	}
+
+	log.Msg(fmt.Sprintf("The clone is available by address: %s. Press Ctrl+C to close the tunnel", tunnel.Endpoints.Local))
+
+	waitCtx, stop := signal.NotifyContext(cliCtx.Context, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	<-waitCtx.Done()
+
+	return nil
+}
+
+func checkConnectModes(cliCtx *cli.Context) error {
+	modes := 0
+
+	for _, flag := range []string{connectDSNFlag, connectPgpassFlag, connectServiceFlag} {
+		if cliCtx.IsSet(flag) {
+			modes++
+		}
+	}
+
+	if modes > 1 {
+		return commands.NewActionError("only one of --dsn, --pgpass and --service can be used")
+	}
+
+	if cliCtx.Bool(connectPgpassFlag) && cliCtx.String("password") == "" {
+		return commands.NewActionError("--password is required to write a .pgpass entry")
+	}
+
+	return nil
+}
+
+func isPsqlMode(cliCtx *cli.Context) bool {
+	return !cliCtx.Bool(connectDSNFlag) && !cliCtx.Bool(connectPgpassFlag) && cliCtx.String(connectServiceFlag) == ""
+}
+
+func getCloneToConnect(cliCtx *cli.Context) (*models.Clone, error) {
+	if cliCtx.String(commands.FwServerURLKey) == "" {
+		dblabClient, err := commands.ClientByCLIContext(cliCtx)
+		if err != nil {
+			return nil, err
+		}
+
+		return dblabClient.GetClone(cliCtx.Context, cliCtx.Args().First())
+	}
+
+	remoteURL, err := url.Parse(cliCtx.String(commands.URLKey))
+	if err != nil {
+		return nil, err
+	}
+
+	wg := &sync.WaitGroup{}
+
+	clone, err := retrieveClone(cliCtx, wg, remoteURL)
+	if err != nil {
+		return nil, err
+	}
+
+	wg.Wait()
+
+	return clone, nil
+}
+
+func openCloneTunnel(cliCtx *cli.Context, params connParams) (*portfwd.SSHTunnel, error) {
+	remoteURL, err := url.Parse(cliCtx.String(commands.URLKey))
+	if err != nil {
+		return nil, err
+	}
+
+	remoteURL.Host = commands.BuildHostname(remoteURL.Hostname(), params.port)
+
+	localPort := cliCtx.String(connectLocalPortFlag)
+	if localPort == "" {
+		localPort = randomLocalPort
+	}
+
+	tunnel, err := commands.BuildLocalTunnel(cliCtx, remoteURL, localPort)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tunnel.Open(); err != nil {
+		return nil, err
+	}
+
+	return tunnel, nil
+}
+
+func connectClone(cliCtx *cli.Context, params connParams) error {
+	switch {
+	case cliCtx.Bool(connectDSNFlag):
+		_, err := fmt.Fprintln(cliCtx.App.Writer, params.DSN())
+		return err
+
+	case cliCtx.Bool(connectPgpassFlag):
+		filename, err := libpqFilename("PGPASSFILE", pgpassFilename)
+		if err != nil {
+			return err
+		}
+
+		if err := writePgpassEntry(filename, params); err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintf(cliCtx.App.Writer, "The password has been written to %s. Connection URI: %s\n",
+			filename, params.withoutPassword().DSN())
+
+		return err
+
+	case cliCtx.String(connectServiceFlag) != "":
+		filename, err := libpqFilename("PGSERVICEFILE", pgServiceFilename)
+		if err != nil {
+			return err
+		}
+
+		serviceName := cliCtx.String(connectServiceFlag)
+
+		if err := writeServiceEntry(filename, serviceName, params); err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintf(cliCtx.App.Writer, "The service %q has been written to %s. Connect with: psql service=%s\n",
+			serviceName, filename, serviceName)
+
+		return err
+	}
+
+	return runPsql(cliCtx, params)
+}
+
+// runPsql runs psql connected to the clone. Extra command arguments are passed to psql.
+func runPsql(cliCtx *cli.Context, params connParams) error {
+	psqlPath, err := exec.LookPath(psqlBinary)
+	if err != nil {
+		return commands.NewActionError("psql is not found in PATH. Use --dsn to get the connection URI")
+	}
+
+	psqlArgs := cliCtx.Args().Tail()
+	if len(psqlArgs) > 0 && psqlArgs[0] == "--" {
+		psqlArgs = psqlArgs[1:]
+	}
+
+	// Pass the password through the environment to keep it out of the process list.
+	cmd := exec.Command(psqlPath, append([]string{params.withoutPassword().DSN()}, psqlArgs...)...)
+	cmd.Env = os.Environ()
+
+	if params.password != "" {
+		cmd.Env = append(cmd.Env, "PGPASSWORD="+params.password)
+	}
+
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = cliCtx.App.Writer
+	cmd.Stderr = cliCtx.App.ErrWriter
+
+	// psql handles Ctrl+C itself to cancel queries, so the tunnel must keep running.
+	signal.Ignore(os.Interrupt)
+	defer signal.Reset(os.Interrupt)
+
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return commands.NewActionError(fmt.Sprintf("psql exited with code %d", exitErr.ExitCode()))
+		}
+
+		return errors.Wrap(err, "failed to run psql")
+	}
+
+	return nil
+}
+
+// libpqFilename returns the path of a libpq configuration file respecting its environment variable.
+func libpqFilename(envVar, defaultName string) (string, error) {
+	if filename := os.Getenv(envVar); filename != "" {
+		return filename, nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get the home directory")
+	}
+
+	return filepath.Join(homeDir, defaultName), nil
+}
+
+// writePgpassEntry adds the connection to the password file replacing an entry with the same host, port, database and user.
+func writePgpassEntry(filename string, params connParams) error {
+	fields := []string{params.host, params.port, params.dbName, params.username}
+	for i, field := range fields {
+		fields[i] = escapePgpassField(field)
+	}
+
+	prefix := strings.Join(fields, ":") + ":"
+
+	lines, err := readLines(filename)
+	if err != nil {
+		return err
+	}
+
+	entries := make([]string, 0, len(lines)+1)
+
+	for _, line := range lines {
+		if strings.HasPrefix(line, prefix) {
+			continue
+		}
+
+		entries = append(entries, line)
+	}
+
+	entries = append(entries, prefix+escapePgpassField(params.password))
+
+	if err := writeLines(filename, entries, pgpassFileMode); err != nil {
+		return err
+	}
+
+	// libpq ignores the password file if it is accessible by others.
+	if err := os.Chmod(filename, pgpassFileMode); err != nil {
+		return errors.Wrapf(err, "failed to change mode of %s", filename)
+	}
+
+	return nil
+}
+
+func escapePgpassField(field string) string {
+	return strings.NewReplacer(`\`, `\\`, `:`, `\:`).Replace(field)
+}
+
+// writeServiceEntry adds the connection service to the service file replacing a service with the same name.
+func writeServiceEntry(filename, serviceName string, params connParams) error {
+	lines, err := readLines(filename)
+	if err != nil {
+		return err
+	}
+
+	entries := make([]string, 0, len(lines))
+	header := "[" + serviceName + "]"
+	skip := false
+
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+
+		if strings.HasPrefix(trimmed, "[") {
+			skip = trimmed == header
+		}
+
+		if skip {
+			continue
+		}
+
+		entries = append(entries, line)
+	}
+
+	if len(entries) > 0 && strings.TrimSpace(entries[len(entries)-1]) != "" {
+		entries = append(entries, "")
+	}
+
+	entries = append(entries,
+		header,
+		"host="+params.host,
+		"port="+params.port,
+		"user="+params.username,
+		"dbname="+params.dbName,
+	)
+
+	return writeLines(filename, entries, pgServiceFileMode)
+}
+
+func readLines(filename string) ([]string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, errors.Wrapf(err, "failed to open %s", filename)
+	}
+
+	defer func() { _ = file.Close() }()
+
+	lines := []string{}
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrapf(err, "failed to read %s", filename)
+	}
+
+	return lines, nil
+}
+
+// writeLines writes lines to the file. The mode is used for a new file, the mode of an existing file is kept.
+func writeLines(filename string, lines []string, mode os.FileMode) error {
+	content := strings.Join(lines, "\n") + "\n"
+
+	if err := os.WriteFile(filename, []byte(content), mode); err != nil {
+		return errors.Wrapf(err, "failed to write %s", filename)
+	}
+
+	return nil
+}
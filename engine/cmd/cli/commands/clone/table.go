@@ -0,0 +1,171 @@
+package clone
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"gitlab.com/postgres-ai/database-lab/v3/cmd/cli/commands"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+// cloneColumns defines the stable column set of clone tables.
+var cloneColumns = []commands.Column{
+	{Name: "ID"},
+	{Name: "STATUS"},
+	{Name: "DATA STATE AT"},
+	{Name: "DIFF SIZE"},
+	{Name: "PROTECTED"},
+	{Name: "CREATED"},
+	{Name: "SNAPSHOT", Wide: true},
+	{Name: "POOL", Wide: true},
+	{Name: "HOST", Wide: true},
+	{Name: "PORT", Wide: true},
+	{Name: "USER", Wide: true},
+	{Name: "DELETE AT", Wide: true},
+	{Name: "LABELS", Wide: true},
+	{Name: "IMAGE", Wide: true},
+}
+
+// cloneTable builds a table of clones.
+func cloneTable(clones ...*models.CloneView) commands.TableBuilder {
+	return func(wide bool) *commands.Table {
+		table := &commands.Table{Columns: cloneColumns}
+
+		for _, clone := range clones {
+			if clone == nil || clone.Clone == nil {
+				continue
+			}
+
+			snapshotID, pool, dataStateAt := "", "", ""
+
+			if clone.Snapshot != nil && clone.Snapshot.Snapshot != nil {
+				snapshotID = clone.Snapshot.ID
+				pool = clone.Snapshot.Pool
+				dataStateAt = commands.FormatTime(clone.Snapshot.DataStateAt, wide)
+			}
+
+			table.Rows = append(table.Rows, []string{
+				clone.ID,
+				string(clone.Status.Code),
+				dataStateAt,
+				clone.Metadata.CloneDiffSize.String(),
+				strconv.FormatBool(clone.Protected),
+				commands.FormatTime(clone.CreatedAt, wide),
+				snapshotID,
+				pool,
+				clone.DB.Host,
+				clone.DB.Port,
+				clone.DB.Username,
+				commands.FormatTime(clone.DeleteAt, wide),
+				commands.FormatLabels(clone.Labels),
+				clone.DockerImage,
+			})
+		}
+
+		return table
+	}
+}
+
+// savepointColumns defines the column set of savepoint tables.
+var savepointColumns = []commands.Column{
+	{Name: "NAME"},
+	{Name: "CREATED"},
+	{Name: "PHYSICAL SIZE"},
+	{Name: "LOGICAL SIZE"},
+}
+
+// savepointTable builds a table of clone savepoints.
+func savepointTable(savepoints ...models.Savepoint) commands.TableBuilder {
+	return func(wide bool) *commands.Table {
+		table := &commands.Table{Columns: savepointColumns}
+
+		for _, savepoint := range savepoints {
+			table.Rows = append(table.Rows, []string{
+				savepoint.Name,
+				commands.FormatTime(savepoint.CreatedAt, wide),
+				models.Size(savepoint.PhysicalSize).String(),
+				models.Size(savepoint.LogicalSize).String(),
+			})
+		}
+
+		return table
+	}
+}
+
+// userColumns defines the column set of clone user tables.
+var userColumns = []commands.Column{
+	{Name: "NAME"},
+	{Name: "ROLE"},
+	{Name: "DATABASE"},
+	{Name: "PRIMARY"},
+	{Name: "GRANTS", Wide: true},
+}
+
+// userTable builds a table of clone database users.
+func userTable(users ...models.CloneUser) commands.TableBuilder {
+	return func(_ bool) *commands.Table {
+		table := &commands.Table{Columns: userColumns}
+
+		for _, user := range users {
+			table.Rows = append(table.Rows, []string{
+				user.Name,
+				string(user.Role),
+				user.DBName,
+				strconv.FormatBool(user.Primary),
+				strings.Join(user.Grants, "; "),
+			})
+		}
+
+		return table
+	}
+}
+
+// upgradeColumns defines the column set of clone upgrade tables.
+var upgradeColumns = []commands.Column{
+	{Name: "STATUS"},
+	{Name: "FROM"},
+	{Name: "TO"},
+	{Name: "CHECK"},
+	{Name: "UPGRADE"},
+	{Name: "ANALYZE"},
+	{Name: "TOTAL"},
+	{Name: "IMAGE", Wide: true},
+	{Name: "STARTED", Wide: true},
+	{Name: "FINISHED", Wide: true},
+}
+
+// upgradeTable builds a table of the clone upgrade with the pg_upgrade --check output in the summary.
+func upgradeTable(cloneUpgrade *models.CloneUpgrade) commands.TableBuilder {
+	return func(wide bool) *commands.Table {
+		table := &commands.Table{Columns: upgradeColumns}
+
+		if cloneUpgrade.Error != "" {
+			table.Summary = append(table.Summary, "Error: "+cloneUpgrade.Error)
+		}
+
+		if checkOutput := strings.TrimSpace(cloneUpgrade.CheckOutput); checkOutput != "" {
+			table.Summary = append(table.Summary, "pg_upgrade --check output:")
+			table.Summary = append(table.Summary, strings.Split(checkOutput, "\n")...)
+		}
+
+		table.Rows = append(table.Rows, []string{
+			string(cloneUpgrade.Status),
+			cloneUpgrade.FromVersion,
+			cloneUpgrade.ToVersion,
+			formatSeconds(cloneUpgrade.Timings.CheckTime),
+			formatSeconds(cloneUpgrade.Timings.UpgradeTime),
+			formatSeconds(cloneUpgrade.Timings.AnalyzeTime),
+			formatSeconds(cloneUpgrade.Timings.TotalTime),
+			cloneUpgrade.DockerImage,
+			commands.FormatTime(cloneUpgrade.StartedAt, wide),
+			commands.FormatTime(cloneUpgrade.FinishedAt, wide),
+		})
+
+		return table
+	}
+}
+
+func formatSeconds(seconds float64) string {
+	return time.Duration(seconds * float64(time.Second)).Round(time.Millisecond).String()
+}
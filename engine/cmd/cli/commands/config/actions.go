@@ -6,7 +6,6 @@
 package config
 
 import (
-	"encoding/json"
 	"fmt"
 	"sort"
 	"strings"
@@ -107,14 +106,7 @@ func view() func(*cli.Context) error {
 
 		environment.EnvironmentID = environmentID
 
-		commandResponse, err := json.MarshalIndent(environment, "", "    ")
-		if err != nil {
-			return commands.ToActionError(err)
-		}
-
-		_, err = fmt.Fprintln(cliCtx.App.Writer, string(commandResponse))
-
-		return commands.ToActionError(err)
+		return commands.ToActionError(commands.Print(cliCtx, environment))
 	}
 }
 
@@ -273,14 +265,7 @@ func showSettings(cliCtx *cli.Context) error {
 		return commands.ToActionError(err)
 	}
 
-	settings, err := json.MarshalIndent(cfg.Settings, "", "    ")
-	if err != nil {
-		return commands.ToActionError(err)
-	}
-
-	_, err = fmt.Fprintln(cliCtx.App.Writer, string(settings))
-
-	return commands.ToActionError(err)
+	return commands.ToActionError(commands.Print(cliCtx, cfg.Settings))
 }
 
 // updateSettings updates CLI settings.
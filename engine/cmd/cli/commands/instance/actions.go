@@ -7,7 +7,6 @@ package instance
 
 import (
 	"encoding/json"
-	"fmt"
 
 	"github.com/urfave/cli/v2"
 
@@ -35,14 +34,7 @@ func status(cliCtx *cli.Context) error {
 		return err
 	}
 
-	commandResponse, err := json.MarshalIndent(instanceStatusView, "", "    ")
-	if err != nil {
-		return err
-	}
-
-	_, err = fmt.Fprintln(cliCtx.App.Writer, string(commandResponse))
-
-	return err
+	return commands.PrintTable(cliCtx, instanceStatusView, statusTable(instanceStatusView))
 }
 
 // health runs a request to get health info of the instance.
@@ -57,12 +49,5 @@ func health(cliCtx *cli.Context) error {
 		return err
 	}
 
-	commandResponse, err := json.MarshalIndent(engineHealth, "", "    ")
-	if err != nil {
-		return err
-	}
-
-	_, err = fmt.Fprintln(cliCtx.App.Writer, string(commandResponse))
-
-	return err
+	return commands.Print(cliCtx, engineHealth)
 }
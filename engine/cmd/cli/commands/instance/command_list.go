@@ -25,6 +25,90 @@ func CommandList() []*cli.Command {
 					Usage:  "display instance's version",
 					Action: health,
 				},
+				{
+					Name:  "config",
+					Usage: "manage instance's configuration",
+					Subcommands: []*cli.Command{
+						{
+							Name:   "get",
+							Usage:  "display instance's configuration",
+							Action: getConfig,
+							Flags: []cli.Flag{
+								&cli.BoolFlag{
+									Name:  "full",
+									Usage: "display the full configuration in YAML with masked sensitive values",
+								},
+							},
+						},
+						{
+							Name:      "set",
+							Usage:     "update instance's configuration",
+							ArgsUsage: "KEY=VALUE [KEY=VALUE...]",
+							Description: "keys are dotted paths of the configuration, values are parsed as YAML, for example:\n" +
+								"   dblab instance config set retrieval.refresh.timetable=\"0 0 * * 1\" global.debug=true",
+							Action: setConfig,
+						},
+						{
+							Name:   "edit",
+							Usage:  "edit instance's configuration in $EDITOR",
+							Action: editConfig,
+						},
+					},
+				},
+				{
+					Name:  "retrieval",
+					Usage: "manage data retrieval",
+					Subcommands: []*cli.Command{
+						{
+							Name:   "status",
+							Usage:  "display the state of data retrieval",
+							Action: retrievalStatus,
+						},
+					},
+				},
+				{
+					Name:   "test-source",
+					Usage:  "check the connection to the source database",
+					Action: testSource,
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:  "host",
+							Usage: "source database host (default: from the configuration)",
+						},
+						&cli.StringFlag{
+							Name:  "port",
+							Usage: "source database port (default: from the configuration)",
+						},
+						&cli.StringFlag{
+							Name:  "dbname",
+							Usage: "source database name (default: from the configuration)",
+						},
+						&cli.StringFlag{
+							Name:  "username",
+							Usage: "source database user (default: from the configuration)",
+						},
+						&cli.StringFlag{
+							Name:  "password",
+							Usage: "source database password (default: from the configuration)",
+						},
+						&cli.StringSliceFlag{
+							Name:  "db-list",
+							Usage: "databases to check (default: from the configuration)",
+						},
+					},
+				},
+				{
+					Name:   "logs",
+					Usage:  "display instance's logs",
+					Action: logs,
+					Flags: []cli.Flag{
+						&cli.BoolFlag{
+							Name:    "follow",
+							Aliases: []string{"f"},
+							Usage:   "keep streaming new log lines",
+						},
+					},
+				},
 			},
 		},
 	}
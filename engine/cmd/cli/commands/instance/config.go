@@ -0,0 +1,280 @@
+package instance
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+
+	"gitlab.com/postgres-ai/database-lab/v3/cmd/cli/commands"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/client/dblabapi"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+const (
+	defaultEditor      = "vi"
+	configFilePattern  = "dblab-config-*.yaml"
+	errorCommentPrefix = "# "
+	configEditHeader   = "# Edit the configuration of the Database Lab instance. Lines starting with '#' are ignored.\n" +
+		"# The configuration is applied when the editor is closed. An unchanged file aborts editing.\n"
+)
+
+// getConfig prints the projected configuration of the instance.
+func getConfig(cliCtx *cli.Context) error {
+	dblabClient, err := commands.ClientByCLIContext(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	if cliCtx.Bool("full") {
+		cfg, err := dblabClient.AdminConfigYaml(cliCtx.Context)
+		if err != nil {
+			return err
+		}
+
+		_, err = cliCtx.App.Writer.Write(cfg)
+
+		return err
+	}
+
+	cfg, err := dblabClient.AdminConfig(cliCtx.Context)
+	if err != nil {
+		return err
+	}
+
+	return commands.Print(cliCtx, cfg)
+}
+
+// setConfig updates values of the projected configuration defined as key.path=value pairs.
+func setConfig(cliCtx *cli.Context) error {
+	if cliCtx.NArg() == 0 {
+		return commands.NewActionError("at least one KEY=VALUE pair is required, for example: retrieval.refresh.timetable=\"0 0 * * *\"")
+	}
+
+	dblabClient, err := commands.ClientByCLIContext(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := dblabClient.AdminConfig(cliCtx.Context)
+	if err != nil {
+		return err
+	}
+
+	for _, arg := range cliCtx.Args().Slice() {
+		path, rawValue, found := strings.Cut(arg, "=")
+		if !found || path == "" {
+			return commands.ActionErrorf("invalid pair %q, expected KEY=VALUE", arg)
+		}
+
+		var value interface{}
+
+		if err := yaml.Unmarshal([]byte(rawValue), &value); err != nil {
+			return commands.ActionErrorf("invalid value of %q: %v", path, err)
+		}
+
+		if err := setConfigValue(cfg, path, value); err != nil {
+			return err
+		}
+	}
+
+	applied, err := applyConfig(cliCtx, dblabClient, cfg)
+	if err != nil {
+		return err
+	}
+
+	return commands.Print(cliCtx, applied)
+}
+
+// setConfigValue sets the value of the nested configuration map by the dotted path.
+func setConfigValue(cfg map[string]interface{}, path string, value interface{}) error {
+	keys := strings.Split(path, ".")
+	node := cfg
+
+	for i, key := range keys[:len(keys)-1] {
+		if key == "" {
+			return commands.ActionErrorf("invalid key %q", path)
+		}
+
+		child, ok := node[key]
+		if !ok || child == nil {
+			nested := map[string]interface{}{}
+			node[key] = nested
+			node = nested
+
+			continue
+		}
+
+		nested, ok := child.(map[string]interface{})
+		if !ok {
+			return commands.ActionErrorf("cannot set %q: %q is not an object", path, strings.Join(keys[:i+1], "."))
+		}
+
+		node = nested
+	}
+
+	lastKey := keys[len(keys)-1]
+	if lastKey == "" {
+		return commands.ActionErrorf("invalid key %q", path)
+	}
+
+	node[lastKey] = value
+
+	return nil
+}
+
+// editConfig opens the projected configuration in the editor and applies the result.
+// The editor is reopened with the error on top until the configuration passes validation or the file is left unchanged.
+func editConfig(cliCtx *cli.Context) error {
+	dblabClient, err := commands.ClientByCLIContext(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := dblabClient.AdminConfig(cliCtx.Context)
+	if err != nil {
+		return err
+	}
+
+	content, err := yaml.Marshal(cfg)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode the configuration")
+	}
+
+	content = append([]byte(configEditHeader), content...)
+
+	file, err := os.CreateTemp("", configFilePattern)
+	if err != nil {
+		return errors.Wrap(err, "failed to create a temporary file")
+	}
+
+	filename := file.Name()
+
+	defer func() { _ = os.Remove(filename) }()
+
+	if err := file.Close(); err != nil {
+		return errors.Wrap(err, "failed to close the temporary file")
+	}
+
+	for {
+		edited, err := runEditor(cliCtx, filename, content)
+		if err != nil {
+			return err
+		}
+
+		if bytes.Equal(stripComments(edited), stripComments(content)) {
+			_, err := fmt.Fprintln(cliCtx.App.Writer, "Edit cancelled, no changes made")
+			return err
+		}
+
+		updated := map[string]interface{}{}
+
+		if err := yaml.Unmarshal(edited, &updated); err != nil {
+			content = withErrorComment(edited, fmt.Sprintf("invalid YAML: %v", err))
+			continue
+		}
+
+		applied, err := dblabClient.SetAdminConfig(cliCtx.Context, updated)
+		if err != nil {
+			var apiErr models.Error
+			if !errors.As(err, &apiErr) {
+				return err
+			}
+
+			content = withErrorComment(edited, apiErr.Message)
+
+			continue
+		}
+
+		return commands.Print(cliCtx, applied)
+	}
+}
+
+func applyConfig(cliCtx *cli.Context, dblabClient *dblabapi.Client, cfg map[string]interface{}) (map[string]interface{}, error) {
+	applied, err := dblabClient.SetAdminConfig(cliCtx.Context, cfg)
+	if err != nil {
+		var apiErr models.Error
+		if errors.As(err, &apiErr) {
+			return nil, commands.ActionErrorf("the configuration has not been applied: %s", apiErr.Message)
+		}
+
+		return nil, err
+	}
+
+	return applied, nil
+}
+
+// runEditor writes the content to the file, opens it in the editor defined by $EDITOR and returns the edited content.
+func runEditor(cliCtx *cli.Context, filename string, content []byte) ([]byte, error) {
+	if err := os.WriteFile(filename, content, 0600); err != nil {
+		return nil, errors.Wrap(err, "failed to write the temporary file")
+	}
+
+	editor := strings.Fields(os.Getenv("EDITOR"))
+	if len(editor) == 0 {
+		editor = []string{defaultEditor}
+	}
+
+	cmd := exec.Command(editor[0], append(editor[1:], filename)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = cliCtx.App.Writer
+	cmd.Stderr = cliCtx.App.ErrWriter
+
+	if err := cmd.Run(); err != nil {
+		return nil, errors.Wrapf(err, "failed to run editor %q", editor[0])
+	}
+
+	edited, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read the edited file")
+	}
+
+	return edited, nil
+}
+
+// withErrorComment replaces leading comments of the content with the error message.
+func withErrorComment(content []byte, message string) []byte {
+	header := bytes.NewBufferString(configEditHeader)
+	header.WriteString(errorCommentPrefix + "Error:\n")
+
+	for _, line := range strings.Split(strings.TrimSpace(message), "\n") {
+		header.WriteString(errorCommentPrefix + "  " + line + "\n")
+	}
+
+	return append(header.Bytes(), stripLeadingComments(content)...)
+}
+
+func stripLeadingComments(content []byte) []byte {
+	for len(content) > 0 && content[0] == '#' {
+		end := bytes.IndexByte(content, '\n')
+		if end < 0 {
+			return nil
+		}
+
+		content = content[end+1:]
+	}
+
+	return content
+}
+
+func stripComments(content []byte) []byte {
+	lines := bytes.Split(content, []byte("\n"))
+	result := make([][]byte, 0, len(lines))
+
+	for _, line := range lines {
+		trimmed := bytes.TrimSpace(line)
+
+		if len(trimmed) == 0 || trimmed[0] == '#' {
+			continue
+		}
+
+		result = append(result, line)
+	}
+
+	return bytes.Join(result, []byte("\n"))
+}
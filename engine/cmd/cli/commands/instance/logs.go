@@ -0,0 +1,72 @@
+package instance
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"gitlab.com/postgres-ai/database-lab/v3/cmd/cli/commands"
+)
+
+// logsQuietPeriod defines how long to wait for new lines before exit if logs are not followed.
+const logsQuietPeriod = 2 * time.Second
+
+// logs prints recent logs of the instance and keeps streaming new lines if the follow flag is set.
+func logs(cliCtx *cli.Context) error {
+	dblabClient, err := commands.ClientByCLIContext(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(cliCtx.Context, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	lines, err := dblabClient.StreamLogs(ctx)
+	if err != nil {
+		return err
+	}
+
+	follow := cliCtx.Bool("follow")
+
+	quietTimer := time.NewTimer(logsQuietPeriod)
+	defer quietTimer.Stop()
+
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				return nil
+			}
+
+			if _, err := fmt.Fprintln(cliCtx.App.Writer, strings.TrimRight(line, "\n")); err != nil {
+				return err
+			}
+
+			if !quietTimer.Stop() {
+				select {
+				case <-quietTimer.C:
+				default:
+				}
+			}
+
+			quietTimer.Reset(logsQuietPeriod)
+
+		case <-quietTimer.C:
+			if !follow {
+				return nil
+			}
+
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
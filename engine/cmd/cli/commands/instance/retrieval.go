@@ -0,0 +1,193 @@
+package instance
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+
+	"gitlab.com/postgres-ai/database-lab/v3/cmd/cli/commands"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+const defaultSourcePort = "5432"
+
+// alertColumns defines the column set of the retrieval alert table.
+var alertColumns = []commands.Column{
+	{Name: "ALERT"},
+	{Name: "LEVEL"},
+	{Name: "COUNT"},
+	{Name: "LAST SEEN"},
+	{Name: "MESSAGE"},
+}
+
+// retrievalStatus prints the state of the data retrieval.
+func retrievalStatus(cliCtx *cli.Context) error {
+	dblabClient, err := commands.ClientByCLIContext(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	retrieving, err := dblabClient.RetrievalStatus(cliCtx.Context)
+	if err != nil {
+		return err
+	}
+
+	return commands.PrintTable(cliCtx, retrieving, retrievalTable(retrieving))
+}
+
+// retrievalTable builds the retrieval summary and the table of alerts.
+func retrievalTable(retrieving *models.Retrieving) commands.TableBuilder {
+	return func(wide bool) *commands.Table {
+		table := &commands.Table{
+			Summary: []string{
+				fmt.Sprintf("Mode:          %s", retrieving.Mode),
+				fmt.Sprintf("Status:        %s", retrieving.Status),
+			},
+			Columns: alertColumns,
+		}
+
+		if lastRefresh := commands.FormatTime(retrieving.LastRefresh, wide); lastRefresh != "" {
+			table.Summary = append(table.Summary, "Last refresh:  "+lastRefresh)
+		}
+
+		if nextRefresh := commands.FormatTime(retrieving.NextRefresh, true); nextRefresh != "" {
+			table.Summary = append(table.Summary, "Next refresh:  "+nextRefresh)
+		}
+
+		alertTypes := make([]string, 0, len(retrieving.Alerts))
+
+		for alertType := range retrieving.Alerts {
+			alertTypes = append(alertTypes, string(alertType))
+		}
+
+		sort.Strings(alertTypes)
+
+		for _, alertType := range alertTypes {
+			alert := retrieving.Alerts[models.AlertType(alertType)]
+			lastSeen := commands.FormatTime(models.NewLocalTime(alert.LastSeen), wide)
+
+			table.Rows = append(table.Rows, []string{
+				alertType,
+				string(alert.Level),
+				strconv.Itoa(alert.Count),
+				lastSeen,
+				alert.Message,
+			})
+		}
+
+		return table
+	}
+}
+
+// testSource checks the connection to the source database. Missing connection parameters are taken from the configuration.
+func testSource(cliCtx *cli.Context) error {
+	dblabClient, err := commands.ClientByCLIContext(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := dblabClient.AdminConfig(cliCtx.Context)
+	if err != nil {
+		return err
+	}
+
+	connection := sourceConnection(cfg)
+
+	for _, param := range []struct {
+		flag  string
+		value *string
+	}{
+		{flag: "host", value: &connection.Host},
+		{flag: "port", value: &connection.Port},
+		{flag: "dbname", value: &connection.DBName},
+		{flag: "username", value: &connection.Username},
+		{flag: "password", value: &connection.Password},
+	} {
+		if cliCtx.IsSet(param.flag) {
+			*param.value = cliCtx.String(param.flag)
+		}
+	}
+
+	if cliCtx.IsSet("db-list") {
+		connection.DBList = cliCtx.StringSlice("db-list")
+	}
+
+	if connection.Port == "" {
+		connection.Port = defaultSourcePort
+	}
+
+	dbSource, err := dblabClient.TestDBSource(cliCtx.Context, connection)
+	if err != nil {
+		return err
+	}
+
+	if err := commands.Print(cliCtx, dbSource); err != nil {
+		return err
+	}
+
+	if dbSource.TestConnection != nil && dbSource.Status == models.TCStatusError {
+		return commands.NewActionError(dbSource.Message)
+	}
+
+	return nil
+}
+
+// sourceConnection extracts the source connection from the projected configuration.
+func sourceConnection(cfg map[string]interface{}) models.ConnectionTest {
+	connection := models.ConnectionTest{}
+
+	source := lookupConfigValue(cfg, "retrieval.spec.logicalDump.options.source.connection")
+
+	params, ok := source.(map[string]interface{})
+	if !ok {
+		return connection
+	}
+
+	connection.Host = configString(params["host"])
+	connection.Port = configString(params["port"])
+	connection.DBName = configString(params["dbname"])
+	connection.Username = configString(params["username"])
+
+	if databases, ok := lookupConfigValue(cfg, "retrieval.spec.logicalDump.options.databases").(map[string]interface{}); ok {
+		for dbName := range databases {
+			connection.DBList = append(connection.DBList, dbName)
+		}
+
+		sort.Strings(connection.DBList)
+	}
+
+	return connection
+}
+
+func lookupConfigValue(cfg map[string]interface{}, path string) interface{} {
+	var value interface{} = cfg
+
+	for _, key := range strings.Split(path, ".") {
+		node, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+
+		value = node[key]
+	}
+
+	return value
+}
+
+func configString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+
+	case string:
+		return v
+
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,93 @@
+package instance
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gitlab.com/postgres-ai/database-lab/v3/cmd/cli/commands"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+// poolColumns defines the stable column set of the pool table of the instance status.
+var poolColumns = []commands.Column{
+	{Name: "POOL"},
+	{Name: "MODE"},
+	{Name: "STATUS"},
+	{Name: "DATA STATE AT"},
+	{Name: "CLONES"},
+	{Name: "SIZE"},
+	{Name: "FREE"},
+	{Name: "DATA SIZE", Wide: true},
+	{Name: "USED BY SNAPSHOTS", Wide: true},
+	{Name: "USED BY CLONES", Wide: true},
+	{Name: "COMPRESS RATIO", Wide: true},
+}
+
+// statusTable builds the instance summary and the table of pools.
+func statusTable(instanceStatus *models.InstanceStatusView) commands.TableBuilder {
+	return func(wide bool) *commands.Table {
+		table := &commands.Table{Columns: poolColumns}
+
+		if instanceStatus == nil || instanceStatus.InstanceStatus == nil {
+			return table
+		}
+
+		if instanceStatus.Status != nil {
+			table.Summary = append(table.Summary,
+				fmt.Sprintf("Status:     %s (%s)", instanceStatus.Status.Code, instanceStatus.Status.Message))
+		}
+
+		engine := strings.TrimSpace(instanceStatus.Engine.Version + " " + instanceStatus.Engine.Edition)
+		if startedAt := commands.FormatTime(instanceStatus.Engine.StartedAt, wide); startedAt != "" {
+			engine += ", started " + startedAt
+		}
+
+		table.Summary = append(table.Summary, "Engine:     "+engine)
+
+		if retrieving := instanceStatus.Retrieving; retrieving.Mode != "" {
+			retrieval := fmt.Sprintf("%s, %s", retrieving.Mode, retrieving.Status)
+			if lastRefresh := commands.FormatTime(retrieving.LastRefresh, wide); lastRefresh != "" {
+				retrieval += ", last refresh " + lastRefresh
+			}
+
+			table.Summary = append(table.Summary, "Retrieval:  "+retrieval)
+		}
+
+		table.Summary = append(table.Summary, fmt.Sprintf("Clones:     %d", instanceStatus.Cloning.NumClones))
+
+		if warmPool := instanceStatus.Cloning.WarmPool; warmPool != nil {
+			table.Summary = append(table.Summary, fmt.Sprintf("Warm pool:  %d/%d ready, %d hits, %d misses",
+				warmPool.Ready, warmPool.Size, warmPool.Hits, warmPool.Misses))
+		}
+
+		for _, pool := range instanceStatus.Pools {
+			if pool.PoolEntry == nil {
+				continue
+			}
+
+			fs := pool.FileSystem
+			compressRatio := ""
+
+			if fs.FileSystem != nil {
+				compressRatio = strconv.FormatFloat(fs.CompressRatio, 'f', 2, 64)
+			}
+
+			table.Rows = append(table.Rows, []string{
+				pool.Name,
+				pool.Mode,
+				string(pool.Status),
+				commands.FormatTime(pool.DataStateAt, wide),
+				strconv.Itoa(len(pool.CloneList)),
+				fs.Size.String(),
+				fs.Free.String(),
+				fs.DataSize.String(),
+				fs.UsedBySnapshots.String(),
+				fs.UsedByClones.String(),
+				compressRatio,
+			})
+		}
+
+		return table
+	}
+}
@@ -0,0 +1,22 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseLabels parses clone labels passed as key=value pairs.
+func ParseLabels(flags []string) (map[string]string, error) {
+	labels := make(map[string]string, len(flags))
+
+	for _, flag := range flags {
+		key, value, found := strings.Cut(flag, "=")
+		if !found || key == "" {
+			return nil, NewActionError(fmt.Sprintf("invalid label %q: the key=value format is expected", flag))
+		}
+
+		labels[key] = value
+	}
+
+	return labels, nil
+}
@@ -0,0 +1,418 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+// OutputKey defines the global flag of the output format.
+const OutputKey = "output"
+
+// Output formats.
+const (
+	OutputJSON       = "json"
+	OutputYAML       = "yaml"
+	OutputTable      = "table"
+	OutputWide       = "wide"
+	OutputGoTemplate = "go-template"
+	OutputJSONPath   = "jsonpath"
+)
+
+const (
+	tableMinWidth = 0
+	tableTabWidth = 8
+	tablePadding  = 3
+	emptyCell     = "-"
+	timeFormat    = "2006-01-02 15:04:05 MST"
+)
+
+// Column describes a table column.
+type Column struct {
+	Name string
+	// Wide columns are shown only in the wide output.
+	Wide bool
+}
+
+// Table describes a table view of a command result.
+type Table struct {
+	// Summary lines are printed before the table.
+	Summary []string
+	Columns []Column
+	Rows    [][]string
+}
+
+// TableBuilder builds the table view of a command result. Wide tables contain values in a detailed form.
+type TableBuilder func(wide bool) *Table
+
+// CheckOutputFormat checks the value of the output flag.
+func CheckOutputFormat(cliCtx *cli.Context) error {
+	format, argument := splitOutputFormat(cliCtx.String(OutputKey))
+
+	switch format {
+	case OutputJSON, OutputYAML, OutputTable, OutputWide:
+		return nil
+
+	case OutputGoTemplate, OutputJSONPath:
+		if argument == "" {
+			return NewActionError(fmt.Sprintf("%s output requires a template, for example: %s={.id}", format, format))
+		}
+
+		return nil
+	}
+
+	return NewActionError(fmt.Sprintf("unknown output format %q, supported formats: json, yaml, table, wide, "+
+		"go-template=TEMPLATE, jsonpath=EXPRESSION", format))
+}
+
+// Print prints the command result in the format defined by the output flag. The table output is not supported.
+func Print(cliCtx *cli.Context, result interface{}) error {
+	return PrintTable(cliCtx, result, nil)
+}
+
+// PrintTable prints the command result in the format defined by the output flag using the table builder for table outputs.
+func PrintTable(cliCtx *cli.Context, result interface{}, buildTable TableBuilder) error {
+	w := cliCtx.App.Writer
+	format, argument := splitOutputFormat(cliCtx.String(OutputKey))
+
+	switch format {
+	case OutputJSON:
+		commandResponse, err := json.MarshalIndent(result, "", "    ")
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintln(w, string(commandResponse))
+
+		return err
+
+	case OutputTable, OutputWide:
+		if buildTable == nil {
+			return NewActionError(fmt.Sprintf("%s output is not supported by the command, use json or yaml", format))
+		}
+
+		return writeTable(w, buildTable(format == OutputWide), format == OutputWide)
+	}
+
+	// Other formats work with the JSON representation to use the same field names.
+	data, err := toJSONValue(result)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case OutputYAML:
+		encoder := yaml.NewEncoder(w)
+
+		if err := encoder.Encode(data); err != nil {
+			return err
+		}
+
+		return encoder.Close()
+
+	case OutputGoTemplate:
+		tmpl, err := template.New(OutputKey).Parse(argument)
+		if err != nil {
+			return ActionErrorf("invalid template: %v", err)
+		}
+
+		return tmpl.Execute(w, data)
+
+	case OutputJSONPath:
+		return writeJSONPath(w, argument, data)
+	}
+
+	return CheckOutputFormat(cliCtx)
+}
+
+func splitOutputFormat(output string) (string, string) {
+	if output == "" {
+		return OutputJSON, ""
+	}
+
+	format, argument, _ := strings.Cut(output, "=")
+
+	return format, argument
+}
+
+func toJSONValue(result interface{}) (interface{}, error) {
+	raw, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+
+	var data interface{}
+
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func writeTable(w io.Writer, table *Table, wide bool) error {
+	for _, line := range table.Summary {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+
+	if len(table.Summary) > 0 {
+		if _, err := fmt.Fprintln(w); err != nil {
+			return err
+		}
+	}
+
+	tw := tabwriter.NewWriter(w, tableMinWidth, tableTabWidth, tablePadding, ' ', 0)
+
+	header := make([]string, 0, len(table.Columns))
+
+	for _, column := range table.Columns {
+		if column.Wide && !wide {
+			continue
+		}
+
+		header = append(header, column.Name)
+	}
+
+	if _, err := fmt.Fprintln(tw, strings.Join(header, "\t")); err != nil {
+		return err
+	}
+
+	for _, row := range table.Rows {
+		cells := make([]string, 0, len(header))
+
+		for i, column := range table.Columns {
+			if column.Wide && !wide {
+				continue
+			}
+
+			cell := ""
+			if i < len(row) {
+				cell = row[i]
+			}
+
+			if cell == "" {
+				cell = emptyCell
+			}
+
+			cells = append(cells, cell)
+		}
+
+		if _, err := fmt.Fprintln(tw, strings.Join(cells, "\t")); err != nil {
+			return err
+		}
+	}
+
+	return tw.Flush()
+}
+
+// FormatTime formats the time relative to now, such as "3 hours ago", or as the absolute time in the configured time zone.
+func FormatTime(t *models.LocalTime, absolute bool) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+
+	if !absolute {
+		return humanize.Time(t.Time)
+	}
+
+	return t.In(timeLocation()).Format(timeFormat)
+}
+
+// timeLocation returns the time zone defined by the TZ setting of CLI.
+func timeLocation() *time.Location {
+	if tz := os.Getenv("TZ"); tz != "" {
+		if location, err := time.LoadLocation(tz); err == nil {
+			return location
+		}
+	}
+
+	return time.Local
+}
+
+// FormatLabels formats labels as sorted key=value pairs.
+func FormatLabels(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+
+	for key, value := range labels {
+		pairs = append(pairs, key+"="+value)
+	}
+
+	sort.Strings(pairs)
+
+	return strings.Join(pairs, ",")
+}
+
+// writeJSONPath writes values selected by JSONPath expressions in curly braces, for example: {.id} {.status.code}.
+// The subset of JSONPath supports fields, indexes and the [*] wildcard.
+func writeJSONPath(w io.Writer, tmpl string, data interface{}) error {
+	if !strings.Contains(tmpl, "{") {
+		tmpl = "{" + tmpl + "}"
+	}
+
+	output := strings.Builder{}
+
+	for tmpl != "" {
+		start := strings.Index(tmpl, "{")
+		if start < 0 {
+			output.WriteString(tmpl)
+			break
+		}
+
+		end := strings.Index(tmpl[start:], "}")
+		if end < 0 {
+			return NewActionError(fmt.Sprintf("unclosed JSONPath expression: %s", tmpl[start:]))
+		}
+
+		output.WriteString(tmpl[:start])
+
+		values, err := evalJSONPath(tmpl[start+1:start+end], data)
+		if err != nil {
+			return err
+		}
+
+		formatted := make([]string, 0, len(values))
+
+		for _, value := range values {
+			text, err := formatJSONValue(value)
+			if err != nil {
+				return err
+			}
+
+			formatted = append(formatted, text)
+		}
+
+		output.WriteString(strings.Join(formatted, " "))
+
+		tmpl = tmpl[start+end+1:]
+	}
+
+	_, err := fmt.Fprintln(w, output.String())
+
+	return err
+}
+
+func evalJSONPath(expression string, data interface{}) ([]interface{}, error) {
+	path := strings.TrimPrefix(strings.TrimSpace(expression), "$")
+	values := []interface{}{data}
+
+	for path != "" {
+		switch path[0] {
+		case '.':
+			path = path[1:]
+
+			end := strings.IndexAny(path, ".[")
+			if end < 0 {
+				end = len(path)
+			}
+
+			key := path[:end]
+			path = path[end:]
+
+			if key == "" {
+				continue
+			}
+
+			values = selectField(values, key)
+
+		case '[':
+			end := strings.Index(path, "]")
+			if end < 0 {
+				return nil, NewActionError(fmt.Sprintf("invalid JSONPath expression %q: unclosed bracket", expression))
+			}
+
+			index := path[1:end]
+			path = path[end+1:]
+
+			selected, err := selectIndex(values, index)
+			if err != nil {
+				return nil, NewActionError(fmt.Sprintf("invalid JSONPath expression %q: %v", expression, err))
+			}
+
+			values = selected
+
+		default:
+			return nil, NewActionError(fmt.Sprintf("invalid JSONPath expression %q", expression))
+		}
+	}
+
+	return values, nil
+}
+
+func selectField(values []interface{}, key string) []interface{} {
+	selected := make([]interface{}, 0, len(values))
+
+	for _, value := range values {
+		if object, ok := value.(map[string]interface{}); ok {
+			if field, ok := object[key]; ok {
+				selected = append(selected, field)
+			}
+		}
+	}
+
+	return selected
+}
+
+func selectIndex(values []interface{}, index string) ([]interface{}, error) {
+	selected := make([]interface{}, 0, len(values))
+
+	for _, value := range values {
+		list, ok := value.([]interface{})
+		if !ok {
+			continue
+		}
+
+		if index == "*" {
+			selected = append(selected, list...)
+			continue
+		}
+
+		i, err := strconv.Atoi(index)
+		if err != nil {
+			return nil, fmt.Errorf("invalid index %q", index)
+		}
+
+		if i < 0 {
+			i += len(list)
+		}
+
+		if i >= 0 && i < len(list) {
+			selected = append(selected, list[i])
+		}
+	}
+
+	return selected, nil
+}
+
+func formatJSONValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+
+	case string:
+		return v, nil
+
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+
+	case map[string]interface{}, []interface{}:
+		raw, err := json.Marshal(v)
+		return string(raw), err
+	}
+
+	return fmt.Sprint(value), nil
+}
@@ -0,0 +1,127 @@
+package commands
+
+import (
+	"bytes"
+	"flag"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+func newOutputContext(t *testing.T, output string) (*cli.Context, *bytes.Buffer) {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	app := &cli.App{Writer: buf}
+
+	set := flag.NewFlagSet("test", flag.ContinueOnError)
+	set.String(OutputKey, "", "")
+	require.NoError(t, set.Set(OutputKey, output))
+
+	return cli.NewContext(app, set, nil), buf
+}
+
+type outputResult struct {
+	ID     string            `json:"id"`
+	Size   models.Size       `json:"size"`
+	Items  []int             `json:"items"`
+	Labels map[string]string `json:"labels"`
+}
+
+func TestPrint(t *testing.T) {
+	result := []outputResult{
+		{ID: "clone1", Size: 2048, Items: []int{1, 2}, Labels: map[string]string{"team": "billing"}},
+		{ID: "clone2", Size: 1000000, Items: []int{3}},
+	}
+
+	testCases := []struct {
+		output   string
+		expected string
+	}{
+		{output: "", expected: "[\n    {\n        \"id\": \"clone1\",\n        \"size\": \"2.0 KiB\",\n        \"items\": [\n" +
+			"            1,\n            2\n        ],\n        \"labels\": {\n            \"team\": \"billing\"\n        }\n    },\n" +
+			"    {\n        \"id\": \"clone2\",\n        \"size\": \"977 KiB\",\n        \"items\": [\n            3\n        ],\n" +
+			"        \"labels\": null\n    }\n]\n"},
+		{output: "yaml", expected: "- id: clone1\n  items:\n    - 1\n    - 2\n  labels:\n    team: billing\n  size: 2.0 KiB\n" +
+			"- id: clone2\n  items:\n    - 3\n  labels: null\n  size: 977 KiB\n"},
+		{output: "jsonpath={[*].id}", expected: "clone1 clone2\n"},
+		{output: "jsonpath=.[0].items", expected: "[1,2]\n"},
+		{output: "jsonpath=ID: {$[-1].id}, items: {[*].items[*]}, labels: {[0].labels}", expected: "ID: clone2, items: 1 2 3, labels: {\"team\":\"billing\"}\n"},
+		{output: "jsonpath={.missing}", expected: "\n"},
+		{output: "go-template={{range .}}{{.id}}={{.size}};{{end}}", expected: "clone1=2.0 KiB;clone2=977 KiB;"},
+	}
+
+	for _, tc := range testCases {
+		cliCtx, buf := newOutputContext(t, tc.output)
+
+		require.NoError(t, Print(cliCtx, result), tc.output)
+		assert.Equal(t, tc.expected, buf.String(), tc.output)
+	}
+}
+
+func TestPrintTable(t *testing.T) {
+	buildTable := func(wide bool) *Table {
+		created := "1 hour ago"
+		if wide {
+			created = "2023-01-01"
+		}
+
+		return &Table{
+			Summary: []string{"Clones: 2"},
+			Columns: []Column{{Name: "ID"}, {Name: "CREATED"}, {Name: "PORT", Wide: true}, {Name: "USER"}},
+			Rows: [][]string{
+				{"clone1", created, "6000", "john"},
+				{"long-clone-id", created, "", ""},
+			},
+		}
+	}
+
+	cliCtx, buf := newOutputContext(t, "table")
+	require.NoError(t, PrintTable(cliCtx, nil, buildTable))
+	assert.Equal(t, "Clones: 2\n\n"+
+		"ID              CREATED      USER\n"+
+		"clone1          1 hour ago   john\n"+
+		"long-clone-id   1 hour ago   -\n", buf.String())
+
+	cliCtx, buf = newOutputContext(t, "wide")
+	require.NoError(t, PrintTable(cliCtx, nil, buildTable))
+	assert.Equal(t, "Clones: 2\n\n"+
+		"ID              CREATED      PORT   USER\n"+
+		"clone1          2023-01-01   6000   john\n"+
+		"long-clone-id   2023-01-01   -      -\n", buf.String())
+
+	cliCtx, _ = newOutputContext(t, "table")
+	assert.Error(t, Print(cliCtx, nil))
+}
+
+func TestCheckOutputFormat(t *testing.T) {
+	for _, output := range []string{"", "json", "yaml", "table", "wide", "go-template={{.id}}", "jsonpath={.id}"} {
+		cliCtx, _ := newOutputContext(t, output)
+		assert.NoError(t, CheckOutputFormat(cliCtx), output)
+	}
+
+	for _, output := range []string{"xml", "go-template", "jsonpath="} {
+		cliCtx, _ := newOutputContext(t, output)
+		assert.Error(t, CheckOutputFormat(cliCtx), output)
+	}
+
+	cliCtx, _ := newOutputContext(t, "jsonpath={.id")
+	assert.Error(t, Print(cliCtx, map[string]string{"id": "clone1"}))
+}
+
+func TestFormatTime(t *testing.T) {
+	t.Setenv("TZ", "Asia/Tokyo")
+
+	moment := models.NewLocalTime(time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC))
+
+	assert.Equal(t, "2023-01-01 19:00:00 JST", FormatTime(moment, true))
+	assert.Equal(t, "1 hour ago", FormatTime(models.NewLocalTime(time.Now().Add(-time.Hour)), false))
+	assert.Empty(t, FormatTime(nil, true))
+	assert.Empty(t, FormatTime(&models.LocalTime{}, false))
+	assert.Equal(t, "a=1,b=2", FormatLabels(map[string]string{"b": "2", "a": "1"}))
+}
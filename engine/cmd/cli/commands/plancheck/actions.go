@@ -0,0 +1,80 @@
+// Package plancheck provides commands for a query plan regression testing.
+package plancheck
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+
+	"gitlab.com/postgres-ai/database-lab/v3/cmd/cli/commands"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/client/dblabapi/types"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+// check runs a request to check query plans.
+func check(cliCtx *cli.Context) error {
+	queries, err := loadQueries(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	if len(queries) == 0 {
+		return commands.NewActionError("at least one query must be specified with --query or --queries-file")
+	}
+
+	dblabClient, err := commands.ClientByCLIContext(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	planCheckRequest := types.PlanCheckRequest{
+		Queries:   queries,
+		SnapshotA: cliCtx.String("snapshot-a"),
+		SnapshotB: cliCtx.String("snapshot-b"),
+		CloneID:   cliCtx.String("clone-id"),
+		Stage:     cliCtx.String("stage"),
+		DBName:    cliCtx.String("db-name"),
+		Thresholds: types.PlanCheckThresholds{
+			ExecutionTime: cliCtx.Float64("max-time-ratio"),
+			Buffers:       cliCtx.Float64("max-buffers-ratio"),
+		},
+	}
+
+	report, err := dblabClient.CheckPlans(cliCtx.Context, planCheckRequest)
+	if err != nil {
+		return err
+	}
+
+	if err := commands.Print(cliCtx, report); err != nil {
+		return err
+	}
+
+	if report.Status == models.PlanCheckFailed {
+		return commands.NewActionError("query plan regressions detected")
+	}
+
+	return nil
+}
+
+func loadQueries(cliCtx *cli.Context) ([]types.PlanQuery, error) {
+	queries := []types.PlanQuery{}
+
+	if filename := cliCtx.String("queries-file"); filename != "" {
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read file %s", filename)
+		}
+
+		if err := json.Unmarshal(data, &queries); err != nil {
+			return nil, errors.Wrapf(err, "failed to parse queries from %s", filename)
+		}
+	}
+
+	for _, query := range cliCtx.StringSlice("query") {
+		queries = append(queries, types.PlanQuery{Query: query})
+	}
+
+	return queries, nil
+}
@@ -0,0 +1,58 @@
+package plancheck
+
+import (
+	"github.com/urfave/cli/v2"
+)
+
+// CommandList returns available commands for a query plan regression testing.
+func CommandList() []*cli.Command {
+	return []*cli.Command{
+		{
+			Name:  "plan-check",
+			Usage: "compare query plans between snapshots or before and after a migration",
+			Description: "Queries are explained with EXPLAIN (ANALYZE, BUFFERS, FORMAT JSON) either on clones created from " +
+				"the snapshots --snapshot-a and --snapshot-b, or on the clone with a running observation session " +
+				"(--clone-id with --stage before|after). The command fails if plan regressions are detected.",
+			Action: check,
+			Flags: []cli.Flag{
+				&cli.StringSliceFlag{
+					Name:  "query",
+					Usage: "query to explain",
+				},
+				&cli.StringFlag{
+					Name:  "queries-file",
+					Usage: "path to a JSON file containing a list of queries: [{\"name\": \"...\", \"query\": \"...\", \"params\": [...]}]",
+				},
+				&cli.StringFlag{
+					Name:  "snapshot-a",
+					Usage: "ID of the baseline snapshot",
+				},
+				&cli.StringFlag{
+					Name:  "snapshot-b",
+					Usage: "ID of the snapshot to compare with the baseline",
+				},
+				&cli.StringFlag{
+					Name:    "clone-id",
+					Usage:   "ID of the clone with a running observation session which keeps the report",
+					EnvVars: []string{"DBLAB_OBSERVATION_CLONE_ID"},
+				},
+				&cli.StringFlag{
+					Name:  "stage",
+					Usage: "stage of the check on the observed clone: \"before\" captures baseline plans, \"after\" compares plans with them",
+				},
+				&cli.StringFlag{
+					Name:  "db-name",
+					Usage: "database name to connect to on the snapshot clones",
+				},
+				&cli.Float64Flag{
+					Name:  "max-time-ratio",
+					Usage: "maximum allowed ratio of the execution time to the baseline one (default: 2)",
+				},
+				&cli.Float64Flag{
+					Name:  "max-buffers-ratio",
+					Usage: "maximum allowed ratio of the touched buffers to the baseline ones (default: 1.5)",
+				},
+			},
+		},
+	}
+}
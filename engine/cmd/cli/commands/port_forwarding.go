@@ -17,10 +17,20 @@ import (
 	"gitlab.com/postgres-ai/database-lab/v3/internal/portfwd"
 )
 
+const localHost = "127.0.0.1"
+
 // BuildTunnel creates a new instance of SSH tunnel.
 func BuildTunnel(cliCtx *cli.Context, remoteHost *url.URL) (*portfwd.SSHTunnel, error) {
-	localEndpoint := forwardingLocalEndpoint(remoteHost, cliCtx.String(FwLocalPortKey))
+	return buildTunnel(cliCtx, remoteHost, forwardingLocalEndpoint(remoteHost, cliCtx.String(FwLocalPortKey)))
+}
+
+// BuildLocalTunnel creates a new instance of SSH tunnel listening on the given local port.
+// The tunnel listens on a random free port if the port is "0".
+func BuildLocalTunnel(cliCtx *cli.Context, remoteHost *url.URL, localPort string) (*portfwd.SSHTunnel, error) {
+	return buildTunnel(cliCtx, remoteHost, BuildHostname(localHost, localPort))
+}
 
+func buildTunnel(cliCtx *cli.Context, remoteHost *url.URL, localEndpoint string) (*portfwd.SSHTunnel, error) {
 	serverURL, err := url.Parse(cliCtx.String(FwServerURLKey))
 	if err != nil {
 		return nil, err
@@ -52,7 +62,7 @@ func forwardingLocalEndpoint(remoteURL *url.URL, localPort string) string {
 		localPort = remoteURL.Port()
 	}
 
-	return fmt.Sprintf("%s:%s", "127.0.0.1", localPort)
+	return BuildHostname(localHost, localPort)
 }
 
 func getAuthMethod(cliCtx *cli.Context) (ssh.AuthMethod, error) {
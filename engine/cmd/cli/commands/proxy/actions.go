@@ -0,0 +1,124 @@
+// Package proxy provides a command to run a local Postgres proxy creating clones on demand.
+package proxy
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/urfave/cli/v2"
+
+	"gitlab.com/postgres-ai/database-lab/v3/cmd/cli/commands"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/cloneproxy"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/client/dblabapi"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+// start runs the proxy until interrupted.
+func start(cliCtx *cli.Context) error {
+	labels, err := commands.ParseLabels(cliCtx.StringSlice("label"))
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(cliCtx.Context, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	dblabClient, dial, closeConnection, err := connectInstance(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	defer closeConnection()
+
+	listener, err := net.Listen("tcp", cliCtx.String("listen"))
+	if err != nil {
+		return err
+	}
+
+	cfg := cloneproxy.Config{
+		KeyParameter: cliCtx.String("key"),
+		Password:     cliCtx.String("password"),
+		SnapshotID:   cliCtx.String("snapshot-id"),
+		Labels:       labels,
+		IdleTimeout:  cliCtx.Duration("idle-timeout"),
+	}
+
+	log.Msg(fmt.Sprintf("The proxy is listening on %s", listener.Addr()))
+
+	return cloneproxy.New(cfg, dblabClient, dial).Serve(ctx, listener)
+}
+
+// connectInstance creates an API client and a clone dialer, both work through an SSH connection if forwarding is configured.
+func connectInstance(cliCtx *cli.Context) (*dblabapi.Client, cloneproxy.Dialer, func(), error) {
+	remoteURL, err := url.Parse(cliCtx.String(commands.URLKey))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	options := commands.ClientOptions(cliCtx)
+
+	if cliCtx.String(commands.FwServerURLKey) == "" {
+		dblabClient, err := dblabapi.NewClient(options)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		dialer := &net.Dialer{}
+
+		dial := func(ctx context.Context, clone *models.Clone) (net.Conn, error) {
+			host := clone.DB.Host
+			if host == "" {
+				host = remoteURL.Hostname()
+			}
+
+			return dialer.DialContext(ctx, "tcp", commands.BuildHostname(host, clone.DB.Port))
+		}
+
+		return dblabClient, dial, func() {}, nil
+	}
+
+	tunnel, err := commands.BuildTunnel(cliCtx, remoteURL)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if err := tunnel.Connect(); err != nil {
+		return nil, nil, nil, err
+	}
+
+	closeTunnel := func() {
+		if err := tunnel.Stop(); err != nil {
+			log.Err("Failed to close the SSH connection: ", err)
+		}
+	}
+
+	options.Transport = &http.Transport{
+		DialContext: func(_ context.Context, _, address string) (net.Conn, error) {
+			return tunnel.Dial(address)
+		},
+		TLSClientConfig: &tls.Config{
+			InsecureSkipVerify: options.Insecure,
+		},
+	}
+
+	dblabClient, err := dblabapi.NewClient(options)
+	if err != nil {
+		closeTunnel()
+
+		return nil, nil, nil, err
+	}
+
+	dial := func(_ context.Context, clone *models.Clone) (net.Conn, error) {
+		return tunnel.Dial(commands.BuildHostname(remoteURL.Hostname(), clone.DB.Port))
+	}
+
+	return dblabClient, dial, closeTunnel, nil
+}
@@ -0,0 +1,74 @@
+package proxy
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"gitlab.com/postgres-ai/database-lab/v3/cmd/cli/commands"
+)
+
+const (
+	defaultListenAddress = "127.0.0.1:6432"
+	defaultIdleTimeout   = 30 * time.Minute
+)
+
+// keyParameters lists startup parameters allowed to select clones.
+var keyParameters = []string{"user", "database", "application_name"}
+
+// CommandList returns available commands for a local proxy.
+func CommandList() []*cli.Command {
+	return []*cli.Command{
+		{
+			Name:  "proxy",
+			Usage: "run a local Postgres proxy creating clones on demand",
+			Description: "The proxy accepts Postgres connections, creates a clone for each user (or another key) " +
+				"or reuses a clone created earlier, and forwards connections to it. " +
+				"Clones without connections are destroyed after the idle timeout.",
+			Before: checkKeyBefore,
+			Action: start,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "listen",
+					Usage: "local address to accept connections",
+					Value: defaultListenAddress,
+				},
+				&cli.StringFlag{
+					Name:  "key",
+					Usage: fmt.Sprintf("startup parameter selecting the clone of a connection: %v", keyParameters),
+					Value: keyParameters[0],
+				},
+				&cli.StringFlag{
+					Name:     "password",
+					Usage:    "password of database users created in clones",
+					Required: true,
+					EnvVars:  []string{"DBLAB_PROXY_PASSWORD"},
+				},
+				&cli.StringFlag{
+					Name:  "snapshot-id",
+					Usage: "snapshot ID of new clones (by default, the latest snapshot)",
+				},
+				&cli.StringSliceFlag{
+					Name:  "label",
+					Usage: "set a label of new clones. An example: team=billing",
+				},
+				&cli.DurationFlag{
+					Name:  "idle-timeout",
+					Usage: "destroy clones without connections after the duration, 0 keeps clones",
+					Value: defaultIdleTimeout,
+				},
+			},
+		},
+	}
+}
+
+func checkKeyBefore(c *cli.Context) error {
+	for _, key := range keyParameters {
+		if c.String("key") == key {
+			return nil
+		}
+	}
+
+	return commands.NewActionError(fmt.Sprintf("invalid key %q, allowed values: %v", c.String("key"), keyParameters))
+}
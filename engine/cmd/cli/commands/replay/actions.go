@@ -0,0 +1,140 @@
+// Package replay provides commands to replay recorded workloads against clones.
+package replay
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+
+	"gitlab.com/postgres-ai/database-lab/v3/cmd/cli/commands"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/client/dblabapi/types"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+const progressInterval = 5 * time.Second
+
+// start runs a request to start a workload replay.
+func start(cliCtx *cli.Context) error {
+	startRequest := types.StartReplayRequest{
+		CloneID:     cliCtx.String("clone-id"),
+		Speed:       cliCtx.Float64("speed"),
+		Concurrency: cliCtx.Int("concurrency"),
+	}
+
+	switch csvLog, statements := cliCtx.String("csv-log"), cliCtx.String("statements"); {
+	case csvLog != "" && statements != "":
+		return commands.NewActionError("options --csv-log and --statements must not be specified together")
+
+	case csvLog != "":
+		data, err := os.ReadFile(csvLog)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read file %s", csvLog)
+		}
+
+		startRequest.Format = types.ReplayFormatCSVLog
+		startRequest.CSVLog = string(data)
+
+	case statements != "":
+		data, err := os.ReadFile(statements)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read file %s", statements)
+		}
+
+		if err := json.Unmarshal(data, &startRequest.Statements); err != nil {
+			return errors.Wrapf(err, "failed to parse statements from %s", statements)
+		}
+
+		startRequest.Format = types.ReplayFormatStatements
+
+	default:
+		return commands.NewActionError("either --csv-log or --statements must be specified")
+	}
+
+	dblabClient, err := commands.ClientByCLIContext(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	replayStatus, err := dblabClient.StartReplay(cliCtx.Context, startRequest)
+	if err != nil {
+		return err
+	}
+
+	if cliCtx.Bool("wait") {
+		if replayStatus, err = waitReplay(cliCtx, replayStatus.CloneID); err != nil {
+			return err
+		}
+	}
+
+	return printStatus(cliCtx, replayStatus)
+}
+
+func waitReplay(cliCtx *cli.Context, cloneID string) (*models.ReplayStatus, error) {
+	dblabClient, err := commands.ClientByCLIContext(cliCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	ticker := time.NewTicker(progressInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-cliCtx.Context.Done():
+			return nil, cliCtx.Context.Err()
+
+		case <-ticker.C:
+		}
+
+		replayStatus, err := dblabClient.ReplayStatus(cliCtx.Context, cloneID)
+		if err != nil {
+			return nil, err
+		}
+
+		if replayStatus.State != models.ReplayRunning {
+			return replayStatus, nil
+		}
+
+		log.Msg(fmt.Sprintf("Replayed %d of %d statements (%.1f%%), errors: %d",
+			replayStatus.Progress.Executed, replayStatus.Progress.Statements, replayStatus.Progress.Percent, replayStatus.Progress.Errors))
+	}
+}
+
+// stop runs a request to stop a workload replay.
+func stop(cliCtx *cli.Context) error {
+	dblabClient, err := commands.ClientByCLIContext(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	replayStatus, err := dblabClient.StopReplay(cliCtx.Context, types.StopReplayRequest{CloneID: cliCtx.String("clone-id")})
+	if err != nil {
+		return err
+	}
+
+	return printStatus(cliCtx, replayStatus)
+}
+
+// status runs a request to get the state of a workload replay.
+func status(cliCtx *cli.Context) error {
+	dblabClient, err := commands.ClientByCLIContext(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	replayStatus, err := dblabClient.ReplayStatus(cliCtx.Context, cliCtx.String("clone-id"))
+	if err != nil {
+		return err
+	}
+
+	return printStatus(cliCtx, replayStatus)
+}
+
+func printStatus(cliCtx *cli.Context, replayStatus *models.ReplayStatus) error {
+	return commands.Print(cliCtx, replayStatus)
+}
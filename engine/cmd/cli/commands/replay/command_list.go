@@ -0,0 +1,64 @@
+package replay
+
+import (
+	"github.com/urfave/cli/v2"
+)
+
+// CommandList returns available commands for a workload replay.
+func CommandList() []*cli.Command {
+	cloneIDFlag := &cli.StringFlag{
+		Name:     "clone-id",
+		Usage:    "ID of the clone with a running observation session",
+		Required: true,
+		EnvVars:  []string{"DBLAB_OBSERVATION_CLONE_ID"},
+	}
+
+	return []*cli.Command{
+		{
+			Name:  "replay",
+			Usage: "[EXPERIMENTAL] replay recorded workloads against clones",
+			Subcommands: []*cli.Command{
+				{
+					Name:   "start",
+					Usage:  "start replaying a workload during the observation session",
+					Action: start,
+					Flags: []cli.Flag{
+						cloneIDFlag,
+						&cli.StringFlag{
+							Name:  "csv-log",
+							Usage: "path to a Postgres CSV log containing statements logged with log_statement or log_min_duration_statement",
+						},
+						&cli.StringFlag{
+							Name:  "statements",
+							Usage: "path to a JSON file containing statement samples: [{\"query\": \"...\", \"params\": [...], \"calls\": 10}]",
+						},
+						&cli.Float64Flag{
+							Name:  "speed",
+							Usage: "replay speed relative to the recorded timing, for example, 2 replays the workload twice as fast (default: 1)",
+						},
+						&cli.IntFlag{
+							Name:  "concurrency",
+							Usage: "maximum number of concurrently replayed sessions (default: 10)",
+						},
+						&cli.BoolFlag{
+							Name:  "wait",
+							Usage: "wait until the replay finishes and report its progress",
+						},
+					},
+				},
+				{
+					Name:   "stop",
+					Usage:  "stop the workload replay",
+					Action: stop,
+					Flags:  []cli.Flag{cloneIDFlag},
+				},
+				{
+					Name:   "status",
+					Usage:  "display the state and progress of the workload replay",
+					Action: status,
+					Flags:  []cli.Flag{cloneIDFlag},
+				},
+			},
+		},
+	}
+}
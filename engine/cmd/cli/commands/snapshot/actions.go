@@ -7,7 +7,6 @@ package snapshot
 
 import (
 	"encoding/json"
-	"fmt"
 
 	"github.com/urfave/cli/v2"
 
@@ -35,12 +34,5 @@ func list(cliCtx *cli.Context) error {
 		return err
 	}
 
-	commandResponse, err := json.MarshalIndent(snapshotListView, "", "    ")
-	if err != nil {
-		return err
-	}
-
-	_, err = fmt.Fprintln(cliCtx.App.Writer, string(commandResponse))
-
-	return err
+	return commands.PrintTable(cliCtx, snapshotListView, snapshotTable(snapshotListView))
 }
@@ -0,0 +1,44 @@
+package snapshot
+
+import (
+	"strconv"
+
+	"gitlab.com/postgres-ai/database-lab/v3/cmd/cli/commands"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+// snapshotColumns defines the stable column set of snapshot tables.
+var snapshotColumns = []commands.Column{
+	{Name: "ID"},
+	{Name: "DATA STATE AT"},
+	{Name: "CREATED"},
+	{Name: "POOL"},
+	{Name: "CLONES"},
+	{Name: "LOGICAL SIZE"},
+	{Name: "PHYSICAL SIZE", Wide: true},
+}
+
+// snapshotTable builds a table of snapshots.
+func snapshotTable(snapshots []*models.SnapshotView) commands.TableBuilder {
+	return func(wide bool) *commands.Table {
+		table := &commands.Table{Columns: snapshotColumns}
+
+		for _, snapshot := range snapshots {
+			if snapshot == nil || snapshot.Snapshot == nil {
+				continue
+			}
+
+			table.Rows = append(table.Rows, []string{
+				snapshot.ID,
+				commands.FormatTime(snapshot.DataStateAt, wide),
+				commands.FormatTime(snapshot.CreatedAt, wide),
+				snapshot.Pool,
+				strconv.Itoa(snapshot.NumClones),
+				snapshot.LogicalSize.String(),
+				snapshot.PhysicalSize.String(),
+			})
+		}
+
+		return table
+	}
+}
@@ -14,6 +14,9 @@ import (
 	"gitlab.com/postgres-ai/database-lab/v3/cmd/cli/commands/config"
 	"gitlab.com/postgres-ai/database-lab/v3/cmd/cli/commands/global"
 	"gitlab.com/postgres-ai/database-lab/v3/cmd/cli/commands/instance"
+	"gitlab.com/postgres-ai/database-lab/v3/cmd/cli/commands/plancheck"
+	"gitlab.com/postgres-ai/database-lab/v3/cmd/cli/commands/proxy"
+	"gitlab.com/postgres-ai/database-lab/v3/cmd/cli/commands/replay"
 	"gitlab.com/postgres-ai/database-lab/v3/cmd/cli/commands/snapshot"
 	"gitlab.com/postgres-ai/database-lab/v3/cmd/cli/templates"
 	dblabLog "gitlab.com/postgres-ai/database-lab/v3/pkg/log"
@@ -26,7 +29,13 @@ func main() {
 		CommandNotFound: func(c *cli.Context, command string) {
 			_, _ = fmt.Fprintf(c.App.Writer, "[ERROR] Command %q not found.\n", command)
 		},
-		Before: loadEnvironmentParams,
+		Before: func(c *cli.Context) error {
+			if err := commands.CheckOutputFormat(c); err != nil {
+				return err
+			}
+
+			return loadEnvironmentParams(c)
+		},
 		Commands: joinCommands(
 			// Config commands.
 			global.List(),
@@ -35,6 +44,9 @@ func main() {
 			clone.CommandList(),
 			instance.CommandList(),
 			snapshot.CommandList(),
+			plancheck.CommandList(),
+			replay.CommandList(),
+			proxy.CommandList(),
 
 			// CLI config.
 			config.CommandList(),
@@ -76,6 +88,13 @@ func main() {
 				Usage:   "select a file from which the identity (private key) for public key authentication is read",
 				EnvVars: []string{"DBLAB_CLI_IDENTITY_FILE"},
 			},
+			&cli.StringFlag{
+				Name:    commands.OutputKey,
+				Aliases: []string{"o"},
+				Usage:   "output format: json, yaml, table, wide, go-template=TEMPLATE or jsonpath=EXPRESSION",
+				Value:   commands.OutputJSON,
+				EnvVars: []string{"DBLAB_CLI_OUTPUT"},
+			},
 			&cli.BoolFlag{
 				Name:    "debug",
 				Usage:   "run in debug mode",
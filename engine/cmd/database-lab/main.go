@@ -26,6 +26,7 @@ import (
 	"gitlab.com/postgres-ai/database-lab/v3/internal/cloning"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/diagnostic"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/embeddedui"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/events"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/observer"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/platform"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/provision"
@@ -140,14 +141,17 @@ func main() {
 	go tokenHolder.RunCleaningUp(ctx)
 
 	observingChan := make(chan string, 1)
+	eventHub := events.NewHub()
 
 	emergencyShutdown := func() {
 		cancel()
 
+		provisioner.StopWarmPool()
+
 		shutdownDatabaseLabEngine(context.Background(), docker, &cfg.Global.Database, engProps.InstanceID, pm.First())
 	}
 
-	cloningSvc := cloning.NewBase(&cfg.Cloning, provisioner, tm, observingChan)
+	cloningSvc := cloning.NewBase(&cfg.Cloning, provisioner, tm, observingChan, eventHub)
 	if err = cloningSvc.Run(ctx); err != nil {
 		log.Err(err)
 		emergencyShutdown()
@@ -182,10 +186,12 @@ func main() {
 	}
 
 	server := srv.NewServer(&cfg.Server, &cfg.Global, &engProps, docker, cloningSvc, provisioner, retrievalSvc, platformSvc,
-		billingSvc, obs, pm, tm, tokenHolder, logFilter, embeddedUI, reloadConfigFn)
+		billingSvc, obs, pm, tm, tokenHolder, eventHub, logFilter, embeddedUI, reloadConfigFn)
 
 	server.InitHandlers()
 
+	go server.WatchInstanceState(ctx)
+
 	go func() {
 		if err := server.Run(); err != nil {
 			log.Msg(err)
@@ -257,6 +263,8 @@ func main() {
 		log.Msg(err)
 	}
 
+	provisioner.StopWarmPool()
+
 	shutdownDatabaseLabEngine(ctxBackground, docker, &cfg.Global.Database, engProps.InstanceID, pm.First())
 	cloningSvc.SaveClonesState()
 	logCleaner.StopLogCleanupJob()
@@ -97,7 +97,17 @@ func main() {
 
 	codeProvider := source.NewCodeProvider(ctx, &cfg.Source)
 
-	srv := runci.NewServer(cfg, dleClient, platformSvc, codeProvider, dockerCLI, networkID)
+	reporter, err := runci.NewReporter(cfg.Report, codeProvider)
+	if err != nil {
+		log.Errf("failed to create a reporter: %v", err)
+		return
+	}
+
+	srv, err := runci.NewServer(cfg, dleClient, platformSvc, codeProvider, reporter, dockerCLI, networkID)
+	if err != nil {
+		log.Errf("failed to create a server: %v", err)
+		return
+	}
 
 	if err := srv.Run(); err != nil {
 		log.Msg(err)
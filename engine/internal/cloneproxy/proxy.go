@@ -0,0 +1,344 @@
+// Package cloneproxy provides Postgres proxies forwarding connections to Database Lab clones.
+package cloneproxy
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/client/dblabapi/types"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+// KeyLabel marks clones created by the proxy with the value of the key parameter.
+const KeyLabel = "dblab-proxy/key"
+
+const (
+	// SQLSTATE codes reported to clients.
+	sqlStateInvalidAuthorization = "28000"
+	sqlStateConnectionFailure    = "08001"
+
+	userParameter    = "user"
+	reapDivider      = 2
+	maxReapInterval  = time.Minute
+	invalidKeySymbol = ",=!"
+)
+
+// Cloner manages clones of a Database Lab instance.
+type Cloner interface {
+	CreateClone(ctx context.Context, cloneRequest types.CloneCreateRequest) (*models.Clone, error)
+	ListClonesPage(ctx context.Context, listRequest types.CloneListRequest) (*models.ClonePage, error)
+	DestroyClone(ctx context.Context, cloneID string) error
+}
+
+// Dialer opens a connection to the clone database.
+type Dialer func(ctx context.Context, clone *models.Clone) (net.Conn, error)
+
+// Config describes the proxy configuration.
+type Config struct {
+	// KeyParameter defines the startup parameter selecting the clone of a connection, for example, user or database.
+	KeyParameter string
+	// Password is the password of database users created in clones.
+	Password string
+	// SnapshotID defines the snapshot of new clones. The latest snapshot is used if it is empty.
+	SnapshotID string
+	// Labels are added to new clones.
+	Labels map[string]string
+	// IdleTimeout defines how long a clone without connections lives. Zero disables destroying of idle clones.
+	IdleTimeout time.Duration
+}
+
+// Proxy accepts Postgres connections and forwards them to clones created on demand.
+type Proxy struct {
+	cfg      Config
+	cloner   Cloner
+	dial     Dialer
+	mu       sync.Mutex
+	sessions map[string]*session
+}
+
+// session describes a clone serving connections with the same key.
+type session struct {
+	key       string
+	ready     chan struct{}
+	clone     *models.Clone
+	err       error
+	conns     int
+	idleSince time.Time
+}
+
+// isReady checks if the clone of the session has been requested.
+func (s *session) isReady() bool {
+	select {
+	case <-s.ready:
+		return true
+
+	default:
+		return false
+	}
+}
+
+// New creates a new proxy.
+func New(cfg Config, cloner Cloner, dial Dialer) *Proxy {
+	return &Proxy{
+		cfg:      cfg,
+		cloner:   cloner,
+		dial:     dial,
+		sessions: make(map[string]*session),
+	}
+}
+
+// Serve accepts connections until the context is done. Clones are kept after the proxy stops,
+// so they are reused on the next start.
+func (p *Proxy) Serve(ctx context.Context, listener net.Listener) error {
+	go func() {
+		<-ctx.Done()
+
+		if err := listener.Close(); err != nil {
+			log.Dbg("Failed to close the proxy listener: ", err)
+		}
+	}()
+
+	if p.cfg.IdleTimeout > 0 {
+		go p.watchIdleClones(ctx)
+	}
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			return errors.Wrap(err, "failed to accept connection")
+		}
+
+		go p.handle(ctx, conn)
+	}
+}
+
+func (p *Proxy) handle(ctx context.Context, conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+
+	startup, err := ReadStartupMessage(conn)
+	if err != nil {
+		log.Dbg("Failed to read startup message: ", err)
+		return
+	}
+
+	key := startup.Parameters[p.cfg.KeyParameter]
+	if key == "" || strings.ContainsAny(key, invalidKeySymbol) {
+		p.reject(conn, sqlStateInvalidAuthorization, fmt.Sprintf("startup parameter %q is missing or invalid", p.cfg.KeyParameter))
+		return
+	}
+
+	s, err := p.acquire(ctx, key, startup.Parameters[userParameter])
+	if err != nil {
+		p.reject(conn, sqlStateConnectionFailure, fmt.Sprintf("failed to get a clone: %v", err))
+		return
+	}
+
+	defer p.release(s)
+
+	serverConn, err := p.dial(ctx, s.clone)
+	if err != nil {
+		// The clone might have been destroyed, so the next connection gets a new one.
+		p.remove(s)
+		p.reject(conn, sqlStateConnectionFailure, fmt.Sprintf("failed to connect to clone %s: %v", s.clone.ID, err))
+
+		return
+	}
+
+	defer func() { _ = serverConn.Close() }()
+
+	if _, err := serverConn.Write(startup.Raw); err != nil {
+		log.Dbg("Failed to forward startup message: ", err)
+		return
+	}
+
+	log.Dbg(fmt.Sprintf("Proxy connection %s to clone %s", conn.RemoteAddr(), s.clone.ID))
+
+	pipe(conn, serverConn)
+}
+
+func (p *Proxy) reject(conn net.Conn, code, message string) {
+	log.Msg(message)
+
+	if _, err := conn.Write(ErrorResponse(code, message)); err != nil {
+		log.Dbg("Failed to send error response: ", err)
+	}
+}
+
+// acquire returns the clone session of the key, the clone is found or created if the session does not exist.
+func (p *Proxy) acquire(ctx context.Context, key, user string) (*session, error) {
+	p.mu.Lock()
+
+	s, ok := p.sessions[key]
+	if !ok {
+		s = &session{key: key, ready: make(chan struct{})}
+		p.sessions[key] = s
+	}
+
+	s.conns++
+
+	p.mu.Unlock()
+
+	if !ok {
+		s.clone, s.err = p.findOrCreateClone(ctx, key, user)
+		close(s.ready)
+	}
+
+	select {
+	case <-s.ready:
+	case <-ctx.Done():
+		p.release(s)
+		return nil, ctx.Err()
+	}
+
+	if s.err != nil {
+		p.release(s)
+		p.remove(s)
+
+		return nil, s.err
+	}
+
+	return s, nil
+}
+
+func (p *Proxy) release(s *session) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	s.conns--
+
+	if s.conns == 0 {
+		s.idleSince = time.Now()
+	}
+}
+
+func (p *Proxy) remove(s *session) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.sessions[s.key] == s {
+		delete(p.sessions, s.key)
+	}
+}
+
+func (p *Proxy) findOrCreateClone(ctx context.Context, key, user string) (*models.Clone, error) {
+	page, err := p.cloner.ListClonesPage(ctx, types.CloneListRequest{
+		Selector: KeyLabel + "=" + key,
+		Status:   []string{string(models.StatusOK)},
+	})
+
+	switch {
+	case err != nil:
+		log.Dbg("Failed to find an existing clone: ", err)
+
+	case len(page.Clones) > 0:
+		log.Msg(fmt.Sprintf("Reuse clone %s for %q", page.Clones[0].ID, key))
+		return page.Clones[0], nil
+	}
+
+	labels := make(map[string]string, len(p.cfg.Labels)+1)
+
+	for labelKey, value := range p.cfg.Labels {
+		labels[labelKey] = value
+	}
+
+	labels[KeyLabel] = key
+
+	cloneRequest := types.CloneCreateRequest{
+		DB: &types.DatabaseRequest{
+			Username: user,
+			Password: p.cfg.Password,
+		},
+		Labels: labels,
+	}
+
+	if p.cfg.SnapshotID != "" {
+		cloneRequest.Snapshot = &types.SnapshotCloneFieldRequest{ID: p.cfg.SnapshotID}
+	}
+
+	log.Msg(fmt.Sprintf("Create a new clone for %q", key))
+
+	clone, err := p.cloner.CreateClone(ctx, cloneRequest)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create clone")
+	}
+
+	log.Msg(fmt.Sprintf("Clone %s has been created for %q", clone.ID, key))
+
+	return clone, nil
+}
+
+func (p *Proxy) watchIdleClones(ctx context.Context) {
+	interval := p.cfg.IdleTimeout / reapDivider
+	if interval > maxReapInterval {
+		interval = maxReapInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			p.destroyIdleClones(ctx, time.Now())
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// destroyIdleClones destroys clones having no connections longer than the idle timeout.
+func (p *Proxy) destroyIdleClones(ctx context.Context, now time.Time) {
+	idleSessions := []*session{}
+
+	p.mu.Lock()
+
+	for key, s := range p.sessions {
+		if s.conns == 0 && s.isReady() && s.clone != nil && now.Sub(s.idleSince) >= p.cfg.IdleTimeout {
+			idleSessions = append(idleSessions, s)
+			delete(p.sessions, key)
+		}
+	}
+
+	p.mu.Unlock()
+
+	for _, s := range idleSessions {
+		log.Msg(fmt.Sprintf("Destroy idle clone %s of %q", s.clone.ID, s.key))
+
+		if err := p.cloner.DestroyClone(ctx, s.clone.ID); err != nil {
+			log.Err(fmt.Sprintf("Failed to destroy clone %s: %v", s.clone.ID, err))
+		}
+	}
+}
+
+// pipe copies data between connections until one of the sides closes the connection.
+func pipe(client, server net.Conn) {
+	const directions = 2
+
+	done := make(chan struct{}, directions)
+
+	copyConn := func(dst, src net.Conn) {
+		if _, err := io.Copy(dst, src); err != nil {
+			log.Dbg("Proxy connection has been closed: ", err)
+		}
+
+		done <- struct{}{}
+	}
+
+	go copyConn(server, client)
+	go copyConn(client, server)
+
+	<-done
+}
@@ -0,0 +1,172 @@
+package cloneproxy
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/client/dblabapi/types"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+type mockCloner struct {
+	mu        sync.Mutex
+	created   []types.CloneCreateRequest
+	destroyed []string
+	existing  map[string]*models.Clone
+}
+
+func (m *mockCloner) CreateClone(_ context.Context, cloneRequest types.CloneCreateRequest) (*models.Clone, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.created = append(m.created, cloneRequest)
+
+	return &models.Clone{ID: fmt.Sprintf("clone%d", len(m.created)), Labels: cloneRequest.Labels}, nil
+}
+
+func (m *mockCloner) ListClonesPage(_ context.Context, listRequest types.CloneListRequest) (*models.ClonePage, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	page := &models.ClonePage{}
+
+	if clone, ok := m.existing[listRequest.Selector]; ok {
+		page.Clones = append(page.Clones, clone)
+	}
+
+	return page, nil
+}
+
+func (m *mockCloner) DestroyClone(_ context.Context, cloneID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.destroyed = append(m.destroyed, cloneID)
+
+	return nil
+}
+
+// echoDialer returns connections to a server echoing everything it receives.
+func echoDialer(dialed chan<- string) Dialer {
+	return func(_ context.Context, clone *models.Clone) (net.Conn, error) {
+		client, server := net.Pipe()
+
+		go func() {
+			defer func() { _ = server.Close() }()
+
+			_, _ = io.Copy(server, server)
+		}()
+
+		dialed <- clone.ID
+
+		return client, nil
+	}
+}
+
+func connect(t *testing.T, addr string, parameters ...string) net.Conn {
+	t.Helper()
+
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+
+	_, err = conn.Write(startupPacket(protocolVersion3, parameters...))
+	require.NoError(t, err)
+
+	return conn
+}
+
+func TestProxy(t *testing.T) {
+	cloner := &mockCloner{existing: map[string]*models.Clone{
+		KeyLabel + "=alice": {ID: "alice-clone"},
+	}}
+	dialed := make(chan string, 10)
+
+	proxy := New(Config{
+		KeyParameter: "user",
+		Password:     "secret",
+		SnapshotID:   "snapshot1",
+		Labels:       map[string]string{"team": "billing"},
+	}, cloner, echoDialer(dialed))
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() { _ = proxy.Serve(ctx, listener) }()
+
+	addr := listener.Addr().String()
+
+	first := connect(t, addr, "user", "john")
+	second := connect(t, addr, "user", "john", "database", "app")
+	third := connect(t, addr, "user", "alice")
+
+	clones := []string{<-dialed, <-dialed, <-dialed}
+	assert.ElementsMatch(t, []string{"clone1", "clone1", "alice-clone"}, clones)
+
+	// The startup message is forwarded, then data is proxied in both directions.
+	startup := startupPacket(protocolVersion3, "user", "john")
+	received := make([]byte, len(startup)+4)
+
+	_, err = first.Write([]byte("ping"))
+	require.NoError(t, err)
+
+	_, err = io.ReadFull(first, received)
+	require.NoError(t, err)
+	assert.Equal(t, append(startup, []byte("ping")...), received)
+
+	require.Len(t, cloner.created, 1)
+	assert.Equal(t, types.CloneCreateRequest{
+		DB:       &types.DatabaseRequest{Username: "john", Password: "secret"},
+		Snapshot: &types.SnapshotCloneFieldRequest{ID: "snapshot1"},
+		Labels:   map[string]string{"team": "billing", KeyLabel: "john"},
+	}, cloner.created[0])
+
+	require.NoError(t, first.Close())
+	require.NoError(t, third.Close())
+
+	// Clones with connections are kept.
+	require.Eventually(t, func() bool {
+		proxy.mu.Lock()
+		defer proxy.mu.Unlock()
+
+		return proxy.sessions["alice"].conns == 0 && proxy.sessions["john"].conns == 1
+	}, time.Second, 10*time.Millisecond)
+
+	proxy.cfg.IdleTimeout = time.Minute
+	proxy.destroyIdleClones(ctx, time.Now().Add(time.Hour))
+
+	assert.Equal(t, []string{"alice-clone"}, cloner.destroyed)
+
+	require.NoError(t, second.Close())
+}
+
+func TestProxyRejectsConnection(t *testing.T) {
+	proxy := New(Config{KeyParameter: "application_name"}, &mockCloner{}, echoDialer(make(chan string, 1)))
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() { _ = proxy.Serve(ctx, listener) }()
+
+	conn := connect(t, listener.Addr().String(), "user", "john")
+	defer func() { _ = conn.Close() }()
+
+	response, err := io.ReadAll(conn)
+	require.NoError(t, err)
+	assert.Equal(t, byte('E'), response[0])
+	assert.True(t, bytes.Contains(response, []byte(`startup parameter "application_name" is missing or invalid`)))
+}
@@ -0,0 +1,303 @@
+package cloneproxy
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+)
+
+const (
+	// RouteSeparator separates the clone ID from the database or user name in startup parameters.
+	RouteSeparator = "__"
+
+	databaseParameter = "database"
+
+	routerDialTimeout    = 10 * time.Second
+	routerStartupTimeout = 30 * time.Second
+)
+
+// sslAccepted is the server response accepting SSL encryption.
+var sslAccepted = []byte{'S'}
+
+// CloneResolver finds clones of routed connections.
+type CloneResolver interface {
+	// ResolveClone returns the address of the clone accepting connections of the client.
+	ResolveClone(cloneID string, clientAddr net.Addr) (string, error)
+}
+
+// Router accepts Postgres connections on a single port and forwards them to clones.
+// The clone is selected by the TLS SNI hostname "<clone-id>.<host>" if SSL is enabled,
+// or by the database or user name in the startup message, for example, "<clone-id>__appdb".
+type Router struct {
+	resolver CloneResolver
+	ssl      bool
+}
+
+// NewRouter creates a new router. SSL connections are passed through to clones, so clones must accept SSL if it is enabled.
+func NewRouter(resolver CloneResolver, ssl bool) *Router {
+	return &Router{resolver: resolver, ssl: ssl}
+}
+
+// Serve accepts connections until the listener is closed.
+func (r *Router) Serve(listener net.Listener) error {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+
+			return errors.Wrap(err, "failed to accept connection")
+		}
+
+		go r.handle(conn)
+	}
+}
+
+func (r *Router) handle(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+
+	if err := conn.SetDeadline(time.Now().Add(routerStartupTimeout)); err != nil {
+		log.Dbg("Failed to set a deadline of the routed connection: ", err)
+		return
+	}
+
+	for {
+		raw, err := readStartupPacket(conn)
+		if err != nil {
+			log.Dbg("Failed to read startup message: ", err)
+			return
+		}
+
+		switch binary.BigEndian.Uint32(raw[startupLengthBytes:]) {
+		case sslRequestCode:
+			if r.ssl {
+				r.routeSSL(conn, raw)
+				return
+			}
+
+			if _, err := conn.Write(sslNotSupported); err != nil {
+				log.Dbg("Failed to decline encryption: ", err)
+				return
+			}
+
+		case gssEncRequestCode:
+			if _, err := conn.Write(sslNotSupported); err != nil {
+				log.Dbg("Failed to decline encryption: ", err)
+				return
+			}
+
+		case protocolVersion3:
+			r.routeStartup(conn, raw)
+			return
+
+		default:
+			r.reject(conn, sqlStateConnectionFailure, "unsupported startup request, cancel requests are not routed")
+			return
+		}
+	}
+}
+
+// routeStartup forwards the unencrypted connection to the clone named in the startup parameters.
+func (r *Router) routeStartup(conn net.Conn, raw []byte) {
+	parameters, err := parseStartupParameters(raw[startupLengthBytes+startupCodeBytes:])
+	if err != nil {
+		log.Dbg("Failed to parse startup parameters: ", err)
+		return
+	}
+
+	cloneID, ok := routeParameters(parameters)
+	if !ok {
+		r.reject(conn, sqlStateInvalidAuthorization,
+			fmt.Sprintf("database or user name must start with the clone ID followed by %q", RouteSeparator))
+
+		return
+	}
+
+	serverConn, err := r.dial(cloneID, conn.RemoteAddr())
+	if err != nil {
+		r.reject(conn, sqlStateConnectionFailure, err.Error())
+		return
+	}
+
+	defer func() { _ = serverConn.Close() }()
+
+	if _, err := serverConn.Write(startupMessage(parameters)); err != nil {
+		log.Dbg("Failed to forward startup message: ", err)
+		return
+	}
+
+	r.proxy(conn, serverConn, cloneID)
+}
+
+// routeSSL accepts SSL, reads the clone ID from the SNI hostname and passes the encrypted connection through to the clone.
+// Errors cannot be reported to the client after SSL is accepted, so the connection is closed.
+func (r *Router) routeSSL(conn net.Conn, sslRequest []byte) {
+	if _, err := conn.Write(sslAccepted); err != nil {
+		log.Dbg("Failed to accept encryption: ", err)
+		return
+	}
+
+	serverName, clientHello, err := peekServerName(conn)
+	if err != nil {
+		log.Dbg("Failed to read TLS ClientHello: ", err)
+		return
+	}
+
+	cloneID, _, _ := strings.Cut(serverName, ".")
+	if cloneID == "" {
+		log.Msg(fmt.Sprintf("Reject SSL connection from %s without SNI hostname", conn.RemoteAddr()))
+		return
+	}
+
+	serverConn, err := r.dial(cloneID, conn.RemoteAddr())
+	if err != nil {
+		log.Msg(fmt.Sprintf("Reject SSL connection from %s: %v", conn.RemoteAddr(), err))
+		return
+	}
+
+	defer func() { _ = serverConn.Close() }()
+
+	if _, err := serverConn.Write(sslRequest); err != nil {
+		log.Dbg("Failed to forward SSL request: ", err)
+		return
+	}
+
+	response := make([]byte, 1)
+
+	if _, err := io.ReadFull(serverConn, response); err != nil || !bytes.Equal(response, sslAccepted) {
+		log.Msg(fmt.Sprintf("Clone %s does not accept SSL connections", cloneID))
+		return
+	}
+
+	if _, err := serverConn.Write(clientHello); err != nil {
+		log.Dbg("Failed to forward TLS ClientHello: ", err)
+		return
+	}
+
+	r.proxy(conn, serverConn, cloneID)
+}
+
+func (r *Router) dial(cloneID string, clientAddr net.Addr) (net.Conn, error) {
+	addr, err := r.resolver.ResolveClone(cloneID, clientAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	serverConn, err := net.DialTimeout("tcp", addr, routerDialTimeout)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to connect to clone %s", cloneID)
+	}
+
+	return serverConn, nil
+}
+
+func (r *Router) proxy(conn, serverConn net.Conn, cloneID string) {
+	if err := conn.SetDeadline(time.Time{}); err != nil {
+		log.Dbg("Failed to reset a deadline of the routed connection: ", err)
+		return
+	}
+
+	log.Dbg(fmt.Sprintf("Route connection %s to clone %s", conn.RemoteAddr(), cloneID))
+
+	pipe(conn, serverConn)
+}
+
+func (r *Router) reject(conn net.Conn, code, message string) {
+	log.Msg(message)
+
+	if _, err := conn.Write(ErrorResponse(code, message)); err != nil {
+		log.Dbg("Failed to send error response: ", err)
+	}
+}
+
+// routeParameters extracts the clone ID from the database name or, if the database is not routed, from the user name.
+// The clone ID is removed from the parameter, so the clone gets the original name.
+func routeParameters(parameters map[string]string) (string, bool) {
+	for _, name := range []string{databaseParameter, userParameter} {
+		cloneID, value, found := strings.Cut(parameters[name], RouteSeparator)
+		if !found || cloneID == "" || value == "" {
+			continue
+		}
+
+		parameters[name] = value
+
+		// Postgres uses the user name if the database is not specified.
+		if name == userParameter && parameters[databaseParameter] == "" {
+			parameters[databaseParameter] = value
+		}
+
+		return cloneID, true
+	}
+
+	return "", false
+}
+
+// startupMessage builds the startup message of the protocol version 3 with the parameters.
+func startupMessage(parameters map[string]string) []byte {
+	body := bytes.NewBuffer(nil)
+
+	for name, value := range parameters {
+		body.WriteString(name)
+		body.WriteByte(0)
+		body.WriteString(value)
+		body.WriteByte(0)
+	}
+
+	body.WriteByte(0)
+
+	msg := make([]byte, startupLengthBytes+startupCodeBytes, startupLengthBytes+startupCodeBytes+body.Len())
+	binary.BigEndian.PutUint32(msg, uint32(len(msg)+body.Len()))
+	binary.BigEndian.PutUint32(msg[startupLengthBytes:], protocolVersion3)
+
+	return append(msg, body.Bytes()...)
+}
+
+// peekServerName reads the TLS ClientHello and returns the SNI hostname with the read bytes to forward them.
+func peekServerName(r io.Reader) (string, []byte, error) {
+	peeked := bytes.NewBuffer(nil)
+
+	var serverName string
+
+	// The handshake stops after the ClientHello is read, because the connection cannot be written.
+	err := tls.Server(readOnlyConn{reader: io.TeeReader(r, peeked)}, &tls.Config{
+		GetConfigForClient: func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+			serverName = hello.ServerName
+
+			return nil, errClientHelloRead
+		},
+	}).HandshakeContext(context.Background())
+
+	if !errors.Is(err, errClientHelloRead) {
+		return "", nil, errors.Wrap(err, "failed to parse ClientHello")
+	}
+
+	return serverName, peeked.Bytes(), nil
+}
+
+var errClientHelloRead = errors.New("ClientHello has been read")
+
+// readOnlyConn lets the TLS server read the ClientHello without responding to the client.
+type readOnlyConn struct {
+	net.Conn
+	reader io.Reader
+}
+
+func (c readOnlyConn) Read(p []byte) (int, error)         { return c.reader.Read(p) }
+func (c readOnlyConn) Write(_ []byte) (int, error)        { return 0, io.ErrClosedPipe }
+func (c readOnlyConn) Close() error                       { return nil }
+func (c readOnlyConn) LocalAddr() net.Addr                { return nil }
+func (c readOnlyConn) RemoteAddr() net.Addr               { return nil }
+func (c readOnlyConn) SetDeadline(_ time.Time) error      { return nil }
+func (c readOnlyConn) SetReadDeadline(_ time.Time) error  { return nil }
+func (c readOnlyConn) SetWriteDeadline(_ time.Time) error { return nil }
@@ -0,0 +1,208 @@
+package cloneproxy
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/certs"
+)
+
+type mockResolver map[string]string
+
+func (m mockResolver) ResolveClone(cloneID string, _ net.Addr) (string, error) {
+	addr, ok := m[cloneID]
+	if !ok {
+		return "", errors.Errorf("clone %s not found", cloneID)
+	}
+
+	return addr, nil
+}
+
+// listenClone starts a server handling one connection of the clone.
+func listenClone(t *testing.T, handle func(conn net.Conn)) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+
+		defer func() { _ = conn.Close() }()
+
+		handle(conn)
+	}()
+
+	return listener.Addr().String()
+}
+
+func startRouter(t *testing.T, resolver CloneResolver, ssl bool) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() { _ = NewRouter(resolver, ssl).Serve(listener) }()
+
+	return listener.Addr().String()
+}
+
+func TestRouterStartupMessage(t *testing.T) {
+	startups := make(chan *StartupMessage, 1)
+
+	cloneAddr := listenClone(t, func(conn net.Conn) {
+		startup, err := ReadStartupMessage(conn)
+		if err != nil {
+			return
+		}
+
+		startups <- startup
+
+		_, _ = io.Copy(conn, conn)
+	})
+
+	routerAddr := startRouter(t, mockResolver{"clone1": cloneAddr}, false)
+
+	conn, err := net.Dial("tcp", routerAddr)
+	require.NoError(t, err)
+
+	defer func() { _ = conn.Close() }()
+
+	_, err = conn.Write(startupPacket(sslRequestCode))
+	require.NoError(t, err)
+
+	response := make([]byte, 1)
+	_, err = io.ReadFull(conn, response)
+	require.NoError(t, err)
+	assert.Equal(t, sslNotSupported, response)
+
+	_, err = conn.Write(startupPacket(protocolVersion3, "user", "john", "database", "clone1__app"))
+	require.NoError(t, err)
+
+	startup := <-startups
+	assert.Equal(t, map[string]string{"user": "john", "database": "app"}, startup.Parameters)
+
+	_, err = conn.Write([]byte("ping"))
+	require.NoError(t, err)
+
+	received := make([]byte, 4)
+	_, err = io.ReadFull(conn, received)
+	require.NoError(t, err)
+	assert.Equal(t, "ping", string(received))
+}
+
+func TestRouterRejectsUnknownClone(t *testing.T) {
+	routerAddr := startRouter(t, mockResolver{}, false)
+
+	conn, err := net.Dial("tcp", routerAddr)
+	require.NoError(t, err)
+
+	defer func() { _ = conn.Close() }()
+
+	_, err = conn.Write(startupPacket(protocolVersion3, "user", "clone2__john"))
+	require.NoError(t, err)
+
+	response, err := io.ReadAll(conn)
+	require.NoError(t, err)
+	assert.Equal(t, byte('E'), response[0])
+	assert.True(t, bytes.Contains(response, []byte("clone clone2 not found")))
+}
+
+func TestRouteParameters(t *testing.T) {
+	testCases := []struct {
+		parameters map[string]string
+		cloneID    string
+		expected   map[string]string
+	}{
+		{
+			parameters: map[string]string{"user": "john", "database": "clone1__app"},
+			cloneID:    "clone1",
+			expected:   map[string]string{"user": "john", "database": "app"},
+		},
+		{
+			parameters: map[string]string{"user": "clone1__john"},
+			cloneID:    "clone1",
+			expected:   map[string]string{"user": "john", "database": "john"},
+		},
+		{
+			parameters: map[string]string{"user": "john", "database": "app"},
+			expected:   map[string]string{"user": "john", "database": "app"},
+		},
+	}
+
+	for _, tc := range testCases {
+		cloneID, ok := routeParameters(tc.parameters)
+		assert.Equal(t, tc.cloneID != "", ok)
+		assert.Equal(t, tc.cloneID, cloneID)
+		assert.Equal(t, tc.expected, tc.parameters)
+	}
+}
+
+func TestRouterSSL(t *testing.T) {
+	ca, err := certs.LoadAuthority(t.TempDir())
+	require.NoError(t, err)
+
+	certPEM, keyPEM, err := ca.IssueServerCert("dblab_clone_6000", []string{"*.dblab.example.com"})
+	require.NoError(t, err)
+
+	serverCert, err := tls.X509KeyPair(certPEM, keyPEM)
+	require.NoError(t, err)
+
+	cloneAddr := listenClone(t, func(conn net.Conn) {
+		if _, err := readStartupPacket(conn); err != nil {
+			return
+		}
+
+		if _, err := conn.Write(sslAccepted); err != nil {
+			return
+		}
+
+		tlsConn := tls.Server(conn, &tls.Config{Certificates: []tls.Certificate{serverCert}})
+
+		_, _ = io.Copy(tlsConn, tlsConn)
+	})
+
+	routerAddr := startRouter(t, mockResolver{"clone1": cloneAddr}, true)
+
+	conn, err := net.Dial("tcp", routerAddr)
+	require.NoError(t, err)
+
+	defer func() { _ = conn.Close() }()
+
+	_, err = conn.Write(startupPacket(sslRequestCode))
+	require.NoError(t, err)
+
+	response := make([]byte, 1)
+	_, err = io.ReadFull(conn, response)
+	require.NoError(t, err)
+	assert.Equal(t, sslAccepted, response)
+
+	roots := x509.NewCertPool()
+	require.True(t, roots.AppendCertsFromPEM(ca.CertPEM()))
+
+	tlsConn := tls.Client(conn, &tls.Config{ServerName: "clone1.dblab.example.com", RootCAs: roots, MinVersion: tls.VersionTLS12})
+	require.NoError(t, tlsConn.Handshake())
+
+	_, err = tlsConn.Write([]byte("ping"))
+	require.NoError(t, err)
+
+	received := make([]byte, 4)
+	_, err = io.ReadFull(tlsConn, received)
+	require.NoError(t, err)
+	assert.Equal(t, "ping", string(received))
+}
@@ -0,0 +1,139 @@
+package cloneproxy
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// Protocol codes of the Postgres startup packets.
+const (
+	protocolVersion3   = 196608
+	sslRequestCode     = 80877103
+	gssEncRequestCode  = 80877104
+	cancelRequestCode  = 80877102
+	startupLengthBytes = 4
+	startupCodeBytes   = 4
+	maxStartupLength   = 10000
+)
+
+// sslNotSupported is the server response declining SSL and GSSAPI encryption.
+var sslNotSupported = []byte{'N'}
+
+// StartupMessage describes the startup message of a Postgres connection.
+type StartupMessage struct {
+	// Parameters contains startup parameters, such as user, database and application_name.
+	Parameters map[string]string
+	// Raw contains the whole message to forward it to the server.
+	Raw []byte
+}
+
+// ReadStartupMessage reads the startup message of a client connection.
+// Encryption requests are declined, so clients continue with an unencrypted connection.
+func ReadStartupMessage(conn io.ReadWriter) (*StartupMessage, error) {
+	for {
+		raw, err := readStartupPacket(conn)
+		if err != nil {
+			return nil, err
+		}
+
+		code := binary.BigEndian.Uint32(raw[startupLengthBytes:])
+
+		switch code {
+		case sslRequestCode, gssEncRequestCode:
+			if _, err := conn.Write(sslNotSupported); err != nil {
+				return nil, errors.Wrap(err, "failed to decline encryption")
+			}
+
+			continue
+
+		case cancelRequestCode:
+			return nil, errors.New("cancel requests are not supported")
+
+		case protocolVersion3:
+			parameters, err := parseStartupParameters(raw[startupLengthBytes+startupCodeBytes:])
+			if err != nil {
+				return nil, err
+			}
+
+			return &StartupMessage{Parameters: parameters, Raw: raw}, nil
+		}
+
+		return nil, errors.Errorf("unsupported protocol version: %d", code)
+	}
+}
+
+func readStartupPacket(r io.Reader) ([]byte, error) {
+	header := make([]byte, startupLengthBytes)
+
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, errors.Wrap(err, "failed to read the startup packet length")
+	}
+
+	length := binary.BigEndian.Uint32(header)
+	if length < startupLengthBytes+startupCodeBytes || length > maxStartupLength {
+		return nil, errors.Errorf("invalid startup packet length: %d", length)
+	}
+
+	raw := make([]byte, length)
+	copy(raw, header)
+
+	if _, err := io.ReadFull(r, raw[startupLengthBytes:]); err != nil {
+		return nil, errors.Wrap(err, "failed to read the startup packet")
+	}
+
+	return raw, nil
+}
+
+// parseStartupParameters parses null-terminated name/value pairs ending with an empty name.
+func parseStartupParameters(data []byte) (map[string]string, error) {
+	parameters := make(map[string]string)
+
+	for {
+		name, rest, found := bytes.Cut(data, []byte{0})
+		if !found {
+			return nil, errors.New("malformed startup parameters")
+		}
+
+		if len(name) == 0 {
+			return parameters, nil
+		}
+
+		value, rest, found := bytes.Cut(rest, []byte{0})
+		if !found {
+			return nil, errors.Errorf("missing value of startup parameter %q", name)
+		}
+
+		parameters[string(name)] = string(value)
+		data = rest
+	}
+}
+
+// ErrorResponse builds a fatal ErrorResponse message to report an error to the client.
+func ErrorResponse(code, message string) []byte {
+	fields := bytes.NewBuffer(nil)
+
+	for _, field := range []struct {
+		kind  byte
+		value string
+	}{
+		{kind: 'S', value: "FATAL"},
+		{kind: 'V', value: "FATAL"},
+		{kind: 'C', value: code},
+		{kind: 'M', value: message},
+	} {
+		fields.WriteByte(field.kind)
+		fields.WriteString(field.value)
+		fields.WriteByte(0)
+	}
+
+	fields.WriteByte(0)
+
+	msg := make([]byte, 1+startupLengthBytes, 1+startupLengthBytes+fields.Len())
+	msg[0] = 'E'
+	binary.BigEndian.PutUint32(msg[1:], uint32(startupLengthBytes+fields.Len()))
+
+	return append(msg, fields.Bytes()...)
+}
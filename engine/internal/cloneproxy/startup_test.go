@@ -0,0 +1,76 @@
+package cloneproxy
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type readWriter struct {
+	*bytes.Reader
+	written bytes.Buffer
+}
+
+func (rw *readWriter) Write(p []byte) (int, error) {
+	return rw.written.Write(p)
+}
+
+func startupPacket(code uint32, payload ...string) []byte {
+	body := bytes.NewBuffer(nil)
+
+	for _, s := range payload {
+		body.WriteString(s)
+		body.WriteByte(0)
+	}
+
+	if len(payload) > 0 {
+		body.WriteByte(0)
+	}
+
+	packet := make([]byte, 8, 8+body.Len())
+	binary.BigEndian.PutUint32(packet, uint32(8+body.Len()))
+	binary.BigEndian.PutUint32(packet[4:], code)
+
+	return append(packet, body.Bytes()...)
+}
+
+func TestReadStartupMessage(t *testing.T) {
+	startup := startupPacket(protocolVersion3, "user", "john", "database", "app")
+
+	conn := &readWriter{Reader: bytes.NewReader(append(startupPacket(sslRequestCode), startup...))}
+
+	msg, err := ReadStartupMessage(conn)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"user": "john", "database": "app"}, msg.Parameters)
+	assert.Equal(t, startup, msg.Raw)
+	assert.Equal(t, "N", conn.written.String())
+}
+
+func TestReadStartupMessageErrors(t *testing.T) {
+	testCases := []struct {
+		name   string
+		packet []byte
+	}{
+		{name: "cancel request", packet: startupPacket(cancelRequestCode)},
+		{name: "unsupported version", packet: startupPacket(131072, "user", "john")},
+		{name: "invalid length", packet: []byte{0, 0, 0, 2}},
+		{name: "missing value", packet: []byte{0, 0, 0, 15, 0, 3, 0, 0, 'u', 's', 'e', 'r', 0, 'j', 'o'}},
+		{name: "truncated", packet: []byte{0, 0, 0, 20, 0}},
+	}
+
+	for _, tc := range testCases {
+		_, err := ReadStartupMessage(&readWriter{Reader: bytes.NewReader(tc.packet)})
+		assert.Error(t, err, tc.name)
+	}
+}
+
+func TestErrorResponse(t *testing.T) {
+	msg := ErrorResponse("08001", "no clone")
+
+	assert.Equal(t, byte('E'), msg[0])
+	assert.Equal(t, uint32(len(msg)-1), binary.BigEndian.Uint32(msg[1:]))
+	assert.Equal(t, "SFATAL\x00VFATAL\x00C08001\x00Mno clone\x00\x00", string(msg[5:]))
+}
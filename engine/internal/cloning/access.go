@@ -0,0 +1,82 @@
+package cloning
+
+import (
+	"net"
+
+	"github.com/pkg/errors"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+// updateAllowedCIDRs changes client CIDRs allowed to connect to the clone.
+// A running clone is busy while its container is reconfigured or restarted, a stopped clone gets the new allowlist on the next start.
+func (c *Base) updateAllowedCIDRs(w *CloneWrapper, cidrs []string) error {
+	allowedCIDRs, err := provision.NormalizeCIDRs(cidrs)
+	if err != nil {
+		return err
+	}
+
+	c.cloneMutex.RLock()
+	status := w.Clone.Status.Code
+	session := w.Session
+	c.cloneMutex.RUnlock()
+
+	if session == nil {
+		return models.New(models.ErrCodeCloneNotStarted, "clone is not started yet")
+	}
+
+	switch status {
+	case models.StatusOK:
+		if _, err := c.transitCloneStatus(w.Clone.ID, []models.StatusCode{models.StatusOK}, models.Status{
+			Code:    models.StatusResetting,
+			Message: models.CloneMessageAccess,
+		}); err != nil {
+			return err
+		}
+
+		if err := c.provision.UpdateSessionAccess(session, allowedCIDRs); err != nil {
+			if updateErr := c.UpdateCloneStatus(w.Clone.ID, models.Status{
+				Code:    models.StatusFatal,
+				Message: errors.Cause(err).Error(),
+			}); updateErr != nil {
+				log.Errf("Failed to update clone status: %v", updateErr)
+			}
+
+			return errors.Wrap(err, "failed to update the client allowlist")
+		}
+
+	case models.StatusStopped:
+		// The allowlist is applied on the next start.
+
+	default:
+		return models.New(models.ErrCodeCloneBusy, "client allowlist cannot be changed, clone status is "+string(status))
+	}
+
+	c.cloneMutex.Lock()
+	w.Clone.AllowedCIDRs = allowedCIDRs
+	w.Session.AllowedCIDRs = allowedCIDRs
+	c.cloneMutex.Unlock()
+
+	if status == models.StatusOK {
+		if err := c.UpdateCloneStatus(w.Clone.ID, models.Status{
+			Code:    models.StatusOK,
+			Message: models.CloneMessageOK,
+		}); err != nil {
+			log.Errf("Failed to update clone status: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// isAllowedClient checks if the client is allowed to connect to the clone.
+func isAllowedClient(w *CloneWrapper, clientAddr net.Addr) bool {
+	tcpAddr, ok := clientAddr.(*net.TCPAddr)
+	if !ok {
+		return false
+	}
+
+	return provision.IsAllowedClient(w.Clone.AllowedCIDRs, tcpAddr.IP)
+}
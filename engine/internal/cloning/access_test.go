@@ -0,0 +1,37 @@
+package cloning
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/resources"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+func (s *BaseCloningSuite) TestUpdateAllowedCIDRs() {
+	stopped := &CloneWrapper{
+		Clone:   &models.Clone{ID: "stopped", Status: models.Status{Code: models.StatusStopped}},
+		Session: &resources.Session{Port: 6000},
+	}
+	resetting := &CloneWrapper{
+		Clone:   &models.Clone{ID: "resetting", Status: models.Status{Code: models.StatusResetting}},
+		Session: &resources.Session{Port: 6001},
+	}
+
+	s.cloning.setWrapper("stopped", stopped)
+	s.cloning.setWrapper("resetting", resetting)
+
+	require.NoError(s.T(), s.cloning.updateAllowedCIDRs(stopped, []string{"127.0.0.1"}))
+	assert.Equal(s.T(), []string{"127.0.0.1/32"}, stopped.Clone.AllowedCIDRs)
+	assert.Equal(s.T(), []string{"127.0.0.1/32"}, stopped.Session.AllowedCIDRs)
+	assert.Equal(s.T(), models.StatusStopped, stopped.Clone.Status.Code)
+
+	err := s.cloning.updateAllowedCIDRs(stopped, []string{"10.1.0.0/16"})
+	assert.Equal(s.T(), models.ErrCodeBadRequest, err.(*models.Error).Code)
+	assert.Equal(s.T(), []string{"127.0.0.1/32"}, stopped.Clone.AllowedCIDRs)
+
+	err = s.cloning.updateAllowedCIDRs(resetting, []string{"127.0.0.1"})
+	assert.Equal(s.T(), models.ErrCodeCloneBusy, err.(*models.Error).Code)
+	assert.Empty(s.T(), resetting.Clone.AllowedCIDRs)
+	assert.Equal(s.T(), models.StatusResetting, resetting.Clone.Status.Code)
+}
@@ -8,6 +8,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"net"
 	"sort"
 	"strconv"
 	"strings"
@@ -20,7 +21,9 @@ import (
 	"github.com/pkg/errors"
 	"github.com/rs/xid"
 
+	"gitlab.com/postgres-ai/database-lab/v3/internal/events"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/provision"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/certs"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/resources"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/telemetry"
 	"gitlab.com/postgres-ai/database-lab/v3/pkg/client/dblabapi/types"
@@ -38,8 +41,12 @@ const (
 
 // Config contains a cloning configuration.
 type Config struct {
-	MaxIdleMinutes uint   `yaml:"maxIdleMinutes"`
-	AccessHost     string `yaml:"accessHost"`
+	MaxIdleMinutes      uint         `yaml:"maxIdleMinutes"`
+	AccessHost          string       `yaml:"accessHost"`
+	HibernateIdleClones bool         `yaml:"hibernateIdleClones"`
+	WakeOnConnect       bool         `yaml:"wakeOnConnect"`
+	TLS                 TLSConfig    `yaml:"tls"`
+	Router              RouterConfig `yaml:"router"`
 }
 
 // Base provides cloning service.
@@ -51,16 +58,29 @@ type Base struct {
 	provision   *provision.Provisioner
 	tm          *telemetry.Agent
 	observingCh chan string
+	events      *events.Hub
+	idempotency idempotencyStore
+
+	wakeMu        sync.Mutex
+	wakeListeners map[string]net.Listener
+
+	tlsMu sync.RWMutex
+	ca    *certs.Authority
+
+	routerMu       sync.Mutex
+	routerListener net.Listener
+	routerSettings routerSettings
 }
 
 // NewBase instances a new Base service.
-func NewBase(cfg *Config, provision *provision.Provisioner, tm *telemetry.Agent, observingCh chan string) *Base {
+func NewBase(cfg *Config, provision *provision.Provisioner, tm *telemetry.Agent, observingCh chan string, eventHub *events.Hub) *Base {
 	return &Base{
 		config:      cfg,
 		clones:      make(map[string]*CloneWrapper),
 		provision:   provision,
 		tm:          tm,
 		observingCh: observingCh,
+		events:      eventHub,
 		snapshotBox: SnapshotBox{
 			items: make(map[string]*models.Snapshot),
 		},
@@ -70,10 +90,22 @@ func NewBase(cfg *Config, provision *provision.Provisioner, tm *telemetry.Agent,
 // Reload reloads base cloning configuration.
 func (c *Base) Reload(cfg Config) {
 	*c.config = cfg
+
+	c.reloadWakeListeners()
+
+	if err := c.setupTLS(); err != nil {
+		log.Err("Failed to reload TLS configuration of clones:", err)
+	}
+
+	c.syncRouter()
 }
 
 // Run initializes and runs cloning component.
 func (c *Base) Run(ctx context.Context) error {
+	if err := c.setupTLS(); err != nil {
+		return err
+	}
+
 	if err := c.provision.RevisePortPool(); err != nil {
 		return fmt.Errorf("failed to revise port pool: %w", err)
 	}
@@ -86,6 +118,10 @@ func (c *Base) Run(ctx context.Context) error {
 		log.Err("Failed to load stored sessions:", err)
 	}
 
+	if err := c.restoreIdempotencyState(); err != nil {
+		log.Err("Failed to load results of idempotent requests:", err)
+	}
+
 	c.restartCloneContainers(ctx)
 
 	c.filterRunningClones(ctx)
@@ -98,8 +134,14 @@ func (c *Base) Run(ctx context.Context) error {
 		return fmt.Errorf("failed to revise port pool: %w", err)
 	}
 
+	c.restoreStoppedClones()
+
+	c.syncRouter()
+
 	go c.runIdleCheck(ctx)
 
+	go c.provision.RunWarmPool(ctx)
+
 	return nil
 }
 
@@ -128,16 +170,33 @@ func (c *Base) CreateClone(cloneRequest *types.CloneCreateRequest) (*models.Clon
 	cloneRequest.ID = strings.TrimSpace(cloneRequest.ID)
 
 	if _, ok := c.findWrapper(cloneRequest.ID); ok {
-		return nil, models.New(models.ErrCodeBadRequest, fmt.Sprintf("clone with ID %q already exists", cloneRequest.ID))
+		return nil, models.New(models.ErrCodeCloneAlreadyExists, fmt.Sprintf("clone with ID %q already exists", cloneRequest.ID))
 	}
 
 	if cloneRequest.ID == "" {
 		cloneRequest.ID = xid.New().String()
 	}
 
+	if err := c.provision.CheckDockerImage(cloneRequest.DockerImage); err != nil {
+		return nil, err
+	}
+
+	allowedCIDRs, err := provision.NormalizeCIDRs(cloneRequest.AllowedCIDRs)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.provision.CheckInitProfile(cloneRequest.InitProfile); err != nil {
+		return nil, err
+	}
+
+	if !c.provision.HasFreePort() {
+		return nil, models.New(models.ErrCodePoolFull, "no available ports to start a clone")
+	}
+
 	createdAt := time.Now()
 
-	err := c.fetchSnapshots()
+	err = c.fetchSnapshots()
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to fetch snapshots")
 	}
@@ -167,12 +226,20 @@ func (c *Base) CreateClone(cloneRequest *types.CloneCreateRequest) (*models.Clon
 			Username: cloneRequest.DB.Username,
 			DBName:   cloneRequest.DB.DBName,
 		},
+		Labels:       cloneRequest.Labels,
+		DockerImage:  cloneRequest.DockerImage,
+		AllowedCIDRs: allowedCIDRs,
+	}
+
+	if clone.DockerImage == "" {
+		clone.DockerImage = c.provision.ContainerOptions().DockerImage
 	}
 
 	w := NewCloneWrapper(clone, createdAt)
 	cloneID := clone.ID
 
 	c.setWrapper(clone.ID, w)
+	c.publishCloneEvent(models.EventCloneStatus, clone)
 
 	ephemeralUser := resources.EphemeralUser{
 		Name:        cloneRequest.DB.Username,
@@ -184,7 +251,8 @@ func (c *Base) CreateClone(cloneRequest *types.CloneCreateRequest) (*models.Clon
 	c.incrementCloneNumber(clone.Snapshot.ID)
 
 	go func() {
-		session, err := c.provision.StartSession(clone.Snapshot.ID, ephemeralUser, cloneRequest.ExtraConf)
+		session, err := c.provision.StartSession(clone.Snapshot.ID, ephemeralUser, cloneRequest.ExtraConf,
+			cloneRequest.DockerImage, allowedCIDRs, cloneRequest.InitProfile)
 		if err != nil {
 			// TODO(anatoly): Empty room case.
 			log.Errf("Failed to start session: %v.", err)
@@ -220,10 +288,8 @@ func (c *Base) fillCloneSession(cloneID string, session *resources.Session) {
 	w.TimeStartedAt = time.Now()
 
 	clone := w.Clone
-	clone.Status = models.Status{
-		Code:    models.StatusOK,
-		Message: models.CloneMessageOK,
-	}
+	clone.Status = readyStatus(session)
+	clone.Init = toCloneInitView(session.InitRun)
 
 	dbName := clone.DB.DBName
 	if dbName == "" {
@@ -232,13 +298,15 @@ func (c *Base) fillCloneSession(cloneID string, session *resources.Session) {
 
 	clone.DB.Port = strconv.FormatUint(uint64(session.Port), 10)
 	clone.DB.Host = c.config.AccessHost
-	clone.DB.ConnStr = fmt.Sprintf("host=%s port=%s user=%s dbname=%s",
-		clone.DB.Host, clone.DB.Port, clone.DB.Username, dbName)
+	clone.DB.ConnStr = c.connStr(clone.DB.Host, clone.DB.Port, clone.DB.Username, dbName)
+	clone.DB.RouterConnStr = c.routerConnStr(clone.ID, clone.DB.Username, dbName)
 
 	clone.Metadata = models.CloneMetadata{
 		CloningTime:    w.TimeStartedAt.Sub(w.TimeCreatedAt).Seconds(),
 		MaxIdleMinutes: c.config.MaxIdleMinutes,
 	}
+
+	c.publishCloneEvent(models.EventCloneStatus, clone)
 }
 
 // ConnectToClone connects to clone by cloneID.
@@ -266,22 +334,34 @@ func connectionString(host, port, username, dbname string) string {
 
 // DestroyClone destroys clone.
 func (c *Base) DestroyClone(cloneID string) error {
+	_, err := c.destroyClone(cloneID, false)
+
+	return err
+}
+
+// destroyClone starts destroying the clone. The returned channel receives the result when the clone is destroyed.
+// Protected clones are destroyed only if forced.
+func (c *Base) destroyClone(cloneID string, force bool) (<-chan error, error) {
 	w, ok := c.findWrapper(cloneID)
 	if !ok {
-		return models.New(models.ErrCodeNotFound, "clone not found")
+		return nil, models.New(models.ErrCodeCloneNotFound, "clone not found")
 	}
 
-	if w.Clone.Protected && w.Clone.Status.Code != models.StatusFatal {
-		return models.New(models.ErrCodeBadRequest, "clone is protected")
+	if w.Clone.Protected && w.Clone.Status.Code != models.StatusFatal && !force {
+		return nil, models.New(models.ErrCodeCloneProtected, "clone is protected")
 	}
 
 	if err := c.UpdateCloneStatus(cloneID, models.Status{
 		Code:    models.StatusDeleting,
 		Message: models.CloneMessageDeleting,
 	}); err != nil {
-		return errors.Wrap(err, "failed to update clone status")
+		return nil, errors.Wrap(err, "failed to update clone status")
 	}
 
+	c.closeWakeListener(cloneID)
+
+	done := make(chan error, 1)
+
 	if w.Session == nil {
 		c.deleteClone(cloneID)
 
@@ -289,7 +369,9 @@ func (c *Base) DestroyClone(cloneID string) error {
 			c.decrementCloneNumber(w.Clone.Snapshot.ID)
 		}
 
-		return nil
+		done <- nil
+
+		return done, nil
 	}
 
 	go func() {
@@ -303,6 +385,8 @@ func (c *Base) DestroyClone(cloneID string) error {
 				log.Errf("Failed to update clone status: %v", updateErr)
 			}
 
+			done <- err
+
 			return
 		}
 
@@ -314,16 +398,18 @@ func (c *Base) DestroyClone(cloneID string) error {
 		c.observingCh <- cloneID
 
 		c.SaveClonesState()
+
+		done <- nil
 	}()
 
-	return nil
+	return done, nil
 }
 
 // GetClone returns clone by ID.
 func (c *Base) GetClone(id string) (*models.Clone, error) {
 	w, ok := c.findWrapper(id)
 	if !ok {
-		return nil, errors.New("clone not found")
+		return nil, models.New(models.ErrCodeCloneNotFound, "clone not found")
 	}
 
 	c.refreshCloneMetadata(w)
@@ -350,17 +436,35 @@ func (c *Base) refreshCloneMetadata(w *CloneWrapper) {
 }
 
 // UpdateClone updates clone.
-func (c *Base) UpdateClone(id string, patch types.CloneUpdateRequest) (*models.Clone, error) {
+func (c *Base) UpdateClone(id string, patch types.ClonePatchRequest) (*models.Clone, error) {
 	w, ok := c.findWrapper(id)
 	if !ok {
-		return nil, models.New(models.ErrCodeNotFound, "clone not found")
+		return nil, models.New(models.ErrCodeCloneNotFound, "clone not found")
+	}
+
+	if patch.AllowedCIDRs != nil {
+		if err := c.updateAllowedCIDRs(w, patch.AllowedCIDRs); err != nil {
+			return nil, err
+		}
 	}
 
 	var clone *models.Clone
 
 	// Set fields.
 	c.cloneMutex.Lock()
-	w.Clone.Protected = patch.Protected
+
+	if patch.Protected != nil {
+		w.Clone.Protected = *patch.Protected
+	}
+
+	if patch.Labels != nil {
+		w.Clone.Labels = make(map[string]string, len(patch.Labels))
+
+		for key, value := range patch.Labels {
+			w.Clone.Labels[key] = value
+		}
+	}
+
 	clone = w.Clone
 	c.cloneMutex.Unlock()
 
@@ -380,19 +484,31 @@ func (c *Base) UpdateCloneStatus(cloneID string, status models.Status) error {
 	}
 
 	w.Clone.Status = status
+	c.publishCloneEvent(models.EventCloneStatus, w.Clone)
 
 	return nil
 }
 
 // ResetClone resets clone to chosen snapshot.
 func (c *Base) ResetClone(cloneID string, resetOptions types.ResetCloneRequest) error {
+	_, err := c.resetClone(cloneID, resetOptions)
+
+	return err
+}
+
+// resetClone starts resetting the clone. The returned channel receives the result when the clone is reset.
+func (c *Base) resetClone(cloneID string, resetOptions types.ResetCloneRequest) (<-chan error, error) {
 	w, ok := c.findWrapper(cloneID)
 	if !ok {
-		return models.New(models.ErrCodeNotFound, "the clone not found")
+		return nil, models.New(models.ErrCodeCloneNotFound, "the clone not found")
 	}
 
 	if w.Session == nil || w.Clone == nil {
-		return models.New(models.ErrCodeNotFound, "clone is not started yet")
+		return nil, models.New(models.ErrCodeCloneNotStarted, "clone is not started yet")
+	}
+
+	if w.Clone.Status.Code == models.StatusStarting {
+		return nil, models.New(models.ErrCodeCloneBusy, "clone is being started")
 	}
 
 	var snapshotID string
@@ -400,7 +516,7 @@ func (c *Base) ResetClone(cloneID string, resetOptions types.ResetCloneRequest)
 	if resetOptions.SnapshotID != "" {
 		snapshot, err := c.getSnapshotByID(resetOptions.SnapshotID)
 		if err != nil {
-			return errors.Wrap(err, "failed to get snapshot ID")
+			return nil, errors.Wrap(err, "failed to get snapshot ID")
 		}
 
 		snapshotID = snapshot.ID
@@ -411,13 +527,30 @@ func (c *Base) ResetClone(cloneID string, resetOptions types.ResetCloneRequest)
 		snapshotID = w.Clone.Snapshot.ID
 	}
 
+	if resetOptions.InitProfile != nil {
+		if err := c.provision.CheckInitProfile(*resetOptions.InitProfile); err != nil {
+			return nil, err
+		}
+	}
+
 	if err := c.UpdateCloneStatus(cloneID, models.Status{
 		Code:    models.StatusResetting,
 		Message: models.CloneMessageResetting,
 	}); err != nil {
-		return errors.Wrap(err, "failed to update clone status")
+		return nil, errors.Wrap(err, "failed to update clone status")
 	}
 
+	// Reset starts the container of a stopped clone, so the port must be released.
+	c.closeWakeListener(cloneID)
+
+	if resetOptions.InitProfile != nil {
+		c.cloneMutex.Lock()
+		w.Session.InitProfile = *resetOptions.InitProfile
+		c.cloneMutex.Unlock()
+	}
+
+	done := make(chan error, 1)
+
 	go func() {
 		var originalSnapshotID string
 
@@ -425,7 +558,13 @@ func (c *Base) ResetClone(cloneID string, resetOptions types.ResetCloneRequest)
 			originalSnapshotID = w.Clone.Snapshot.ID
 		}
 
+		var initRun *resources.InitRun
+
 		snapshot, err := c.provision.ResetSession(w.Session, snapshotID)
+		if err == nil {
+			initRun, err = c.provision.RunInitProfile(w.Session)
+		}
+
 		if err != nil {
 			log.Errf("Failed to reset clone: %v", err)
 
@@ -436,19 +575,21 @@ func (c *Base) ResetClone(cloneID string, resetOptions types.ResetCloneRequest)
 				log.Errf("failed to update clone status: %v", updateErr)
 			}
 
+			done <- err
+
 			return
 		}
 
 		c.cloneMutex.Lock()
+		w.Session.InitRun = initRun
 		w.Clone.Snapshot = snapshot
+		w.Clone.DockerImage = c.sessionDockerImage(w.Session)
+		w.Clone.Init = toCloneInitView(initRun)
 		c.cloneMutex.Unlock()
 		c.decrementCloneNumber(originalSnapshotID)
 		c.incrementCloneNumber(snapshot.ID)
 
-		if err := c.UpdateCloneStatus(cloneID, models.Status{
-			Code:    models.StatusOK,
-			Message: models.CloneMessageOK,
-		}); err != nil {
+		if err := c.UpdateCloneStatus(cloneID, readyStatus(w.Session)); err != nil {
 			log.Errf("failed to update clone status: %v", err)
 		}
 
@@ -459,9 +600,11 @@ func (c *Base) ResetClone(cloneID string, resetOptions types.ResetCloneRequest)
 			CloningTime: w.Clone.Metadata.CloningTime,
 			DSADiff:     util.GetDataFreshness(snapshot.DataStateAt.Time),
 		})
+
+		done <- initRunError(w.Session)
 	}()
 
-	return nil
+	return done, nil
 }
 
 // GetCloningState returns the current state of instance.
@@ -471,6 +614,7 @@ func (c *Base) GetCloningState() models.Cloning {
 		ExpectedCloningTime: c.getExpectedCloningTime(),
 		Clones:              clones,
 		NumClones:           uint64(len(clones)),
+		WarmPool:            c.provision.WarmPoolState(),
 	}
 
 	return cloning
@@ -535,8 +679,24 @@ func (c *Base) setWrapper(id string, wrapper *CloneWrapper) {
 // deleteClone removes the clone by ID.
 func (c *Base) deleteClone(cloneID string) {
 	c.cloneMutex.Lock()
-	delete(c.clones, cloneID)
-	c.cloneMutex.Unlock()
+	defer c.cloneMutex.Unlock()
+
+	if w, ok := c.clones[cloneID]; ok {
+		delete(c.clones, cloneID)
+		c.publishCloneEvent(models.EventCloneDeleted, w.Clone)
+	}
+}
+
+// publishCloneEvent notifies event subscribers about the clone state.
+// A copy of the clone is published, so the caller must prevent concurrent modifications of the clone.
+func (c *Base) publishCloneEvent(eventType models.EventType, clone *models.Clone) {
+	if clone == nil {
+		return
+	}
+
+	cloneCopy := *clone
+
+	c.events.Publish(models.Event{Type: eventType, Clone: &cloneCopy})
 }
 
 // lenClones returns the number of clones.
@@ -599,13 +759,25 @@ func (c *Base) destroyIdleClones(ctx context.Context) {
 				continue
 			}
 
-			if isIdleClone {
-				log.Msg(fmt.Sprintf("Idle clone %q is going to be removed.", cloneWrapper.Clone.ID))
+			if !isIdleClone {
+				continue
+			}
+
+			if c.config.HibernateIdleClones {
+				log.Msg(fmt.Sprintf("Idle clone %q is going to be stopped.", cloneWrapper.Clone.ID))
 
-				if err = c.DestroyClone(cloneWrapper.Clone.ID); err != nil {
-					log.Errf("Failed to destroy clone: %v.", err)
-					continue
+				if err = c.StopClone(cloneWrapper.Clone.ID); err != nil {
+					log.Errf("Failed to stop clone: %v.", err)
 				}
+
+				continue
+			}
+
+			log.Msg(fmt.Sprintf("Idle clone %q is going to be removed.", cloneWrapper.Clone.ID))
+
+			if err = c.DestroyClone(cloneWrapper.Clone.ID); err != nil {
+				log.Errf("Failed to destroy clone: %v.", err)
+				continue
 			}
 		}
 	}
@@ -618,7 +790,8 @@ func (c *Base) isIdleClone(wrapper *CloneWrapper) (bool, error) {
 	idleDuration := time.Duration(c.config.MaxIdleMinutes) * time.Minute
 	minimumTime := currentTime.Add(-idleDuration)
 
-	if wrapper.Clone.Protected || wrapper.Clone.Status.Code == models.StatusExporting || wrapper.TimeStartedAt.After(minimumTime) {
+	if wrapper.Clone.Protected || wrapper.Clone.Status.Code == models.StatusExporting || isStoppedClone(wrapper) ||
+		wrapper.TimeStartedAt.After(minimumTime) {
 		return false, nil
 	}
 
@@ -12,6 +12,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/client/dblabapi/types"
 	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
 )
 
@@ -133,3 +134,19 @@ func (s *BaseCloningSuite) TestLenClones() {
 	lenClones = s.cloning.lenClones()
 	assert.Equal(s.T(), 1, lenClones)
 }
+
+func (s *BaseCloningSuite) TestUpdateCloneKeepsProtection() {
+	s.cloning.setWrapper("testCloneID", &CloneWrapper{Clone: &models.Clone{ID: "testCloneID", Protected: true}})
+
+	clone, err := s.cloning.UpdateClone("testCloneID", types.ClonePatchRequest{Labels: map[string]string{"team": "billing"}})
+	require.NoError(s.T(), err)
+	assert.True(s.T(), clone.Protected)
+	assert.Equal(s.T(), map[string]string{"team": "billing"}, clone.Labels)
+
+	protected := false
+
+	clone, err = s.cloning.UpdateClone("testCloneID", types.ClonePatchRequest{Protected: &protected})
+	require.NoError(s.T(), err)
+	assert.False(s.T(), clone.Protected)
+	assert.Equal(s.T(), map[string]string{"team": "billing"}, clone.Labels)
+}
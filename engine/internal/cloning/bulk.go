@@ -0,0 +1,169 @@
+package cloning
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/client/dblabapi/types"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+// maxBulkParallelism limits the number of clone operations running concurrently in a bulk.
+const maxBulkParallelism = 4
+
+// busyStatuses contains statuses of clones skipped by bulk operations.
+var busyStatuses = map[models.StatusCode]struct{}{
+	models.StatusCreating:  {},
+	models.StatusResetting: {},
+	models.StatusDeleting:  {},
+	models.StatusExporting: {},
+	models.StatusUpgrading: {},
+}
+
+// cloneOperation starts an operation on the clone and returns a channel receiving the result of the operation.
+type cloneOperation func(cloneID string) (<-chan error, error)
+
+// DestroyClones destroys clones selected by the request and waits until the clones are destroyed.
+func (c *Base) DestroyClones(request types.BulkCloneRequest) (*models.BulkResult, error) {
+	return c.runBulk(request, func(cloneID string) (<-chan error, error) {
+		return c.destroyClone(cloneID, request.Force)
+	})
+}
+
+// ResetClones resets clones selected by the request and waits until the clones are reset.
+func (c *Base) ResetClones(request types.BulkResetRequest) (*models.BulkResult, error) {
+	if err := request.ResetCloneRequest.Validate(); err != nil {
+		return nil, models.New(models.ErrCodeValidationFailed, err.Error())
+	}
+
+	return c.runBulk(request.BulkCloneRequest, func(cloneID string) (<-chan error, error) {
+		return c.resetClone(cloneID, request.ResetCloneRequest)
+	})
+}
+
+func (c *Base) runBulk(request types.BulkCloneRequest, operation cloneOperation) (*models.BulkResult, error) {
+	clones, err := c.selectClones(request)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]models.CloneOperationResult, len(clones))
+	semaphore := make(chan struct{}, maxBulkParallelism)
+	wg := sync.WaitGroup{}
+
+	for i, clone := range clones {
+		results[i].CloneID = clone.ID
+
+		if reason := skipReason(clone, request.Force); reason != "" {
+			results[i].Result = models.BulkResultSkipped
+			results[i].Message = reason
+
+			continue
+		}
+
+		if request.DryRun {
+			results[i].Result = models.BulkResultPlanned
+			continue
+		}
+
+		wg.Add(1)
+		semaphore <- struct{}{}
+
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			if err := runOperation(results[i].CloneID, operation); err != nil {
+				log.Errf("Bulk operation failed on clone %s: %v", results[i].CloneID, err)
+
+				results[i].Result = models.BulkResultFailed
+				results[i].Message = err.Error()
+
+				return
+			}
+
+			results[i].Result = models.BulkResultSucceeded
+		}(i)
+	}
+
+	wg.Wait()
+
+	bulkResult := &models.BulkResult{DryRun: request.DryRun, Clones: results}
+
+	for _, result := range results {
+		switch result.Result {
+		case models.BulkResultSucceeded:
+			bulkResult.Succeeded++
+
+		case models.BulkResultFailed:
+			bulkResult.Failed++
+
+		case models.BulkResultSkipped:
+			bulkResult.Skipped++
+		}
+	}
+
+	return bulkResult, nil
+}
+
+func runOperation(cloneID string, operation cloneOperation) error {
+	done, err := operation(cloneID)
+	if err != nil {
+		return err
+	}
+
+	return <-done
+}
+
+// selectClones returns clones matching the request.
+func (c *Base) selectClones(request types.BulkCloneRequest) ([]*models.Clone, error) {
+	if err := request.Validate(); err != nil {
+		return nil, models.New(models.ErrCodeValidationFailed, err.Error())
+	}
+
+	var minAge time.Duration
+
+	if request.OlderThan != "" {
+		olderThan, err := time.ParseDuration(request.OlderThan)
+		if err != nil || olderThan <= 0 {
+			return nil, models.New(models.ErrCodeValidationFailed, fmt.Sprintf("invalid older_than: %q", request.OlderThan))
+		}
+
+		minAge = olderThan
+	}
+
+	requirements, err := parseSelector(request.Selector)
+	if err != nil {
+		return nil, models.New(models.ErrCodeBadRequest, err.Error())
+	}
+
+	filter := cloneFilter{
+		request:      types.CloneListRequest{Status: request.Status, MinAge: minAge},
+		requirements: requirements,
+		now:          time.Now(),
+	}
+
+	clones := []*models.Clone{}
+
+	for _, clone := range c.GetClones() {
+		if filter.matches(clone) {
+			clones = append(clones, clone)
+		}
+	}
+
+	return clones, nil
+}
+
+func skipReason(clone *models.Clone, force bool) string {
+	if _, ok := busyStatuses[clone.Status.Code]; ok {
+		return fmt.Sprintf("clone is busy: %s", clone.Status.Code)
+	}
+
+	if clone.Protected && clone.Status.Code != models.StatusFatal && !force {
+		return "clone is protected"
+	}
+
+	return ""
+}
@@ -0,0 +1,104 @@
+package cloning
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/client/dblabapi/types"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+func TestDestroyClones(t *testing.T) {
+	now := time.Now()
+
+	newBase := func() *Base {
+		newClone := func(id string, age time.Duration, status models.StatusCode, protected bool) *CloneWrapper {
+			return &CloneWrapper{Clone: &models.Clone{
+				ID:        id,
+				CreatedAt: models.NewLocalTime(now.Add(-age)),
+				Status:    models.Status{Code: status},
+				Protected: protected,
+				Labels:    map[string]string{"team": "billing"},
+			}}
+		}
+
+		return &Base{
+			clones: map[string]*CloneWrapper{
+				"clone1": newClone("clone1", time.Hour, models.StatusOK, false),
+				"clone2": newClone("clone2", 3*time.Hour, models.StatusOK, false),
+				"clone3": newClone("clone3", 3*time.Hour, models.StatusOK, true),
+				"clone4": newClone("clone4", 3*time.Hour, models.StatusResetting, false),
+			},
+		}
+	}
+
+	results := func(bulkResult *models.BulkResult) map[string]string {
+		result := make(map[string]string)
+		for _, clone := range bulkResult.Clones {
+			result[clone.CloneID] = clone.Result
+		}
+
+		return result
+	}
+
+	t.Run("dry run", func(t *testing.T) {
+		c := newBase()
+
+		bulkResult, err := c.DestroyClones(types.BulkCloneRequest{OlderThan: "2h", DryRun: true})
+		require.NoError(t, err)
+
+		assert.True(t, bulkResult.DryRun)
+		assert.Equal(t, 2, bulkResult.Skipped)
+		assert.Equal(t, map[string]string{
+			"clone2": models.BulkResultPlanned,
+			"clone3": models.BulkResultSkipped,
+			"clone4": models.BulkResultSkipped,
+		}, results(bulkResult))
+		assert.Equal(t, 4, c.lenClones())
+	})
+
+	t.Run("protected clones are skipped", func(t *testing.T) {
+		c := newBase()
+
+		bulkResult, err := c.DestroyClones(types.BulkCloneRequest{Selector: "team=billing", OlderThan: "2h"})
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, bulkResult.Succeeded)
+		assert.Equal(t, 2, bulkResult.Skipped)
+		assert.Equal(t, 3, c.lenClones())
+
+		_, ok := c.findWrapper("clone2")
+		assert.False(t, ok)
+	})
+
+	t.Run("forced", func(t *testing.T) {
+		c := newBase()
+
+		bulkResult, err := c.DestroyClones(types.BulkCloneRequest{Status: []string{"OK"}, Force: true})
+		require.NoError(t, err)
+
+		assert.Equal(t, 3, bulkResult.Succeeded)
+		assert.Equal(t, 0, bulkResult.Skipped)
+		assert.Equal(t, 1, c.lenClones())
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		c := newBase()
+
+		for _, request := range []types.BulkCloneRequest{
+			{},
+			{Force: true},
+			{OlderThan: "two hours"},
+			{OlderThan: "-1h"},
+			{Selector: "!"},
+		} {
+			_, err := c.DestroyClones(request)
+			assert.Error(t, err, request)
+		}
+
+		assert.Equal(t, 4, c.lenClones())
+	})
+}
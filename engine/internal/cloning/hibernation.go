@@ -0,0 +1,315 @@
+package cloning
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/cloneproxy"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+const (
+	// sqlStateCannotConnectNow is reported to clients connecting to a clone that is being started.
+	sqlStateCannotConnectNow = "57P03"
+
+	// sqlStateInvalidAuthorization is reported to clients which are not allowed to connect to a clone.
+	sqlStateInvalidAuthorization = "28000"
+
+	wakeConnTimeout = 10 * time.Second
+)
+
+// StopClone stops the clone container and keeps the clone data, so the clone can be started again with the same port.
+func (c *Base) StopClone(cloneID string) error {
+	w, err := c.transitCloneStatus(cloneID, []models.StatusCode{models.StatusOK}, models.Status{
+		Code:    models.StatusStopped,
+		Message: models.CloneMessageStopped,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := c.provision.StopSessionContainer(w.Session); err != nil {
+		log.Errf("Failed to stop clone %s: %v.", cloneID, err)
+
+		if updateErr := c.UpdateCloneStatus(cloneID, models.Status{
+			Code:    models.StatusFatal,
+			Message: errors.Cause(err).Error(),
+		}); updateErr != nil {
+			log.Errf("Failed to update clone status: %v", updateErr)
+		}
+
+		return errors.Wrap(err, "failed to stop clone")
+	}
+
+	if c.config.WakeOnConnect {
+		c.listenWakeConnections(cloneID, w.Session.Port)
+	}
+
+	c.SaveClonesState()
+
+	return nil
+}
+
+// StartClone starts the container of a stopped clone.
+func (c *Base) StartClone(cloneID string) error {
+	_, err := c.startClone(cloneID)
+
+	return err
+}
+
+// startClone starts the clone container. The returned channel receives the result when the clone is ready.
+func (c *Base) startClone(cloneID string) (<-chan error, error) {
+	w, err := c.transitCloneStatus(cloneID, []models.StatusCode{models.StatusStopped}, models.Status{
+		Code:    models.StatusStarting,
+		Message: models.CloneMessageStarting,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The port must be released before the container binds it.
+	c.closeWakeListener(cloneID)
+
+	done := make(chan error, 1)
+
+	go func() {
+		if err := c.provision.StartSessionContainer(w.Session); err != nil {
+			log.Errf("Failed to start clone %s: %v.", cloneID, err)
+
+			if updateErr := c.UpdateCloneStatus(cloneID, models.Status{
+				Code:    models.StatusFatal,
+				Message: errors.Cause(err).Error(),
+			}); updateErr != nil {
+				log.Errf("Failed to update clone status: %v", updateErr)
+			}
+
+			done <- err
+
+			return
+		}
+
+		c.cloneMutex.Lock()
+		// The idle time is counted from the start.
+		w.TimeStartedAt = time.Now()
+		c.cloneMutex.Unlock()
+
+		if err := c.UpdateCloneStatus(cloneID, models.Status{
+			Code:    models.StatusOK,
+			Message: models.CloneMessageOK,
+		}); err != nil {
+			log.Errf("Failed to update clone status: %v", err)
+		}
+
+		c.SaveClonesState()
+
+		done <- nil
+	}()
+
+	return done, nil
+}
+
+// transitCloneStatus sets the clone status if the current status is one of the expected statuses.
+func (c *Base) transitCloneStatus(cloneID string, expected []models.StatusCode, status models.Status) (*CloneWrapper, error) {
+	c.cloneMutex.Lock()
+	defer c.cloneMutex.Unlock()
+
+	w, ok := c.clones[cloneID]
+	if !ok {
+		return nil, models.New(models.ErrCodeCloneNotFound, "clone not found")
+	}
+
+	if w.Session == nil {
+		return nil, models.New(models.ErrCodeCloneNotStarted, "clone is not started yet")
+	}
+
+	for _, code := range expected {
+		if w.Clone.Status.Code == code {
+			w.Clone.Status = status
+			c.publishCloneEvent(models.EventCloneStatus, w.Clone)
+
+			return w, nil
+		}
+	}
+
+	return nil, models.New(models.ErrCodeCloneBusy, fmt.Sprintf("clone status is %s", w.Clone.Status.Code))
+}
+
+// isStoppedClone checks if the clone container is stopped on purpose.
+func isStoppedClone(w *CloneWrapper) bool {
+	return w.Clone != nil && (w.Clone.Status.Code == models.StatusStopped || w.Clone.Status.Code == models.StatusStarting)
+}
+
+// restoreStoppedClones keeps ports of stopped clones reserved and starts listening to wake-up connections.
+// Clones stopped during the start are considered as stopped.
+func (c *Base) restoreStoppedClones() {
+	c.cloneMutex.Lock()
+
+	stoppedClones := make(map[string]uint)
+
+	for cloneID, w := range c.clones {
+		if !isStoppedClone(w) || w.Session == nil {
+			continue
+		}
+
+		if w.Clone.Status.Code == models.StatusStarting {
+			w.Clone.Status = models.Status{Code: models.StatusStopped, Message: models.CloneMessageStopped}
+		}
+
+		if err := c.provision.ReservePort(w.Session.Port); err != nil {
+			log.Err(fmt.Sprintf("Failed to reserve port of stopped clone %s: %v", cloneID, err))
+		}
+
+		stoppedClones[cloneID] = w.Session.Port
+	}
+
+	c.cloneMutex.Unlock()
+
+	c.syncWakeListeners(stoppedClones)
+}
+
+// reloadWakeListeners starts or stops listening to wake-up connections after the configuration has changed.
+func (c *Base) reloadWakeListeners() {
+	c.cloneMutex.RLock()
+
+	stoppedClones := make(map[string]uint)
+
+	for cloneID, w := range c.clones {
+		if w.Clone != nil && w.Clone.Status.Code == models.StatusStopped && w.Session != nil {
+			stoppedClones[cloneID] = w.Session.Port
+		}
+	}
+
+	c.cloneMutex.RUnlock()
+
+	c.syncWakeListeners(stoppedClones)
+}
+
+func (c *Base) syncWakeListeners(stoppedClones map[string]uint) {
+	if !c.config.WakeOnConnect {
+		c.wakeMu.Lock()
+
+		cloneIDs := make([]string, 0, len(c.wakeListeners))
+		for cloneID := range c.wakeListeners {
+			cloneIDs = append(cloneIDs, cloneID)
+		}
+
+		c.wakeMu.Unlock()
+
+		for _, cloneID := range cloneIDs {
+			c.closeWakeListener(cloneID)
+		}
+
+		return
+	}
+
+	for cloneID, port := range stoppedClones {
+		c.listenWakeConnections(cloneID, port)
+	}
+}
+
+// listenWakeConnections listens to the port of the stopped clone and starts the clone on the first connection
+// of a client allowed to connect to the clone. The connection is rejected with a message to retry,
+// because the port is released for the clone container.
+func (c *Base) listenWakeConnections(cloneID string, port uint) {
+	c.wakeMu.Lock()
+	defer c.wakeMu.Unlock()
+
+	if _, ok := c.wakeListeners[cloneID]; ok {
+		return
+	}
+
+	listener, err := net.Listen("tcp", net.JoinHostPort("", strconv.FormatUint(uint64(port), 10)))
+	if err != nil {
+		log.Err(fmt.Sprintf("Failed to listen to wake-up connections of clone %s: %v", cloneID, err))
+		return
+	}
+
+	if c.wakeListeners == nil {
+		c.wakeListeners = make(map[string]net.Listener)
+	}
+
+	c.wakeListeners[cloneID] = listener
+
+	log.Dbg(fmt.Sprintf("Listen to wake-up connections of clone %s on port %d", cloneID, port))
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			if !c.isAllowedWakeClient(cloneID, conn.RemoteAddr()) {
+				log.Msg(fmt.Sprintf("Reject connection to stopped clone %s from %s: client is not allowed", cloneID, conn.RemoteAddr()))
+
+				go rejectStartup(conn, sqlStateInvalidAuthorization,
+					fmt.Sprintf("client %s is not allowed to connect to clone %s", conn.RemoteAddr(), cloneID))
+
+				continue
+			}
+
+			log.Msg(fmt.Sprintf("Connection to stopped clone %s from %s, starting the clone", cloneID, conn.RemoteAddr()))
+
+			if _, err := c.startClone(cloneID); err != nil {
+				log.Dbg(fmt.Sprintf("Clone %s has not been started: %v", cloneID, err))
+			}
+
+			go rejectWakeConnection(conn, cloneID)
+		}
+	}()
+}
+
+func (c *Base) closeWakeListener(cloneID string) {
+	c.wakeMu.Lock()
+	defer c.wakeMu.Unlock()
+
+	listener, ok := c.wakeListeners[cloneID]
+	if !ok {
+		return
+	}
+
+	delete(c.wakeListeners, cloneID)
+
+	if err := listener.Close(); err != nil {
+		log.Err(fmt.Sprintf("Failed to close the wake-up listener of clone %s: %v", cloneID, err))
+	}
+}
+
+func (c *Base) isAllowedWakeClient(cloneID string, clientAddr net.Addr) bool {
+	c.cloneMutex.RLock()
+	defer c.cloneMutex.RUnlock()
+
+	w, ok := c.clones[cloneID]
+
+	return ok && w.Clone != nil && isAllowedClient(w, clientAddr)
+}
+
+// rejectWakeConnection reports to the client that the clone is being started.
+func rejectWakeConnection(conn net.Conn, cloneID string) {
+	rejectStartup(conn, sqlStateCannotConnectNow, fmt.Sprintf("clone %s is being started, retry in a few seconds", cloneID))
+}
+
+// rejectStartup responds to the startup message of the client with the error and closes the connection.
+func rejectStartup(conn net.Conn, code, message string) {
+	defer func() { _ = conn.Close() }()
+
+	if err := conn.SetDeadline(time.Now().Add(wakeConnTimeout)); err != nil {
+		log.Dbg("Failed to set a deadline of the wake-up connection: ", err)
+		return
+	}
+
+	// The startup message is read to respond with a Postgres error understood by clients.
+	if _, err := cloneproxy.ReadStartupMessage(conn); err != nil {
+		log.Dbg("Failed to read the startup message of the wake-up connection: ", err)
+		return
+	}
+
+	if _, err := conn.Write(cloneproxy.ErrorResponse(code, message)); err != nil {
+		log.Dbg("Failed to respond to the wake-up connection: ", err)
+	}
+}
@@ -0,0 +1,137 @@
+package cloning
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/resources"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/telemetry"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+func (s *BaseCloningSuite) TestTransitCloneStatus() {
+	s.cloning.setWrapper("running", &CloneWrapper{
+		Clone:   &models.Clone{ID: "running", Status: models.Status{Code: models.StatusOK}},
+		Session: &resources.Session{Port: 6000},
+	})
+	s.cloning.setWrapper("creating", &CloneWrapper{
+		Clone: &models.Clone{ID: "creating", Status: models.Status{Code: models.StatusCreating}},
+	})
+
+	stopped := models.Status{Code: models.StatusStopped, Message: models.CloneMessageStopped}
+
+	w, err := s.cloning.transitCloneStatus("running", []models.StatusCode{models.StatusOK}, stopped)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), stopped, w.Clone.Status)
+
+	_, err = s.cloning.transitCloneStatus("running", []models.StatusCode{models.StatusOK}, stopped)
+	assert.Equal(s.T(), models.ErrCodeCloneBusy, err.(*models.Error).Code)
+
+	_, err = s.cloning.transitCloneStatus("creating", []models.StatusCode{models.StatusCreating}, stopped)
+	assert.Equal(s.T(), models.ErrCodeCloneNotStarted, err.(*models.Error).Code)
+
+	_, err = s.cloning.transitCloneStatus("absent", []models.StatusCode{models.StatusOK}, stopped)
+	assert.Equal(s.T(), models.ErrCodeCloneNotFound, err.(*models.Error).Code)
+}
+
+func TestRestoreStoppedClones(t *testing.T) {
+	const stateData = `{
+  "stopped": {
+    "clone": {"id": "stopped", "snapshot": {"id": "pool@snapshot"}, "status": {"code": "STOPPED"}},
+    "session": {"id": "1", "port": 2}
+  },
+  "starting": {
+    "clone": {"id": "starting", "snapshot": {"id": "pool@snapshot"}, "status": {"code": "STARTING"}},
+    "session": {"id": "2", "port": 3}
+  }
+}`
+
+	filepath, err := prepareStateFile(stateData)
+	require.NoError(t, err)
+
+	defer func() { _ = os.Remove(filepath) }()
+
+	prov, err := newProvisioner()
+	require.NoError(t, err)
+
+	s := NewBase(&Config{}, prov, &telemetry.Agent{}, nil, nil)
+	s.snapshotBox.items["pool@snapshot"] = &models.Snapshot{ID: "pool@snapshot"}
+
+	require.NoError(t, s.loadSessionState(filepath))
+
+	// Containers of stopped clones do not exist, but the clones are kept.
+	s.filterRunningClones(context.Background())
+	require.Len(t, s.clones, 2)
+
+	s.restoreStoppedClones()
+
+	assert.Equal(t, models.StatusStopped, s.clones["stopped"].Clone.Status.Code)
+	assert.Equal(t, models.StatusStopped, s.clones["starting"].Clone.Status.Code)
+	assert.Empty(t, s.wakeListeners)
+}
+
+func startupPacket() []byte {
+	startup := []byte("user\x00john\x00\x00")
+	packet := make([]byte, 8, 8+len(startup))
+	binary.BigEndian.PutUint32(packet, uint32(8+len(startup)))
+	binary.BigEndian.PutUint32(packet[4:], 196608)
+
+	return append(packet, startup...)
+}
+
+func TestRejectWakeConnection(t *testing.T) {
+	client, server := net.Pipe()
+
+	go rejectWakeConnection(server, "clone1")
+
+	_, err := client.Write(startupPacket())
+	require.NoError(t, err)
+
+	response, err := io.ReadAll(client)
+	require.NoError(t, err)
+
+	require.NotEmpty(t, response)
+	assert.Equal(t, byte('E'), response[0])
+	assert.Contains(t, string(response), sqlStateCannotConnectNow)
+	assert.Contains(t, string(response), "clone clone1 is being started")
+}
+
+func (s *BaseCloningSuite) TestWakeConnectionFromNotAllowedClient() {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(s.T(), err)
+
+	port := uint(listener.Addr().(*net.TCPAddr).Port)
+	require.NoError(s.T(), listener.Close())
+
+	s.cloning.setWrapper("restricted", &CloneWrapper{
+		Clone:   &models.Clone{ID: "restricted", Status: models.Status{Code: models.StatusStopped}, AllowedCIDRs: []string{"10.1.0.0/16"}},
+		Session: &resources.Session{Port: port},
+	})
+
+	s.cloning.listenWakeConnections("restricted", port)
+	defer s.cloning.closeWakeListener("restricted")
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(s.T(), err)
+
+	defer func() { _ = conn.Close() }()
+
+	_, err = conn.Write(startupPacket())
+	require.NoError(s.T(), err)
+
+	response, err := io.ReadAll(conn)
+	require.NoError(s.T(), err)
+
+	require.NotEmpty(s.T(), response)
+	assert.Equal(s.T(), byte('E'), response[0])
+	assert.Contains(s.T(), string(response), sqlStateInvalidAuthorization)
+	assert.Contains(s.T(), string(response), "is not allowed to connect to clone restricted")
+	assert.Equal(s.T(), models.StatusStopped, s.cloning.clones["restricted"].Clone.Status.Code)
+}
@@ -0,0 +1,175 @@
+package cloning
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/util"
+)
+
+const (
+	idempotencyFilename = "idempotency.json"
+
+	// idempotencyRetention defines how long results of idempotent requests are kept.
+	idempotencyRetention = 24 * time.Hour
+)
+
+// IdempotencyRecord contains the result of a request made with an idempotency key.
+type IdempotencyRecord struct {
+	RequestHash string          `json:"requestHash"`
+	StatusCode  int             `json:"statusCode"`
+	Response    json.RawMessage `json:"response,omitempty"`
+	CreatedAt   time.Time       `json:"createdAt"`
+}
+
+// idempotencyStore keeps results of idempotent requests.
+type idempotencyStore struct {
+	mu         sync.Mutex
+	records    map[string]*IdempotencyRecord
+	inProgress map[string]string
+}
+
+// BeginIdempotentRequest reserves the idempotency key for the request.
+// It returns the stored result if the request with the key has already been completed.
+func (c *Base) BeginIdempotentRequest(key, requestHash string) (*IdempotencyRecord, error) {
+	store := &c.idempotency
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.removeExpired(time.Now())
+
+	if record, ok := store.records[key]; ok {
+		if record.RequestHash != requestHash {
+			return nil, models.New(models.ErrCodeIdempotencyConflict, "idempotency key has been used with another request")
+		}
+
+		return record, nil
+	}
+
+	if _, ok := store.inProgress[key]; ok {
+		return nil, models.New(models.ErrCodeIdempotencyConflict, "request with the same idempotency key is in progress")
+	}
+
+	if store.inProgress == nil {
+		store.inProgress = make(map[string]string)
+	}
+
+	store.inProgress[key] = requestHash
+
+	return nil, nil
+}
+
+// CompleteIdempotentRequest stores the result of the request and releases the idempotency key.
+// Only successful results are stored, so failed requests can be retried with the same key.
+func (c *Base) CompleteIdempotentRequest(key string, statusCode int, response []byte) {
+	store := &c.idempotency
+
+	store.mu.Lock()
+
+	requestHash, ok := store.inProgress[key]
+	if !ok {
+		store.mu.Unlock()
+		return
+	}
+
+	delete(store.inProgress, key)
+
+	if statusCode < 200 || statusCode >= 300 {
+		store.mu.Unlock()
+		return
+	}
+
+	if store.records == nil {
+		store.records = make(map[string]*IdempotencyRecord)
+	}
+
+	store.records[key] = &IdempotencyRecord{
+		RequestHash: requestHash,
+		StatusCode:  statusCode,
+		Response:    response,
+		CreatedAt:   time.Now(),
+	}
+
+	store.mu.Unlock()
+
+	c.saveIdempotencyState()
+}
+
+func (s *idempotencyStore) removeExpired(now time.Time) {
+	for key, record := range s.records {
+		if now.Sub(record.CreatedAt) > idempotencyRetention {
+			delete(s.records, key)
+		}
+	}
+}
+
+// restoreIdempotencyState restores results of idempotent requests from disk.
+func (c *Base) restoreIdempotencyState() error {
+	idempotencyPath, err := util.GetMetaPath(idempotencyFilename)
+	if err != nil {
+		return fmt.Errorf("failed to get path of an idempotency file: %w", err)
+	}
+
+	return c.loadIdempotencyState(idempotencyPath)
+}
+
+func (c *Base) loadIdempotencyState(idempotencyPath string) error {
+	store := &c.idempotency
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.records = make(map[string]*IdempotencyRecord)
+
+	data, err := os.ReadFile(idempotencyPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		return fmt.Errorf("failed to read idempotency data: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &store.records); err != nil {
+		return fmt.Errorf("failed to decode idempotency data: %w", err)
+	}
+
+	store.removeExpired(time.Now())
+
+	return nil
+}
+
+// saveIdempotencyState writes results of idempotent requests to disk.
+func (c *Base) saveIdempotencyState() {
+	idempotencyPath, err := util.GetMetaPath(idempotencyFilename)
+	if err != nil {
+		log.Err("failed to get path of an idempotency file", err)
+		return
+	}
+
+	if err := c.writeIdempotencyState(idempotencyPath); err != nil {
+		log.Err("Failed to save results of idempotent requests", err)
+	}
+}
+
+func (c *Base) writeIdempotencyState(idempotencyPath string) error {
+	store := &c.idempotency
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.removeExpired(time.Now())
+
+	data, err := json.Marshal(store.records)
+	if err != nil {
+		return fmt.Errorf("failed to encode idempotency data: %w", err)
+	}
+
+	return os.WriteFile(idempotencyPath, data, 0600)
+}
@@ -0,0 +1,88 @@
+package cloning
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+func TestIdempotentRequests(t *testing.T) {
+	c := &Base{}
+
+	record, err := c.BeginIdempotentRequest("key1", "hash1")
+	require.NoError(t, err)
+	require.Nil(t, record)
+
+	_, err = c.BeginIdempotentRequest("key1", "hash1")
+	assertErrorCode(t, err, models.ErrCodeIdempotencyConflict)
+
+	c.CompleteIdempotentRequest("key1", 201, []byte(`{"id":"clone1"}`))
+
+	record, err = c.BeginIdempotentRequest("key1", "hash1")
+	require.NoError(t, err)
+	require.NotNil(t, record)
+	assert.Equal(t, 201, record.StatusCode)
+	assert.JSONEq(t, `{"id":"clone1"}`, string(record.Response))
+
+	_, err = c.BeginIdempotentRequest("key1", "hash2")
+	assertErrorCode(t, err, models.ErrCodeIdempotencyConflict)
+
+	// Failed requests are not stored and can be retried.
+	record, err = c.BeginIdempotentRequest("key2", "hash2")
+	require.NoError(t, err)
+	require.Nil(t, record)
+
+	c.CompleteIdempotentRequest("key2", 500, nil)
+
+	record, err = c.BeginIdempotentRequest("key2", "hash2")
+	require.NoError(t, err)
+	require.Nil(t, record)
+
+	// Expired results are removed.
+	c.idempotency.records["key1"].CreatedAt = time.Now().Add(-idempotencyRetention - time.Minute)
+
+	record, err = c.BeginIdempotentRequest("key1", "hash3")
+	require.NoError(t, err)
+	require.Nil(t, record)
+}
+
+func TestIdempotencyState(t *testing.T) {
+	idempotencyPath := filepath.Join(t.TempDir(), idempotencyFilename)
+
+	c := &Base{}
+
+	_, err := c.BeginIdempotentRequest("key1", "hash1")
+	require.NoError(t, err)
+
+	c.idempotency.records = map[string]*IdempotencyRecord{
+		"key1": {RequestHash: "hash1", StatusCode: 200, CreatedAt: time.Now()},
+		"key2": {RequestHash: "hash2", StatusCode: 200, CreatedAt: time.Now().Add(-idempotencyRetention - time.Minute)},
+	}
+
+	require.NoError(t, c.writeIdempotencyState(idempotencyPath))
+
+	restored := &Base{}
+	require.NoError(t, restored.loadIdempotencyState(idempotencyPath))
+
+	require.Len(t, restored.idempotency.records, 1)
+	assert.Equal(t, "hash1", restored.idempotency.records["key1"].RequestHash)
+
+	require.NoError(t, os.Remove(idempotencyPath))
+	require.NoError(t, restored.loadIdempotencyState(idempotencyPath))
+	assert.Empty(t, restored.idempotency.records)
+}
+
+func assertErrorCode(t *testing.T, err error, code models.ErrorCode) {
+	t.Helper()
+
+	var modelErr *models.Error
+
+	require.ErrorAs(t, err, &modelErr)
+	assert.Equal(t, code, modelErr.Code)
+}
@@ -0,0 +1,46 @@
+package cloning
+
+import (
+	"github.com/pkg/errors"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/resources"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+// readyStatus returns the status of the started clone. A failed init profile is reported as a cloning failure;
+// the clone keeps running, so the output can be inspected before the clone is reset or destroyed.
+func readyStatus(session *resources.Session) models.Status {
+	if err := initRunError(session); err != nil {
+		return models.Status{Code: models.StatusFatal, Message: err.Error()}
+	}
+
+	return models.Status{Code: models.StatusOK, Message: models.CloneMessageOK}
+}
+
+func initRunError(session *resources.Session) error {
+	if session.InitRun == nil || session.InitRun.Error == "" {
+		return nil
+	}
+
+	return errors.Errorf("init profile %s failed: %s", session.InitRun.Profile, session.InitRun.Error)
+}
+
+func toCloneInitView(run *resources.InitRun) *models.CloneInit {
+	if run == nil {
+		return nil
+	}
+
+	steps := make([]models.CloneInitStep, 0, len(run.Steps))
+
+	for _, step := range run.Steps {
+		steps = append(steps, models.CloneInitStep{Name: step.Name, Output: step.Output, Error: step.Error})
+	}
+
+	return &models.CloneInit{
+		Profile:    run.Profile,
+		StartedAt:  models.NewLocalTime(run.StartedAt),
+		FinishedAt: models.NewLocalTime(run.FinishedAt),
+		Steps:      steps,
+		Error:      run.Error,
+	}
+}
@@ -0,0 +1,21 @@
+package cloning
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/resources"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+func TestReadyStatus(t *testing.T) {
+	assert.Equal(t, models.StatusOK, readyStatus(&resources.Session{}).Code)
+	assert.Equal(t, models.StatusOK, readyStatus(&resources.Session{InitRun: &resources.InitRun{Profile: "billing"}}).Code)
+
+	status := readyStatus(&resources.Session{InitRun: &resources.InitRun{Profile: "billing", Error: `step "sql" failed: syntax error`}})
+	assert.Equal(t, models.Status{
+		Code:    models.StatusFatal,
+		Message: `init profile billing failed: step "sql" failed: syntax error`,
+	}, status)
+}
@@ -0,0 +1,294 @@
+package cloning
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/client/dblabapi/types"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+const (
+	defaultListLimit = 100
+	maxListLimit     = 1000
+
+	descendingSortPrefix = "-"
+	defaultListSort      = descendingSortPrefix + sortByCreatedAt
+)
+
+// Sort fields of the clone list.
+const (
+	sortByCreatedAt = "created_at"
+	sortByID        = "id"
+	sortByStatus    = "status"
+	sortBySnapshot  = "snapshot"
+	sortByDiffSize  = "diff_size"
+)
+
+type selectorOperator int
+
+const (
+	labelExists selectorOperator = iota
+	labelNotExists
+	labelEquals
+	labelNotEquals
+)
+
+// labelRequirement describes a single requirement of the label selector.
+type labelRequirement struct {
+	key      string
+	value    string
+	operator selectorOperator
+}
+
+// parseSelector parses a comma-separated list of label requirements: key=value, key!=value, key and !key.
+func parseSelector(selector string) ([]labelRequirement, error) {
+	requirements := []labelRequirement{}
+
+	for _, term := range strings.Split(selector, ",") {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+
+		var requirement labelRequirement
+
+		switch {
+		case strings.Contains(term, "!="):
+			parts := strings.SplitN(term, "!=", 2)
+			requirement = labelRequirement{key: parts[0], value: parts[1], operator: labelNotEquals}
+
+		case strings.Contains(term, "="):
+			parts := strings.SplitN(term, "=", 2)
+			requirement = labelRequirement{key: parts[0], value: parts[1], operator: labelEquals}
+
+		case strings.HasPrefix(term, "!"):
+			requirement = labelRequirement{key: strings.TrimPrefix(term, "!"), operator: labelNotExists}
+
+		default:
+			requirement = labelRequirement{key: term, operator: labelExists}
+		}
+
+		requirement.key = strings.TrimSpace(requirement.key)
+		requirement.value = strings.TrimSpace(requirement.value)
+
+		if requirement.key == "" {
+			return nil, fmt.Errorf("invalid selector term %q: empty label key", term)
+		}
+
+		requirements = append(requirements, requirement)
+	}
+
+	return requirements, nil
+}
+
+func (r labelRequirement) matches(labels map[string]string) bool {
+	value, ok := labels[r.key]
+
+	switch r.operator {
+	case labelExists:
+		return ok
+
+	case labelNotExists:
+		return !ok
+
+	case labelEquals:
+		return ok && value == r.value
+
+	case labelNotEquals:
+		return !ok || value != r.value
+	}
+
+	return false
+}
+
+// cloneFilter checks if clones match the list request.
+type cloneFilter struct {
+	request      types.CloneListRequest
+	requirements []labelRequirement
+	now          time.Time
+}
+
+func (f cloneFilter) matches(clone *models.Clone) bool {
+	for _, requirement := range f.requirements {
+		if !requirement.matches(clone.Labels) {
+			return false
+		}
+	}
+
+	if len(f.request.Status) > 0 && !containsStatus(f.request.Status, clone.Status.Code) {
+		return false
+	}
+
+	if f.request.Snapshot != "" && (clone.Snapshot == nil || clone.Snapshot.ID != f.request.Snapshot) {
+		return false
+	}
+
+	if f.request.Pool != "" && (clone.Snapshot == nil || clone.Snapshot.Pool != f.request.Pool) {
+		return false
+	}
+
+	if f.request.Creator != "" && clone.DB.Username != f.request.Creator {
+		return false
+	}
+
+	if f.request.MinAge > 0 || f.request.MaxAge > 0 {
+		if clone.CreatedAt == nil {
+			return false
+		}
+
+		age := f.now.Sub(clone.CreatedAt.Time)
+
+		if age < f.request.MinAge || (f.request.MaxAge > 0 && age > f.request.MaxAge) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func containsStatus(statuses []string, code models.StatusCode) bool {
+	for _, status := range statuses {
+		if strings.EqualFold(status, string(code)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ListClones returns a page of clones filtered and sorted according to the request.
+func (c *Base) ListClones(request types.CloneListRequest) (*models.ClonePage, error) {
+	if err := applyListDefaults(&request); err != nil {
+		return nil, err
+	}
+
+	requirements, err := parseSelector(request.Selector)
+	if err != nil {
+		return nil, models.New(models.ErrCodeBadRequest, err.Error())
+	}
+
+	less, err := cloneLessFunc(request.Sort)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := cloneFilter{request: request, requirements: requirements, now: time.Now()}
+	clones := []*models.Clone{}
+
+	for _, clone := range c.GetClones() {
+		if filter.matches(clone) {
+			clones = append(clones, clone)
+		}
+	}
+
+	sort.SliceStable(clones, func(i, j int) bool {
+		return less(clones[i], clones[j])
+	})
+
+	page := &models.ClonePage{
+		Clones: []*models.Clone{},
+		Total:  len(clones),
+		Limit:  request.Limit,
+		Offset: request.Offset,
+	}
+
+	if request.Offset < len(clones) {
+		end := request.Offset + request.Limit
+		if end > len(clones) {
+			end = len(clones)
+		}
+
+		page.Clones = clones[request.Offset:end]
+	}
+
+	return page, nil
+}
+
+func applyListDefaults(request *types.CloneListRequest) error {
+	if request.Limit < 0 || request.Limit > maxListLimit {
+		return models.New(models.ErrCodeBadRequest, fmt.Sprintf("limit must be between 1 and %d", maxListLimit))
+	}
+
+	if request.Limit == 0 {
+		request.Limit = defaultListLimit
+	}
+
+	if request.Offset < 0 {
+		return models.New(models.ErrCodeBadRequest, "offset must not be negative")
+	}
+
+	if request.MinAge < 0 || request.MaxAge < 0 || (request.MaxAge > 0 && request.MinAge > request.MaxAge) {
+		return models.New(models.ErrCodeBadRequest, "invalid clone age range")
+	}
+
+	if request.Sort == "" {
+		request.Sort = defaultListSort
+	}
+
+	return nil
+}
+
+// cloneLessFunc returns the comparison function of clones for the sort option.
+func cloneLessFunc(sortOption string) (func(a, b *models.Clone) bool, error) {
+	field := strings.TrimPrefix(sortOption, descendingSortPrefix)
+
+	var less func(a, b *models.Clone) bool
+
+	switch field {
+	case sortByCreatedAt:
+		less = func(a, b *models.Clone) bool {
+			return createdAt(a).Before(createdAt(b))
+		}
+
+	case sortByID:
+		less = func(a, b *models.Clone) bool {
+			return a.ID < b.ID
+		}
+
+	case sortByStatus:
+		less = func(a, b *models.Clone) bool {
+			return a.Status.Code < b.Status.Code
+		}
+
+	case sortBySnapshot:
+		less = func(a, b *models.Clone) bool {
+			return snapshotID(a) < snapshotID(b)
+		}
+
+	case sortByDiffSize:
+		less = func(a, b *models.Clone) bool {
+			return a.Metadata.CloneDiffSize < b.Metadata.CloneDiffSize
+		}
+
+	default:
+		return nil, models.New(models.ErrCodeBadRequest, fmt.Sprintf("unknown sort field %q, supported: %s",
+			field, strings.Join([]string{sortByCreatedAt, sortByID, sortByStatus, sortBySnapshot, sortByDiffSize}, ", ")))
+	}
+
+	if strings.HasPrefix(sortOption, descendingSortPrefix) {
+		return func(a, b *models.Clone) bool {
+			return less(b, a)
+		}, nil
+	}
+
+	return less, nil
+}
+
+func createdAt(clone *models.Clone) time.Time {
+	if clone.CreatedAt == nil {
+		return time.Time{}
+	}
+
+	return clone.CreatedAt.Time
+}
+
+func snapshotID(clone *models.Clone) string {
+	if clone.Snapshot == nil {
+		return ""
+	}
+
+	return clone.Snapshot.ID
+}
@@ -0,0 +1,134 @@
+package cloning
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/client/dblabapi/types"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+func TestParseSelector(t *testing.T) {
+	requirements, err := parseSelector("team=billing, env != production,owner,!temporary,,")
+	require.NoError(t, err)
+
+	assert.Equal(t, []labelRequirement{
+		{key: "team", value: "billing", operator: labelEquals},
+		{key: "env", value: "production", operator: labelNotEquals},
+		{key: "owner", operator: labelExists},
+		{key: "temporary", operator: labelNotExists},
+	}, requirements)
+
+	for _, selector := range []string{"=billing", "!=billing", "!"} {
+		_, err := parseSelector(selector)
+		assert.Error(t, err, selector)
+	}
+}
+
+func TestLabelRequirement(t *testing.T) {
+	labels := map[string]string{"team": "billing", "env": "staging"}
+
+	testCases := []struct {
+		requirement labelRequirement
+		result      bool
+	}{
+		{requirement: labelRequirement{key: "team", value: "billing", operator: labelEquals}, result: true},
+		{requirement: labelRequirement{key: "team", value: "search", operator: labelEquals}, result: false},
+		{requirement: labelRequirement{key: "env", value: "production", operator: labelNotEquals}, result: true},
+		{requirement: labelRequirement{key: "owner", value: "john", operator: labelNotEquals}, result: true},
+		{requirement: labelRequirement{key: "owner", operator: labelExists}, result: false},
+		{requirement: labelRequirement{key: "owner", operator: labelNotExists}, result: true},
+		{requirement: labelRequirement{key: "team", operator: labelNotExists}, result: false},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.result, tc.requirement.matches(labels), tc.requirement)
+	}
+}
+
+func TestListClones(t *testing.T) {
+	now := time.Now()
+	snapshot1 := &models.Snapshot{ID: "snapshot1", Pool: "pool1"}
+	snapshot2 := &models.Snapshot{ID: "snapshot2", Pool: "pool2"}
+
+	newClone := func(id string, age time.Duration, status models.StatusCode, snapshot *models.Snapshot,
+		username string, labels map[string]string) *CloneWrapper {
+		return &CloneWrapper{Clone: &models.Clone{
+			ID:        id,
+			CreatedAt: models.NewLocalTime(now.Add(-age)),
+			Status:    models.Status{Code: status},
+			Snapshot:  snapshot,
+			DB:        models.Database{Username: username},
+			Labels:    labels,
+		}}
+	}
+
+	c := &Base{
+		clones: map[string]*CloneWrapper{
+			"clone1": newClone("clone1", time.Hour, models.StatusOK, snapshot1, "john", map[string]string{"team": "billing"}),
+			"clone2": newClone("clone2", 2*time.Hour, models.StatusOK, snapshot2, "jane", map[string]string{"team": "search"}),
+			"clone3": newClone("clone3", 3*time.Hour, models.StatusFatal, snapshot1, "john", nil),
+			"clone4": newClone("clone4", 4*time.Hour, models.StatusOK, snapshot1, "jane", map[string]string{"team": "billing"}),
+		},
+		snapshotBox: SnapshotBox{items: map[string]*models.Snapshot{snapshot1.ID: snapshot1, snapshot2.ID: snapshot2}},
+	}
+
+	ids := func(page *models.ClonePage) []string {
+		result := []string{}
+		for _, clone := range page.Clones {
+			result = append(result, clone.ID)
+		}
+
+		return result
+	}
+
+	testCases := []struct {
+		name    string
+		request types.CloneListRequest
+		ids     []string
+		total   int
+	}{
+		{name: "all clones", ids: []string{"clone1", "clone2", "clone3", "clone4"}, total: 4},
+		{name: "selector", request: types.CloneListRequest{Selector: "team=billing"}, ids: []string{"clone1", "clone4"}, total: 2},
+		{name: "status", request: types.CloneListRequest{Status: []string{"fatal"}}, ids: []string{"clone3"}, total: 1},
+		{name: "snapshot", request: types.CloneListRequest{Snapshot: "snapshot2"}, ids: []string{"clone2"}, total: 1},
+		{name: "pool", request: types.CloneListRequest{Pool: "pool1"}, ids: []string{"clone1", "clone3", "clone4"}, total: 3},
+		{name: "creator", request: types.CloneListRequest{Creator: "jane"}, ids: []string{"clone2", "clone4"}, total: 2},
+		{
+			name:    "age",
+			request: types.CloneListRequest{MinAge: 90 * time.Minute, MaxAge: 210 * time.Minute},
+			ids:     []string{"clone2", "clone3"},
+			total:   2,
+		},
+		{name: "sort", request: types.CloneListRequest{Sort: "created_at"}, ids: []string{"clone4", "clone3", "clone2", "clone1"}, total: 4},
+		{name: "sort descending", request: types.CloneListRequest{Sort: "-id"}, ids: []string{"clone4", "clone3", "clone2", "clone1"}, total: 4},
+		{name: "pagination", request: types.CloneListRequest{Sort: "id", Limit: 3, Offset: 2}, ids: []string{"clone3", "clone4"}, total: 4},
+		{name: "out of range", request: types.CloneListRequest{Offset: 10}, ids: []string{}, total: 4},
+	}
+
+	for _, tc := range testCases {
+		page, err := c.ListClones(tc.request)
+		require.NoError(t, err, tc.name)
+
+		assert.Equal(t, tc.ids, ids(page), tc.name)
+		assert.Equal(t, tc.total, page.Total, tc.name)
+	}
+
+	page, err := c.ListClones(types.CloneListRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, defaultListLimit, page.Limit)
+
+	for _, request := range []types.CloneListRequest{
+		{Limit: maxListLimit + 1},
+		{Offset: -1},
+		{MinAge: time.Hour, MaxAge: time.Minute},
+		{Sort: "size"},
+		{Selector: "=value"},
+	} {
+		_, err := c.ListClones(request)
+		assert.Error(t, err, request)
+	}
+}
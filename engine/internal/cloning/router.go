@@ -0,0 +1,137 @@
+package cloning
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/cloneproxy"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/util"
+)
+
+// RouterConfig defines the configuration of the router accepting connections to all clones on a single port.
+type RouterConfig struct {
+	Enabled bool `yaml:"enabled"`
+	Port    uint `yaml:"port"`
+}
+
+// routerSettings defines settings requiring a restart of the router.
+type routerSettings struct {
+	port uint
+	ssl  bool
+}
+
+// syncRouter starts, restarts or stops the router according to the configuration.
+func (c *Base) syncRouter() {
+	c.routerMu.Lock()
+	defer c.routerMu.Unlock()
+
+	settings := routerSettings{port: c.config.Router.Port, ssl: c.config.TLS.Enabled}
+
+	if c.routerListener != nil {
+		if c.config.Router.Enabled && c.routerSettings == settings {
+			return
+		}
+
+		if err := c.routerListener.Close(); err != nil {
+			log.Err("Failed to stop the clone router:", err)
+		}
+
+		c.routerListener = nil
+	}
+
+	if !c.config.Router.Enabled {
+		return
+	}
+
+	if settings.port == 0 {
+		log.Err(`Clone router is not started: "cloning.router.port" must be defined`)
+		return
+	}
+
+	listener, err := net.Listen("tcp", net.JoinHostPort("", strconv.FormatUint(uint64(settings.port), 10)))
+	if err != nil {
+		log.Err("Failed to start the clone router:", err)
+		return
+	}
+
+	c.routerListener = listener
+	c.routerSettings = settings
+
+	log.Msg(fmt.Sprintf("Clone router is listening on port %d", settings.port))
+
+	go func() {
+		if err := cloneproxy.NewRouter(c, settings.ssl).Serve(listener); err != nil {
+			log.Err("Clone router has stopped:", err)
+		}
+	}()
+}
+
+// ResolveClone returns the internal address of the clone for routed connections.
+// Routed connections come from the engine, so the router checks the client allowlist of the clone.
+// A stopped clone is started if waking up on connection is enabled.
+func (c *Base) ResolveClone(cloneID string, clientAddr net.Addr) (string, error) {
+	c.cloneMutex.RLock()
+
+	w, ok := c.clones[cloneID]
+	if !ok || w.Session == nil || w.Clone == nil {
+		c.cloneMutex.RUnlock()
+
+		return "", fmt.Errorf("clone %s not found", cloneID)
+	}
+
+	if !isAllowedClient(w, clientAddr) {
+		c.cloneMutex.RUnlock()
+
+		return "", fmt.Errorf("client %s is not allowed to connect to clone %s", clientAddr, cloneID)
+	}
+
+	status := w.Clone.Status.Code
+	port := w.Session.Port
+
+	c.cloneMutex.RUnlock()
+
+	switch status {
+	case models.StatusOK:
+		return net.JoinHostPort(util.GetCloneName(port), strconv.FormatUint(uint64(port), 10)), nil
+
+	case models.StatusStopped:
+		if !c.config.WakeOnConnect {
+			return "", fmt.Errorf("clone %s is stopped", cloneID)
+		}
+
+		if _, err := c.startClone(cloneID); err != nil {
+			log.Dbg(fmt.Sprintf("Clone %s has not been started: %v", cloneID, err))
+		}
+
+		return "", fmt.Errorf("clone %s is being started, retry in a few seconds", cloneID)
+	}
+
+	return "", fmt.Errorf("clone %s is not ready, status %s", cloneID, status)
+}
+
+// routerConnStr builds the connection string of the clone through the router.
+// SSL connections are routed by the SNI hostname, so the access host must be a DNS name resolving subdomains.
+func (c *Base) routerConnStr(cloneID, username, dbName string) string {
+	if !c.config.Router.Enabled {
+		return ""
+	}
+
+	port := strconv.FormatUint(uint64(c.config.Router.Port), 10)
+
+	if c.config.TLS.Enabled {
+		if !isDNSName(c.config.AccessHost) {
+			return ""
+		}
+
+		return c.connStr(cloneID+"."+c.config.AccessHost, port, username, dbName)
+	}
+
+	return c.connStr(c.config.AccessHost, port, username, cloneID+cloneproxy.RouteSeparator+dbName)
+}
+
+func isDNSName(host string) bool {
+	return host != "" && net.ParseIP(host) == nil
+}
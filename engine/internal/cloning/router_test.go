@@ -0,0 +1,78 @@
+package cloning
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/resources"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+func TestRouterConnStr(t *testing.T) {
+	testCases := []struct {
+		config   Config
+		expected string
+	}{
+		{
+			config:   Config{AccessHost: "dblab.example.com"},
+			expected: "",
+		},
+		{
+			config:   Config{AccessHost: "dblab.example.com", Router: RouterConfig{Enabled: true, Port: 6432}},
+			expected: "host=dblab.example.com port=6432 user=john dbname=clone1__app",
+		},
+		{
+			config:   Config{AccessHost: "dblab.example.com", Router: RouterConfig{Enabled: true, Port: 6432}, TLS: TLSConfig{Enabled: true}},
+			expected: "host=clone1.dblab.example.com port=6432 user=john dbname=app sslmode=verify-full",
+		},
+		{
+			config:   Config{AccessHost: "10.0.0.5", Router: RouterConfig{Enabled: true, Port: 6432}, TLS: TLSConfig{Enabled: true}},
+			expected: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		c := &Base{config: &tc.config}
+		assert.Equal(t, tc.expected, c.routerConnStr("clone1", "john", "app"))
+	}
+}
+
+func (s *BaseCloningSuite) TestResolveClone() {
+	s.cloning.config = &Config{}
+
+	s.cloning.setWrapper("running", &CloneWrapper{
+		Clone:   &models.Clone{ID: "running", Status: models.Status{Code: models.StatusOK}},
+		Session: &resources.Session{Port: 6000},
+	})
+	s.cloning.setWrapper("stopped", &CloneWrapper{
+		Clone:   &models.Clone{ID: "stopped", Status: models.Status{Code: models.StatusStopped}},
+		Session: &resources.Session{Port: 6001},
+	})
+
+	s.cloning.setWrapper("restricted", &CloneWrapper{
+		Clone:   &models.Clone{ID: "restricted", Status: models.Status{Code: models.StatusOK}, AllowedCIDRs: []string{"10.1.0.0/16"}},
+		Session: &resources.Session{Port: 6002},
+	})
+
+	client := &net.TCPAddr{IP: net.ParseIP("192.168.1.5"), Port: 50000}
+
+	addr, err := s.cloning.ResolveClone("running", client)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), "dblab_clone_6000:6000", addr)
+
+	_, err = s.cloning.ResolveClone("stopped", client)
+	assert.EqualError(s.T(), err, "clone stopped is stopped")
+
+	_, err = s.cloning.ResolveClone("absent", client)
+	assert.EqualError(s.T(), err, "clone absent not found")
+
+	_, err = s.cloning.ResolveClone("restricted", client)
+	assert.EqualError(s.T(), err, "client 192.168.1.5:50000 is not allowed to connect to clone restricted")
+
+	addr, err = s.cloning.ResolveClone("restricted", &net.TCPAddr{IP: net.ParseIP("10.1.2.3"), Port: 50000})
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), "dblab_clone_6002:6002", addr)
+}
@@ -0,0 +1,194 @@
+package cloning
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/resources"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/client/dblabapi/types"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/util"
+)
+
+// CreateSavepoint saves the current state of the clone data. The name is generated from the current time if it is empty.
+// The clone is busy while the savepoint is created.
+func (c *Base) CreateSavepoint(cloneID string, request types.SavepointCreateRequest) (*models.Savepoint, error) {
+	name := request.Name
+	if name == "" {
+		name = time.Now().UTC().Format(util.DataStateAtFormat)
+	}
+
+	w, err := c.transitCloneStatus(cloneID, []models.StatusCode{models.StatusOK}, models.Status{
+		Code:    models.StatusResetting,
+		Message: models.CloneMessageSavepoint,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The clone data is not changed even if the savepoint cannot be created, so the clone is ready anyway.
+	defer func() {
+		if err := c.UpdateCloneStatus(cloneID, models.Status{
+			Code:    models.StatusOK,
+			Message: models.CloneMessageOK,
+		}); err != nil {
+			log.Errf("Failed to update clone status: %v", err)
+		}
+	}()
+
+	savepoints, err := c.provision.ListSavepoints(w.Session)
+	if err != nil {
+		return nil, err
+	}
+
+	if hasSavepoint(savepoints, name) {
+		return nil, models.New(models.ErrCodeSavepointExists, fmt.Sprintf("savepoint %q already exists", name))
+	}
+
+	savepoint, err := c.provision.CreateSavepoint(w.Session, name)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Msg(fmt.Sprintf("Savepoint %s of clone %s has been created", name, cloneID))
+
+	return toSavepointView(*savepoint), nil
+}
+
+// RollbackSavepoint starts rolling the clone back to the savepoint. Savepoints created after this one are destroyed.
+func (c *Base) RollbackSavepoint(cloneID string, request types.SavepointRollbackRequest) error {
+	_, err := c.rollbackSavepoint(cloneID, request.Name)
+
+	return err
+}
+
+// rollbackSavepoint starts the rollback. The returned channel receives the result when the clone is ready.
+func (c *Base) rollbackSavepoint(cloneID, name string) (<-chan error, error) {
+	w, err := c.runningClone(cloneID)
+	if err != nil {
+		return nil, err
+	}
+
+	savepoints, err := c.provision.ListSavepoints(w.Session)
+	if err != nil {
+		return nil, err
+	}
+
+	if !hasSavepoint(savepoints, name) {
+		return nil, models.New(models.ErrCodeSavepointNotFound, fmt.Sprintf("savepoint %q not found", name))
+	}
+
+	if _, err := c.transitCloneStatus(cloneID, []models.StatusCode{models.StatusOK}, models.Status{
+		Code:    models.StatusResetting,
+		Message: models.CloneMessageResetting,
+	}); err != nil {
+		return nil, err
+	}
+
+	done := make(chan error, 1)
+
+	go func() {
+		if err := c.provision.RollbackSavepoint(w.Session, name); err != nil {
+			log.Errf("Failed to rollback clone %s to savepoint %s: %v", cloneID, name, err)
+
+			if updateErr := c.UpdateCloneStatus(cloneID, models.Status{
+				Code:    models.StatusFatal,
+				Message: errors.Cause(err).Error(),
+			}); updateErr != nil {
+				log.Errf("Failed to update clone status: %v", updateErr)
+			}
+
+			done <- err
+
+			return
+		}
+
+		c.cloneMutex.Lock()
+		w.Clone.DockerImage = c.sessionDockerImage(w.Session)
+		c.cloneMutex.Unlock()
+
+		if err := c.UpdateCloneStatus(cloneID, models.Status{
+			Code:    models.StatusOK,
+			Message: models.CloneMessageOK,
+		}); err != nil {
+			log.Errf("Failed to update clone status: %v", err)
+		}
+
+		c.SaveClonesState()
+
+		log.Msg(fmt.Sprintf("Clone %s has been rolled back to savepoint %s", cloneID, name))
+
+		done <- nil
+	}()
+
+	return done, nil
+}
+
+// ListSavepoints lists savepoints of the clone ordered by creation time.
+func (c *Base) ListSavepoints(cloneID string) ([]models.Savepoint, error) {
+	w, ok := c.findWrapper(cloneID)
+	if !ok {
+		return nil, models.New(models.ErrCodeCloneNotFound, "clone not found")
+	}
+
+	if w.Session == nil {
+		return nil, models.New(models.ErrCodeCloneNotStarted, "clone is not started yet")
+	}
+
+	savepoints, err := c.provision.ListSavepoints(w.Session)
+	if err != nil {
+		return nil, err
+	}
+
+	views := make([]models.Savepoint, 0, len(savepoints))
+
+	for _, savepoint := range savepoints {
+		views = append(views, *toSavepointView(savepoint))
+	}
+
+	return views, nil
+}
+
+// runningClone finds the clone and checks that its database is running.
+func (c *Base) runningClone(cloneID string) (*CloneWrapper, error) {
+	w, ok := c.findWrapper(cloneID)
+	if !ok {
+		return nil, models.New(models.ErrCodeCloneNotFound, "clone not found")
+	}
+
+	if w.Session == nil {
+		return nil, models.New(models.ErrCodeCloneNotStarted, "clone is not started yet")
+	}
+
+	c.cloneMutex.RLock()
+	status := w.Clone.Status.Code
+	c.cloneMutex.RUnlock()
+
+	if status != models.StatusOK {
+		return nil, models.New(models.ErrCodeCloneBusy, fmt.Sprintf("clone status is %s", status))
+	}
+
+	return w, nil
+}
+
+func hasSavepoint(savepoints []resources.Savepoint, name string) bool {
+	for _, savepoint := range savepoints {
+		if savepoint.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func toSavepointView(savepoint resources.Savepoint) *models.Savepoint {
+	return &models.Savepoint{
+		Name:         savepoint.Name,
+		CreatedAt:    models.NewLocalTime(savepoint.CreatedAt),
+		PhysicalSize: savepoint.Used,
+		LogicalSize:  savepoint.LogicalReferenced,
+	}
+}
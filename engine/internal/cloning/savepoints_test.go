@@ -0,0 +1,27 @@
+package cloning
+
+import (
+	"github.com/stretchr/testify/assert"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/resources"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/client/dblabapi/types"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+func (s *BaseCloningSuite) TestCreateSavepointOfBusyClone() {
+	resetting := &CloneWrapper{
+		Clone:   &models.Clone{ID: "resetting", Status: models.Status{Code: models.StatusResetting, Message: models.CloneMessageSavepoint}},
+		Session: &resources.Session{Port: 6000},
+	}
+
+	s.cloning.setWrapper("resetting", resetting)
+
+	// A concurrent savepoint is rejected while the clone is busy, the status is kept.
+	_, err := s.cloning.CreateSavepoint("resetting", types.SavepointCreateRequest{Name: "before-migration"})
+	assert.Equal(s.T(), models.ErrCodeCloneBusy, err.(*models.Error).Code)
+
+	assert.Equal(s.T(), models.StatusResetting, resetting.Clone.Status.Code)
+
+	_, err = s.cloning.CreateSavepoint("absent", types.SavepointCreateRequest{})
+	assert.Equal(s.T(), models.ErrCodeCloneNotFound, err.(*models.Error).Code)
+}
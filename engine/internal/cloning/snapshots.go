@@ -79,10 +79,30 @@ func (c *Base) cloneCounter() map[string]int {
 func (c *Base) resetSnapshots(snapshotMap map[string]*models.Snapshot, latestSnapshot *models.Snapshot) {
 	c.snapshotBox.snapshotMutex.Lock()
 
+	previous := c.snapshotBox.items
 	c.snapshotBox.latestSnapshot = latestSnapshot
 	c.snapshotBox.items = snapshotMap
 
 	c.snapshotBox.snapshotMutex.Unlock()
+
+	c.publishSnapshotChanges(previous, snapshotMap)
+}
+
+// publishSnapshotChanges notifies event subscribers about created and deleted snapshots.
+func (c *Base) publishSnapshotChanges(previous, current map[string]*models.Snapshot) {
+	for snapshotID, snapshot := range current {
+		if _, ok := previous[snapshotID]; !ok {
+			snapshotCopy := *snapshot
+			c.events.Publish(models.Event{Type: models.EventSnapshotCreated, Snapshot: &snapshotCopy})
+		}
+	}
+
+	for snapshotID, snapshot := range previous {
+		if _, ok := current[snapshotID]; !ok {
+			snapshotCopy := *snapshot
+			c.events.Publish(models.Event{Type: models.EventSnapshotDeleted, Snapshot: &snapshotCopy})
+		}
+	}
 }
 
 func (c *Base) addSnapshot(snapshot *models.Snapshot) {
@@ -112,7 +132,7 @@ func (c *Base) getLatestSnapshot() (*models.Snapshot, error) {
 		return c.snapshotBox.latestSnapshot, nil
 	}
 
-	return nil, errors.New("no snapshot found")
+	return nil, models.New(models.ErrCodeSnapshotNotFound, "no snapshot found")
 }
 
 // getSnapshotByID returns the snapshot by ID.
@@ -122,7 +142,7 @@ func (c *Base) getSnapshotByID(snapshotID string) (*models.Snapshot, error) {
 
 	snapshot, ok := c.snapshotBox.items[snapshotID]
 	if !ok {
-		return nil, errors.New("no snapshot found")
+		return nil, models.New(models.ErrCodeSnapshotNotFound, "no snapshot found")
 	}
 
 	return snapshot, nil
@@ -10,6 +10,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"gitlab.com/postgres-ai/database-lab/v3/internal/events"
 	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
 )
 
@@ -121,6 +122,32 @@ func TestCloneCounter(t *testing.T) {
 	require.Equal(t, 0, snapshot.NumClones)
 }
 
+func TestSnapshotEvents(t *testing.T) {
+	c := &Base{events: events.NewHub()}
+	c.snapshotBox.items = map[string]*models.Snapshot{
+		"snapshot1": {ID: "snapshot1"},
+		"snapshot2": {ID: "snapshot2"},
+	}
+
+	subscription := c.events.Subscribe()
+	defer subscription.Close()
+
+	c.resetSnapshots(map[string]*models.Snapshot{
+		"snapshot2": {ID: "snapshot2"},
+		"snapshot3": {ID: "snapshot3"},
+	}, nil)
+
+	event := <-subscription.Events()
+	require.Equal(t, models.EventSnapshotCreated, event.Type)
+	require.Equal(t, "snapshot3", event.Snapshot.ID)
+
+	event = <-subscription.Events()
+	require.Equal(t, models.EventSnapshotDeleted, event.Type)
+	require.Equal(t, "snapshot1", event.Snapshot.ID)
+
+	require.Len(t, subscription.Events(), 0)
+}
+
 func TestInitialCloneCounter(t *testing.T) {
 	c := &Base{}
 	c.clones = make(map[string]*CloneWrapper)
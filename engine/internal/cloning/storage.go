@@ -51,7 +51,7 @@ func (c *Base) restartCloneContainers(ctx context.Context) {
 	defer c.cloneMutex.Unlock()
 
 	for _, wrapper := range c.clones {
-		if wrapper.Clone == nil || wrapper.Session == nil {
+		if wrapper.Clone == nil || wrapper.Session == nil || isStoppedClone(wrapper) {
 			continue
 		}
 
@@ -102,7 +102,8 @@ func (c *Base) filterRunningClones(ctx context.Context) {
 			snapshotCache[snapshot.ID] = struct{}{}
 		}
 
-		if !c.provision.IsCloneRunning(ctx, util.GetCloneName(wrapper.Session.Port)) {
+		// Containers of stopped clones are removed, but their data is kept.
+		if !isStoppedClone(wrapper) && !c.provision.IsCloneRunning(ctx, util.GetCloneName(wrapper.Session.Port)) {
 			delete(c.clones, cloneID)
 		}
 
@@ -120,6 +121,8 @@ func (c *Base) SaveClonesState() {
 	if err := c.saveClonesState(sessionsPath); err != nil {
 		log.Err("Failed to save the state of running clones", err)
 	}
+
+	c.saveIdempotencyState()
 }
 
 // saveClonesState tries to write clones state to disk and returns an error on failure.
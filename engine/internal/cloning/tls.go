@@ -0,0 +1,111 @@
+package cloning
+
+import (
+	"fmt"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/certs"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/util"
+)
+
+const (
+	// caDirName defines the name of the metadata directory storing the certificate authority of clones.
+	caDirName = "ca"
+
+	// defaultCertHost defines the certificate host of clones if the access host is not set.
+	defaultCertHost = "localhost"
+
+	// verifyFullSSLMode defines the client SSL mode verifying both the certificate chain and the host name.
+	verifyFullSSLMode = "verify-full"
+)
+
+// TLSConfig defines the TLS configuration of clone connections.
+type TLSConfig struct {
+	Enabled bool `yaml:"enabled"`
+}
+
+// cloneCertIssuer issues clone certificates valid for the access host of clones.
+type cloneCertIssuer struct {
+	ca     *certs.Authority
+	config *Config
+}
+
+// IssueCloneCert issues a server certificate of the clone.
+func (i *cloneCertIssuer) IssueCloneCert(cloneName string) (certPEM, keyPEM []byte, err error) {
+	return i.ca.IssueServerCert(cloneName, certHosts(i.config))
+}
+
+// certHosts returns hosts of clone certificates. The router passes SSL connections to "<clone-id>.<access host>" through,
+// so certificates are valid for subdomains of the access host if the router is enabled.
+func certHosts(cfg *Config) []string {
+	if cfg.AccessHost == "" {
+		return []string{defaultCertHost}
+	}
+
+	hosts := []string{cfg.AccessHost}
+
+	if cfg.Router.Enabled && isDNSName(cfg.AccessHost) {
+		hosts = append(hosts, "*."+cfg.AccessHost)
+	}
+
+	return hosts
+}
+
+// setupTLS loads the certificate authority and makes the provisioner issue certificates of clones if TLS is enabled.
+func (c *Base) setupTLS() error {
+	if !c.config.TLS.Enabled {
+		c.tlsMu.Lock()
+		defer c.tlsMu.Unlock()
+
+		if c.ca != nil {
+			c.ca = nil
+			c.provision.SetCertIssuer(nil)
+		}
+
+		return nil
+	}
+
+	caDir, err := util.GetMetaPath(caDirName)
+	if err != nil {
+		return fmt.Errorf("failed to get path of the certificate authority: %w", err)
+	}
+
+	ca, err := certs.LoadAuthority(caDir)
+	if err != nil {
+		return fmt.Errorf("failed to load the certificate authority: %w", err)
+	}
+
+	c.tlsMu.Lock()
+	c.ca = ca
+	c.tlsMu.Unlock()
+
+	c.provision.SetCertIssuer(&cloneCertIssuer{ca: ca, config: c.config})
+
+	log.Msg("TLS is enabled for clone connections")
+
+	return nil
+}
+
+// CACert returns the PEM-encoded certificate of the authority issuing clone certificates.
+func (c *Base) CACert() ([]byte, error) {
+	c.tlsMu.RLock()
+	defer c.tlsMu.RUnlock()
+
+	if c.ca == nil {
+		return nil, models.New(models.ErrCodeNotFound, "TLS is not enabled for clone connections")
+	}
+
+	return c.ca.CertPEM(), nil
+}
+
+// connStr builds the connection string of the clone.
+func (c *Base) connStr(host, port, username, dbName string) string {
+	connStr := fmt.Sprintf("host=%s port=%s user=%s dbname=%s", host, port, username, dbName)
+
+	if c.config.TLS.Enabled {
+		connStr += " sslmode=" + verifyFullSSLMode
+	}
+
+	return connStr
+}
@@ -0,0 +1,113 @@
+package cloning
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/resources"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/client/dblabapi/types"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+// UpgradeClone starts upgrading the clone to the Postgres major version of the requested Docker image.
+// The clone has the UPGRADING status until the upgrade is finished, the result is reported by GetCloneUpgrade.
+func (c *Base) UpgradeClone(cloneID string, request types.CloneUpgradeRequest) error {
+	dockerImage := request.DockerImage
+
+	w, err := c.runningClone(cloneID)
+	if err != nil {
+		return err
+	}
+
+	if err := c.provision.CheckUpgrade(w.Session, dockerImage); err != nil {
+		return err
+	}
+
+	if _, err := c.transitCloneStatus(cloneID, []models.StatusCode{models.StatusOK}, models.Status{
+		Code:    models.StatusUpgrading,
+		Message: models.CloneMessageUpgrading,
+	}); err != nil {
+		return err
+	}
+
+	c.cloneMutex.Lock()
+	w.Upgrade = &models.CloneUpgrade{
+		Status:      models.UpgradeStatusRunning,
+		DockerImage: dockerImage,
+		StartedAt:   models.NewLocalTime(time.Now()),
+	}
+	c.cloneMutex.Unlock()
+
+	go func() {
+		report, err := c.provision.UpgradeSession(w.Session, dockerImage)
+
+		c.cloneMutex.Lock()
+		w.Upgrade = report
+		w.Clone.DockerImage = c.sessionDockerImage(w.Session)
+		c.cloneMutex.Unlock()
+
+		if err != nil {
+			log.Errf("Failed to upgrade clone %s: %v.", cloneID, err)
+
+			if updateErr := c.UpdateCloneStatus(cloneID, models.Status{
+				Code:    models.StatusFatal,
+				Message: errors.Cause(err).Error(),
+			}); updateErr != nil {
+				log.Errf("Failed to update clone status: %v", updateErr)
+			}
+
+			c.SaveClonesState()
+
+			return
+		}
+
+		if err := c.UpdateCloneStatus(cloneID, models.Status{
+			Code:    models.StatusOK,
+			Message: models.CloneMessageOK,
+		}); err != nil {
+			log.Errf("Failed to update clone status: %v", err)
+		}
+
+		c.SaveClonesState()
+
+		if report.Status != models.UpgradeStatusFinished {
+			log.Msg(fmt.Sprintf("Clone %s has not been upgraded: %s", cloneID, report.Error))
+			return
+		}
+
+		log.Msg(fmt.Sprintf("Clone %s has been upgraded from Postgres %s to %s", cloneID, report.FromVersion, report.ToVersion))
+	}()
+
+	return nil
+}
+
+// GetCloneUpgrade returns the report of the last major-version upgrade of the clone.
+func (c *Base) GetCloneUpgrade(cloneID string) (*models.CloneUpgrade, error) {
+	w, ok := c.findWrapper(cloneID)
+	if !ok {
+		return nil, models.New(models.ErrCodeCloneNotFound, "clone not found")
+	}
+
+	c.cloneMutex.RLock()
+	defer c.cloneMutex.RUnlock()
+
+	if w.Upgrade == nil {
+		return nil, models.New(models.ErrCodeNotFound, "clone has not been upgraded")
+	}
+
+	upgrade := *w.Upgrade
+
+	return &upgrade, nil
+}
+
+// sessionDockerImage returns the Docker image the clone container is running on.
+func (c *Base) sessionDockerImage(session *resources.Session) string {
+	if session == nil || session.DockerImage == "" {
+		return c.provision.ContainerOptions().DockerImage
+	}
+
+	return session.DockerImage
+}
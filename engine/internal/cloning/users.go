@@ -0,0 +1,146 @@
+package cloning
+
+import (
+	"fmt"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/databases/postgres"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/resources"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/client/dblabapi/types"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+// CreateCloneUser adds a database user to the running clone. The user is kept in the session state
+// to be recreated after the clone reset.
+func (c *Base) CreateCloneUser(cloneID string, request types.CloneUserRequest) (*models.CloneUser, error) {
+	w, err := c.runningClone(cloneID)
+	if err != nil {
+		return nil, err
+	}
+
+	c.cloneMutex.RLock()
+	exists := findSessionUser(w.Session, request.Username) != nil
+	c.cloneMutex.RUnlock()
+
+	if exists {
+		return nil, models.New(models.ErrCodeUserExists, fmt.Sprintf("user %q already exists", request.Username))
+	}
+
+	user := resources.EphemeralUser{
+		Name:        request.Username,
+		Password:    request.Password,
+		Role:        string(request.Role),
+		AvailableDB: request.DBName,
+		Grants:      request.Grants,
+	}
+
+	if err := c.provision.CreateSessionUser(w.Session, user); err != nil {
+		return nil, err
+	}
+
+	c.cloneMutex.Lock()
+	w.Session.ExtraUsers = append(w.Session.ExtraUsers, user)
+	c.cloneMutex.Unlock()
+
+	c.SaveClonesState()
+
+	log.Msg(fmt.Sprintf("User %s with role %s has been added to clone %s", user.Name, user.Role, cloneID))
+
+	return toCloneUserView(user, false), nil
+}
+
+// DeleteCloneUser drops an additional database user of the running clone.
+func (c *Base) DeleteCloneUser(cloneID, username string) error {
+	w, err := c.runningClone(cloneID)
+	if err != nil {
+		return err
+	}
+
+	c.cloneMutex.RLock()
+	user := findSessionUser(w.Session, username)
+	isPrimary := w.Session.EphemeralUser.Name == username
+	c.cloneMutex.RUnlock()
+
+	if user == nil {
+		return models.New(models.ErrCodeUserNotFound, fmt.Sprintf("user %q not found", username))
+	}
+
+	if isPrimary {
+		return models.New(models.ErrCodeBadRequest, "the user defined on clone creation cannot be deleted")
+	}
+
+	if err := c.provision.DropSessionUser(w.Session, username); err != nil {
+		return err
+	}
+
+	c.cloneMutex.Lock()
+	w.Session.ExtraUsers = removeUser(w.Session.ExtraUsers, username)
+	c.cloneMutex.Unlock()
+
+	c.SaveClonesState()
+
+	log.Msg(fmt.Sprintf("User %s has been deleted from clone %s", username, cloneID))
+
+	return nil
+}
+
+// ListCloneUsers lists database users of the clone starting with the user defined on clone creation.
+// Passwords are not reported.
+func (c *Base) ListCloneUsers(cloneID string) ([]models.CloneUser, error) {
+	w, ok := c.findWrapper(cloneID)
+	if !ok {
+		return nil, models.New(models.ErrCodeCloneNotFound, "clone not found")
+	}
+
+	c.cloneMutex.RLock()
+	defer c.cloneMutex.RUnlock()
+
+	if w.Session == nil {
+		return nil, models.New(models.ErrCodeCloneNotStarted, "clone is not started yet")
+	}
+
+	users := make([]models.CloneUser, 0, len(w.Session.ExtraUsers)+1)
+	users = append(users, *toCloneUserView(w.Session.EphemeralUser, true))
+
+	for _, user := range w.Session.ExtraUsers {
+		users = append(users, *toCloneUserView(user, false))
+	}
+
+	return users, nil
+}
+
+func findSessionUser(session *resources.Session, username string) *resources.EphemeralUser {
+	if session.EphemeralUser.Name == username {
+		return &session.EphemeralUser
+	}
+
+	for i := range session.ExtraUsers {
+		if session.ExtraUsers[i].Name == username {
+			return &session.ExtraUsers[i]
+		}
+	}
+
+	return nil
+}
+
+func removeUser(users []resources.EphemeralUser, username string) []resources.EphemeralUser {
+	kept := make([]resources.EphemeralUser, 0, len(users))
+
+	for _, user := range users {
+		if user.Name != username {
+			kept = append(kept, user)
+		}
+	}
+
+	return kept
+}
+
+func toCloneUserView(user resources.EphemeralUser, primary bool) *models.CloneUser {
+	return &models.CloneUser{
+		Name:    user.Name,
+		Role:    postgres.UserRole(user),
+		DBName:  user.AvailableDB,
+		Grants:  user.Grants,
+		Primary: primary,
+	}
+}
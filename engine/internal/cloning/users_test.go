@@ -0,0 +1,44 @@
+package cloning
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/resources"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/client/dblabapi/types"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+func (s *BaseCloningSuite) TestCloneUsers() {
+	s.cloning.setWrapper("shared", &CloneWrapper{
+		Clone: &models.Clone{ID: "shared", Status: models.Status{Code: models.StatusOK}},
+		Session: &resources.Session{
+			Port:          6000,
+			EphemeralUser: resources.EphemeralUser{Name: "app", Password: "secret", Restricted: true, AvailableDB: "appdb"},
+			ExtraUsers: []resources.EphemeralUser{
+				{Name: "analyst", Password: "secret", Role: string(models.UserRoleReadOnly)},
+				{Name: "auditor", Password: "secret", Role: string(models.UserRoleCustom), Grants: []string{"grant select on audit to auditor"}},
+			},
+		},
+	})
+
+	users, err := s.cloning.ListCloneUsers("shared")
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), []models.CloneUser{
+		{Name: "app", Role: models.UserRoleRestricted, DBName: "appdb", Primary: true},
+		{Name: "analyst", Role: models.UserRoleReadOnly},
+		{Name: "auditor", Role: models.UserRoleCustom, Grants: []string{"grant select on audit to auditor"}},
+	}, users)
+
+	_, err = s.cloning.CreateCloneUser("shared", types.CloneUserRequest{Username: "analyst", Password: "pwd", Role: models.UserRoleReadOnly})
+	assert.EqualError(s.T(), err, `user "analyst" already exists`)
+
+	err = s.cloning.DeleteCloneUser("shared", "app")
+	assert.EqualError(s.T(), err, "the user defined on clone creation cannot be deleted")
+
+	err = s.cloning.DeleteCloneUser("shared", "absent")
+	assert.EqualError(s.T(), err, `user "absent" not found`)
+
+	_, err = s.cloning.ListCloneUsers("absent")
+	assert.EqualError(s.T(), err, "clone not found")
+}
@@ -20,6 +20,9 @@ type CloneWrapper struct {
 
 	TimeCreatedAt time.Time `json:"time_created_at"`
 	TimeStartedAt time.Time `json:"time_started_at"`
+
+	// Upgrade keeps the report of the last major-version upgrade of the clone.
+	Upgrade *models.CloneUpgrade `json:"upgrade,omitempty"`
 }
 
 // NewCloneWrapper constructs a new CloneWrapper.
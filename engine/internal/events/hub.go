@@ -0,0 +1,128 @@
+// Package events provides broadcasting of instance state changes to subscribers.
+package events
+
+import (
+	"sync"
+	"time"
+
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+// subscriptionBufferSize defines the number of events buffered for a subscriber.
+const subscriptionBufferSize = 64
+
+// Hub delivers published events to subscribers. A nil Hub discards events.
+type Hub struct {
+	mu          sync.RWMutex
+	lastID      uint64
+	subscribers map[*Subscription]struct{}
+}
+
+// Subscription receives events published to the hub.
+type Subscription struct {
+	hub    *Hub
+	events chan models.Event
+}
+
+// NewHub creates a new event hub.
+func NewHub() *Hub {
+	return &Hub{subscribers: make(map[*Subscription]struct{})}
+}
+
+// Publish sends the event to all subscribers. Publishing never blocks:
+// subscribers that do not keep up are dropped and their channels are closed.
+func (h *Hub) Publish(event models.Event) {
+	if h == nil {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.lastID++
+	event.ID = h.lastID
+
+	if event.Time == nil {
+		event.Time = models.NewLocalTime(time.Now())
+	}
+
+	for s := range h.subscribers {
+		select {
+		case s.events <- event:
+
+		default:
+			log.Dbg("Event subscriber does not keep up, drop it")
+			h.unsubscribe(s)
+		}
+	}
+}
+
+// Subscribe registers a new subscriber.
+func (h *Hub) Subscribe() *Subscription {
+	s := &Subscription{hub: h, events: make(chan models.Event, subscriptionBufferSize)}
+
+	h.mu.Lock()
+	h.subscribers[s] = struct{}{}
+	h.mu.Unlock()
+
+	return s
+}
+
+// HasSubscribers checks if the hub has active subscribers.
+func (h *Hub) HasSubscribers() bool {
+	if h == nil {
+		return false
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return len(h.subscribers) > 0
+}
+
+func (h *Hub) unsubscribe(s *Subscription) {
+	if _, ok := h.subscribers[s]; !ok {
+		return
+	}
+
+	delete(h.subscribers, s)
+	close(s.events)
+}
+
+// Events returns the channel of events. The channel is closed when the subscription is closed or dropped.
+func (s *Subscription) Events() <-chan models.Event {
+	return s.events
+}
+
+// Close unsubscribes from the hub.
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	s.hub.unsubscribe(s)
+	s.hub.mu.Unlock()
+}
+
+// Filter selects events by clone and type. Empty fields match any event.
+type Filter struct {
+	CloneID string
+	Types   []models.EventType
+}
+
+// Match checks if the event matches the filter.
+func (f Filter) Match(event models.Event) bool {
+	if f.CloneID != "" && event.CloneID() != f.CloneID {
+		return false
+	}
+
+	if len(f.Types) == 0 {
+		return true
+	}
+
+	for _, eventType := range f.Types {
+		if event.Type == eventType {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,80 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+func TestHub(t *testing.T) {
+	hub := NewHub()
+	assert.False(t, hub.HasSubscribers())
+
+	s := hub.Subscribe()
+	assert.True(t, hub.HasSubscribers())
+
+	hub.Publish(models.Event{Type: models.EventCloneStatus, Clone: &models.Clone{ID: "clone1"}})
+	hub.Publish(models.Event{Type: models.EventCloneDeleted, Clone: &models.Clone{ID: "clone1"}})
+
+	event := <-s.Events()
+	assert.Equal(t, uint64(1), event.ID)
+	assert.Equal(t, models.EventCloneStatus, event.Type)
+	assert.NotNil(t, event.Time)
+
+	event = <-s.Events()
+	assert.Equal(t, uint64(2), event.ID)
+	assert.Equal(t, models.EventCloneDeleted, event.Type)
+
+	s.Close()
+	s.Close()
+
+	_, ok := <-s.Events()
+	assert.False(t, ok)
+	assert.False(t, hub.HasSubscribers())
+}
+
+func TestHubDropsSlowSubscribers(t *testing.T) {
+	hub := NewHub()
+	slow := hub.Subscribe()
+
+	for i := 0; i < subscriptionBufferSize+1; i++ {
+		hub.Publish(models.Event{Type: models.EventPoolStatus})
+	}
+
+	assert.False(t, hub.HasSubscribers())
+
+	received := 0
+	for range slow.Events() {
+		received++
+	}
+
+	require.Equal(t, subscriptionBufferSize, received)
+
+	slow.Close()
+}
+
+func TestNilHub(t *testing.T) {
+	var hub *Hub
+
+	hub.Publish(models.Event{Type: models.EventPoolStatus})
+	assert.False(t, hub.HasSubscribers())
+}
+
+func TestFilter(t *testing.T) {
+	cloneEvent := models.Event{Type: models.EventCloneStatus, Clone: &models.Clone{ID: "clone1"}}
+	poolEvent := models.Event{Type: models.EventPoolStatus, Pool: &models.PoolEntry{Name: "pool1"}}
+
+	assert.True(t, Filter{}.Match(cloneEvent))
+	assert.True(t, Filter{}.Match(poolEvent))
+
+	assert.True(t, Filter{CloneID: "clone1"}.Match(cloneEvent))
+	assert.False(t, Filter{CloneID: "clone2"}.Match(cloneEvent))
+	assert.False(t, Filter{CloneID: "clone1"}.Match(poolEvent))
+
+	typesFilter := Filter{Types: []models.EventType{models.EventCloneStatus, models.EventCloneDeleted}}
+	assert.True(t, typesFilter.Match(cloneEvent))
+	assert.False(t, typesFilter.Match(poolEvent))
+}
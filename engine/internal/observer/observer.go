@@ -13,6 +13,7 @@ import (
 	"os"
 	"regexp"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -33,6 +34,9 @@ const (
 	statusFailed = "failed"
 
 	observerApplicationName = "observer"
+
+	// CSVLogTimeFormat defines the format of timestamps in CSV logs.
+	CSVLogTimeFormat = "2006-01-02 15:04:05.999 MST"
 )
 
 // Observer manages observation sessions.
@@ -155,11 +159,11 @@ func (o *Observer) scanCSVLogFile(ctx context.Context, reader io.Reader, writer
 		}
 
 		// Check an application name to skip observer log entries.
-		if entry[22] == observerApplicationName {
+		if IsObserverLogEntry(entry) {
 			continue
 		}
 
-		logTime, err := time.Parse("2006-01-02 15:04:05.999 MST", entry[0])
+		logTime, err := time.Parse(CSVLogTimeFormat, entry[0])
 		if err != nil {
 			return err
 		}
@@ -184,6 +188,33 @@ func (o *Observer) scanCSVLogFile(ctx context.Context, reader io.Reader, writer
 	return nil
 }
 
+// MaskQuery applies replacement rules to the query text.
+func (o *Observer) MaskQuery(query string) string {
+	for _, rule := range o.replacementRules {
+		query = rule.re.ReplaceAllString(query, rule.replace)
+	}
+
+	return query
+}
+
+// CSVLogFieldIndex returns the position of the field in an entry of the CSV log, or -1 if the field is unknown.
+func CSVLogFieldIndex(field string) int {
+	for i, csvField := range strings.Split(csvFields, ",") {
+		if csvField == field {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// IsObserverLogEntry checks if the entry of the CSV log has been produced by the observer itself.
+func IsObserverLogEntry(entry []string) bool {
+	index := CSVLogFieldIndex("application_name")
+
+	return index < len(entry) && entry[index] == observerApplicationName
+}
+
 func (o *Observer) maskLogs(entry []string, maskedFieldIndexes []int) {
 	for _, maskedFieldIndex := range maskedFieldIndexes {
 		for _, rule := range o.replacementRules {
@@ -165,6 +165,46 @@ func (c *ObservingClone) ReadSummary(sessionID uint64) ([]byte, error) {
 	return c.readFileStats(sessionID, summaryFilename)
 }
 
+// StoreArtifact stores an artifact produced outside of the observation routine in the current observation session.
+func (c *ObservingClone) StoreArtifact(artifactType string, data []byte) error {
+	if c.session == nil {
+		return errors.New("observation session has not been initialized")
+	}
+
+	if c.session.IsFinished() {
+		return errors.New("observation session has already finished")
+	}
+
+	if !IsAvailableArtifactType(artifactType) {
+		return errors.Errorf("unknown artifact type %q", artifactType)
+	}
+
+	dstPath := path.Join(c.currentArtifactsSessionPath(), artifactsSubDir, BuildArtifactFilename(artifactType))
+
+	if err := os.WriteFile(dstPath, data, 0644); err != nil {
+		return errors.Wrapf(err, "failed to store artifact %q", artifactType)
+	}
+
+	for _, sessionArtifact := range c.session.Artifacts {
+		if sessionArtifact == artifactType {
+			return nil
+		}
+	}
+
+	c.session.Artifacts = append(c.session.Artifacts, artifactType)
+
+	return nil
+}
+
+// ReadArtifact reads an artifact of the current observation session.
+func (c *ObservingClone) ReadArtifact(artifactType string) ([]byte, error) {
+	if c.session == nil {
+		return nil, errors.New("observation session has not been initialized")
+	}
+
+	return os.ReadFile(c.BuildArtifactPath(c.session.SessionID, artifactType))
+}
+
 // Init initializes observation session.
 func (c *ObservingClone) Init(clone *models.Clone, sessionID uint64, startedAt time.Time, tags map[string]string) error {
 	c.session = NewSession(sessionID, startedAt, c.config, tags)
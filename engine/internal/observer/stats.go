@@ -42,6 +42,21 @@ const (
 	defaultArtifactFormat = "json"
 )
 
+// Types of artifacts which are produced outside of the observation routine.
+const (
+	// RunnerOutputType defines the type of artifact containing the output of external migration commands.
+	RunnerOutputType = "runner_output"
+
+	// PlanBaselineType defines the type of artifact containing query plans captured before a migration.
+	PlanBaselineType = "plan_baseline"
+
+	// PlanCheckType defines the type of artifact containing the report of a query plan regression check.
+	PlanCheckType = "plan_check"
+
+	// ReplayLatencyType defines the type of artifact containing latency histograms of a replayed workload.
+	ReplayLatencyType = "replay_latency"
+)
+
 var availableArtifactTypes = map[string]struct{}{
 	pgStatStatementsType:   {},
 	pgStatUserTablesType:   {},
@@ -57,6 +72,15 @@ var availableArtifactTypes = map[string]struct{}{
 	pgStatSLRUType:         {},
 	objectsSizeType:        {},
 	logErrorsType:          {},
+	RunnerOutputType:       {},
+	PlanBaselineType:       {},
+	PlanCheckType:          {},
+	ReplayLatencyType:      {},
+}
+
+// uploadableArtifactTypes contains artifact types which are produced outside of Database Lab Engine.
+var uploadableArtifactTypes = map[string]struct{}{
+	RunnerOutputType: {},
 }
 
 func (c *ObservingClone) storeSummary() error {
@@ -391,6 +415,13 @@ func IsAvailableArtifactType(artifactType string) bool {
 	return ok
 }
 
+// IsUploadableArtifactType checks if artifact type can be uploaded to an observation session.
+func IsUploadableArtifactType(artifactType string) bool {
+	_, ok := uploadableArtifactTypes[artifactType]
+
+	return ok
+}
+
 // BuildArtifactPath generates a full path to the artifact file.
 func (c *ObservingClone) BuildArtifactPath(sessionID uint64, artifactType string) string {
 	fullFilename := path.Join(c.artifactsSessionPath(sessionID), artifactsSubDir, BuildArtifactFilename(artifactType))
@@ -0,0 +1,384 @@
+package plancheck
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgtype/pgxtype"
+	"github.com/pkg/errors"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/cloning"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/observer"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/tools"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/client/dblabapi/types"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+const (
+	cloneUsername       = "dblab_plan_check"
+	cloneStatusInterval = time.Second
+	explainPrefix       = "EXPLAIN (ANALYZE, BUFFERS, FORMAT JSON) "
+)
+
+// Checker runs query plan regression checks on clones.
+type Checker struct {
+	cloning  *cloning.Base
+	observer *observer.Observer
+}
+
+// NewChecker creates a new plan checker.
+func NewChecker(cloning *cloning.Base, observer *observer.Observer) *Checker {
+	return &Checker{
+		cloning:  cloning,
+		observer: observer,
+	}
+}
+
+// Check explains the requested queries and compares their plans.
+//
+// If snapshots are specified, the queries are explained on temporary clones created from both snapshots.
+// Otherwise, the queries are explained on the clone with the running observation session:
+// the "before" stage captures baseline plans, and the "after" stage compares current plans with them.
+// If the clone has a running observation session, the report is stored as a session artifact.
+func (c *Checker) Check(ctx context.Context, request *types.PlanCheckRequest) (*models.PlanCheckReport, error) {
+	if err := validateRequest(request); err != nil {
+		return nil, err
+	}
+
+	var observingClone *observer.ObservingClone
+
+	if request.CloneID != "" {
+		var err error
+
+		if observingClone, err = c.observer.GetObservingClone(request.CloneID); err != nil {
+			return nil, models.New(models.ErrCodeBadRequest, "observation session of the clone has not been started")
+		}
+	}
+
+	if request.SnapshotA != "" {
+		return c.checkSnapshots(ctx, request, observingClone)
+	}
+
+	return c.checkSession(ctx, request, observingClone)
+}
+
+func validateRequest(request *types.PlanCheckRequest) error {
+	if len(request.Queries) == 0 {
+		return models.New(models.ErrCodeBadRequest, "at least one query is required")
+	}
+
+	names := make(map[string]struct{}, len(request.Queries))
+
+	for i := range request.Queries {
+		query := &request.Queries[i]
+
+		if strings.TrimSpace(query.Query) == "" {
+			return models.New(models.ErrCodeBadRequest, fmt.Sprintf("query #%d is empty", i+1))
+		}
+
+		if query.Name == "" {
+			query.Name = fmt.Sprintf("query_%d", i+1)
+		}
+
+		if _, ok := names[query.Name]; ok {
+			return models.New(models.ErrCodeBadRequest, fmt.Sprintf("query name %q is not unique", query.Name))
+		}
+
+		names[query.Name] = struct{}{}
+	}
+
+	if (request.SnapshotA == "") != (request.SnapshotB == "") {
+		return models.New(models.ErrCodeBadRequest, "both snapshots must be specified")
+	}
+
+	if request.SnapshotA == "" {
+		if request.CloneID == "" {
+			return models.New(models.ErrCodeBadRequest, "either snapshots or a clone ID must be specified")
+		}
+
+		if request.Stage != types.PlanCheckStageBefore && request.Stage != types.PlanCheckStageAfter {
+			return models.New(models.ErrCodeBadRequest,
+				fmt.Sprintf("stage must be %q or %q", types.PlanCheckStageBefore, types.PlanCheckStageAfter))
+		}
+	}
+
+	return nil
+}
+
+func (c *Checker) checkSnapshots(ctx context.Context, request *types.PlanCheckRequest,
+	observingClone *observer.ObservingClone) (*models.PlanCheckReport, error) {
+	report := newReport(request.Queries, "snapshot "+request.SnapshotA, "snapshot "+request.SnapshotB)
+
+	baseline, err := c.explainOnSnapshot(ctx, request.SnapshotA, request)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to explain queries on snapshot %s", request.SnapshotA)
+	}
+
+	target, err := c.explainOnSnapshot(ctx, request.SnapshotB, request)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to explain queries on snapshot %s", request.SnapshotB)
+	}
+
+	thresholds := applyThresholdDefaults(request.Thresholds)
+
+	for i := range report.Queries {
+		setResult(&report.Queries[i], baseline[i], true)
+		setResult(&report.Queries[i], target[i], false)
+		comparePlans(&report.Queries[i], thresholds)
+	}
+
+	report.Status = reportStatus(report.Queries)
+	report.FinishedAt = time.Now()
+
+	if observingClone != nil {
+		if err := storeArtifact(observingClone, observer.PlanCheckType, report); err != nil {
+			return nil, err
+		}
+	}
+
+	return report, nil
+}
+
+func (c *Checker) checkSession(ctx context.Context, request *types.PlanCheckRequest,
+	observingClone *observer.ObservingClone) (*models.PlanCheckReport, error) {
+	if request.Stage == types.PlanCheckStageBefore {
+		report := newReport(request.Queries, "clone "+request.CloneID+" before migration", "")
+
+		results, err := c.explainOnClone(ctx, request.CloneID, request.Queries)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range report.Queries {
+			setResult(&report.Queries[i], results[i], true)
+		}
+
+		report.Status = models.PlanCheckBaseline
+		report.FinishedAt = time.Now()
+
+		if err := storeArtifact(observingClone, observer.PlanBaselineType, report); err != nil {
+			return nil, err
+		}
+
+		return report, nil
+	}
+
+	baselineData, err := observingClone.ReadArtifact(observer.PlanBaselineType)
+	if err != nil {
+		return nil, models.New(models.ErrCodeBadRequest, "baseline plans have not been captured: run the \"before\" stage first")
+	}
+
+	var baselineReport models.PlanCheckReport
+
+	if err := json.Unmarshal(baselineData, &baselineReport); err != nil {
+		return nil, errors.Wrap(err, "failed to read baseline plans")
+	}
+
+	baselinePlans := make(map[string]models.QueryPlanReport, len(baselineReport.Queries))
+
+	for _, query := range baselineReport.Queries {
+		baselinePlans[query.Name] = query
+	}
+
+	report := newReport(request.Queries, baselineReport.Baseline, "clone "+request.CloneID+" after migration")
+
+	results, err := c.explainOnClone(ctx, request.CloneID, request.Queries)
+	if err != nil {
+		return nil, err
+	}
+
+	thresholds := applyThresholdDefaults(request.Thresholds)
+
+	for i := range report.Queries {
+		queryReport := &report.Queries[i]
+
+		baselinePlan, ok := baselinePlans[queryReport.Name]
+
+		switch {
+		case !ok:
+			queryReport.Error = "baseline plan has not been captured"
+
+		case baselinePlan.Error != "":
+			queryReport.Error = "baseline: " + baselinePlan.Error
+
+		default:
+			queryReport.Baseline = baselinePlan.Baseline
+		}
+
+		setResult(queryReport, results[i], false)
+		comparePlans(queryReport, thresholds)
+	}
+
+	report.Status = reportStatus(report.Queries)
+	report.FinishedAt = time.Now()
+
+	if err := storeArtifact(observingClone, observer.PlanCheckType, report); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// queryResult contains the outcome of a query explained on a clone.
+type queryResult struct {
+	stats *models.QueryPlanStats
+	err   error
+}
+
+func newReport(queries []types.PlanQuery, baseline, target string) *models.PlanCheckReport {
+	report := &models.PlanCheckReport{
+		Baseline:  baseline,
+		Target:    target,
+		StartedAt: time.Now(),
+		Queries:   make([]models.QueryPlanReport, 0, len(queries)),
+	}
+
+	for _, query := range queries {
+		report.Queries = append(report.Queries, models.QueryPlanReport{Name: query.Name, Query: query.Query})
+	}
+
+	return report
+}
+
+func setResult(report *models.QueryPlanReport, result queryResult, baseline bool) {
+	if result.err != nil {
+		if report.Error == "" {
+			report.Error = result.err.Error()
+		}
+
+		return
+	}
+
+	if baseline {
+		report.Baseline = result.stats
+		return
+	}
+
+	report.Target = result.stats
+}
+
+// explainOnSnapshot explains queries on a temporary clone created from the snapshot.
+func (c *Checker) explainOnSnapshot(ctx context.Context, snapshotID string, request *types.PlanCheckRequest) ([]queryResult, error) {
+	password, err := tools.GeneratePassword()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate a password")
+	}
+
+	clone, err := c.cloning.CreateClone(&types.CloneCreateRequest{
+		DB: &types.DatabaseRequest{
+			Username: cloneUsername,
+			Password: password,
+			DBName:   request.DBName,
+		},
+		Snapshot: &types.SnapshotCloneFieldRequest{ID: snapshotID},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create clone")
+	}
+
+	defer func() {
+		if err := c.cloning.DestroyClone(clone.ID); err != nil {
+			log.Err("failed to destroy plan check clone: ", err)
+		}
+	}()
+
+	if err := c.waitForClone(ctx, clone.ID); err != nil {
+		return nil, err
+	}
+
+	return c.explainOnClone(ctx, clone.ID, request.Queries)
+}
+
+func (c *Checker) waitForClone(ctx context.Context, cloneID string) error {
+	ticker := time.NewTicker(cloneStatusInterval)
+	defer ticker.Stop()
+
+	for {
+		clone, err := c.cloning.GetClone(cloneID)
+		if err != nil {
+			return errors.Wrap(err, "failed to get clone")
+		}
+
+		switch clone.Status.Code {
+		case models.StatusOK:
+			return nil
+
+		case models.StatusFatal:
+			return errors.Errorf("failed to create clone: %s", clone.Status.Message)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case <-ticker.C:
+		}
+	}
+}
+
+// explainOnClone explains queries on the clone. Query errors are reported per query.
+func (c *Checker) explainOnClone(ctx context.Context, cloneID string, queries []types.PlanQuery) ([]queryResult, error) {
+	db, err := c.cloning.ConnectToClone(ctx, cloneID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to connect to clone")
+	}
+
+	defer func() {
+		if conn, ok := db.(interface{ Close(context.Context) error }); ok {
+			if err := conn.Close(context.Background()); err != nil {
+				log.Err("failed to close connection: ", err)
+			}
+		}
+	}()
+
+	results := make([]queryResult, 0, len(queries))
+
+	for _, query := range queries {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		stats, err := explain(ctx, db, query)
+		results = append(results, queryResult{stats: stats, err: err})
+	}
+
+	return results, nil
+}
+
+// explain runs EXPLAIN ANALYZE inside a transaction which is rolled back, so data-modifying queries leave no changes.
+func explain(ctx context.Context, db pgxtype.Querier, query types.PlanQuery) (*models.QueryPlanStats, error) {
+	if _, err := db.Exec(ctx, "begin"); err != nil {
+		return nil, errors.Wrap(err, "failed to begin transaction")
+	}
+
+	defer func() {
+		if _, err := db.Exec(context.Background(), "rollback"); err != nil {
+			log.Err("failed to rollback transaction: ", err)
+		}
+	}()
+
+	var plan []byte
+
+	if err := db.QueryRow(ctx, explainPrefix+query.Query, query.Params...).Scan(&plan); err != nil {
+		return nil, errors.Wrap(err, "failed to explain query")
+	}
+
+	return parsePlan(plan)
+}
+
+func storeArtifact(observingClone *observer.ObservingClone, artifactType string, report *models.PlanCheckReport) error {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode plan check report")
+	}
+
+	if err := observingClone.StoreArtifact(artifactType, data); err != nil {
+		return errors.Wrap(err, "failed to store plan check report")
+	}
+
+	return nil
+}
@@ -0,0 +1,119 @@
+package plancheck
+
+import (
+	"fmt"
+
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/client/dblabapi/types"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+const (
+	defaultTimeThreshold    = 2.0
+	defaultBuffersThreshold = 1.5
+
+	// minComparableTime defines the execution time (in milliseconds) below which timing fluctuations are ignored.
+	minComparableTime = 1.0
+)
+
+func applyThresholdDefaults(thresholds types.PlanCheckThresholds) types.PlanCheckThresholds {
+	if thresholds.ExecutionTime <= 0 {
+		thresholds.ExecutionTime = defaultTimeThreshold
+	}
+
+	if thresholds.Buffers <= 0 {
+		thresholds.Buffers = defaultBuffersThreshold
+	}
+
+	return thresholds
+}
+
+// comparePlans compares the target plan of the query with the baseline one.
+func comparePlans(report *models.QueryPlanReport, thresholds types.PlanCheckThresholds) {
+	if report.Baseline == nil || report.Target == nil {
+		return
+	}
+
+	report.PlanDiff = diffNodes(report.Baseline.Nodes, report.Target.Nodes)
+	report.PlanChanged = len(report.PlanDiff) > 0
+
+	if report.Baseline.ExecutionTime > 0 {
+		report.TimeRatio = report.Target.ExecutionTime / report.Baseline.ExecutionTime
+
+		if report.Target.ExecutionTime >= minComparableTime && report.TimeRatio > thresholds.ExecutionTime {
+			report.Regressions = append(report.Regressions, fmt.Sprintf("execution time grew %.2fx (%.3f ms -> %.3f ms)",
+				report.TimeRatio, report.Baseline.ExecutionTime, report.Target.ExecutionTime))
+		}
+	}
+
+	if baselineBuffers := report.Baseline.Buffers(); baselineBuffers > 0 {
+		report.BuffersRatio = float64(report.Target.Buffers()) / float64(baselineBuffers)
+
+		if report.BuffersRatio > thresholds.Buffers {
+			report.Regressions = append(report.Regressions, fmt.Sprintf("buffers grew %.2fx (%d -> %d)",
+				report.BuffersRatio, baselineBuffers, report.Target.Buffers()))
+		}
+	}
+}
+
+// diffNodes builds a line-based diff of plan nodes: removed nodes are prefixed with "-", added ones with "+".
+func diffNodes(baseline, target []string) []string {
+	// lcs[i][j] holds the length of the longest common subsequence of baseline[i:] and target[j:].
+	lcs := make([][]int, len(baseline)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(target)+1)
+	}
+
+	for i := len(baseline) - 1; i >= 0; i-- {
+		for j := len(target) - 1; j >= 0; j-- {
+			switch {
+			case baseline[i] == target[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	diff := []string{}
+	i, j := 0, 0
+
+	for i < len(baseline) && j < len(target) {
+		switch {
+		case baseline[i] == target[j]:
+			i++
+			j++
+
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			diff = append(diff, "- "+baseline[i])
+			i++
+
+		default:
+			diff = append(diff, "+ "+target[j])
+			j++
+		}
+	}
+
+	for ; i < len(baseline); i++ {
+		diff = append(diff, "- "+baseline[i])
+	}
+
+	for ; j < len(target); j++ {
+		diff = append(diff, "+ "+target[j])
+	}
+
+	return diff
+}
+
+func reportStatus(queries []models.QueryPlanReport) string {
+	for _, query := range queries {
+		if query.Error != "" || len(query.Regressions) > 0 {
+			return models.PlanCheckFailed
+		}
+	}
+
+	return models.PlanCheckPassed
+}
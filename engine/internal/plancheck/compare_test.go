@@ -0,0 +1,155 @@
+package plancheck
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/client/dblabapi/types"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+const indexScanPlan = `[{
+  "Plan": {
+    "Node Type": "Nested Loop", "Join Type": "Inner", "Shared Hit Blocks": 40, "Shared Read Blocks": 2,
+    "Plans": [
+      {"Node Type": "Index Scan", "Relation Name": "orders", "Index Name": "orders_user_id_idx", "Shared Hit Blocks": 30},
+      {"Node Type": "Index Scan", "Relation Name": "users", "Index Name": "users_pkey", "Shared Hit Blocks": 10}
+    ]
+  },
+  "Planning Time": 0.2,
+  "Execution Time": 1.5
+}]`
+
+const seqScanPlan = `[{
+  "Plan": {
+    "Node Type": "Hash Join", "Join Type": "Left", "Shared Hit Blocks": 100, "Shared Read Blocks": 900,
+    "Plans": [
+      {"Node Type": "Seq Scan", "Relation Name": "orders", "Shared Hit Blocks": 90, "Shared Read Blocks": 900},
+      {"Node Type": "Index Scan", "Relation Name": "users", "Index Name": "users_pkey", "Shared Hit Blocks": 10}
+    ]
+  },
+  "Planning Time": 0.3,
+  "Execution Time": 45.1
+}]`
+
+func TestParsePlan(t *testing.T) {
+	stats, err := parsePlan([]byte(indexScanPlan))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"Nested Loop",
+		"  Index Scan using orders_user_id_idx on orders",
+		"  Index Scan using users_pkey on users",
+	}, stats.Nodes)
+	assert.Equal(t, 1.5, stats.ExecutionTime)
+	assert.Equal(t, 0.2, stats.PlanningTime)
+	assert.Equal(t, int64(42), stats.Buffers())
+
+	_, err = parsePlan([]byte(`[]`))
+	assert.Error(t, err)
+
+	_, err = parsePlan([]byte(`{`))
+	assert.Error(t, err)
+}
+
+func TestComparePlans(t *testing.T) {
+	baseline, err := parsePlan([]byte(indexScanPlan))
+	require.NoError(t, err)
+
+	target, err := parsePlan([]byte(seqScanPlan))
+	require.NoError(t, err)
+
+	report := models.QueryPlanReport{Baseline: baseline, Target: target}
+	comparePlans(&report, applyThresholdDefaults(types.PlanCheckThresholds{}))
+
+	assert.True(t, report.PlanChanged)
+	assert.Equal(t, []string{
+		"- Nested Loop",
+		"- " + "  Index Scan using orders_user_id_idx on orders",
+		"+ Hash Join (Left)",
+		"+ " + "  Seq Scan on orders",
+	}, report.PlanDiff)
+	assert.InDelta(t, 30.07, report.TimeRatio, 0.01)
+	assert.InDelta(t, 23.81, report.BuffersRatio, 0.01)
+	assert.Len(t, report.Regressions, 2)
+	assert.Equal(t, models.PlanCheckFailed, reportStatus([]models.QueryPlanReport{report}))
+
+	same := models.QueryPlanReport{Baseline: baseline, Target: baseline}
+	comparePlans(&same, applyThresholdDefaults(types.PlanCheckThresholds{}))
+
+	assert.False(t, same.PlanChanged)
+	assert.Empty(t, same.Regressions)
+	assert.Equal(t, models.PlanCheckPassed, reportStatus([]models.QueryPlanReport{same}))
+}
+
+func TestComparePlansIgnoresFastQueries(t *testing.T) {
+	report := models.QueryPlanReport{
+		Baseline: &models.QueryPlanStats{ExecutionTime: 0.1, SharedHit: 10},
+		Target:   &models.QueryPlanStats{ExecutionTime: 0.5, SharedHit: 12},
+	}
+
+	comparePlans(&report, applyThresholdDefaults(types.PlanCheckThresholds{}))
+
+	assert.InDelta(t, 5, report.TimeRatio, 0.01)
+	assert.Empty(t, report.Regressions)
+}
+
+func TestDiffNodes(t *testing.T) {
+	testCases := []struct {
+		baseline []string
+		target   []string
+		diff     []string
+	}{
+		{baseline: []string{"a", "b"}, target: []string{"a", "b"}, diff: []string{}},
+		{baseline: []string{"a", "b", "c"}, target: []string{"a", "c"}, diff: []string{"- b"}},
+		{baseline: []string{"a"}, target: []string{"a", "b", "c"}, diff: []string{"+ b", "+ c"}},
+		{baseline: []string{"a", "b"}, target: []string{"c", "b"}, diff: []string{"- a", "+ c"}},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.diff, diffNodes(tc.baseline, tc.target))
+	}
+}
+
+func TestValidateRequest(t *testing.T) {
+	request := &types.PlanCheckRequest{
+		Queries:   []types.PlanQuery{{Query: "select 1"}, {Name: "users", Query: "select * from users where id = $1"}},
+		SnapshotA: "snapshot_a",
+		SnapshotB: "snapshot_b",
+	}
+
+	require.NoError(t, validateRequest(request))
+	assert.Equal(t, "query_1", request.Queries[0].Name)
+
+	testCases := []struct {
+		request types.PlanCheckRequest
+		message string
+	}{
+		{request: types.PlanCheckRequest{}, message: "at least one query is required"},
+		{request: types.PlanCheckRequest{Queries: []types.PlanQuery{{Query: " "}}}, message: "query #1 is empty"},
+		{
+			request: types.PlanCheckRequest{Queries: []types.PlanQuery{{Name: "q", Query: "select 1"}, {Name: "q", Query: "select 2"}}},
+			message: `query name "q" is not unique`,
+		},
+		{
+			request: types.PlanCheckRequest{Queries: []types.PlanQuery{{Query: "select 1"}}, SnapshotA: "snapshot_a"},
+			message: "both snapshots must be specified",
+		},
+		{
+			request: types.PlanCheckRequest{Queries: []types.PlanQuery{{Query: "select 1"}}},
+			message: "either snapshots or a clone ID must be specified",
+		},
+		{
+			request: types.PlanCheckRequest{Queries: []types.PlanQuery{{Query: "select 1"}}, CloneID: "clone"},
+			message: `stage must be "before" or "after"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		err := validateRequest(&tc.request)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), tc.message)
+	}
+}
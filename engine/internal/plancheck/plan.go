@@ -0,0 +1,85 @@
+// Package plancheck provides regression testing of query plans on clones.
+package plancheck
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+// explainResult represents an item of the EXPLAIN (FORMAT JSON) output.
+type explainResult struct {
+	Plan          planNode `json:"Plan"`
+	PlanningTime  float64  `json:"Planning Time"`
+	ExecutionTime float64  `json:"Execution Time"`
+}
+
+// planNode represents a node of the query plan.
+type planNode struct {
+	NodeType     string     `json:"Node Type"`
+	JoinType     string     `json:"Join Type"`
+	RelationName string     `json:"Relation Name"`
+	IndexName    string     `json:"Index Name"`
+	SharedHit    int64      `json:"Shared Hit Blocks"`
+	SharedRead   int64      `json:"Shared Read Blocks"`
+	Plans        []planNode `json:"Plans"`
+}
+
+// parsePlan extracts the key metrics from the output of EXPLAIN (ANALYZE, BUFFERS, FORMAT JSON).
+func parsePlan(data []byte) (*models.QueryPlanStats, error) {
+	var results []explainResult
+
+	if err := json.Unmarshal(data, &results); err != nil {
+		return nil, errors.Wrap(err, "failed to parse the query plan")
+	}
+
+	if len(results) == 0 {
+		return nil, errors.New("query plan is empty")
+	}
+
+	result := results[0]
+
+	stats := &models.QueryPlanStats{
+		Nodes:         describeNodes(result.Plan, 0, nil),
+		PlanningTime:  result.PlanningTime,
+		ExecutionTime: result.ExecutionTime,
+		SharedHit:     result.Plan.SharedHit,
+		SharedRead:    result.Plan.SharedRead,
+		Plan:          data,
+	}
+
+	return stats, nil
+}
+
+// describeNodes flattens the plan tree into a list of node descriptions indented by the node depth.
+func describeNodes(node planNode, depth int, nodes []string) []string {
+	nodes = append(nodes, strings.Repeat("  ", depth)+describeNode(node))
+
+	for _, child := range node.Plans {
+		nodes = describeNodes(child, depth+1, nodes)
+	}
+
+	return nodes
+}
+
+func describeNode(node planNode) string {
+	description := node.NodeType
+
+	if node.JoinType != "" && node.JoinType != "Inner" {
+		description = fmt.Sprintf("%s (%s)", description, node.JoinType)
+	}
+
+	if node.IndexName != "" {
+		description += " using " + node.IndexName
+	}
+
+	if node.RelationName != "" {
+		description += " on " + node.RelationName
+	}
+
+	return description
+}
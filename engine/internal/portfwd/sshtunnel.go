@@ -59,6 +59,14 @@ func (tunnel *SSHTunnel) Open() error {
 
 	tunnel.listener = listener
 
+	// Keep the actual address if the tunnel listens on a random port.
+	tunnel.Endpoints.Local = listener.Addr().String()
+
+	return tunnel.Connect()
+}
+
+// Connect opens a connection to the SSH server without listening locally.
+func (tunnel *SSHTunnel) Connect() error {
 	log.Dbg("Opening server connection...", tunnel.Endpoints.Server)
 
 	serverConn, err := ssh.Dial("tcp", tunnel.Endpoints.Server, tunnel.Config)
@@ -71,6 +79,20 @@ func (tunnel *SSHTunnel) Open() error {
 	return nil
 }
 
+// Dial opens a connection to the address through the SSH server.
+func (tunnel *SSHTunnel) Dial(address string) (net.Conn, error) {
+	if tunnel.serverConn == nil {
+		return nil, errors.New("server connection is not ready")
+	}
+
+	conn, err := tunnel.serverConn.Dial("tcp", address)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to dial %s through the SSH server", address)
+	}
+
+	return conn, nil
+}
+
 // Listen waits for and processes connections to the SSH tunnel.
 func (tunnel *SSHTunnel) Listen(ctx context.Context) error {
 	if tunnel.listener == nil || tunnel.serverConn == nil {
@@ -0,0 +1,146 @@
+package provision
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/databases/postgres"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/resources"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/util"
+)
+
+const (
+	// loopbackAddress defines the host address publishing ports of clones accessible only from the host.
+	loopbackAddress = "127.0.0.1"
+
+	// loopbackPrefixLength defines the prefix length of the IPv4 loopback network.
+	loopbackPrefixLength = 8
+
+	ipv4Bits = 32
+	ipv6Bits = 128
+)
+
+// NormalizeCIDRs checks client CIDRs allowed to connect to a clone. Single IP addresses are converted to CIDRs.
+// Only loopback CIDRs are accepted: the allowlist of other networks would be enforced only in pg_hba.conf,
+// which allows all connections from the Docker networks of the clone, until it is enforced at the network layer.
+func NormalizeCIDRs(cidrs []string) ([]string, error) {
+	normalized := make([]string, 0, len(cidrs))
+
+	for _, cidr := range cidrs {
+		cidr = strings.TrimSpace(cidr)
+
+		if ip := net.ParseIP(cidr); ip != nil {
+			normalized = append(normalized, hostCIDR(ip))
+			continue
+		}
+
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, models.New(models.ErrCodeBadRequest, fmt.Sprintf("invalid client CIDR %q", cidr))
+		}
+
+		normalized = append(normalized, ipNet.String())
+	}
+
+	if len(normalized) > 0 && !isLoopbackOnly(normalized) {
+		return nil, models.New(models.ErrCodeBadRequest, "only loopback client CIDRs are supported, for example, 127.0.0.1/32")
+	}
+
+	return normalized, nil
+}
+
+func hostCIDR(ip net.IP) string {
+	if ip.To4() != nil {
+		return ip.String() + "/32"
+	}
+
+	return ip.String() + "/128"
+}
+
+// IsAllowedClient checks if the client address belongs to the allowed CIDRs. An empty list allows all clients.
+func IsAllowedClient(cidrs []string, clientIP net.IP) bool {
+	if len(cidrs) == 0 {
+		return true
+	}
+
+	for _, cidr := range cidrs {
+		if _, ipNet, err := net.ParseCIDR(cidr); err == nil && ipNet.Contains(clientIP) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isLoopbackOnly checks if only clients of the host are allowed.
+func isLoopbackOnly(cidrs []string) bool {
+	if len(cidrs) == 0 {
+		return false
+	}
+
+	for _, cidr := range cidrs {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil || !ipNet.IP.IsLoopback() {
+			return false
+		}
+
+		ones, bits := ipNet.Mask.Size()
+		if (bits == ipv4Bits && ones < loopbackPrefixLength) || (bits == ipv6Bits && ones < bits) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// setAllowedCIDRs restricts clients of the clone. The port of a clone allowing only clients of the host
+// is published only on the loopback interface.
+func (p *Provisioner) setAllowedCIDRs(appConfig *resources.AppConfig, cidrs []string) {
+	appConfig.AllowedCIDRs = cidrs
+
+	if isLoopbackOnly(cidrs) {
+		appConfig.ProvisionHosts = p.restrictProvisionHosts(loopbackAddress)
+	}
+}
+
+// UpdateSessionAccess changes client CIDRs allowed to connect to the running clone of the session.
+// The clone container is restarted if the port has to be published on other host addresses.
+func (p *Provisioner) UpdateSessionAccess(session *resources.Session, cidrs []string) error {
+	fsm, err := p.pm.GetFSManager(session.Pool)
+	if err != nil {
+		return errors.Wrap(err, "failed to find a filesystem manager of this session")
+	}
+
+	cloneName := util.GetCloneName(session.Port)
+
+	appConfig := p.getAppConfig(fsm.Pool(), cloneName, session.Port)
+	appConfig.SetExtraConf(session.ExtraConfig)
+	setDockerImage(appConfig, session.DockerImage)
+	p.setAllowedCIDRs(appConfig, cidrs)
+
+	if isLoopbackOnly(cidrs) != isLoopbackOnly(session.AllowedCIDRs) {
+		if err := postgres.Stop(p.runner, fsm.Pool(), cloneName); err != nil {
+			return errors.Wrap(err, "failed to stop a container")
+		}
+
+		if err := postgres.Start(p.runner, appConfig); err != nil {
+			return errors.Wrap(err, "failed to start a container")
+		}
+
+		return nil
+	}
+
+	if err := postgres.ConfigureHBA(appConfig); err != nil {
+		return errors.Wrap(err, "failed to configure client authentication")
+	}
+
+	if err := postgres.ReloadConfig(appConfig); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,38 @@
+package provision
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeCIDRs(t *testing.T) {
+	cidrs, err := NormalizeCIDRs([]string{"127.1.2.3/16", " 127.0.0.1", "::1"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"127.1.0.0/16", "127.0.0.1/32", "::1/128"}, cidrs)
+
+	cidrs, err = NormalizeCIDRs(nil)
+	require.NoError(t, err)
+	assert.Empty(t, cidrs)
+
+	_, err = NormalizeCIDRs([]string{"127.0.0.0/33"})
+	assert.EqualError(t, err, `invalid client CIDR "127.0.0.0/33"`)
+
+	_, err = NormalizeCIDRs([]string{"127.0.0.1", "10.1.0.0/16"})
+	assert.EqualError(t, err, "only loopback client CIDRs are supported, for example, 127.0.0.1/32")
+}
+
+func TestIsAllowedClient(t *testing.T) {
+	assert.True(t, IsAllowedClient(nil, net.ParseIP("192.168.1.5")))
+	assert.True(t, IsAllowedClient([]string{"10.1.0.0/16", "192.168.1.5/32"}, net.ParseIP("192.168.1.5")))
+	assert.False(t, IsAllowedClient([]string{"10.1.0.0/16"}, net.ParseIP("192.168.1.5")))
+}
+
+func TestIsLoopbackOnly(t *testing.T) {
+	assert.False(t, isLoopbackOnly(nil))
+	assert.True(t, isLoopbackOnly([]string{"127.0.0.1/32", "::1/128"}))
+	assert.False(t, isLoopbackOnly([]string{"127.0.0.1/32", "10.1.0.0/16"}))
+	assert.False(t, isLoopbackOnly([]string{"0.0.0.0/0"}))
+}
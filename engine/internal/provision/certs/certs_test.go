@@ -0,0 +1,42 @@
+package certs
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIssueServerCert(t *testing.T) {
+	dir := t.TempDir()
+
+	ca, err := LoadAuthority(dir)
+	require.NoError(t, err)
+
+	reloaded, err := LoadAuthority(dir)
+	require.NoError(t, err)
+	assert.Equal(t, ca.CertPEM(), reloaded.CertPEM())
+
+	certPEM, keyPEM, err := reloaded.IssueServerCert("dblab_clone_6000", []string{"10.0.0.5", "dblab.example.com"})
+	require.NoError(t, err)
+	assert.NotEmpty(t, keyPEM)
+
+	block, _ := pem.Decode(certPEM)
+	require.NotNil(t, block)
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+
+	roots := x509.NewCertPool()
+	require.True(t, roots.AppendCertsFromPEM(ca.CertPEM()))
+
+	for _, host := range []string{"10.0.0.5", "dblab.example.com"} {
+		_, err = cert.Verify(x509.VerifyOptions{DNSName: host, Roots: roots})
+		assert.NoError(t, err, host)
+	}
+
+	_, err = cert.Verify(x509.VerifyOptions{DNSName: "other.example.com", Roots: roots})
+	assert.Error(t, err)
+}
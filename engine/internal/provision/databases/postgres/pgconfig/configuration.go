@@ -26,6 +26,15 @@ import (
 const (
 	initializedLabel = "## DBLAB_INITIALIZED"
 
+	// allowlistLabel marks pg_hba.conf rules changed to restrict access to clones.
+	allowlistLabel = "## DBLAB_ALLOWLIST"
+
+	// hostSSLLabel marks pg_hba.conf rules changed to accept only SSL connections.
+	hostSSLLabel = "## DBLAB_HOSTSSL"
+
+	// sameNetAddress matches addresses of subnets the clone container is connected to.
+	sameNetAddress = "samenet"
+
 	// defaultPgCfgDir defines directory with default Postgres configs.
 	defaultPgCfgDir = "default"
 
@@ -223,6 +232,104 @@ func (m *Manager) adjustHBAConf() error {
 	return nil
 }
 
+// AdjustHostRules restricts open host rules of pg_hba.conf to the client CIDRs and makes host rules accept
+// only SSL connections if SSL is required. Rules changed before are restored first, so an empty list removes
+// the restriction and host rules accept connections without SSL again if it is not required.
+func (m *Manager) AdjustHostRules(cidrs []string, requireSSL bool) error {
+	pgHbaPath := path.Join(m.dataDir, pgHbaConfName)
+
+	input, err := os.ReadFile(pgHbaPath)
+	if err != nil {
+		return errors.Wrapf(err, "cannot read %s", pgHbaConfName)
+	}
+
+	// The SSL rules are restored before adjusting the allowlist, since the allowlist recognizes its rules by the trailing label.
+	output := hostSSLRules(allowlistRules(hostSSLRules(input, false), cidrs), requireSSL)
+	if bytes.Equal(input, output) {
+		return nil
+	}
+
+	if err := os.WriteFile(pgHbaPath, output, 0644); err != nil {
+		return errors.Wrapf(err, "cannot write %s", pgHbaConfName)
+	}
+
+	return nil
+}
+
+// hostSSLRules replaces the "host" connection type with "hostssl" keeping other rules and comments.
+// The replaced rules are marked with the host SSL label to restore them.
+func hostSSLRules(hbaConf []byte, require bool) []byte {
+	lines := strings.Split(string(hbaConf), "\n")
+
+	for i, line := range lines {
+		if strings.HasSuffix(line, " "+hostSSLLabel) {
+			line = strings.Replace(strings.TrimSuffix(line, " "+hostSSLLabel), "hostssl", "host", 1)
+		}
+
+		fields := strings.Fields(line)
+
+		if require && len(fields) > 0 && fields[0] == "host" {
+			line = strings.Replace(line, "host", "hostssl", 1) + " " + hostSSLLabel
+		}
+
+		lines[i] = line
+	}
+
+	return []byte(strings.Join(lines, "\n"))
+}
+
+// allowlistRules replaces host rules open to all addresses with rules for each CIDR.
+// The replaced rules are kept commented out with the allowlist label to restore them.
+// Connections from the networks of the clone container are allowed to keep access through the router and docker-proxy,
+// so pg_hba.conf does not restrict other containers on these networks, including other clones, and host clients
+// passing through docker-proxy. Only the router checks original client addresses of these connections.
+func allowlistRules(hbaConf []byte, cidrs []string) []byte {
+	lines := strings.Split(string(hbaConf), "\n")
+	rules := make([]string, 0, len(lines))
+
+	for _, line := range lines {
+		switch {
+		case strings.HasSuffix(line, " "+allowlistLabel):
+			continue
+
+		case strings.HasPrefix(line, allowlistLabel+" "):
+			line = strings.TrimPrefix(line, allowlistLabel+" ")
+		}
+
+		fields := strings.Fields(line)
+
+		if len(cidrs) == 0 || !isOpenHostRule(fields) {
+			rules = append(rules, line)
+			continue
+		}
+
+		rules = append(rules, allowlistLabel+" "+line)
+
+		for _, address := range append([]string{sameNetAddress}, cidrs...) {
+			rule := append([]string{fields[0], fields[1], fields[2], address}, fields[4:]...)
+			rules = append(rules, strings.Join(rule, " ")+" "+allowlistLabel)
+		}
+	}
+
+	return []byte(strings.Join(rules, "\n"))
+}
+
+// isOpenHostRule checks if the pg_hba.conf rule allows host connections from all addresses.
+func isOpenHostRule(fields []string) bool {
+	const addressField = 3
+
+	if len(fields) <= addressField || (fields[0] != "host" && fields[0] != "hostssl") {
+		return false
+	}
+
+	switch fields[addressField] {
+	case "all", "0.0.0.0/0", "::/0":
+		return true
+	}
+
+	return false
+}
+
 // adjustGeneralConfigs corrects general PostgreSQL parameters with Database Lab configs.
 func (m *Manager) adjustGeneralConfigs() error {
 	log.Dbg("Configuring Postgres...")
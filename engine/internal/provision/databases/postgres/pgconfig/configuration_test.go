@@ -2,6 +2,7 @@ package pgconfig
 
 import (
 	"os"
+	"path"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -35,3 +36,92 @@ recovery_target_timeline = latest
 	assert.Equal(t, expected["standby_mode"], fileConfig["standby_mode"])
 	assert.Equal(t, expected["recovery_target_timeline"], fileConfig["recovery_target_timeline"])
 }
+
+func TestHostSSLRules(t *testing.T) {
+	hbaConf := `# TYPE  DATABASE        USER            ADDRESS                 METHOD
+local all all trust
+host all all 0.0.0.0/0 md5
+hostssl all all ::/0 md5
+`
+	expected := `# TYPE  DATABASE        USER            ADDRESS                 METHOD
+local all all trust
+hostssl all all 0.0.0.0/0 md5 ## DBLAB_HOSTSSL
+hostssl all all ::/0 md5
+`
+
+	output := hostSSLRules([]byte(hbaConf), true)
+	assert.Equal(t, expected, string(output))
+	assert.Equal(t, expected, string(hostSSLRules(output, true)))
+
+	// Rules accept connections without SSL again if SSL is not required.
+	assert.Equal(t, hbaConf, string(hostSSLRules(output, false)))
+}
+
+func TestAdjustHostRules(t *testing.T) {
+	hbaConf := `local all all trust
+host all all 0.0.0.0/0 md5
+`
+	dataDir := t.TempDir()
+	pgHbaPath := path.Join(dataDir, pgHbaConfName)
+	require.NoError(t, os.WriteFile(pgHbaPath, []byte(hbaConf), 0644))
+
+	m := &Manager{dataDir: dataDir}
+
+	require.NoError(t, m.AdjustHostRules([]string{"10.1.0.0/16"}, true))
+	assertFileContent(t, pgHbaPath, `local all all trust
+## DBLAB_ALLOWLIST host all all 0.0.0.0/0 md5
+hostssl all all samenet md5 ## DBLAB_ALLOWLIST ## DBLAB_HOSTSSL
+hostssl all all 10.1.0.0/16 md5 ## DBLAB_ALLOWLIST ## DBLAB_HOSTSSL
+`)
+
+	require.NoError(t, m.AdjustHostRules([]string{"172.16.0.0/12"}, false))
+	assertFileContent(t, pgHbaPath, `local all all trust
+## DBLAB_ALLOWLIST host all all 0.0.0.0/0 md5
+host all all samenet md5 ## DBLAB_ALLOWLIST
+host all all 172.16.0.0/12 md5 ## DBLAB_ALLOWLIST
+`)
+
+	require.NoError(t, m.AdjustHostRules(nil, true))
+	assertFileContent(t, pgHbaPath, `local all all trust
+hostssl all all 0.0.0.0/0 md5 ## DBLAB_HOSTSSL
+`)
+
+	require.NoError(t, m.AdjustHostRules(nil, false))
+	assertFileContent(t, pgHbaPath, hbaConf)
+}
+
+func assertFileContent(t *testing.T, filename, expected string) {
+	t.Helper()
+
+	content, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	assert.Equal(t, expected, string(content))
+}
+
+func TestAllowlistRules(t *testing.T) {
+	hbaConf := `local all all trust
+host all all 0.0.0.0/0 md5
+host replication all 10.0.0.0/8 md5
+`
+	restricted := `local all all trust
+## DBLAB_ALLOWLIST host all all 0.0.0.0/0 md5
+host all all samenet md5 ## DBLAB_ALLOWLIST
+host all all 10.1.0.0/16 md5 ## DBLAB_ALLOWLIST
+host all all 192.168.1.5/32 md5 ## DBLAB_ALLOWLIST
+host replication all 10.0.0.0/8 md5
+`
+
+	output := allowlistRules([]byte(hbaConf), []string{"10.1.0.0/16", "192.168.1.5/32"})
+	assert.Equal(t, restricted, string(output))
+
+	// The allowlist is replaced on the next change.
+	output = allowlistRules(output, []string{"172.16.0.0/12"})
+	assert.Equal(t, `local all all trust
+## DBLAB_ALLOWLIST host all all 0.0.0.0/0 md5
+host all all samenet md5 ## DBLAB_ALLOWLIST
+host all all 172.16.0.0/12 md5 ## DBLAB_ALLOWLIST
+host replication all 10.0.0.0/8 md5
+`, string(output))
+
+	assert.Equal(t, hbaConf, string(allowlistRules(output, nil)))
+}
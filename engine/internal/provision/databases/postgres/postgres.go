@@ -9,14 +9,16 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"os"
+	"path"
 	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/docker/docker/client"
 
-	_ "github.com/lib/pq" // Register Postgres database driver.
-
+	"github.com/lib/pq"
 	"github.com/pkg/errors"
 
 	"gitlab.com/postgres-ai/database-lab/v3/internal/diagnostic"
@@ -39,13 +41,37 @@ const (
 
 	// logsMinuteWindow defines number of minutes to get logs from container.
 	logsMinuteWindow = 1
+
+	// sslCertFile defines the name of the clone server certificate file in the data directory.
+	sslCertFile = "dblab_server.crt"
+
+	// sslKeyFile defines the name of the clone server private key file in the data directory.
+	sslKeyFile = "dblab_server.key"
+
+	sslCertFileMode = 0644
+	sslKeyFileMode  = 0600
 )
 
 // Start starts Postgres instance.
 func Start(r runners.Runner, c *resources.AppConfig) error {
 	log.Dbg("Starting Postgres container...")
 
-	if extraConf := c.ExtraConf(); len(extraConf) > 0 {
+	if err := ConfigureHBA(c); err != nil {
+		return errors.Wrap(err, "failed to configure client authentication")
+	}
+
+	extraConf := c.ExtraConf()
+
+	if c.CertIssuer != nil {
+		sslConf, err := configureSSL(c)
+		if err != nil {
+			return errors.Wrap(err, "failed to configure SSL")
+		}
+
+		extraConf = mergeConfigs(extraConf, sslConf)
+	}
+
+	if len(extraConf) > 0 {
 		configManager, err := pgconfig.NewCorrector(c.DataDir())
 		if err != nil {
 			return errors.Wrap(err, "failed to create a config manager")
@@ -128,6 +154,79 @@ func Start(r runners.Runner, c *resources.AppConfig) error {
 	return nil
 }
 
+// ConfigureHBA restricts host connections to the allowed client CIDRs and requires SSL if clones get certificates.
+// Rules changed before are restored if the restrictions are lifted. Changes are applied on the next start or configuration reload.
+func ConfigureHBA(c *resources.AppConfig) error {
+	configManager, err := pgconfig.NewCorrector(c.DataDir())
+	if err != nil {
+		return errors.Wrap(err, "failed to create a config manager")
+	}
+
+	return configManager.AdjustHostRules(c.AllowedCIDRs, c.CertIssuer != nil)
+}
+
+// configureSSL writes a new server certificate of the clone and returns the Postgres parameters enabling SSL.
+func configureSSL(c *resources.AppConfig) (map[string]string, error) {
+	certPEM, keyPEM, err := c.CertIssuer.IssueCloneCert(c.CloneName)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to issue a server certificate")
+	}
+
+	dataDir := c.DataDir()
+
+	if err := writeOwnedFile(path.Join(dataDir, sslCertFile), certPEM, sslCertFileMode, dataDir); err != nil {
+		return nil, errors.Wrap(err, "failed to write the server certificate")
+	}
+
+	if err := writeOwnedFile(path.Join(dataDir, sslKeyFile), keyPEM, sslKeyFileMode, dataDir); err != nil {
+		return nil, errors.Wrap(err, "failed to write the server private key")
+	}
+
+	return map[string]string{
+		"ssl":           "on",
+		"ssl_cert_file": sslCertFile,
+		"ssl_key_file":  sslKeyFile,
+	}, nil
+}
+
+// writeOwnedFile writes the file giving it to the owner of the data directory because Postgres refuses to use
+// a private key owned by another user.
+func writeOwnedFile(filename string, data []byte, perm os.FileMode, dataDir string) error {
+	dataDirInfo, err := os.Stat(dataDir)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(filename, data, perm); err != nil {
+		return err
+	}
+
+	if err := os.Chmod(filename, perm); err != nil {
+		return err
+	}
+
+	if stat, ok := dataDirInfo.Sys().(*syscall.Stat_t); ok {
+		return os.Chown(filename, int(stat.Uid), int(stat.Gid))
+	}
+
+	return nil
+}
+
+// mergeConfigs returns a new configuration containing parameters of both configurations.
+func mergeConfigs(base, overrides map[string]string) map[string]string {
+	merged := make(map[string]string, len(base)+len(overrides))
+
+	for key, value := range base {
+		merged[key] = value
+	}
+
+	for key, value := range overrides {
+		merged[key] = value
+	}
+
+	return merged
+}
+
 func collectDiagnostics(c *resources.AppConfig) {
 	dockerClient, err := client.NewClientWithOpts(client.FromEnv)
 	if err != nil {
@@ -171,6 +270,132 @@ func pgctlPromote(r runners.Runner, c *resources.AppConfig) (string, error) {
 	return docker.Exec(r, c, promoteCmd)
 }
 
+// Checkpoint forces a checkpoint, so the data directory is consistent on disk.
+func Checkpoint(c *resources.AppConfig) error {
+	if _, err := runSimpleSQL("checkpoint", getPgConnStr(c.Host, c.DB.DBName, c.DB.Username, c.Port)); err != nil {
+		return errors.Wrap(err, "failed to run checkpoint")
+	}
+
+	return nil
+}
+
+// ReloadConfig makes Postgres reload configuration files.
+func ReloadConfig(c *resources.AppConfig) error {
+	if _, err := runSimpleSQL("select pg_reload_conf()", getPgConnStr(c.Host, c.DB.DBName, c.DB.Username, c.Port)); err != nil {
+		return errors.Wrap(err, "failed to reload configuration")
+	}
+
+	return nil
+}
+
+// selectInstalledExtensions provides a query to list extensions installed in a database.
+const selectInstalledExtensions = "select extname from pg_catalog.pg_extension"
+
+// InstalledExtensions lists extensions installed in all databases of the clone.
+func InstalledExtensions(c *resources.AppConfig) ([]string, error) {
+	databaseList, err := runSQLSelectQuery(selectAllDatabases, getPgConnStr(c.Host, c.DB.DBName, c.DB.Username, c.Port))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list databases")
+	}
+
+	extensions := []string{}
+	seen := make(map[string]struct{})
+
+	for _, database := range databaseList {
+		databaseExtensions, err := runSQLSelectQuery(selectInstalledExtensions, getPgConnStr(c.Host, database, c.DB.Username, c.Port))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to list extensions of database %s", database)
+		}
+
+		for _, extension := range databaseExtensions {
+			if _, ok := seen[extension]; ok {
+				continue
+			}
+
+			seen[extension] = struct{}{}
+			extensions = append(extensions, extension)
+		}
+	}
+
+	return extensions, nil
+}
+
+// selectInitParams provides a query to get the cluster parameters defined by initdb.
+const selectInitParams = `select concat_ws('|', pg_encoding_to_char(encoding), datcollate, datctype, current_setting('data_checksums'))
+from pg_catalog.pg_database where datname = 'template0'`
+
+// InitParams describes the cluster parameters defined by initdb.
+type InitParams struct {
+	Encoding      string
+	LCCollate     string
+	LCCType       string
+	DataChecksums bool
+}
+
+// GetInitParams gets the encoding, locale and checksum settings of the clone cluster.
+func GetInitParams(c *resources.AppConfig) (*InitParams, error) {
+	out, err := runSimpleSQL(selectInitParams, getPgConnStr(c.Host, c.DB.DBName, c.DB.Username, c.Port))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cluster parameters")
+	}
+
+	const initParamsNumber = 4
+
+	params := strings.Split(out, "|")
+	if len(params) != initParamsNumber {
+		return nil, errors.Errorf("unexpected cluster parameters: %q", out)
+	}
+
+	return &InitParams{
+		Encoding:      params[0],
+		LCCollate:     params[1],
+		LCCType:       params[2],
+		DataChecksums: params[3] == "on",
+	}, nil
+}
+
+// Analyze collects statistics in all databases of the clone.
+func Analyze(c *resources.AppConfig) error {
+	databaseList, err := runSQLSelectQuery(selectAllDatabases, getPgConnStr(c.Host, c.DB.DBName, c.DB.Username, c.Port))
+	if err != nil {
+		return errors.Wrap(err, "failed to list databases")
+	}
+
+	for _, database := range databaseList {
+		if _, err := runSimpleSQL("analyze", getPgConnStr(c.Host, database, c.DB.Username, c.Port)); err != nil {
+			return errors.Wrapf(err, "failed to analyze database %s", database)
+		}
+	}
+
+	return nil
+}
+
+// ExecSQL executes SQL statements in the database and returns notices raised by them.
+func ExecSQL(c *resources.AppConfig, dbName, query string) (string, error) {
+	connector, err := pq.NewConnector(getPgConnStr(c.Host, dbName, c.DB.Username, c.Port))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create a connector")
+	}
+
+	var notices strings.Builder
+
+	db := sql.OpenDB(pq.ConnectorWithNoticeHandler(connector, func(notice *pq.Error) {
+		notices.WriteString(notice.Severity + ": " + notice.Message + "\n")
+	}))
+
+	defer func() {
+		if err := db.Close(); err != nil {
+			log.Err("Cannot close database connection.")
+		}
+	}()
+
+	if _, err := db.Exec(query); err != nil {
+		return notices.String(), err
+	}
+
+	return notices.String(), nil
+}
+
 // Generate postgres connection string.
 func getPgConnStr(host, dbname, username string, port uint) string {
 	var sb strings.Builder
@@ -13,6 +13,7 @@ import (
 
 	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/resources"
 	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
 )
 
 // ResetPasswordsQuery provides a template for a reset password query.
@@ -82,7 +83,8 @@ func CreateUser(c *resources.AppConfig, user resources.EphemeralUser) error {
 		dbName = user.AvailableDB
 	}
 
-	if user.Restricted {
+	switch UserRole(user) {
+	case models.UserRoleRestricted:
 		// create restricted user
 		query = restrictedUserQuery(user.Name, user.Password)
 		out, err := runSimpleSQL(query, getPgConnStr(c.Host, dbName, c.DB.Username, c.Port))
@@ -110,7 +112,40 @@ func CreateUser(c *resources.AppConfig, user resources.EphemeralUser) error {
 
 			log.Dbg("Objects restriction applied", database, out)
 		}
-	} else {
+
+	case models.UserRoleReadOnly:
+		if _, err := runSimpleSQL(loginUserQuery(user.Name, user.Password), getPgConnStr(c.Host, dbName, c.DB.Username, c.Port)); err != nil {
+			return fmt.Errorf("failed to create read-only user: %w", err)
+		}
+
+		// grant read access in all databases
+		databaseList, err := runSQLSelectQuery(selectAllDatabases, getPgConnStr(c.Host, dbName, c.DB.Username, c.Port))
+		if err != nil {
+			return fmt.Errorf("failed list all databases: %w", err)
+		}
+
+		for _, database := range databaseList {
+			if _, err := runSimpleSQL(readOnlyObjectsQuery(user.Name), getPgConnStr(c.Host, database, c.DB.Username, c.Port)); err != nil {
+				return fmt.Errorf("failed to grant read access in database %s: %w", database, err)
+			}
+		}
+
+		log.Dbg("Read-only user has been created: ", user.Name)
+
+	case models.UserRoleCustom:
+		if _, err := runSimpleSQL(loginUserQuery(user.Name, user.Password), getPgConnStr(c.Host, dbName, c.DB.Username, c.Port)); err != nil {
+			return fmt.Errorf("failed to create user: %w", err)
+		}
+
+		for _, grant := range user.Grants {
+			if _, err := runSimpleSQL(grant, getPgConnStr(c.Host, dbName, c.DB.Username, c.Port)); err != nil {
+				return fmt.Errorf("failed to run grant %q: %w", grant, err)
+			}
+		}
+
+		log.Dbg("User with custom grants has been created: ", user.Name)
+
+	default:
 		query = superuserQuery(user.Name, user.Password)
 
 		out, err := runSimpleSQL(query, getPgConnStr(c.Host, dbName, c.DB.Username, c.Port))
@@ -124,10 +159,67 @@ func CreateUser(c *resources.AppConfig, user resources.EphemeralUser) error {
 	return nil
 }
 
+// UserRole returns the role preset of the user.
+func UserRole(user resources.EphemeralUser) models.UserRole {
+	if user.Role != "" {
+		return models.UserRole(user.Role)
+	}
+
+	if user.Restricted {
+		return models.UserRoleRestricted
+	}
+
+	return models.UserRoleSuperuser
+}
+
+// UserExists checks if the Postgres user exists.
+func UserExists(c *resources.AppConfig, username string) (bool, error) {
+	query := fmt.Sprintf("select rolname from pg_catalog.pg_roles where rolname = %s", pq.QuoteLiteral(username))
+
+	roles, err := runSQLSelectQuery(query, getPgConnStr(c.Host, c.DB.DBName, c.DB.Username, c.Port))
+	if err != nil {
+		return false, fmt.Errorf("failed to check user: %w", err)
+	}
+
+	return len(roles) > 0, nil
+}
+
+// DropUser drops the Postgres user. Objects owned by the user are reassigned to the administrative user.
+func DropUser(c *resources.AppConfig, username string) error {
+	databaseList, err := runSQLSelectQuery(selectAllDatabases, getPgConnStr(c.Host, c.DB.DBName, c.DB.Username, c.Port))
+	if err != nil {
+		return fmt.Errorf("failed list all databases: %w", err)
+	}
+
+	for _, database := range databaseList {
+		if _, err := runSimpleSQL(dropOwnedQuery(username, c.DB.Username), getPgConnStr(c.Host, database, c.DB.Username, c.Port)); err != nil {
+			return fmt.Errorf("failed to drop objects of user in database %s: %w", database, err)
+		}
+	}
+
+	query := fmt.Sprintf("drop role %s;", pq.QuoteIdentifier(username))
+
+	if _, err := runSimpleSQL(query, getPgConnStr(c.Host, c.DB.DBName, c.DB.Username, c.Port)); err != nil {
+		return fmt.Errorf("failed to drop user: %w", err)
+	}
+
+	log.Dbg("User has been dropped: ", username)
+
+	return nil
+}
+
 func superuserQuery(username, password string) string {
 	return fmt.Sprintf(`create user %s with password %s login superuser;`, pq.QuoteIdentifier(username), pq.QuoteLiteral(password))
 }
 
+func loginUserQuery(username, password string) string {
+	return fmt.Sprintf(`create user %s with password %s login;`, pq.QuoteIdentifier(username), pq.QuoteLiteral(password))
+}
+
+func dropOwnedQuery(username, newOwner string) string {
+	return fmt.Sprintf(`reassign owned by %[1]s to %[2]s; drop owned by %[1]s;`, pq.QuoteIdentifier(username), pq.QuoteIdentifier(newOwner))
+}
+
 const restrictionUserCreationTemplate = `
 -- create a new user 
 create user @username with password @password login;
@@ -325,3 +417,61 @@ func restrictedObjectsQuery(username string) string {
 
 	return repl.Replace(restrictionTemplate)
 }
+
+// readOnlyTemplate grants read access to existing and future tables and sequences of all schemas in the current database.
+// Default privileges are granted for schema owners and owners of objects in the schemas, so tables created later by these roles
+// are readable too. The role pg_database_owner owning the public schema since Postgres 15 never owns objects,
+// the database owner is used instead. Objects created later by other roles are not readable.
+const readOnlyTemplate = `
+do $$
+declare
+  r record;
+  o record;
+begin
+  execute format('grant connect on database %I to %I;', current_database(), @usernameStr);
+
+  for r in
+    select n.oid, n.nspname, n.nspowner, pg_catalog.pg_get_userbyid(n.nspowner) as owner
+    from pg_catalog.pg_namespace n
+    where n.nspname not like 'pg\_%' and n.nspname <> 'information_schema'
+  loop
+    raise debug 'Granting read access to schema % to %', r.nspname, @usernameStr;
+
+    execute format('grant usage on schema %I to %I;', r.nspname, @usernameStr);
+    execute format('grant select on all tables in schema %I to %I;', r.nspname, @usernameStr);
+    execute format('grant select on all sequences in schema %I to %I;', r.nspname, @usernameStr);
+
+    for o in
+      select distinct pg_catalog.pg_get_userbyid(owners.roleid) as rolname
+      from (
+        select r.nspowner as roleid
+        union
+        select d.datdba from pg_catalog.pg_database d
+        where d.datname = current_database() and r.owner = 'pg_database_owner'
+        union
+        select c.relowner from pg_catalog.pg_class c
+        where c.relnamespace = r.oid and c.relkind in ('r', 'p', 'v', 'm', 'f', 'S')
+      ) owners
+      where pg_catalog.pg_get_userbyid(owners.roleid) <> 'pg_database_owner'
+    loop
+      execute format(
+        'alter default privileges for role %I in schema %I grant select on tables to %I;',
+        o.rolname,
+        r.nspname,
+        @usernameStr
+      );
+      execute format(
+        'alter default privileges for role %I in schema %I grant select on sequences to %I;',
+        o.rolname,
+        r.nspname,
+        @usernameStr
+      );
+    end loop;
+  end loop;
+end
+$$;
+`
+
+func readOnlyObjectsQuery(username string) string {
+	return strings.ReplaceAll(readOnlyTemplate, "@usernameStr", pq.QuoteLiteral(username))
+}
@@ -8,6 +8,9 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/resources"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
 )
 
 func TestSuperuserQuery(t *testing.T) {
@@ -53,3 +56,26 @@ func TestRestrictedUserQuery(t *testing.T) {
 	})
 
 }
+
+func TestReadOnlyObjectsQuery(t *testing.T) {
+	query := readOnlyObjectsQuery("analyst\"")
+
+	assert.Contains(t, query, `execute format('grant select on all tables in schema %I to %I;', r.nspname, 'analyst"');`)
+	assert.Contains(t, query, `'alter default privileges for role %I in schema %I grant select on tables to %I;'`)
+	assert.NotContains(t, query, "@usernameStr")
+
+	// Default privileges are granted for owners of objects and for the database owner instead of pg_database_owner.
+	assert.Contains(t, query, `select c.relowner from pg_catalog.pg_class c`)
+	assert.Contains(t, query, `where d.datname = current_database() and r.owner = 'pg_database_owner'`)
+	assert.Contains(t, query, `where pg_catalog.pg_get_userbyid(owners.roleid) <> 'pg_database_owner'`)
+}
+
+func TestDropOwnedQuery(t *testing.T) {
+	assert.Equal(t, `reassign owned by "analyst" to "postgres"; drop owned by "analyst";`, dropOwnedQuery("analyst", "postgres"))
+}
+
+func TestUserRole(t *testing.T) {
+	assert.Equal(t, models.UserRoleSuperuser, UserRole(resources.EphemeralUser{Name: "john"}))
+	assert.Equal(t, models.UserRoleRestricted, UserRole(resources.EphemeralUser{Name: "john", Restricted: true}))
+	assert.Equal(t, models.UserRoleReadOnly, UserRole(resources.EphemeralUser{Name: "analyst", Role: "readonly"}))
+}
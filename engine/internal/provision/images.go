@@ -0,0 +1,132 @@
+package provision
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/databases/postgres"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/resources"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/tools"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/tools/db"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/config/global"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+// imageCheckContainerPrefix defines the name prefix of containers collecting the content of clone images.
+const imageCheckContainerPrefix = "dblab_image_check_"
+
+// CheckDockerImage checks if the Docker image is allowed for clones.
+// An empty image and the default image are always allowed.
+func (p *Provisioner) CheckDockerImage(dockerImage string) error {
+	if isAllowedImage(dockerImage, p.config.DockerImage, p.config.AllowedDockerImages) {
+		return nil
+	}
+
+	return models.New(models.ErrCodeBadRequest,
+		fmt.Sprintf("Docker image %q is not allowed for clones, check the provision.allowedDockerImages option", dockerImage))
+}
+
+func isAllowedImage(dockerImage, defaultImage string, allowedImages []string) bool {
+	if dockerImage == "" || dockerImage == defaultImage {
+		return true
+	}
+
+	for _, allowedImage := range allowedImages {
+		if dockerImage == allowedImage {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkImageVersion checks if the Docker image provides the major Postgres version of the clone data
+// created from the snapshot, which may differ from the version of the current pool data.
+func (p *Provisioner) checkImageVersion(dataDir, dockerImage string) error {
+	pgVersion, err := tools.DetectPGVersion(dataDir)
+	if err != nil {
+		return errors.Wrap(err, "failed to detect Postgres version of the snapshot")
+	}
+
+	content, err := p.imageContent(dockerImage)
+	if err != nil {
+		return err
+	}
+
+	if content.PGVersion() != pgVersion {
+		return models.New(models.ErrCodeBadRequest, fmt.Sprintf("Docker image %q provides Postgres %g, but the snapshot requires Postgres %g",
+			dockerImage, content.PGVersion(), pgVersion))
+	}
+
+	return nil
+}
+
+// checkImageExtensions checks if all extensions installed in the clone databases are available in the Docker image.
+func (p *Provisioner) checkImageExtensions(appConfig *resources.AppConfig, dockerImage string) error {
+	installed, err := postgres.InstalledExtensions(appConfig)
+	if err != nil {
+		return errors.Wrap(err, "failed to list installed extensions")
+	}
+
+	content, err := p.imageContent(dockerImage)
+	if err != nil {
+		return err
+	}
+
+	if missing := missingExtensions(installed, content.Extensions()); len(missing) > 0 {
+		return models.New(models.ErrCodeBadRequest, fmt.Sprintf("Docker image %q does not provide extensions installed in the snapshot: %s",
+			dockerImage, strings.Join(missing, ", ")))
+	}
+
+	return nil
+}
+
+// missingExtensions returns sorted installed extensions missing in the available ones.
+func missingExtensions(installed []string, available map[string]string) []string {
+	missing := []string{}
+
+	for _, extension := range installed {
+		if _, ok := available[extension]; !ok {
+			missing = append(missing, extension)
+		}
+	}
+
+	sort.Strings(missing)
+
+	return missing
+}
+
+// imageContent collects the content of the Docker image once and keeps it for next clones.
+func (p *Provisioner) imageContent(dockerImage string) (*db.ImageContent, error) {
+	p.imageMu.Lock()
+	defer p.imageMu.Unlock()
+
+	if content, ok := p.imageContents[dockerImage]; ok {
+		return content, nil
+	}
+
+	if p.imageContents == nil {
+		p.imageContents = make(map[string]*db.ImageContent)
+	}
+
+	content := db.NewImageContent(global.EngineProps{InstanceID: p.instanceID})
+	content.SetContainerName(imageCheckContainerPrefix + p.instanceID)
+
+	if err := content.Collect(dockerImage); err != nil {
+		return nil, errors.Wrapf(err, "failed to collect the content of Docker image %s", dockerImage)
+	}
+
+	p.imageContents[dockerImage] = content
+
+	return content, nil
+}
+
+// setDockerImage makes the clone container use the Docker image if it is specified.
+func setDockerImage(appConfig *resources.AppConfig, dockerImage string) {
+	if dockerImage != "" {
+		appConfig.DockerImage = dockerImage
+	}
+}
@@ -0,0 +1,35 @@
+package provision
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsAllowedImage(t *testing.T) {
+	const defaultImage = "postgresai/extended-postgres:16"
+
+	allowedImages := []string{"postgresai/extended-postgres:16-0.5.1"}
+
+	testCases := []struct {
+		image   string
+		allowed bool
+	}{
+		{image: "", allowed: true},
+		{image: defaultImage, allowed: true},
+		{image: "postgresai/extended-postgres:16-0.5.1", allowed: true},
+		{image: "postgresai/extended-postgres:17", allowed: false},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.allowed, isAllowedImage(tc.image, defaultImage, allowedImages), tc.image)
+	}
+}
+
+func TestMissingExtensions(t *testing.T) {
+	available := map[string]string{"plpgsql": "1.0", "pg_stat_statements": "1.10"}
+
+	assert.Empty(t, missingExtensions([]string{"plpgsql", "pg_stat_statements"}, available))
+	assert.Equal(t, []string{"postgis", "timescaledb"},
+		missingExtensions([]string{"timescaledb", "plpgsql", "postgis"}, available))
+}
@@ -0,0 +1,249 @@
+package provision
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/pkg/errors"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/databases/postgres"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/docker"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/resources"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/tools"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/tools/cont"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+const (
+	// initContainerPrefix defines the name prefix of containers running commands of init profiles.
+	initContainerPrefix = "dblab_init_"
+
+	// defaultInitCommandTimeout limits the duration of each init command if the profile does not define a timeout.
+	defaultInitCommandTimeout = 10 * time.Minute
+
+	// maxInitOutputSize limits the recorded output of each init step.
+	maxInitOutputSize = 64 * 1024
+)
+
+// InitProfile defines the setup run in a clone after it starts and after each reset,
+// for example, disabling scheduled jobs or replacing webhook URLs. Steps run in the order of the fields.
+type InitProfile struct {
+	// DBName defines the database of the steps, the database of the clone user is used by default.
+	DBName string `yaml:"dbName"`
+	// SQLFiles lists files with SQL statements executed by the administrative user.
+	SQLFiles []string `yaml:"sqlFiles"`
+	// SQL defines inline SQL statements executed by the administrative user.
+	SQL string `yaml:"sql"`
+	// Commands defines shell commands run in a container connected to the clone with PGHOST, PGPORT, PGUSER and PGDATABASE.
+	Commands []string `yaml:"commands"`
+	// Image defines the Docker image of the commands container, the image of the clone is used by default.
+	Image string `yaml:"image"`
+	// Timeout limits the duration of each command in seconds.
+	Timeout uint `yaml:"timeout"`
+}
+
+// CheckInitProfile checks if the init profile is defined in the configuration. An empty name means no profile.
+func (p *Provisioner) CheckInitProfile(name string) error {
+	if name == "" {
+		return nil
+	}
+
+	if _, ok := p.config.InitProfiles[name]; !ok {
+		return models.New(models.ErrCodeBadRequest, fmt.Sprintf("init profile %q is not defined", name))
+	}
+
+	return nil
+}
+
+// RunInitProfile runs the init profile of the session in the running clone and returns the run,
+// nil if the session has no init profile. The caller records the run in the session.
+func (p *Provisioner) RunInitProfile(session *resources.Session) (*resources.InitRun, error) {
+	if session.InitProfile == "" {
+		return nil, nil
+	}
+
+	appConfig, err := p.sessionAppConfig(session)
+	if err != nil {
+		return nil, err
+	}
+
+	setDockerImage(appConfig, session.DockerImage)
+
+	return p.runInitProfile(appConfig, session), nil
+}
+
+// runInitProfile runs the init profile of the session in the clone and returns the run, nil if the session has no init profile.
+// The run stops on the first failed step. Failures do not stop the clone, they are reported by the clone status.
+func (p *Provisioner) runInitProfile(appConfig *resources.AppConfig, session *resources.Session) *resources.InitRun {
+	if session.InitProfile == "" {
+		return nil
+	}
+
+	run := &resources.InitRun{Profile: session.InitProfile, StartedAt: time.Now()}
+
+	defer func() {
+		run.FinishedAt = time.Now()
+
+		if run.Error != "" {
+			log.Warn(fmt.Sprintf("Init profile %s failed in clone %s: %s", run.Profile, appConfig.CloneName, run.Error))
+		}
+	}()
+
+	profile, ok := p.config.InitProfiles[session.InitProfile]
+	if !ok {
+		run.Error = fmt.Sprintf("init profile %q is not defined", session.InitProfile)
+		return run
+	}
+
+	dbName := initDBName(profile, appConfig, session.EphemeralUser)
+
+	for _, filename := range profile.SQLFiles {
+		query, err := os.ReadFile(filename)
+
+		var output string
+
+		if err == nil {
+			output, err = postgres.ExecSQL(appConfig, dbName, string(query))
+		}
+
+		if !recordInitStep(run, "sql file "+filename, output, err) {
+			return run
+		}
+	}
+
+	if profile.SQL != "" {
+		output, err := postgres.ExecSQL(appConfig, dbName, profile.SQL)
+		if !recordInitStep(run, "sql", output, err) {
+			return run
+		}
+	}
+
+	if len(profile.Commands) > 0 {
+		p.runInitCommands(appConfig, profile, dbName, run)
+	}
+
+	return run
+}
+
+// runInitCommands runs commands of the init profile in a temporary container sharing the socket directory of the clone.
+func (p *Provisioner) runInitCommands(appConfig *resources.AppConfig, profile InitProfile, dbName string, run *resources.InitRun) {
+	containerID, err := p.startInitContainer(appConfig, profile, dbName)
+	if err != nil {
+		recordInitStep(run, "start commands container", "", err)
+		return
+	}
+
+	defer tools.RemoveContainer(p.ctx, p.dockerClient, containerID, 0)
+
+	timeout := defaultInitCommandTimeout
+	if profile.Timeout > 0 {
+		timeout = time.Duration(profile.Timeout) * time.Second
+	}
+
+	for _, command := range profile.Commands {
+		output, err := p.execInitCommand(containerID, command, timeout)
+		if !recordInitStep(run, "command "+command, output, err) {
+			return
+		}
+	}
+}
+
+func (p *Provisioner) startInitContainer(appConfig *resources.AppConfig, profile InitProfile, dbName string) (string, error) {
+	image := profile.Image
+	if image == "" {
+		image = appConfig.DockerImage
+	}
+
+	if err := docker.PrepareImage(p.ctx, p.dockerClient, image); err != nil {
+		return "", errors.Wrap(err, "failed to prepare the image of init commands")
+	}
+
+	hostConfig := &container.HostConfig{}
+
+	if err := tools.AddVolumesToHostConfig(p.ctx, p.dockerClient, hostConfig, appConfig.Host); err != nil {
+		return "", errors.Wrap(err, "failed to set up volumes")
+	}
+
+	containerName := initContainerPrefix + appConfig.CloneName
+
+	containerID, err := tools.CreateContainerIfMissing(p.ctx, p.dockerClient, containerName, &container.Config{
+		Labels: map[string]string{
+			cont.DBLabControlLabel:    cont.DBLabInitLabel,
+			cont.DBLabInstanceIDLabel: p.instanceID,
+		},
+		Entrypoint: []string{"sleep", "infinity"},
+		Image:      image,
+		Env: []string{
+			"PGHOST=" + appConfig.Host,
+			"PGPORT=" + strconv.FormatUint(uint64(appConfig.Port), 10),
+			"PGUSER=" + appConfig.DB.Username,
+			"PGDATABASE=" + dbName,
+		},
+	}, hostConfig)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to create container %s", containerName)
+	}
+
+	if err := p.dockerClient.ContainerStart(p.ctx, containerID, container.StartOptions{}); err != nil {
+		return "", errors.Wrapf(err, "failed to start container %s", containerName)
+	}
+
+	return containerID, nil
+}
+
+func (p *Provisioner) execInitCommand(containerID, command string, timeout time.Duration) (string, error) {
+	ctx, cancel := context.WithTimeout(p.ctx, timeout)
+	defer cancel()
+
+	output := bytes.NewBuffer(nil)
+
+	exitCode, err := tools.ExecCommandWithStreams(ctx, p.dockerClient, containerID,
+		types.ExecConfig{Cmd: []string{"sh", "-c", command}}, output, output)
+	if err != nil {
+		return output.String(), err
+	}
+
+	if exitCode != 0 {
+		return output.String(), errors.Errorf("exit code %d", exitCode)
+	}
+
+	return output.String(), nil
+}
+
+// initDBName returns the database of init steps.
+func initDBName(profile InitProfile, appConfig *resources.AppConfig, user resources.EphemeralUser) string {
+	if profile.DBName != "" {
+		return profile.DBName
+	}
+
+	if user.AvailableDB != "" {
+		return user.AvailableDB
+	}
+
+	return appConfig.DB.DBName
+}
+
+// recordInitStep adds the step to the run and reports if the run can be continued.
+func recordInitStep(run *resources.InitRun, name, output string, err error) bool {
+	if len(output) > maxInitOutputSize {
+		output = output[len(output)-maxInitOutputSize:]
+	}
+
+	step := resources.InitStep{Name: name, Output: output}
+
+	if err != nil {
+		step.Error = err.Error()
+		run.Error = fmt.Sprintf("step %q failed: %v", name, err)
+	}
+
+	run.Steps = append(run.Steps, step)
+
+	return err == nil
+}
@@ -0,0 +1,65 @@
+package provision
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/resources"
+)
+
+func TestCheckInitProfile(t *testing.T) {
+	p := &Provisioner{config: &Config{InitProfiles: map[string]InitProfile{"billing": {SQL: "select 1"}}}}
+
+	assert.NoError(t, p.CheckInitProfile(""))
+	assert.NoError(t, p.CheckInitProfile("billing"))
+	assert.EqualError(t, p.CheckInitProfile("absent"), `init profile "absent" is not defined`)
+}
+
+func TestRunUndefinedInitProfile(t *testing.T) {
+	p := &Provisioner{config: &Config{}}
+	session := &resources.Session{InitProfile: "removed"}
+
+	run := p.runInitProfile(&resources.AppConfig{CloneName: "dblab_clone_6000"}, session)
+
+	require.NotNil(t, run)
+	assert.Equal(t, `init profile "removed" is not defined`, run.Error)
+	assert.False(t, run.FinishedAt.IsZero())
+
+	// The run is returned to be recorded by the caller holding the lock of the session.
+	assert.Nil(t, session.InitRun)
+
+	session.InitProfile = ""
+	assert.Nil(t, p.runInitProfile(&resources.AppConfig{CloneName: "dblab_clone_6000"}, session))
+
+	run, err := p.RunInitProfile(session)
+	require.NoError(t, err)
+	assert.Nil(t, run)
+}
+
+func TestRecordInitStep(t *testing.T) {
+	run := &resources.InitRun{Profile: "billing"}
+
+	assert.True(t, recordInitStep(run, "sql", "NOTICE: jobs disabled\n", nil))
+	assert.Empty(t, run.Error)
+
+	output := strings.Repeat("a", maxInitOutputSize) + "tail"
+
+	assert.False(t, recordInitStep(run, "command ./seed.sh", output, errors.New("exit code 1")))
+	assert.Equal(t, `step "command ./seed.sh" failed: exit code 1`, run.Error)
+
+	require.Len(t, run.Steps, 2)
+	assert.Len(t, run.Steps[1].Output, maxInitOutputSize)
+	assert.True(t, strings.HasSuffix(run.Steps[1].Output, "tail"))
+}
+
+func TestInitDBName(t *testing.T) {
+	appConfig := &resources.AppConfig{DB: &resources.DB{DBName: "postgres"}}
+
+	assert.Equal(t, "postgres", initDBName(InitProfile{}, appConfig, resources.EphemeralUser{}))
+	assert.Equal(t, "app", initDBName(InitProfile{}, appConfig, resources.EphemeralUser{AvailableDB: "app"}))
+	assert.Equal(t, "billing", initDBName(InitProfile{DBName: "billing"}, appConfig, resources.EphemeralUser{AvailableDB: "app"}))
+}
@@ -31,6 +31,7 @@ import (
 	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/resources"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/runners"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/tools"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/tools/db"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/tools/fs"
 	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
 	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
@@ -60,6 +61,11 @@ type Config struct {
 	KeepUserPasswords    bool              `yaml:"keepUserPasswords"`
 	ContainerConfig      map[string]string `yaml:"containerConfig"`
 	CloneAccessAddresses string            `yaml:"cloneAccessAddresses"`
+	WarmPool             WarmPoolConfig    `yaml:"warmPool"`
+	AllowedDockerImages  []string          `yaml:"allowedDockerImages"`
+	Upgrade              UpgradeConfig     `yaml:"upgrade"`
+	// InitProfiles defines named setups run in clones after they start.
+	InitProfiles map[string]InitProfile `yaml:"initProfiles"`
 }
 
 // Provisioner describes a struct for ports and clones management.
@@ -77,6 +83,11 @@ type Provisioner struct {
 	networkID      string
 	instanceID     string
 	gateway        string
+	warmPool       *warmPool
+	imageMu        sync.Mutex
+	imageContents  map[string]*db.ImageContent
+	certMu         sync.RWMutex
+	certIssuer     resources.CertIssuer
 }
 
 // New creates a new Provisioner instance.
@@ -99,6 +110,7 @@ func New(ctx context.Context, cfg *Config, dbCfg *resources.DB, docker *client.C
 		instanceID:   instanceID,
 		gateway:      gateway,
 		ports:        make([]bool, cfg.PortPool.To-cfg.PortPool.From+1),
+		warmPool:     newWarmPool(),
 	}
 
 	return p, nil
@@ -140,6 +152,15 @@ func (p *Provisioner) Init() error {
 func (p *Provisioner) Reload(cfg Config, dbCfg resources.DB) {
 	*p.config = cfg
 	*p.dbCfg = dbCfg
+
+	p.warmPool.requestRefill()
+}
+
+// SetCertIssuer sets the issuer of TLS certificates for clones started next. A nil issuer disables TLS.
+func (p *Provisioner) SetCertIssuer(issuer resources.CertIssuer) {
+	p.certMu.Lock()
+	p.certIssuer = issuer
+	p.certMu.Unlock()
 }
 
 // ContainerOptions returns provisioner configuration for running containers.
@@ -151,14 +172,42 @@ func (p *Provisioner) ContainerOptions() models.ContainerOptions {
 }
 
 // StartSession starts a new session.
-func (p *Provisioner) StartSession(snapshotID string, user resources.EphemeralUser,
-	extraConfig map[string]string) (*resources.Session, error) {
+// An empty dockerImage means the default Docker image of clones, empty allowedCIDRs allow all clients.
+// The init profile runs after the database is prepared, its result is recorded in the session.
+func (p *Provisioner) StartSession(snapshotID string, user resources.EphemeralUser, extraConfig map[string]string,
+	dockerImage string, allowedCIDRs []string, initProfile string) (*resources.Session, error) {
 	snapshot, err := p.getSnapshot(snapshotID)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get snapshots")
 	}
 
+	if dockerImage == p.config.DockerImage {
+		dockerImage = ""
+	}
+
+	// Warm clones are started with the default configuration.
+	if len(extraConfig) == 0 && dockerImage == "" && len(allowedCIDRs) == 0 {
+		if session := p.takeWarmSession(snapshot.ID, user); session != nil {
+			session.InitProfile = initProfile
+
+			if initProfile != "" {
+				appConfig, err := p.sessionAppConfig(session)
+				if err != nil {
+					return nil, err
+				}
+
+				session.InitRun = p.runInitProfile(appConfig, session)
+			}
+
+			return session, nil
+		}
+	}
+
 	port, err := p.allocatePort()
+	if err != nil && p.evictWarmSession() {
+		port, err = p.allocatePort()
+	}
+
 	if err != nil {
 		return nil, errors.New("failed to get a free port")
 	}
@@ -182,38 +231,74 @@ func (p *Provisioner) StartSession(snapshotID string, user resources.EphemeralUs
 		}
 	}()
 
-	if err = fsm.CreateClone(name, snapshot.ID); err != nil {
+	appConfig, err := p.startClone(fsm, snapshot.ID, port, extraConfig, dockerImage, allowedCIDRs)
+	if err != nil {
+		return nil, err
+	}
+
+	if dockerImage != "" {
+		if err = p.checkImageExtensions(appConfig, dockerImage); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = p.prepareDB(appConfig, user); err != nil {
+		return nil, errors.Wrap(err, "failed to prepare a database")
+	}
+
+	session := p.newSession(fsm.Pool().Name, appConfig, user, extraConfig)
+	session.DockerImage = dockerImage
+	session.AllowedCIDRs = allowedCIDRs
+	session.InitProfile = initProfile
+	session.InitRun = p.runInitProfile(appConfig, session)
+
+	return session, nil
+}
+
+// startClone creates the clone of the snapshot and starts its container.
+func (p *Provisioner) startClone(fsm pool.FSManager, snapshotID string, port uint,
+	extraConfig map[string]string, dockerImage string, allowedCIDRs []string) (*resources.AppConfig, error) {
+	name := util.GetCloneName(port)
+
+	if err := fsm.CreateClone(name, snapshotID); err != nil {
 		return nil, errors.Wrap(err, "failed to create clone")
 	}
 
 	appConfig := p.getAppConfig(fsm.Pool(), name, port)
 	appConfig.SetExtraConf(extraConfig)
+	setDockerImage(appConfig, dockerImage)
+	p.setAllowedCIDRs(appConfig, allowedCIDRs)
+
+	if dockerImage != "" {
+		if err := p.checkImageVersion(appConfig.DataDir(), dockerImage); err != nil {
+			return nil, err
+		}
+	}
 
 	if err := fs.CleanupLogsDir(appConfig.DataDir()); err != nil {
 		log.Warn("Failed to clean up logs directory:", err.Error())
 	}
 
-	if err = postgres.Start(p.runner, appConfig); err != nil {
+	if err := postgres.Start(p.runner, appConfig); err != nil {
 		return nil, errors.Wrap(err, "failed to start a container")
 	}
 
-	if err = p.prepareDB(appConfig, user); err != nil {
-		return nil, errors.Wrap(err, "failed to prepare a database")
-	}
+	return appConfig, nil
+}
 
-	atomic.AddUint32(&p.sessionCounter, 1)
+func (p *Provisioner) newSession(poolName string, appConfig *resources.AppConfig, user resources.EphemeralUser,
+	extraConfig map[string]string) *resources.Session {
+	sessionID := atomic.AddUint32(&p.sessionCounter, 1)
 
-	session := &resources.Session{
-		ID:            strconv.FormatUint(uint64(p.sessionCounter), 10),
-		Pool:          fsm.Pool().Name,
-		Port:          port,
+	return &resources.Session{
+		ID:            strconv.FormatUint(uint64(sessionID), 10),
+		Pool:          poolName,
+		Port:          appConfig.Port,
 		User:          appConfig.DB.Username,
 		SocketHost:    appConfig.Host,
 		EphemeralUser: user,
 		ExtraConfig:   extraConfig,
 	}
-
-	return session, nil
 }
 
 // StopSession stops an existing session.
@@ -240,7 +325,52 @@ func (p *Provisioner) StopSession(session *resources.Session) error {
 	return nil
 }
 
-// ResetSession resets an existing session.
+// StopSessionContainer stops the container of the session keeping its clone data and port.
+func (p *Provisioner) StopSessionContainer(session *resources.Session) error {
+	fsm, err := p.pm.GetFSManager(session.Pool)
+	if err != nil {
+		return errors.Wrap(err, "failed to find a filesystem manager of this session")
+	}
+
+	appConfig := p.getAppConfig(fsm.Pool(), util.GetCloneName(session.Port), session.Port)
+
+	if err := p.shutdownContainer(appConfig); err != nil {
+		return errors.Wrap(err, "failed to stop a container")
+	}
+
+	return nil
+}
+
+// shutdownContainer shuts Postgres of the clone down cleanly and removes the clone container.
+// The container is removed anyway if Postgres cannot be stopped, for example, if it is not running.
+func (p *Provisioner) shutdownContainer(appConfig *resources.AppConfig) error {
+	if err := tools.StopPostgres(p.ctx, p.dockerClient, appConfig.CloneName, appConfig.DataDir(), tools.DefaultStopTimeout); err != nil {
+		log.Warn("Failed to shut down Postgres of the clone:", appConfig.CloneName, err)
+	}
+
+	return postgres.Stop(p.runner, appConfig.Pool, appConfig.CloneName)
+}
+
+// StartSessionContainer starts a new container of the session using its existing clone data.
+func (p *Provisioner) StartSessionContainer(session *resources.Session) error {
+	fsm, err := p.pm.GetFSManager(session.Pool)
+	if err != nil {
+		return errors.Wrap(err, "failed to find a filesystem manager of this session")
+	}
+
+	appConfig := p.getAppConfig(fsm.Pool(), util.GetCloneName(session.Port), session.Port)
+	appConfig.SetExtraConf(session.ExtraConfig)
+	setDockerImage(appConfig, session.DockerImage)
+	p.setAllowedCIDRs(appConfig, session.AllowedCIDRs)
+
+	if err := postgres.Start(p.runner, appConfig); err != nil {
+		return errors.Wrap(err, "failed to start a container")
+	}
+
+	return nil
+}
+
+// ResetSession resets an existing session. The init profile of the session is run separately, see RunInitProfile.
 func (p *Provisioner) ResetSession(session *resources.Session, snapshotID string) (*models.Snapshot, error) {
 	fsm, err := p.pm.GetFSManager(session.Pool)
 	if err != nil {
@@ -263,7 +393,15 @@ func (p *Provisioner) ResetSession(session *resources.Session, snapshotID string
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to find filesystem manager for a new session")
 		}
+	}
+
+	if session.UpgradedFrom != "" {
+		// Snapshots keep the Postgres version of the image used before the major-version upgrade of the clone.
+		session.DockerImage = p.sessionDockerImage(session.UpgradedFrom)
+		session.UpgradedFrom = ""
+	}
 
+	if snapshot.Pool != session.Pool {
 		session.Pool = snapshot.Pool
 		session.SocketHost = newFSManager.Pool().SocketCloneDir(name)
 	}
@@ -288,6 +426,14 @@ func (p *Provisioner) ResetSession(session *resources.Session, snapshotID string
 
 	appConfig := p.getAppConfig(newFSManager.Pool(), name, session.Port)
 	appConfig.SetExtraConf(session.ExtraConfig)
+	setDockerImage(appConfig, session.DockerImage)
+	p.setAllowedCIDRs(appConfig, session.AllowedCIDRs)
+
+	if session.DockerImage != "" {
+		if err = p.checkImageVersion(appConfig.DataDir(), session.DockerImage); err != nil {
+			return nil, err
+		}
+	}
 
 	if err := fs.CleanupLogsDir(appConfig.DataDir()); err != nil {
 		log.Warn("Failed to clean up logs directory:", err.Error())
@@ -301,6 +447,10 @@ func (p *Provisioner) ResetSession(session *resources.Session, snapshotID string
 		return nil, errors.Wrap(err, "failed to prepare database")
 	}
 
+	if err = p.createExtraUsers(appConfig, session.ExtraUsers); err != nil {
+		return nil, errors.Wrap(err, "failed to recreate clone users")
+	}
+
 	snapshotModel := &models.Snapshot{
 		ID:          snapshot.ID,
 		CreatedAt:   models.NewLocalTime(snapshot.CreatedAt),
@@ -539,6 +689,33 @@ func externalIP() (string, error) {
 	return string(bytes.TrimSpace(res)), nil
 }
 
+// HasFreePort checks if the port pool has ports that are not allocated to clones.
+// Ports of warm clones are considered as free because warm clones are destroyed when ports run out.
+func (p *Provisioner) HasFreePort() bool {
+	if p.warmPool.size() > 0 {
+		return true
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, bind := range p.ports {
+		if !bind {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ReservePort marks the port as busy, so it is not allocated to new clones.
+func (p *Provisioner) ReservePort(port uint) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.setPortStatus(port, true)
+}
+
 // FreePort marks the port as free.
 func (p *Provisioner) FreePort(port uint) error {
 	p.mu.Lock()
@@ -595,6 +772,12 @@ func (p *Provisioner) stopPoolSessions(fsm pool.FSManager, exceptClones map[stri
 		}
 	}
 
+	return destroyOrphanClones(fsm, exceptClones)
+}
+
+// destroyOrphanClones destroys clone datasets not owned by the kept clones, for example, datasets of warm clones
+// left by an engine that has not been stopped properly. Otherwise, new clones fail to take the ports of these datasets.
+func destroyOrphanClones(fsm pool.FSManager, exceptClones map[string]struct{}) error {
 	clones, err := fsm.ListClonesNames()
 	if err != nil {
 		return err
@@ -607,6 +790,8 @@ func (p *Provisioner) stopPoolSessions(fsm pool.FSManager, exceptClones map[stri
 			continue
 		}
 
+		log.Dbg("Destroying orphan clone:", clone)
+
 		if err := fsm.DestroyClone(clone); err != nil {
 			return err
 		}
@@ -630,13 +815,20 @@ func (p *Provisioner) getAppConfig(pool *resources.Pool, name string, port uint)
 		ProvisionHosts: provisionHosts,
 	}
 
+	p.certMu.RLock()
+	appConfig.CertIssuer = p.certIssuer
+	p.certMu.RUnlock()
+
 	return appConfig
 }
 
 // getProvisionHosts adds an internal Docker gateway to the hosts rule if the user restricts access to IP addresses.
 func (p *Provisioner) getProvisionHosts() string {
-	provisionHosts := p.config.CloneAccessAddresses
+	return p.restrictProvisionHosts(p.config.CloneAccessAddresses)
+}
 
+// restrictProvisionHosts adds an internal Docker gateway to the host addresses publishing ports of clones.
+func (p *Provisioner) restrictProvisionHosts(provisionHosts string) string {
 	if provisionHosts == "" || provisionHosts == wildcardIP {
 		return provisionHosts
 	}
@@ -772,12 +964,8 @@ func (p *Provisioner) scanCSVLogFile(ctx context.Context, filename string, avail
 }
 
 func (p *Provisioner) prepareDB(pgConf *resources.AppConfig, user resources.EphemeralUser) error {
-	if !p.config.KeepUserPasswords {
-		whitelist := []string{p.dbCfg.Username}
-
-		if err := postgres.ResetAllPasswords(pgConf, whitelist); err != nil {
-			return errors.Wrap(err, "failed to reset all passwords")
-		}
+	if err := p.resetPasswords(pgConf); err != nil {
+		return err
 	}
 
 	if err := postgres.CreateUser(pgConf, user); err != nil {
@@ -787,6 +975,20 @@ func (p *Provisioner) prepareDB(pgConf *resources.AppConfig, user resources.Ephe
 	return nil
 }
 
+func (p *Provisioner) resetPasswords(pgConf *resources.AppConfig) error {
+	if p.config.KeepUserPasswords {
+		return nil
+	}
+
+	whitelist := []string{p.dbCfg.Username}
+
+	if err := postgres.ResetAllPasswords(pgConf, whitelist); err != nil {
+		return errors.Wrap(err, "failed to reset all passwords")
+	}
+
+	return nil
+}
+
 // IsCloneRunning checks if clone is running.
 func (p *Provisioner) IsCloneRunning(ctx context.Context, cloneName string) bool {
 	isRunning, err := docker.IsContainerRunning(ctx, p.dockerClient, cloneName)
@@ -44,6 +44,8 @@ func TestPortAllocation(t *testing.T) {
 	_, err = p.allocatePort()
 	require.NoError(t, err)
 
+	assert.False(t, p.HasFreePort())
+
 	// Impossible allocate a new port.
 	_, err = p.allocatePort()
 	assert.IsType(t, errors.Cause(err), &NoRoomError{})
@@ -51,6 +53,8 @@ func TestPortAllocation(t *testing.T) {
 
 	// Free port and allocate a new one.
 	require.NoError(t, p.FreePort(port))
+	assert.True(t, p.HasFreePort())
+
 	port, err = p.allocatePort()
 	require.NoError(t, err)
 	assert.GreaterOrEqual(t, port, p.config.PortPool.From)
@@ -109,6 +113,18 @@ func (m mockFSManager) Pool() *resources.Pool {
 	return m.pool
 }
 
+func (m mockFSManager) CreateSavepoint(_, _ string) (*resources.Savepoint, error) {
+	return nil, nil
+}
+
+func (m mockFSManager) RollbackSavepoint(_, _ string) error {
+	return nil
+}
+
+func (m mockFSManager) ListSavepoints(_ string) ([]resources.Savepoint, error) {
+	return nil, nil
+}
+
 func TestBuildPoolEntry(t *testing.T) {
 	testCases := []struct {
 		pool          *resources.Pool
@@ -25,6 +25,7 @@ type FSManager interface {
 	Snapshotter
 	StateReporter
 	Pooler
+	Savepointer
 }
 
 // Cloner describes methods of clone management.
@@ -49,6 +50,13 @@ type Snapshotter interface {
 	RefreshSnapshotList()
 }
 
+// Savepointer describes methods of clone savepoint management.
+type Savepointer interface {
+	CreateSavepoint(cloneName, savepointName string) (*resources.Savepoint, error)
+	RollbackSavepoint(cloneName, savepointName string) error
+	ListSavepoints(cloneName string) ([]resources.Savepoint, error)
+}
+
 // Pooler describes methods for Pool providing.
 type Pooler interface {
 	Pool() *resources.Pool
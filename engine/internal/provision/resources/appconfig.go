@@ -18,11 +18,18 @@ type AppConfig struct {
 	DB             *DB
 	NetworkID      string
 	ProvisionHosts string
+	CertIssuer     CertIssuer
+	AllowedCIDRs   []string
 
 	ContainerConf map[string]string
 	pgExtraConf   map[string]string
 }
 
+// CertIssuer issues TLS server certificates for clones.
+type CertIssuer interface {
+	IssueCloneCert(cloneName string) (certPEM, keyPEM []byte, err error)
+}
+
 // DB describes a default database configuration.
 type DB struct {
 	Username string
@@ -20,6 +20,19 @@ type Session struct {
 	SocketHost    string            `json:"socketHost"`
 	EphemeralUser EphemeralUser     `json:"ephemeralUser"`
 	ExtraConfig   map[string]string `json:"extraConfig"`
+	// DockerImage overrides the default Docker image of clones.
+	DockerImage string `json:"dockerImage,omitempty"`
+	// UpgradedFrom keeps the Docker image used before the major-version upgrade of the clone.
+	UpgradedFrom string `json:"upgradedFrom,omitempty"`
+	// AllowedCIDRs restricts client addresses allowed to connect to the clone.
+	// Connections from the Docker networks of the clone are not restricted by pg_hba.conf.
+	AllowedCIDRs []string `json:"allowedCIDRs,omitempty"`
+	// ExtraUsers keeps database users added to the running clone to recreate them after the clone reset.
+	ExtraUsers []EphemeralUser `json:"extraUsers,omitempty"`
+	// InitProfile defines the init profile run after the clone starts and after each reset.
+	InitProfile string `json:"initProfile,omitempty"`
+	// InitRun describes the last run of the init profile.
+	InitRun *InitRun `json:"initRun,omitempty"`
 }
 
 // EphemeralUser describes an ephemeral database user defined by Database Lab users.
@@ -29,6 +42,26 @@ type EphemeralUser struct {
 	Password    string `json:"password"`
 	Restricted  bool   `json:"restricted"`
 	AvailableDB string `json:"availableDB"`
+	// Role defines the role preset of additional users, the role of the primary user depends on Restricted.
+	Role string `json:"role,omitempty"`
+	// Grants keeps SQL statements granting privileges to users with the custom role.
+	Grants []string `json:"grants,omitempty"`
+}
+
+// InitRun describes a run of the init profile in a clone.
+type InitRun struct {
+	Profile    string     `json:"profile"`
+	StartedAt  time.Time  `json:"startedAt"`
+	FinishedAt time.Time  `json:"finishedAt"`
+	Steps      []InitStep `json:"steps"`
+	Error      string     `json:"error,omitempty"`
+}
+
+// InitStep describes a step of the init profile run.
+type InitStep struct {
+	Name   string `json:"name"`
+	Output string `json:"output,omitempty"`
+	Error  string `json:"error,omitempty"`
 }
 
 // Snapshot defines snapshot of the data with related meta-information.
@@ -41,6 +74,14 @@ type Snapshot struct {
 	Pool              string
 }
 
+// Savepoint defines a snapshot of the clone data.
+type Savepoint struct {
+	Name              string
+	CreatedAt         time.Time
+	Used              uint64
+	LogicalReferenced uint64
+}
+
 // SessionState defines current state of a Session.
 type SessionState struct {
 	CloneDiffSize     uint64
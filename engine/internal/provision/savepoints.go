@@ -0,0 +1,98 @@
+package provision
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/databases/postgres"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/resources"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/thinclones"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/util"
+)
+
+// CreateSavepoint makes a checkpoint in the clone database and snapshots the clone data.
+func (p *Provisioner) CreateSavepoint(session *resources.Session, name string) (*resources.Savepoint, error) {
+	fsm, err := p.pm.GetFSManager(session.Pool)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to find a filesystem manager of this session")
+	}
+
+	cloneName := util.GetCloneName(session.Port)
+
+	// The snapshot is crash-consistent anyway, the checkpoint only shortens the recovery after a rollback.
+	if err := postgres.Checkpoint(p.getAppConfig(fsm.Pool(), cloneName, session.Port)); err != nil {
+		log.Warn(fmt.Sprintf("Failed to run checkpoint before creating savepoint %s of clone %s: %v", name, cloneName, err))
+	}
+
+	savepoint, err := fsm.CreateSavepoint(cloneName, name)
+	if err != nil {
+		return nil, savepointError(err, "failed to create a savepoint")
+	}
+
+	return savepoint, nil
+}
+
+// RollbackSavepoint restarts the clone container with the clone data rolled back to the savepoint.
+// Savepoints created after the requested one are destroyed.
+func (p *Provisioner) RollbackSavepoint(session *resources.Session, name string) error {
+	fsm, err := p.pm.GetFSManager(session.Pool)
+	if err != nil {
+		return errors.Wrap(err, "failed to find a filesystem manager of this session")
+	}
+
+	cloneName := util.GetCloneName(session.Port)
+
+	if err := postgres.Stop(p.runner, fsm.Pool(), cloneName); err != nil {
+		return errors.Wrap(err, "failed to stop a container")
+	}
+
+	if err := fsm.RollbackSavepoint(cloneName, name); err != nil {
+		return errors.Wrap(err, "failed to rollback to the savepoint")
+	}
+
+	appConfig := p.getAppConfig(fsm.Pool(), cloneName, session.Port)
+	appConfig.SetExtraConf(session.ExtraConfig)
+
+	// The savepoint might have been created before the major-version upgrade of the clone.
+	p.restorePreUpgradeImage(session, appConfig.DataDir())
+	setDockerImage(appConfig, session.DockerImage)
+	p.setAllowedCIDRs(appConfig, session.AllowedCIDRs)
+
+	if err := postgres.Start(p.runner, appConfig); err != nil {
+		return errors.Wrap(err, "failed to start a container")
+	}
+
+	// Users might have been added after the savepoint was created.
+	if err := p.createExtraUsers(appConfig, session.ExtraUsers); err != nil {
+		return errors.Wrap(err, "failed to recreate clone users")
+	}
+
+	return nil
+}
+
+// ListSavepoints lists savepoints of the session clone.
+func (p *Provisioner) ListSavepoints(session *resources.Session) ([]resources.Savepoint, error) {
+	fsm, err := p.pm.GetFSManager(session.Pool)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to find a filesystem manager of this session")
+	}
+
+	savepoints, err := fsm.ListSavepoints(util.GetCloneName(session.Port))
+	if err != nil {
+		return nil, savepointError(err, "failed to list savepoints")
+	}
+
+	return savepoints, nil
+}
+
+// savepointError reports unsupported operations as bad requests.
+func savepointError(err error, message string) error {
+	if errors.Is(err, thinclones.ErrNotSupported) {
+		return models.New(models.ErrCodeBadRequest, err.Error())
+	}
+
+	return errors.Wrap(err, message)
+}
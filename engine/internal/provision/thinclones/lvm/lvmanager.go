@@ -12,6 +12,7 @@ import (
 
 	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/resources"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/runners"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/thinclones"
 	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
 	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
 )
@@ -140,3 +141,18 @@ func (m *LVManager) GetFilesystemState() (models.FileSystem, error) {
 	// TODO(anatoly): Implement.
 	return models.FileSystem{Mode: PoolMode}, nil
 }
+
+// CreateSavepoint is not supported in LVM mode because LVM clones are snapshots that cannot be snapshotted again.
+func (m *LVManager) CreateSavepoint(_, _ string) (*resources.Savepoint, error) {
+	return nil, errors.Wrap(thinclones.ErrNotSupported, "savepoints are not supported in LVM mode")
+}
+
+// RollbackSavepoint is not supported in LVM mode.
+func (m *LVManager) RollbackSavepoint(_, _ string) error {
+	return errors.Wrap(thinclones.ErrNotSupported, "savepoints are not supported in LVM mode")
+}
+
+// ListSavepoints is not supported in LVM mode.
+func (m *LVManager) ListSavepoints(_ string) ([]resources.Savepoint, error) {
+	return nil, errors.Wrap(thinclones.ErrNotSupported, "savepoints are not supported in LVM mode")
+}
@@ -6,9 +6,13 @@
 package thinclones
 
 import (
+	"errors"
 	"fmt"
 )
 
+// ErrNotSupported defines an error when the thin-clone manager does not support an operation.
+var ErrNotSupported = errors.New("operation is not supported by the thin-clone manager")
+
 // SnapshotExistsError defines an error when snapshot already exists.
 type SnapshotExistsError struct {
 	name string
@@ -0,0 +1,113 @@
+package zfs
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/resources"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/util"
+)
+
+// savepointPrefix distinguishes clone savepoints from snapshots of the pool.
+const savepointPrefix = "savepoint_"
+
+// savepointFieldsNumber defines the number of fields in the savepoint list output.
+const savepointFieldsNumber = 4
+
+// CreateSavepoint creates a snapshot of the clone dataset.
+func (m *Manager) CreateSavepoint(cloneName, savepointName string) (*resources.Savepoint, error) {
+	snapshotName := m.getSavepointSnapshotName(cloneName, savepointName)
+
+	if _, err := m.runner.Run("zfs snapshot " + snapshotName); err != nil {
+		return nil, errors.Wrap(err, "failed to create a savepoint")
+	}
+
+	out, err := m.runner.Run(buildSavepointListCommand(snapshotName), false)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the created savepoint")
+	}
+
+	created, err := parseSavepoints(out)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(created) == 0 {
+		return nil, errors.Errorf("savepoint %q not found after creation", savepointName)
+	}
+
+	return &created[0], nil
+}
+
+// RollbackSavepoint rolls the clone dataset back to the savepoint.
+// Savepoints created after the requested one are destroyed.
+func (m *Manager) RollbackSavepoint(cloneName, savepointName string) error {
+	cmd := "zfs rollback -r " + m.getSavepointSnapshotName(cloneName, savepointName)
+
+	if _, err := m.runner.Run(cmd, true); err != nil {
+		return errors.Wrap(err, "failed to rollback to the savepoint")
+	}
+
+	return nil
+}
+
+// ListSavepoints lists savepoints of the clone ordered by creation time.
+func (m *Manager) ListSavepoints(cloneName string) ([]resources.Savepoint, error) {
+	out, err := m.runner.Run(buildSavepointListCommand(m.config.Pool.Name+"/"+cloneName), false)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list savepoints")
+	}
+
+	return parseSavepoints(out)
+}
+
+func (m *Manager) getSavepointSnapshotName(cloneName, savepointName string) string {
+	return fmt.Sprintf("%s/%s@%s%s", m.config.Pool.Name, cloneName, savepointPrefix, savepointName)
+}
+
+// isSavepoint checks if the snapshot is a clone savepoint.
+func isSavepoint(snapshotName string) bool {
+	return strings.Contains(snapshotName, "@"+savepointPrefix)
+}
+
+func buildSavepointListCommand(dataset string) string {
+	return "zfs list -t snapshot -H -p -o name,creation,used,logicalreferenced -s creation -d 1 " + dataset
+}
+
+func parseSavepoints(out string) ([]resources.Savepoint, error) {
+	savepoints := []resources.Savepoint{}
+
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		fields := strings.Fields(line)
+
+		if len(fields) != savepointFieldsNumber || !isSavepoint(fields[0]) {
+			continue
+		}
+
+		createdAt, err := util.ParseUnixTime(fields[1])
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse the creation time of %s", fields[0])
+		}
+
+		used, err := util.ParseBytes(fields[2])
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse the used size of %s", fields[0])
+		}
+
+		logicalReferenced, err := util.ParseBytes(fields[3])
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse the logical size of %s", fields[0])
+		}
+
+		savepoints = append(savepoints, resources.Savepoint{
+			Name:              fields[0][strings.Index(fields[0], "@"+savepointPrefix)+len("@"+savepointPrefix):],
+			CreatedAt:         createdAt,
+			Used:              used,
+			LogicalReferenced: logicalReferenced,
+		})
+	}
+
+	return savepoints, nil
+}
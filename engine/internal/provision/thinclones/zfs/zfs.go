@@ -557,6 +557,11 @@ func (m *Manager) getSnapshots() ([]resources.Snapshot, error) {
 			continue
 		}
 
+		// Clone savepoints are not pool snapshots.
+		if isSavepoint(entry.Name) {
+			continue
+		}
+
 		snapshot := resources.Snapshot{
 			ID:                entry.Name,
 			CreatedAt:         entry.Creation,
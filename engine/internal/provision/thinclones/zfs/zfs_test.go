@@ -224,3 +224,25 @@ func TestSnapshotList(t *testing.T) {
 		require.Equal(t, []resources.Snapshot{{ID: "test3"}, {ID: "test1"}}, fsManager.SnapshotList())
 	})
 }
+
+func TestListSavepoints(t *testing.T) {
+	fsManager := NewFSManager(runnerMock{
+		cmdOutput: "datastore/dblab_clone_6000@snapshot_20231010120000\t1696939200\t0\t1024\n" +
+			"datastore/dblab_clone_6000@savepoint_before_migration\t1696942800\t4096\t2048\n" +
+			"datastore/dblab_clone_6000@savepoint_20231010140000\t1696946400\t8192\t3072\n",
+	}, Config{Pool: &resources.Pool{Name: "datastore"}})
+
+	savepoints, err := fsManager.ListSavepoints("dblab_clone_6000")
+	require.NoError(t, err)
+	require.Len(t, savepoints, 2)
+
+	assert.Equal(t, "before_migration", savepoints[0].Name)
+	assert.Equal(t, int64(1696942800), savepoints[0].CreatedAt.Unix())
+	assert.Equal(t, uint64(4096), savepoints[0].Used)
+	assert.Equal(t, uint64(2048), savepoints[0].LogicalReferenced)
+	assert.Equal(t, "20231010140000", savepoints[1].Name)
+
+	assert.Equal(t, "datastore/dblab_clone_6000@savepoint_before_migration",
+		fsManager.getSavepointSnapshotName("dblab_clone_6000", "before_migration"))
+	assert.False(t, isSavepoint("datastore@snapshot_20231010120000"))
+}
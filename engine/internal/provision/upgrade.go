@@ -0,0 +1,414 @@
+package provision
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+	"github.com/pkg/errors"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/databases/postgres"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/docker"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/resources"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/tools"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/tools/cont"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/util"
+)
+
+const (
+	// upgradeContainerPrefix defines the name prefix of containers running pg_upgrade.
+	upgradeContainerPrefix = "dblab_upgrade_"
+
+	// upgradedDataSuffix defines the suffix of the data directory initialized for the new Postgres version.
+	upgradedDataSuffix = "_upgraded"
+
+	// previousDataSuffix defines the suffix of the replaced data directory, it is removed after the swap.
+	previousDataSuffix = "_pre_upgrade"
+
+	// upgradeWorkDir defines the working directory of pg_upgrade, it keeps sockets and logs of old versions.
+	upgradeWorkDir = "/tmp"
+
+	upgradeUser = "postgres"
+
+	// pgVersionChecksumsByDefault defines the Postgres version that enables data checksums in initdb by default.
+	pgVersionChecksumsByDefault = 18
+)
+
+// pgBinDirTemplate defines the location of Postgres binaries of the given version in the helper image.
+const pgBinDirTemplate = "/usr/lib/postgresql/%g/bin"
+
+// UpgradeConfig defines the major-version upgrade of clones.
+type UpgradeConfig struct {
+	// HelperImage defines the Docker image with binaries of both the old and the new Postgres versions.
+	HelperImage string `yaml:"helperImage"`
+}
+
+// CheckUpgrade checks if the session clone can be upgraded to the Docker image.
+func (p *Provisioner) CheckUpgrade(session *resources.Session, dockerImage string) error {
+	if p.config.Upgrade.HelperImage == "" {
+		return models.New(models.ErrCodeBadRequest, "upgrade helper image is not configured, set provision.upgrade.helperImage")
+	}
+
+	if err := p.CheckDockerImage(dockerImage); err != nil {
+		return err
+	}
+
+	fsm, err := p.pm.GetFSManager(session.Pool)
+	if err != nil {
+		return errors.Wrap(err, "failed to find a filesystem manager of this session")
+	}
+
+	if fsm.Pool().DataSubDir == "" {
+		return models.New(models.ErrCodeBadRequest, "upgrade requires the data directory to be a subdirectory of the clone, "+
+			"set poolManager.dataSubDir")
+	}
+
+	return nil
+}
+
+// UpgradeSession upgrades the session clone to the new Postgres major version of the Docker image.
+// The data directory is upgraded with pg_upgrade --link inside the same clone and the clone is restarted on the new image.
+// The returned report describes the upgrade. The error is returned only if the clone cannot be started anymore;
+// if pg_upgrade --check fails, the clone is started again on the previous image and the report has the failed status.
+func (p *Provisioner) UpgradeSession(session *resources.Session, dockerImage string) (*models.CloneUpgrade, error) {
+	startedAt := time.Now()
+
+	report := &models.CloneUpgrade{
+		Status:      models.UpgradeStatusRunning,
+		DockerImage: dockerImage,
+		StartedAt:   models.NewLocalTime(startedAt),
+	}
+
+	failed := func(err error) *models.CloneUpgrade {
+		report.Status = models.UpgradeStatusFailed
+		report.Error = errors.Cause(err).Error()
+		report.FinishedAt = models.NewLocalTime(time.Now())
+		report.Timings.TotalTime = time.Since(startedAt).Seconds()
+
+		return report
+	}
+
+	fsm, err := p.pm.GetFSManager(session.Pool)
+	if err != nil {
+		return failed(err), nil
+	}
+
+	cloneName := util.GetCloneName(session.Port)
+
+	appConfig := p.getAppConfig(fsm.Pool(), cloneName, session.Port)
+	appConfig.SetExtraConf(session.ExtraConfig)
+	setDockerImage(appConfig, session.DockerImage)
+	p.setAllowedCIDRs(appConfig, session.AllowedCIDRs)
+
+	upgrade, err := p.prepareUpgrade(appConfig, dockerImage)
+	if err != nil {
+		return failed(err), nil
+	}
+
+	report.FromVersion = fmt.Sprintf("%g", upgrade.oldVersion)
+	report.ToVersion = fmt.Sprintf("%g", upgrade.newVersion)
+
+	// pg_upgrade requires the old cluster to be shut down cleanly.
+	if err := p.shutdownContainer(appConfig); err != nil {
+		return failed(err), errors.Wrap(err, "failed to stop a container")
+	}
+
+	containerID, err := p.startUpgradeContainer(appConfig)
+	if err != nil {
+		return failed(err), p.restartAfterUpgradeFailure(appConfig, err)
+	}
+
+	defer tools.RemoveContainer(p.ctx, p.dockerClient, containerID, 0)
+
+	upgrade.containerID = containerID
+
+	if err := upgrade.initNewCluster(p.ctx, p.dockerClient); err != nil {
+		upgrade.removeNewCluster(p.ctx, p.dockerClient)
+		return failed(err), p.restartAfterUpgradeFailure(appConfig, err)
+	}
+
+	checkStartedAt := time.Now()
+	report.CheckOutput, err = upgrade.run(p.ctx, p.dockerClient, true)
+	report.Timings.CheckTime = time.Since(checkStartedAt).Seconds()
+
+	if err != nil {
+		upgrade.removeNewCluster(p.ctx, p.dockerClient)
+		return failed(errors.Wrap(err, "pg_upgrade --check failed")), p.restartAfterUpgradeFailure(appConfig, err)
+	}
+
+	// The old cluster cannot be started safely once the data files are linked, so the clone has to be reset on failures.
+	upgradeStartedAt := time.Now()
+	output, err := upgrade.run(p.ctx, p.dockerClient, false)
+	report.Timings.UpgradeTime = time.Since(upgradeStartedAt).Seconds()
+
+	if err != nil {
+		log.Dbg("pg_upgrade output: ", output)
+		return failed(err), errors.Wrap(err, "failed to run pg_upgrade, reset the clone to restore the data")
+	}
+
+	if err := upgrade.swapDataDir(p.ctx, p.dockerClient); err != nil {
+		return failed(err), errors.Wrap(err, "failed to swap the data directory, reset the clone to restore the data")
+	}
+
+	appConfig.DockerImage = dockerImage
+
+	if err := postgres.Start(p.runner, appConfig); err != nil {
+		return failed(err), errors.Wrap(err, "failed to start a container on the new image")
+	}
+
+	if session.UpgradedFrom == "" {
+		session.UpgradedFrom = p.config.DockerImage
+
+		if session.DockerImage != "" {
+			session.UpgradedFrom = session.DockerImage
+		}
+	}
+
+	session.DockerImage = p.sessionDockerImage(dockerImage)
+
+	analyzeStartedAt := time.Now()
+
+	if err := postgres.Analyze(appConfig); err != nil {
+		// The clone is already upgraded and running, statistics are collected by autovacuum eventually.
+		log.Warn(fmt.Sprintf("Failed to analyze the upgraded clone %s: %v", cloneName, err))
+	}
+
+	report.Timings.AnalyzeTime = time.Since(analyzeStartedAt).Seconds()
+
+	report.Status = models.UpgradeStatusFinished
+	report.FinishedAt = models.NewLocalTime(time.Now())
+	report.Timings.TotalTime = time.Since(startedAt).Seconds()
+
+	return report, nil
+}
+
+// restartAfterUpgradeFailure starts the clone on the previous image when the data directory has not been changed.
+func (p *Provisioner) restartAfterUpgradeFailure(appConfig *resources.AppConfig, upgradeErr error) error {
+	log.Warn(fmt.Sprintf("Upgrade of clone %s failed, starting the clone on the previous image: %v", appConfig.CloneName, upgradeErr))
+
+	if err := postgres.Start(p.runner, appConfig); err != nil {
+		return errors.Wrap(err, "failed to start a container after the failed upgrade")
+	}
+
+	return nil
+}
+
+// sessionDockerImage returns the Docker image to store in the session, the default image is not stored.
+func (p *Provisioner) sessionDockerImage(dockerImage string) string {
+	if dockerImage == p.config.DockerImage {
+		return ""
+	}
+
+	return dockerImage
+}
+
+// restorePreUpgradeImage switches the session back to the Docker image used before the upgrade
+// if the clone data does not have the major version of the upgraded image anymore.
+func (p *Provisioner) restorePreUpgradeImage(session *resources.Session, dataDir string) {
+	if session.UpgradedFrom == "" {
+		return
+	}
+
+	pgVersion, err := tools.DetectPGVersion(dataDir)
+	if err != nil {
+		log.Warn(fmt.Sprintf("Failed to detect Postgres version of clone on port %d: %v", session.Port, err))
+		return
+	}
+
+	dockerImage := session.DockerImage
+	if dockerImage == "" {
+		dockerImage = p.config.DockerImage
+	}
+
+	content, err := p.imageContent(dockerImage)
+	if err != nil {
+		log.Warn(fmt.Sprintf("Failed to check the image of clone on port %d: %v", session.Port, err))
+		return
+	}
+
+	if content.PGVersion() == pgVersion {
+		return
+	}
+
+	session.DockerImage = p.sessionDockerImage(session.UpgradedFrom)
+	session.UpgradedFrom = ""
+}
+
+// pgUpgrade runs pg_upgrade in the helper container.
+type pgUpgrade struct {
+	containerID string
+	oldVersion  float64
+	newVersion  float64
+	oldDataDir  string
+	newDataDir  string
+	username    string
+	initParams  *postgres.InitParams
+}
+
+// prepareUpgrade collects the details of the running clone required to upgrade it.
+func (p *Provisioner) prepareUpgrade(appConfig *resources.AppConfig, dockerImage string) (*pgUpgrade, error) {
+	oldVersion, err := tools.DetectPGVersion(appConfig.DataDir())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to detect Postgres version of the clone")
+	}
+
+	content, err := p.imageContent(dockerImage)
+	if err != nil {
+		return nil, err
+	}
+
+	if content.PGVersion() <= oldVersion {
+		return nil, errors.Errorf("Docker image %q provides Postgres %g, it must be newer than Postgres %g of the clone",
+			dockerImage, content.PGVersion(), oldVersion)
+	}
+
+	if err := p.checkImageExtensions(appConfig, dockerImage); err != nil {
+		return nil, err
+	}
+
+	initParams, err := postgres.GetInitParams(appConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pgUpgrade{
+		oldVersion: oldVersion,
+		newVersion: content.PGVersion(),
+		oldDataDir: appConfig.DataDir(),
+		newDataDir: appConfig.DataDir() + upgradedDataSuffix,
+		username:   appConfig.DB.Username,
+		initParams: initParams,
+	}, nil
+}
+
+// startUpgradeContainer starts the helper container with the clone directory mounted.
+func (p *Provisioner) startUpgradeContainer(appConfig *resources.AppConfig) (string, error) {
+	if err := docker.PrepareImage(p.ctx, p.dockerClient, p.config.Upgrade.HelperImage); err != nil {
+		return "", errors.Wrap(err, "failed to prepare the upgrade helper image")
+	}
+
+	hostConfig := &container.HostConfig{}
+
+	if err := tools.AddVolumesToHostConfig(p.ctx, p.dockerClient, hostConfig, appConfig.CloneDir()); err != nil {
+		return "", errors.Wrap(err, "failed to set up volumes")
+	}
+
+	containerName := upgradeContainerPrefix + appConfig.CloneName
+
+	containerID, err := tools.CreateContainerIfMissing(p.ctx, p.dockerClient, containerName, &container.Config{
+		Labels: map[string]string{
+			cont.DBLabControlLabel:    cont.DBLabUpgradeLabel,
+			cont.DBLabInstanceIDLabel: p.instanceID,
+		},
+		// The helper image is used only for its binaries, so Postgres is not started.
+		Entrypoint: []string{"sleep", "infinity"},
+		Image:      p.config.Upgrade.HelperImage,
+	}, hostConfig)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to create container %s", containerName)
+	}
+
+	if err := p.dockerClient.ContainerStart(p.ctx, containerID, container.StartOptions{}); err != nil {
+		return "", errors.Wrapf(err, "failed to start container %s", containerName)
+	}
+
+	return containerID, nil
+}
+
+// initNewCluster initializes the data directory of the new version keeping the settings that pg_upgrade requires to match.
+func (u *pgUpgrade) initNewCluster(ctx context.Context, dockerClient *client.Client) error {
+	output, err := u.exec(ctx, dockerClient, u.initDBCommand())
+	if err != nil {
+		log.Dbg("initdb output: ", output)
+		return errors.Wrap(err, "failed to initialize the new data directory")
+	}
+
+	return nil
+}
+
+func (u *pgUpgrade) initDBCommand() []string {
+	command := []string{
+		path.Join(fmt.Sprintf(pgBinDirTemplate, u.newVersion), "initdb"),
+		"--pgdata", u.newDataDir,
+		"--username", u.username,
+		"--auth", "trust",
+		"--encoding", u.initParams.Encoding,
+		"--lc-collate", u.initParams.LCCollate,
+		"--lc-ctype", u.initParams.LCCType,
+	}
+
+	if u.initParams.DataChecksums {
+		command = append(command, "--data-checksums")
+	} else if u.newVersion >= pgVersionChecksumsByDefault {
+		command = append(command, "--no-data-checksums")
+	}
+
+	return command
+}
+
+// run runs pg_upgrade in the link mode and returns its output.
+func (u *pgUpgrade) run(ctx context.Context, dockerClient *client.Client, check bool) (string, error) {
+	return u.exec(ctx, dockerClient, u.upgradeCommand(check))
+}
+
+func (u *pgUpgrade) upgradeCommand(check bool) []string {
+	command := []string{
+		path.Join(fmt.Sprintf(pgBinDirTemplate, u.newVersion), "pg_upgrade"),
+		"--old-bindir", fmt.Sprintf(pgBinDirTemplate, u.oldVersion),
+		"--new-bindir", fmt.Sprintf(pgBinDirTemplate, u.newVersion),
+		"--old-datadir", u.oldDataDir,
+		"--new-datadir", u.newDataDir,
+		"--username", u.username,
+		"--link",
+	}
+
+	if check {
+		command = append(command, "--check")
+	}
+
+	return command
+}
+
+// swapDataDir replaces the clone data directory with the upgraded one keeping the Database Lab configuration files.
+func (u *pgUpgrade) swapDataDir(ctx context.Context, dockerClient *client.Client) error {
+	previousDataDir := u.oldDataDir + previousDataSuffix
+
+	script := strings.Join([]string{
+		fmt.Sprintf("cp -p %[1]s/postgresql.conf %[1]s/pg_hba.conf %[1]s/pg_ident.conf %[2]s/", u.oldDataDir, u.newDataDir),
+		fmt.Sprintf("(cp -p %s/postgresql.dblab.* %s/ 2>/dev/null || true)", u.oldDataDir, u.newDataDir),
+		fmt.Sprintf("mv %s %s", u.oldDataDir, previousDataDir),
+		fmt.Sprintf("mv %s %s", u.newDataDir, u.oldDataDir),
+		fmt.Sprintf("rm -rf %s", previousDataDir),
+	}, " && ")
+
+	if output, err := u.exec(ctx, dockerClient, []string{"sh", "-c", script}); err != nil {
+		log.Dbg("Swap output: ", output)
+		return errors.Wrap(err, "failed to swap the data directory")
+	}
+
+	return nil
+}
+
+// removeNewCluster removes the new data directory if the upgrade has not been started.
+func (u *pgUpgrade) removeNewCluster(ctx context.Context, dockerClient *client.Client) {
+	if output, err := u.exec(ctx, dockerClient, []string{"rm", "-rf", u.newDataDir}); err != nil {
+		log.Warn(fmt.Sprintf("Failed to remove the new data directory %s: %v. %s", u.newDataDir, err, output))
+	}
+}
+
+func (u *pgUpgrade) exec(ctx context.Context, dockerClient *client.Client, command []string) (string, error) {
+	log.Dbg("Run upgrade command: ", command)
+
+	return tools.ExecCommandWithOutput(ctx, dockerClient, u.containerID, types.ExecConfig{
+		User:       upgradeUser,
+		WorkingDir: upgradeWorkDir,
+		Cmd:        command,
+	})
+}
@@ -0,0 +1,48 @@
+package provision
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/databases/postgres"
+)
+
+func TestPgUpgradeCommands(t *testing.T) {
+	upgrade := &pgUpgrade{
+		oldVersion: 9.6,
+		newVersion: 18,
+		oldDataDir: "/var/lib/dblab/dblab_pool/clones/dblab_clone_6000/data",
+		newDataDir: "/var/lib/dblab/dblab_pool/clones/dblab_clone_6000/data_upgraded",
+		username:   "postgres",
+		initParams: &postgres.InitParams{Encoding: "UTF8", LCCollate: "en_US.UTF-8", LCCType: "en_US.UTF-8"},
+	}
+
+	assert.Equal(t, []string{
+		"/usr/lib/postgresql/18/bin/initdb",
+		"--pgdata", "/var/lib/dblab/dblab_pool/clones/dblab_clone_6000/data_upgraded",
+		"--username", "postgres",
+		"--auth", "trust",
+		"--encoding", "UTF8",
+		"--lc-collate", "en_US.UTF-8",
+		"--lc-ctype", "en_US.UTF-8",
+		"--no-data-checksums",
+	}, upgrade.initDBCommand())
+
+	assert.Equal(t, []string{
+		"/usr/lib/postgresql/18/bin/pg_upgrade",
+		"--old-bindir", "/usr/lib/postgresql/9.6/bin",
+		"--new-bindir", "/usr/lib/postgresql/18/bin",
+		"--old-datadir", "/var/lib/dblab/dblab_pool/clones/dblab_clone_6000/data",
+		"--new-datadir", "/var/lib/dblab/dblab_pool/clones/dblab_clone_6000/data_upgraded",
+		"--username", "postgres",
+		"--link",
+		"--check",
+	}, upgrade.upgradeCommand(true))
+
+	upgrade.newVersion = 17
+	upgrade.initParams.DataChecksums = true
+
+	assert.Contains(t, upgrade.initDBCommand(), "--data-checksums")
+	assert.NotContains(t, upgrade.upgradeCommand(false), "--check")
+}
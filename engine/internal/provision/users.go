@@ -0,0 +1,104 @@
+package provision
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/databases/postgres"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/resources"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/util"
+)
+
+// CreateSessionUser creates an additional database user in the running clone of the session.
+func (p *Provisioner) CreateSessionUser(session *resources.Session, user resources.EphemeralUser) error {
+	appConfig, err := p.sessionAppConfig(session)
+	if err != nil {
+		return err
+	}
+
+	exists, err := postgres.UserExists(appConfig, user.Name)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		return models.New(models.ErrCodeUserExists, fmt.Sprintf("user %q already exists", user.Name))
+	}
+
+	if err := createNewUser(appConfig, user); err != nil {
+		return errors.Wrap(err, "failed to create user")
+	}
+
+	return nil
+}
+
+// DropSessionUser drops a database user of the running clone of the session.
+func (p *Provisioner) DropSessionUser(session *resources.Session, username string) error {
+	appConfig, err := p.sessionAppConfig(session)
+	if err != nil {
+		return err
+	}
+
+	if err := postgres.DropUser(appConfig, username); err != nil {
+		return errors.Wrap(err, "failed to drop user")
+	}
+
+	return nil
+}
+
+// createExtraUsers recreates additional users of the session after the clone data has been replaced.
+// Users kept by the clone data, for example, by a savepoint, are not changed.
+func (p *Provisioner) createExtraUsers(appConfig *resources.AppConfig, users []resources.EphemeralUser) error {
+	for _, user := range users {
+		exists, err := postgres.UserExists(appConfig, user.Name)
+		if err != nil {
+			return err
+		}
+
+		if exists {
+			log.Dbg(fmt.Sprintf("User %s already exists in clone %s", user.Name, appConfig.CloneName))
+			continue
+		}
+
+		if err := createNewUser(appConfig, user); err != nil {
+			return errors.Wrapf(err, "failed to create user %s", user.Name)
+		}
+	}
+
+	return nil
+}
+
+// createNewUser creates the user which does not exist in the clone. The role is created before privileges are granted,
+// so it is dropped if a later step fails. Otherwise, the role would be left in the clone without being tracked by the session.
+func createNewUser(appConfig *resources.AppConfig, user resources.EphemeralUser) error {
+	createErr := postgres.CreateUser(appConfig, user)
+	if createErr == nil {
+		return nil
+	}
+
+	exists, err := postgres.UserExists(appConfig, user.Name)
+	if err != nil {
+		log.Err(fmt.Sprintf("Failed to check user %s after the failed creation: %v", user.Name, err))
+		return createErr
+	}
+
+	if exists {
+		if err := postgres.DropUser(appConfig, user.Name); err != nil {
+			log.Err(fmt.Sprintf("Failed to drop user %s after the failed creation: %v", user.Name, err))
+		}
+	}
+
+	return createErr
+}
+
+func (p *Provisioner) sessionAppConfig(session *resources.Session) (*resources.AppConfig, error) {
+	fsm, err := p.pm.GetFSManager(session.Pool)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to find a filesystem manager of this session")
+	}
+
+	return p.getAppConfig(fsm.Pool(), util.GetCloneName(session.Port), session.Port), nil
+}
@@ -0,0 +1,344 @@
+package provision
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/databases/postgres"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/resources"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/util"
+)
+
+// warmPoolCheckInterval defines how often the warm pool is checked for the latest snapshot.
+const warmPoolCheckInterval = time.Minute
+
+// WarmPoolConfig defines the pool of pre-created clones.
+type WarmPoolConfig struct {
+	// Size defines the number of started clones of the latest snapshot waiting to be handed out.
+	Size uint `yaml:"size"`
+}
+
+// warmSession describes a started clone without the ephemeral user.
+type warmSession struct {
+	pool       string
+	port       uint
+	snapshotID string
+}
+
+// warmPool keeps warm clones and counts their usage.
+type warmPool struct {
+	mu       sync.Mutex
+	sessions []*warmSession
+	hits     uint64
+	misses   uint64
+	refillCh chan struct{}
+	// closed stops accepting warm sessions when the engine shuts down.
+	closed bool
+}
+
+func newWarmPool() *warmPool {
+	return &warmPool{
+		refillCh: make(chan struct{}, 1),
+	}
+}
+
+func (wp *warmPool) size() int {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	return len(wp.sessions)
+}
+
+// add puts the session into the pool. It returns false if the pool is closed, so the session must be destroyed.
+func (wp *warmPool) add(session *warmSession) bool {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	if wp.closed {
+		return false
+	}
+
+	wp.sessions = append(wp.sessions, session)
+
+	return true
+}
+
+// close removes all sessions from the pool and stops accepting new ones.
+func (wp *warmPool) close() []*warmSession {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	sessions := wp.sessions
+	wp.sessions = nil
+	wp.closed = true
+
+	return sessions
+}
+
+// take removes the oldest warm session of the snapshot from the pool.
+func (wp *warmPool) take(snapshotID string) *warmSession {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	for i, session := range wp.sessions {
+		if session.snapshotID == snapshotID {
+			wp.sessions = append(wp.sessions[:i], wp.sessions[i+1:]...)
+			wp.hits++
+
+			return session
+		}
+	}
+
+	wp.misses++
+
+	return nil
+}
+
+// takeOldest removes the oldest warm session from the pool.
+func (wp *warmPool) takeOldest() *warmSession {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	if len(wp.sessions) == 0 {
+		return nil
+	}
+
+	session := wp.sessions[0]
+	wp.sessions = wp.sessions[1:]
+
+	return session
+}
+
+// takeStale removes warm sessions of other snapshots and sessions exceeding the pool size.
+func (wp *warmPool) takeStale(snapshotID string, size int) []*warmSession {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	stale := []*warmSession{}
+	actual := make([]*warmSession, 0, len(wp.sessions))
+
+	for _, session := range wp.sessions {
+		if session.snapshotID != snapshotID || len(actual) >= size {
+			stale = append(stale, session)
+			continue
+		}
+
+		actual = append(actual, session)
+	}
+
+	wp.sessions = actual
+
+	return stale
+}
+
+func (wp *warmPool) requestRefill() {
+	select {
+	case wp.refillCh <- struct{}{}:
+	default:
+	}
+}
+
+// RunWarmPool keeps the configured number of warm clones of the latest snapshot.
+func (p *Provisioner) RunWarmPool(ctx context.Context) {
+	ticker := time.NewTicker(warmPoolCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		p.maintainWarmPool(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+
+		case <-p.warmPool.refillCh:
+		}
+	}
+}
+
+// maintainWarmPool destroys warm clones of outdated snapshots and creates missing ones.
+func (p *Provisioner) maintainWarmPool(ctx context.Context) {
+	size := int(p.config.WarmPool.Size)
+
+	var latestSnapshotID string
+
+	if size > 0 {
+		snapshot, err := p.getSnapshot("")
+		if err != nil {
+			log.Dbg("Warm pool is not filled: ", err)
+			return
+		}
+
+		latestSnapshotID = snapshot.ID
+	}
+
+	for _, session := range p.warmPool.takeStale(latestSnapshotID, size) {
+		p.destroyWarmSession(session)
+	}
+
+	for p.warmPool.size() < size && ctx.Err() == nil {
+		session, err := p.startWarmSession(latestSnapshotID)
+		if err != nil {
+			var noRoomErr *NoRoomError
+			if errors.As(err, &noRoomErr) {
+				log.Dbg("Warm pool is not filled: ", err)
+				return
+			}
+
+			log.Err(fmt.Sprintf("Failed to start a warm clone of snapshot %s: %v", latestSnapshotID, err))
+
+			return
+		}
+
+		if !p.warmPool.add(session) {
+			p.destroyWarmSession(session)
+			return
+		}
+
+		log.Dbg(fmt.Sprintf("Warm clone of snapshot %s started on port %d", latestSnapshotID, session.port))
+	}
+}
+
+// StopWarmPool destroys warm clones and stops filling the warm pool.
+// Warm clones are not stored in the sessions file, so they must not outlive the engine.
+func (p *Provisioner) StopWarmPool() {
+	for _, session := range p.warmPool.close() {
+		p.destroyWarmSession(session)
+	}
+}
+
+// startWarmSession starts a clone that is ready to get the ephemeral user.
+func (p *Provisioner) startWarmSession(snapshotID string) (_ *warmSession, err error) {
+	snapshot, err := p.getSnapshot(snapshotID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get snapshots")
+	}
+
+	fsm, err := p.pm.GetFSManager(snapshot.Pool)
+	if err != nil {
+		return nil, fmt.Errorf("cannot work with pool %s: %w", snapshot.Pool, err)
+	}
+
+	port, err := p.allocatePort()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get a free port")
+	}
+
+	defer func() {
+		if err != nil {
+			p.revertSession(fsm, util.GetCloneName(port))
+
+			if portErr := p.FreePort(port); portErr != nil {
+				log.Err(portErr)
+			}
+		}
+	}()
+
+	appConfig, err := p.startClone(fsm, snapshot.ID, port, nil, "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.resetPasswords(appConfig); err != nil {
+		return nil, errors.Wrap(err, "failed to prepare a database")
+	}
+
+	return &warmSession{
+		pool:       fsm.Pool().Name,
+		port:       port,
+		snapshotID: snapshot.ID,
+	}, nil
+}
+
+// takeWarmSession hands out a warm clone of the snapshot creating the ephemeral user in it.
+// It returns nil if there is no suitable warm clone, so the clone has to be created from scratch.
+func (p *Provisioner) takeWarmSession(snapshotID string, user resources.EphemeralUser) *resources.Session {
+	if p.config.WarmPool.Size == 0 {
+		return nil
+	}
+
+	warm := p.warmPool.take(snapshotID)
+
+	p.warmPool.requestRefill()
+
+	if warm == nil {
+		return nil
+	}
+
+	fsm, err := p.pm.GetFSManager(warm.pool)
+	if err != nil {
+		log.Err(fmt.Sprintf("Failed to find the pool of the warm clone on port %d: %v", warm.port, err))
+		return nil
+	}
+
+	appConfig := p.getAppConfig(fsm.Pool(), util.GetCloneName(warm.port), warm.port)
+
+	if err := postgres.CreateUser(appConfig, user); err != nil {
+		log.Err(fmt.Sprintf("Failed to create user in the warm clone on port %d: %v", warm.port, err))
+		p.destroyWarmSession(warm)
+
+		return nil
+	}
+
+	log.Dbg(fmt.Sprintf("Warm clone on port %d has been handed out", warm.port))
+
+	return p.newSession(fsm.Pool().Name, appConfig, user, nil)
+}
+
+// evictWarmSession destroys the oldest warm clone to release its port.
+func (p *Provisioner) evictWarmSession() bool {
+	warm := p.warmPool.takeOldest()
+	if warm == nil {
+		return false
+	}
+
+	p.destroyWarmSession(warm)
+
+	return true
+}
+
+func (p *Provisioner) destroyWarmSession(warm *warmSession) {
+	fsm, err := p.pm.GetFSManager(warm.pool)
+	if err != nil {
+		log.Err(fmt.Sprintf("Failed to find the pool of the warm clone on port %d: %v", warm.port, err))
+		return
+	}
+
+	p.revertSession(fsm, util.GetCloneName(warm.port))
+
+	if err := p.FreePort(warm.port); err != nil {
+		log.Err(err)
+	}
+
+	log.Dbg(fmt.Sprintf("Warm clone of snapshot %s on port %d has been destroyed", warm.snapshotID, warm.port))
+}
+
+// WarmPoolState reports the state of the warm pool. It returns nil if the warm pool is disabled.
+func (p *Provisioner) WarmPoolState() *models.WarmPool {
+	if p.config.WarmPool.Size == 0 && p.warmPool.size() == 0 {
+		return nil
+	}
+
+	p.warmPool.mu.Lock()
+	defer p.warmPool.mu.Unlock()
+
+	state := &models.WarmPool{
+		Size:   p.config.WarmPool.Size,
+		Ready:  uint(len(p.warmPool.sessions)),
+		Hits:   p.warmPool.hits,
+		Misses: p.warmPool.misses,
+	}
+
+	if len(p.warmPool.sessions) > 0 {
+		state.SnapshotID = p.warmPool.sessions[0].snapshotID
+	}
+
+	return state
+}
@@ -0,0 +1,106 @@
+package provision
+
+import (
+	"context"
+	"testing"
+
+	"github.com/docker/docker/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/pool"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/resources"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+func TestWarmPoolTake(t *testing.T) {
+	wp := newWarmPool()
+
+	wp.add(&warmSession{port: 6000, snapshotID: "pool@snapshot_1"})
+	wp.add(&warmSession{port: 6001, snapshotID: "pool@snapshot_2"})
+	wp.add(&warmSession{port: 6002, snapshotID: "pool@snapshot_2"})
+
+	session := wp.take("pool@snapshot_2")
+	require.NotNil(t, session)
+	assert.Equal(t, uint(6001), session.port)
+
+	assert.Nil(t, wp.take("pool@snapshot_3"))
+	assert.Equal(t, uint64(1), wp.hits)
+	assert.Equal(t, uint64(1), wp.misses)
+
+	assert.Equal(t, uint(6000), wp.takeOldest().port)
+	assert.Equal(t, 1, wp.size())
+}
+
+func TestWarmPoolTakeStale(t *testing.T) {
+	wp := newWarmPool()
+
+	wp.add(&warmSession{port: 6000, snapshotID: "pool@snapshot_1"})
+	wp.add(&warmSession{port: 6001, snapshotID: "pool@snapshot_2"})
+	wp.add(&warmSession{port: 6002, snapshotID: "pool@snapshot_2"})
+
+	stale := wp.takeStale("pool@snapshot_2", 1)
+	require.Len(t, stale, 2)
+	assert.Equal(t, uint(6000), stale[0].port)
+	assert.Equal(t, uint(6002), stale[1].port)
+
+	require.Equal(t, 1, wp.size())
+	assert.Equal(t, uint(6001), wp.sessions[0].port)
+
+	// The disabled warm pool releases all clones.
+	assert.Len(t, wp.takeStale("", 0), 1)
+	assert.Equal(t, 0, wp.size())
+}
+
+func TestWarmPoolState(t *testing.T) {
+	cfg := &Config{PortPool: PortPool{From: 6000, To: 6000}}
+
+	p, err := New(context.Background(), cfg, &resources.DB{}, &client.Client{}, &pool.Manager{}, "instanceID", "networkID", "")
+	require.NoError(t, err)
+
+	assert.Nil(t, p.WarmPoolState())
+
+	_, err = p.allocatePort()
+	require.NoError(t, err)
+	assert.False(t, p.HasFreePort())
+
+	p.config.WarmPool.Size = 2
+	p.warmPool.add(&warmSession{port: 6000, snapshotID: "pool@snapshot_1"})
+
+	// Ports of warm clones can be released for new clones.
+	assert.True(t, p.HasFreePort())
+
+	assert.Equal(t, &models.WarmPool{Size: 2, Ready: 1, SnapshotID: "pool@snapshot_1"}, p.WarmPoolState())
+}
+
+func TestWarmPoolClose(t *testing.T) {
+	wp := newWarmPool()
+
+	require.True(t, wp.add(&warmSession{port: 6000, snapshotID: "pool@snapshot_1"}))
+
+	sessions := wp.close()
+	require.Len(t, sessions, 1)
+	assert.Equal(t, uint(6000), sessions[0].port)
+	assert.Equal(t, 0, wp.size())
+
+	// Clones started after the pool is closed must be destroyed by the caller.
+	assert.False(t, wp.add(&warmSession{port: 6001, snapshotID: "pool@snapshot_1"}))
+	assert.Equal(t, 0, wp.size())
+}
+
+type destroyRecorder struct {
+	mockFSManager
+	destroyed []string
+}
+
+func (m *destroyRecorder) DestroyClone(name string) error {
+	m.destroyed = append(m.destroyed, name)
+	return nil
+}
+
+func TestDestroyOrphanClones(t *testing.T) {
+	fsm := &destroyRecorder{mockFSManager: mockFSManager{cloneList: []string{"dblab_clone_6000", "dblab_clone_6001", "dblab_clone_6002"}}}
+
+	require.NoError(t, destroyOrphanClones(fsm, map[string]struct{}{"dblab_clone_6001": {}}))
+	assert.Equal(t, []string{"dblab_clone_6000", "dblab_clone_6002"}, fsm.destroyed)
+}
@@ -0,0 +1,169 @@
+package replay
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+// maxReportedQueries limits the number of queries in the report, the slowest queries by total time are reported.
+const maxReportedQueries = 100
+
+// latencyBounds defines upper bounds of histogram buckets in milliseconds.
+var latencyBounds = []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000}
+
+// latencies collects latencies of executed statements in milliseconds.
+type latencies struct {
+	values []float64
+	total  float64
+	errors int
+}
+
+func (l *latencies) add(latency float64) {
+	l.values = append(l.values, latency)
+	l.total += latency
+}
+
+func (l *latencies) histogram(withBuckets bool) models.LatencyHistogram {
+	histogram := models.LatencyHistogram{
+		Count:  len(l.values),
+		Errors: l.errors,
+		Total:  round(l.total),
+	}
+
+	if len(l.values) == 0 {
+		return histogram
+	}
+
+	sorted := make([]float64, len(l.values))
+	copy(sorted, l.values)
+	sort.Float64s(sorted)
+
+	histogram.Min = round(sorted[0])
+	histogram.Max = round(sorted[len(sorted)-1])
+	histogram.Mean = round(l.total / float64(len(sorted)))
+	histogram.P50 = percentile(sorted, 50)
+	histogram.P90 = percentile(sorted, 90)
+	histogram.P95 = percentile(sorted, 95)
+	histogram.P99 = percentile(sorted, 99)
+
+	if withBuckets {
+		histogram.Buckets = buildBuckets(sorted)
+	}
+
+	return histogram
+}
+
+// percentile returns the nearest-rank percentile of sorted values.
+func percentile(sorted []float64, p float64) float64 {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+
+	return round(sorted[rank-1])
+}
+
+func buildBuckets(sorted []float64) []models.LatencyBucket {
+	buckets := make([]models.LatencyBucket, 0, len(latencyBounds)+1)
+
+	for _, bound := range latencyBounds {
+		buckets = append(buckets, models.LatencyBucket{UpperBound: bound})
+	}
+
+	buckets = append(buckets, models.LatencyBucket{})
+
+	bucket := 0
+
+	for _, value := range sorted {
+		for bucket < len(latencyBounds) && value > latencyBounds[bucket] {
+			bucket++
+		}
+
+		buckets[bucket].Count++
+	}
+
+	return buckets
+}
+
+func round(value float64) float64 {
+	const precision = 1000
+
+	return math.Round(value*precision) / precision
+}
+
+// recorder collects latencies of the replayed workload.
+type recorder struct {
+	mu       sync.Mutex
+	overall  latencies
+	queries  map[string]*latencies
+	executed int
+}
+
+func newRecorder() *recorder {
+	return &recorder{queries: make(map[string]*latencies)}
+}
+
+func (r *recorder) record(query string, latency time.Duration, err error) {
+	query = strings.Join(strings.Fields(query), " ")
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.executed++
+
+	queryLatencies, ok := r.queries[query]
+	if !ok {
+		queryLatencies = &latencies{}
+		r.queries[query] = queryLatencies
+	}
+
+	if err != nil {
+		r.overall.errors++
+		queryLatencies.errors++
+
+		return
+	}
+
+	ms := float64(latency) / float64(time.Millisecond)
+
+	r.overall.add(ms)
+	queryLatencies.add(ms)
+}
+
+func (r *recorder) progress() (executed, errors int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.executed, r.overall.errors
+}
+
+// report builds latency histograms. Query texts are masked using the provided function.
+func (r *recorder) report(mask func(string) string) (models.LatencyHistogram, []models.QueryLatency) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	queries := make([]models.QueryLatency, 0, len(r.queries))
+
+	for query, queryLatencies := range r.queries {
+		queries = append(queries, models.QueryLatency{Query: mask(query), Latency: queryLatencies.histogram(false)})
+	}
+
+	sort.Slice(queries, func(i, j int) bool {
+		if queries[i].Latency.Total == queries[j].Latency.Total {
+			return queries[i].Query < queries[j].Query
+		}
+
+		return queries[i].Latency.Total > queries[j].Latency.Total
+	})
+
+	if len(queries) > maxReportedQueries {
+		queries = queries[:maxReportedQueries]
+	}
+
+	return r.overall.histogram(true), queries
+}
@@ -0,0 +1,80 @@
+package replay
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+func TestLatencyHistogram(t *testing.T) {
+	l := latencies{}
+
+	for i := 1; i <= 100; i++ {
+		l.add(float64(i))
+	}
+
+	histogram := l.histogram(true)
+
+	assert.Equal(t, 100, histogram.Count)
+	assert.Equal(t, 5050.0, histogram.Total)
+	assert.Equal(t, 1.0, histogram.Min)
+	assert.Equal(t, 100.0, histogram.Max)
+	assert.Equal(t, 50.5, histogram.Mean)
+	assert.Equal(t, 50.0, histogram.P50)
+	assert.Equal(t, 90.0, histogram.P90)
+	assert.Equal(t, 95.0, histogram.P95)
+	assert.Equal(t, 99.0, histogram.P99)
+
+	require.Len(t, histogram.Buckets, len(latencyBounds)+1)
+
+	counts := map[float64]int{}
+	total := 0
+
+	for _, bucket := range histogram.Buckets {
+		counts[bucket.UpperBound] = bucket.Count
+		total += bucket.Count
+	}
+
+	assert.Equal(t, 100, total)
+	assert.Equal(t, 1, counts[1])
+	assert.Equal(t, 1, counts[2.5])
+	assert.Equal(t, 3, counts[5])
+	assert.Equal(t, 25, counts[50])
+	assert.Equal(t, 50, counts[100])
+
+	assert.Equal(t, models.LatencyHistogram{}, (&latencies{}).histogram(true))
+}
+
+func TestRecorder(t *testing.T) {
+	r := newRecorder()
+
+	r.record("select  1", 2*time.Millisecond, nil)
+	r.record("select 1", 4*time.Millisecond, nil)
+	r.record("select\n2", time.Second, nil)
+	r.record("select 3", 0, errors.New("failed"))
+
+	executed, errorsCount := r.progress()
+	assert.Equal(t, 4, executed)
+	assert.Equal(t, 1, errorsCount)
+
+	overall, queries := r.report(strings.ToUpper)
+
+	assert.Equal(t, 3, overall.Count)
+	assert.Equal(t, 1, overall.Errors)
+	assert.Equal(t, 1006.0, overall.Total)
+
+	require.Len(t, queries, 3)
+	assert.Equal(t, "SELECT 2", queries[0].Query)
+	assert.Equal(t, "SELECT 1", queries[1].Query)
+	assert.Equal(t, 2, queries[1].Latency.Count)
+	assert.Equal(t, 3.0, queries[1].Latency.Mean)
+	assert.Empty(t, queries[1].Latency.Buckets)
+	assert.Equal(t, "SELECT 3", queries[2].Query)
+	assert.Equal(t, 1, queries[2].Latency.Errors)
+}
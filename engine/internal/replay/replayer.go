@@ -0,0 +1,354 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/cloning"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/observer"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/client/dblabapi/types"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+const (
+	defaultSpeed       = 1.0
+	defaultConcurrency = 10
+	maxConcurrency     = 100
+)
+
+// Replayer replays recorded workloads against clones with running observation sessions.
+type Replayer struct {
+	cloning  *cloning.Base
+	observer *observer.Observer
+
+	mu      sync.Mutex
+	replays map[string]*replay
+}
+
+// replay describes a workload replay on a clone.
+type replay struct {
+	workload *workload
+	recorder *recorder
+	cancel   context.CancelFunc
+	done     chan struct{}
+
+	mu     sync.Mutex
+	status models.ReplayStatus
+}
+
+// NewReplayer creates a new workload replayer.
+func NewReplayer(cloning *cloning.Base, observer *observer.Observer) *Replayer {
+	return &Replayer{
+		cloning:  cloning,
+		observer: observer,
+		replays:  make(map[string]*replay),
+	}
+}
+
+// Start starts replaying the workload against the clone. The clone must have a running observation session,
+// the latency histograms are stored as an artifact of the session when the replay finishes.
+func (r *Replayer) Start(request *types.StartReplayRequest) (*models.ReplayStatus, error) {
+	if err := applyDefaults(request); err != nil {
+		return nil, err
+	}
+
+	observingClone, err := r.observer.GetObservingClone(request.CloneID)
+	if err != nil {
+		return nil, models.New(models.ErrCodeBadRequest, "observation session of the clone has not been started")
+	}
+
+	session := observingClone.Session()
+	if session == nil || session.IsFinished() {
+		return nil, models.New(models.ErrCodeBadRequest, "observation session of the clone is not running")
+	}
+
+	w, err := buildWorkload(request)
+	if err != nil {
+		return nil, models.New(models.ErrCodeBadRequest, err.Error())
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if current, ok := r.replays[request.CloneID]; ok && current.isRunning() {
+		return nil, models.New(models.ErrCodeBadRequest, "workload replay is already running on the clone")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	rp := &replay{
+		workload: w,
+		recorder: newRecorder(),
+		cancel:   cancel,
+		done:     make(chan struct{}),
+		status: models.ReplayStatus{
+			CloneID:     request.CloneID,
+			SessionID:   session.SessionID,
+			State:       models.ReplayRunning,
+			Speed:       request.Speed,
+			Concurrency: request.Concurrency,
+			StartedAt:   time.Now(),
+		},
+	}
+
+	r.replays[request.CloneID] = rp
+
+	go r.run(ctx, rp, observingClone)
+
+	return rp.getStatus(), nil
+}
+
+// Stop stops the workload replay and waits until the latency histograms are stored.
+func (r *Replayer) Stop(cloneID string) (*models.ReplayStatus, error) {
+	rp, err := r.getReplay(cloneID)
+	if err != nil {
+		return nil, err
+	}
+
+	rp.cancel()
+	<-rp.done
+
+	return rp.getStatus(), nil
+}
+
+// Status returns the state and progress of the last workload replay on the clone.
+func (r *Replayer) Status(cloneID string) (*models.ReplayStatus, error) {
+	rp, err := r.getReplay(cloneID)
+	if err != nil {
+		return nil, err
+	}
+
+	return rp.getStatus(), nil
+}
+
+func (r *Replayer) getReplay(cloneID string) (*replay, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rp, ok := r.replays[cloneID]
+	if !ok {
+		return nil, models.New(models.ErrCodeNotFound, "workload replay not found")
+	}
+
+	return rp, nil
+}
+
+func applyDefaults(request *types.StartReplayRequest) error {
+	if request.CloneID == "" {
+		return models.New(models.ErrCodeBadRequest, "clone ID must be specified")
+	}
+
+	if request.Speed < 0 {
+		return models.New(models.ErrCodeBadRequest, "speed must be positive")
+	}
+
+	if request.Speed == 0 {
+		request.Speed = defaultSpeed
+	}
+
+	if request.Concurrency < 0 || request.Concurrency > maxConcurrency {
+		return models.New(models.ErrCodeBadRequest, fmt.Sprintf("concurrency must be between 1 and %d", maxConcurrency))
+	}
+
+	if request.Concurrency == 0 {
+		request.Concurrency = defaultConcurrency
+	}
+
+	return nil
+}
+
+func buildWorkload(request *types.StartReplayRequest) (*workload, error) {
+	switch request.Format {
+	case types.ReplayFormatCSVLog:
+		return parseCSVLog(strings.NewReader(request.CSVLog))
+
+	case types.ReplayFormatStatements:
+		return sampleWorkload(request.Statements, request.Concurrency)
+
+	default:
+		return nil, errors.Errorf("unknown workload format %q, supported: %s, %s",
+			request.Format, types.ReplayFormatCSVLog, types.ReplayFormatStatements)
+	}
+}
+
+func (r *Replayer) run(ctx context.Context, rp *replay, observingClone *observer.ObservingClone) {
+	defer close(rp.done)
+	defer rp.cancel()
+
+	log.Msg(fmt.Sprintf("Start replaying %d statements in %d sessions on clone %s",
+		rp.workload.statements, len(rp.workload.sessions), rp.status.CloneID))
+
+	err := r.replayWorkload(ctx, rp)
+
+	finishedAt := time.Now()
+
+	rp.mu.Lock()
+	rp.status.FinishedAt = &finishedAt
+
+	switch {
+	case err != nil:
+		rp.status.State = models.ReplayFailed
+		rp.status.Error = err.Error()
+
+	case ctx.Err() != nil:
+		rp.status.State = models.ReplayStopped
+
+	default:
+		rp.status.State = models.ReplayCompleted
+	}
+	rp.mu.Unlock()
+
+	if err := r.storeReport(rp, observingClone); err != nil {
+		log.Err("failed to store replay report: ", err)
+
+		rp.mu.Lock()
+		rp.status.Error = err.Error()
+		rp.mu.Unlock()
+	}
+
+	log.Msg(fmt.Sprintf("Workload replay on clone %s has been finished: %s", rp.status.CloneID, rp.getStatus().State))
+}
+
+// replayWorkload replays sessions concurrently. Statements are executed at their original offsets scaled by the speed.
+func (r *Replayer) replayWorkload(ctx context.Context, rp *replay) error {
+	sessions := make(chan *session)
+	errCh := make(chan error, rp.status.Concurrency)
+	startedAt := time.Now()
+
+	replayCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	wg := sync.WaitGroup{}
+
+	for i := 0; i < rp.status.Concurrency; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for s := range sessions {
+				if err := r.replaySession(replayCtx, rp, s, startedAt); err != nil {
+					errCh <- err
+					cancel()
+
+					return
+				}
+			}
+		}()
+	}
+
+dispatch:
+	for _, s := range rp.workload.sessions {
+		select {
+		case sessions <- s:
+
+		case <-replayCtx.Done():
+			break dispatch
+		}
+	}
+
+	close(sessions)
+	wg.Wait()
+	close(errCh)
+
+	return <-errCh
+}
+
+func (r *Replayer) replaySession(ctx context.Context, rp *replay, s *session, startedAt time.Time) error {
+	db, err := r.cloning.ConnectToClone(ctx, rp.status.CloneID)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		return errors.Wrap(err, "failed to connect to clone")
+	}
+
+	defer func() {
+		if conn, ok := db.(interface{ Close(context.Context) error }); ok {
+			if err := conn.Close(context.Background()); err != nil {
+				log.Err("failed to close connection: ", err)
+			}
+		}
+	}()
+
+	for _, st := range s.statements {
+		delay := time.Until(startedAt.Add(time.Duration(float64(st.offset) / rp.status.Speed)))
+
+		if delay > 0 {
+			select {
+			case <-ctx.Done():
+				return nil
+
+			case <-time.After(delay):
+			}
+		}
+
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		// The simple protocol lets the server infer types of parameters logged as text.
+		args := append([]interface{}{pgx.QuerySimpleProtocol(true)}, st.params...)
+
+		statementStartedAt := time.Now()
+		_, err := db.Exec(ctx, st.query, args...)
+		latency := time.Since(statementStartedAt)
+
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		rp.recorder.record(st.query, latency, err)
+	}
+
+	return nil
+}
+
+func (r *Replayer) storeReport(rp *replay, observingClone *observer.ObservingClone) error {
+	latency, queries := rp.recorder.report(r.observer.MaskQuery)
+
+	report := models.ReplayReport{
+		ReplayStatus: *rp.getStatus(),
+		Latency:      latency,
+		Queries:      queries,
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode replay report")
+	}
+
+	return observingClone.StoreArtifact(observer.ReplayLatencyType, data)
+}
+
+func (rp *replay) isRunning() bool {
+	return rp.getStatus().State == models.ReplayRunning
+}
+
+// getStatus returns a copy of the replay status with the current progress.
+func (rp *replay) getStatus() *models.ReplayStatus {
+	executed, errorsCount := rp.recorder.progress()
+
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+
+	status := rp.status
+	status.Progress = models.ReplayProgress{
+		Sessions:   len(rp.workload.sessions),
+		Statements: rp.workload.statements,
+		Executed:   executed,
+		Errors:     errorsCount,
+		Percent:    round(float64(executed) * 100 / float64(rp.workload.statements)),
+	}
+
+	return &status
+}
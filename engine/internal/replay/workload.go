@@ -0,0 +1,239 @@
+// Package replay provides replaying of recorded workloads against clones.
+package replay
+
+import (
+	"encoding/csv"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/observer"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/client/dblabapi/types"
+)
+
+const (
+	logSeverity     = "LOG"
+	paramsPrefix    = "parameters: "
+	paramNullValue  = "NULL"
+	defaultCalls    = 1
+	maxSampledCalls = 1000000
+)
+
+// statementMessageRe matches log messages produced by log_statement and log_min_duration_statement.
+var statementMessageRe = regexp.MustCompile(`(?s)^(?:duration: [0-9.]+ ms\s+)?(?:statement|execute [^:]*): (.*)$`)
+
+var (
+	logTimeIndex   = observer.CSVLogFieldIndex("log_time")
+	sessionIDIndex = observer.CSVLogFieldIndex("session_id")
+	severityIndex  = observer.CSVLogFieldIndex("error_severity")
+	messageIndex   = observer.CSVLogFieldIndex("message")
+	detailIndex    = observer.CSVLogFieldIndex("detail")
+)
+
+// statement describes a replayed statement.
+type statement struct {
+	offset time.Duration
+	query  string
+	params []interface{}
+}
+
+// session contains statements executed sequentially on a single connection.
+type session struct {
+	id         string
+	statements []statement
+}
+
+// workload contains sessions replayed concurrently.
+type workload struct {
+	sessions   []*session
+	statements int
+}
+
+func (w *workload) add(sessionID string, st statement, sessions map[string]*session) {
+	s, ok := sessions[sessionID]
+	if !ok {
+		s = &session{id: sessionID}
+		sessions[sessionID] = s
+		w.sessions = append(w.sessions, s)
+	}
+
+	s.statements = append(s.statements, st)
+	w.statements++
+}
+
+// parseCSVLog builds a workload from a PostgreSQL CSV log. Statement offsets are relative to the first log entry.
+func parseCSVLog(reader io.Reader) (*workload, error) {
+	csvReader := csv.NewReader(reader)
+
+	// The number of fields depends on the Postgres version, all versions share the same leading fields.
+	csvReader.FieldsPerRecord = -1
+
+	w := &workload{}
+	sessions := make(map[string]*session)
+
+	var startedAt time.Time
+
+	for {
+		entry, err := csvReader.Read()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+
+			return nil, errors.Wrap(err, "failed to read CSV log")
+		}
+
+		if len(entry) <= detailIndex || entry[severityIndex] != logSeverity || observer.IsObserverLogEntry(entry) {
+			continue
+		}
+
+		match := statementMessageRe.FindStringSubmatch(entry[messageIndex])
+		if match == nil {
+			continue
+		}
+
+		logTime, err := time.Parse(observer.CSVLogTimeFormat, entry[logTimeIndex])
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse log time of line %d", lineNumber(csvReader))
+		}
+
+		params, err := parseParams(entry[detailIndex])
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse parameters of line %d", lineNumber(csvReader))
+		}
+
+		if startedAt.IsZero() {
+			startedAt = logTime
+		}
+
+		w.add(entry[sessionIDIndex], statement{offset: logTime.Sub(startedAt), query: match[1], params: params}, sessions)
+	}
+
+	if w.statements == 0 {
+		return nil, errors.New("no statements found in CSV log: check that log_statement or log_min_duration_statement is enabled")
+	}
+
+	return w, nil
+}
+
+func lineNumber(reader *csv.Reader) int {
+	line, _ := reader.FieldPos(0)
+
+	return line
+}
+
+// parseParams parses parameter values logged in the detail field, for example: parameters: $1 = '1', $2 = NULL.
+func parseParams(detail string) ([]interface{}, error) {
+	if !strings.HasPrefix(detail, paramsPrefix) {
+		return nil, nil
+	}
+
+	rest := strings.TrimPrefix(detail, paramsPrefix)
+	params := []interface{}{}
+
+	for rest != "" {
+		separator := strings.Index(rest, " = ")
+		if separator == -1 || !strings.HasPrefix(rest, "$") {
+			return nil, errors.Errorf("invalid parameter: %q", rest)
+		}
+
+		position, err := strconv.Atoi(rest[1:separator])
+		if err != nil || position != len(params)+1 {
+			return nil, errors.Errorf("unexpected parameter: %q", rest[:separator])
+		}
+
+		rest = rest[separator+len(" = "):]
+
+		var value interface{}
+
+		if value, rest, err = parseParamValue(rest); err != nil {
+			return nil, err
+		}
+
+		params = append(params, value)
+		rest = strings.TrimPrefix(rest, ", ")
+	}
+
+	return params, nil
+}
+
+// parseParamValue reads a quoted value or NULL and returns the rest of the string.
+func parseParamValue(s string) (interface{}, string, error) {
+	if strings.HasPrefix(s, paramNullValue) {
+		return nil, s[len(paramNullValue):], nil
+	}
+
+	if !strings.HasPrefix(s, "'") {
+		return nil, "", errors.Errorf("invalid parameter value: %q", s)
+	}
+
+	value := strings.Builder{}
+
+	for i := 1; i < len(s); i++ {
+		if s[i] != '\'' {
+			value.WriteByte(s[i])
+			continue
+		}
+
+		// Quotes inside values are doubled.
+		if i+1 < len(s) && s[i+1] == '\'' {
+			value.WriteByte('\'')
+			i++
+
+			continue
+		}
+
+		return value.String(), s[i+1:], nil
+	}
+
+	return nil, "", errors.Errorf("unterminated parameter value: %q", s)
+}
+
+// sampleWorkload builds a workload from statement samples. Calls of the statements are interleaved
+// and distributed among the sessions, so the statements are executed without delays.
+func sampleWorkload(samples []types.ReplayStatement, sessionsNumber int) (*workload, error) {
+	calls := make([]int, len(samples))
+	total := 0
+
+	for i, sample := range samples {
+		if strings.TrimSpace(sample.Query) == "" {
+			return nil, errors.Errorf("statement #%d is empty", i+1)
+		}
+
+		calls[i] = sample.Calls
+		if calls[i] <= 0 {
+			calls[i] = defaultCalls
+		}
+
+		total += calls[i]
+	}
+
+	if total == 0 {
+		return nil, errors.New("no statements to replay")
+	}
+
+	if total > maxSampledCalls {
+		return nil, errors.Errorf("too many calls to replay: %d, maximum: %d", total, maxSampledCalls)
+	}
+
+	w := &workload{}
+	sessions := make(map[string]*session, sessionsNumber)
+
+	for w.statements < total {
+		for i, sample := range samples {
+			if calls[i] == 0 {
+				continue
+			}
+
+			calls[i]--
+
+			w.add(strconv.Itoa(w.statements%sessionsNumber), statement{query: sample.Query, params: sample.Params}, sessions)
+		}
+	}
+
+	return w, nil
+}
@@ -0,0 +1,102 @@
+package replay
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/client/dblabapi/types"
+)
+
+const csvLog = `2022-05-05 10:00:00.000 UTC,"app","db",101,"10.0.0.1:5000",6273a0a0.65,1,"idle",2022-05-05 09:59:00 UTC,3/1,0,LOG,00000,"statement: begin",,,,,,,,,"app"
+2022-05-05 10:00:00.500 UTC,"app","db",102,"10.0.0.2:5000",6273a0a0.66,1,"SELECT",2022-05-05 09:59:00 UTC,4/1,0,LOG,00000,"duration: 0.512 ms  execute <unnamed>: select * from users
+where id = $1 and name = $2","parameters: $1 = '42', $2 = 'O''Brien'",,,,,,,,"app"
+2022-05-05 10:00:01.000 UTC,"app","db",101,"10.0.0.1:5000",6273a0a0.65,2,"idle",2022-05-05 09:59:00 UTC,3/1,0,LOG,00000,"statement: commit",,,,,,,,,"app"
+2022-05-05 10:00:01.100 UTC,"app","db",102,"10.0.0.2:5000",6273a0a0.66,2,"SELECT",2022-05-05 09:59:00 UTC,4/1,0,ERROR,42P01,"relation ""missing"" does not exist",,,,,,"select * from missing",15,,"app"
+2022-05-05 10:00:01.200 UTC,"postgres","db",103,"[local]",6273a0a0.67,1,"idle",2022-05-05 09:59:00 UTC,5/1,0,LOG,00000,"statement: select 1",,,,,,,,,"observer"
+2022-05-05 10:00:01.300 UTC,"app","db",102,"10.0.0.2:5000",6273a0a0.66,3,"idle",2022-05-05 09:59:00 UTC,4/1,0,LOG,00000,"duration: 12.000 ms",,,,,,,,,"app"
+`
+
+func TestParseCSVLog(t *testing.T) {
+	w, err := parseCSVLog(strings.NewReader(csvLog))
+	require.NoError(t, err)
+
+	require.Len(t, w.sessions, 2)
+	assert.Equal(t, 3, w.statements)
+
+	assert.Equal(t, "6273a0a0.65", w.sessions[0].id)
+	assert.Equal(t, []statement{
+		{offset: 0, query: "begin", params: nil},
+		{offset: time.Second, query: "commit", params: nil},
+	}, w.sessions[0].statements)
+
+	assert.Equal(t, "6273a0a0.66", w.sessions[1].id)
+	assert.Equal(t, []statement{{
+		offset: 500 * time.Millisecond,
+		query:  "select * from users\nwhere id = $1 and name = $2",
+		params: []interface{}{"42", "O'Brien"},
+	}}, w.sessions[1].statements)
+}
+
+func TestParseCSVLogWithoutStatements(t *testing.T) {
+	_, err := parseCSVLog(strings.NewReader(""))
+	assert.Error(t, err)
+
+	_, err = parseCSVLog(strings.NewReader(`"broken`))
+	assert.Error(t, err)
+}
+
+func TestParseParams(t *testing.T) {
+	testCases := []struct {
+		detail string
+		params []interface{}
+	}{
+		{detail: "", params: nil},
+		{detail: "some detail", params: nil},
+		{detail: "parameters: $1 = '1'", params: []interface{}{"1"}},
+		{detail: "parameters: $1 = NULL, $2 = 'a, $3 = ''b'''", params: []interface{}{nil, "a, $3 = 'b'"}},
+	}
+
+	for _, tc := range testCases {
+		params, err := parseParams(tc.detail)
+		require.NoError(t, err)
+		assert.Equal(t, tc.params, params)
+	}
+
+	for _, detail := range []string{"parameters: $2 = '1'", "parameters: $1 = 1", "parameters: $1 = 'unterminated", "parameters: 1"} {
+		_, err := parseParams(detail)
+		assert.Error(t, err, detail)
+	}
+}
+
+func TestSampleWorkload(t *testing.T) {
+	w, err := sampleWorkload([]types.ReplayStatement{
+		{Query: "select 1", Calls: 3},
+		{Query: "select $1::int", Params: []interface{}{2}},
+	}, 2)
+	require.NoError(t, err)
+
+	assert.Equal(t, 4, w.statements)
+	require.Len(t, w.sessions, 2)
+
+	queries := func(s *session) []string {
+		result := []string{}
+		for _, st := range s.statements {
+			result = append(result, st.query)
+		}
+
+		return result
+	}
+
+	assert.Equal(t, []string{"select 1", "select 1"}, queries(w.sessions[0]))
+	assert.Equal(t, []string{"select $1::int", "select 1"}, queries(w.sessions[1]))
+
+	_, err = sampleWorkload([]types.ReplayStatement{{Query: " "}}, 1)
+	assert.Error(t, err)
+
+	_, err = sampleWorkload(nil, 1)
+	assert.Error(t, err)
+}
@@ -59,6 +59,10 @@ const (
 	DBLabEmbeddedUILabel = "dblab_embedded_ui"
 	// DBLabFoundationLabel defines a label value to mark foundation containers.
 	DBLabFoundationLabel = "dblab_foundation"
+	// DBLabUpgradeLabel defines a label value for containers upgrading clones with pg_upgrade.
+	DBLabUpgradeLabel = "dblab_upgrade"
+	// DBLabInitLabel defines a label value for containers running commands of clone init profiles.
+	DBLabInitLabel = "dblab_init"
 
 	// DBLabRunner defines a label to mark runner containers.
 	DBLabRunner = "dblab_runner"
@@ -28,6 +28,7 @@ import (
 
 const (
 	extensionQuery = "select jsonb_object_agg(name, default_version) from pg_available_extensions"
+	versionQuery   = "select current_setting('server_version_num')::int"
 
 	port     = "5432"
 	username = "postgres"
@@ -37,15 +38,26 @@ const (
 	foundationName = "dblab_foundation_"
 
 	defaultRetries = 10
+
+	// versionNumDivisor splits server_version_num into the major version and the rest.
+	versionNumDivisor = 10000
+
+	// legacyMinorDivisor extracts the second part of the major version before Postgres 10, for example, 6 of 90624.
+	legacyMinorDivisor = 100
+
+	// modernVersionNum defines server_version_num of Postgres 10 that has a single-number major version.
+	modernVersionNum = 100000
 )
 
 // ImageContent keeps the content lists from the foundation image.
 type ImageContent struct {
-	engineProps global.EngineProps
-	isReady     bool
-	extensions  map[string]string
-	locales     map[string]struct{}
-	databases   map[string]struct{}
+	engineProps   global.EngineProps
+	containerName string
+	isReady       bool
+	pgVersion     float64
+	extensions    map[string]string
+	locales       map[string]struct{}
+	databases     map[string]struct{}
 }
 
 // IsReady reports if the ImageContent has collected details about the current image.
@@ -56,13 +68,25 @@ func (i *ImageContent) IsReady() bool {
 // NewImageContent creates a new ImageContent.
 func NewImageContent(engineProps global.EngineProps) *ImageContent {
 	return &ImageContent{
-		engineProps: engineProps,
-		extensions:  make(map[string]string, 0),
-		locales:     make(map[string]struct{}, 0),
-		databases:   make(map[string]struct{}, 0),
+		engineProps:   engineProps,
+		containerName: getFoundationName(engineProps.InstanceID),
+		extensions:    make(map[string]string, 0),
+		locales:       make(map[string]struct{}, 0),
+		databases:     make(map[string]struct{}, 0),
 	}
 }
 
+// SetContainerName sets the name of the container used to collect the image content.
+// It allows collecting the content of several images without clashing with the foundation container.
+func (i *ImageContent) SetContainerName(containerName string) {
+	i.containerName = containerName
+}
+
+// PGVersion provides the major Postgres version of the image.
+func (i *ImageContent) PGVersion() float64 {
+	return i.pgVersion
+}
+
 // Extensions provides list of Postgres extensions from the foundation image.
 func (i *ImageContent) Extensions() map[string]string {
 	return i.extensions
@@ -118,14 +142,14 @@ func getFoundationName(instanceID string) string {
 }
 
 func (i *ImageContent) collectImageContent(ctx context.Context, docker *client.Client, dockerImage string) error {
-	containerID, err := createContainer(ctx, docker, dockerImage, i.engineProps)
+	containerID, err := createContainer(ctx, docker, dockerImage, i.containerName, i.engineProps)
 	if err != nil {
 		return fmt.Errorf("failed to create a Docker container: %w", err)
 	}
 
 	defer tools.RemoveContainer(ctx, docker, containerID, 0)
 
-	if err := i.collectExtensions(ctx, i.engineProps.InstanceID); err != nil {
+	if err := i.collectExtensions(ctx); err != nil {
 		return fmt.Errorf("failed to collect extensions from the image %s: %w", dockerImage, err)
 	}
 
@@ -136,12 +160,22 @@ func (i *ImageContent) collectImageContent(ctx context.Context, docker *client.C
 	return nil
 }
 
-func (i *ImageContent) collectExtensions(ctx context.Context, instanceID string) error {
-	conn, err := pgx.Connect(ctx, ConnectionString(getFoundationName(instanceID), port, username, dbname, password))
+func (i *ImageContent) collectExtensions(ctx context.Context) error {
+	conn, err := pgx.Connect(ctx, ConnectionString(i.containerName, port, username, dbname, password))
 	if err != nil {
 		return fmt.Errorf("failed to connect: %w", err)
 	}
 
+	defer func() { _ = conn.Close(ctx) }()
+
+	var versionNum int
+
+	if err = conn.QueryRow(ctx, versionQuery).Scan(&versionNum); err != nil {
+		return fmt.Errorf("failed to get Postgres version: %w", err)
+	}
+
+	i.pgVersion = majorVersion(versionNum)
+
 	var row []byte
 
 	if err = conn.QueryRow(ctx, extensionQuery).Scan(&row); err != nil {
@@ -159,6 +193,17 @@ func (i *ImageContent) collectExtensions(ctx context.Context, instanceID string)
 	return nil
 }
 
+// majorVersion converts server_version_num to the major version in the PG_VERSION format, for example, 9.6 or 16.
+func majorVersion(versionNum int) float64 {
+	if versionNum >= modernVersionNum {
+		return float64(versionNum / versionNumDivisor)
+	}
+
+	legacyMinor := versionNum % versionNumDivisor / legacyMinorDivisor
+
+	return float64(versionNum/versionNumDivisor*10+legacyMinor) / 10
+}
+
 func (i *ImageContent) collectLocales(ctx context.Context, docker *client.Client, containerID string) error {
 	out, err := getLocales(ctx, docker, containerID)
 	if err != nil {
@@ -179,7 +224,8 @@ func (i *ImageContent) collectLocales(ctx context.Context, docker *client.Client
 	return nil
 }
 
-func createContainer(ctx context.Context, docker *client.Client, image string, props global.EngineProps) (string, error) {
+func createContainer(ctx context.Context, docker *client.Client, image, containerName string,
+	props global.EngineProps) (string, error) {
 	if err := dockerTools.PrepareImage(ctx, docker, image); err != nil {
 		return "", fmt.Errorf("failed to prepare Docker image: %w", err)
 	}
@@ -198,8 +244,6 @@ func createContainer(ctx context.Context, docker *client.Client, image string, p
 			health.OptionInterval(health.DefaultRestoreInterval), health.OptionRetries(defaultRetries)),
 	}
 
-	containerName := getFoundationName(props.InstanceID)
-
 	containerID, err := tools.CreateContainerIfMissing(ctx, docker, containerName, containerConf, &container.HostConfig{})
 	if err != nil {
 		return "", fmt.Errorf("failed to create container %q %w", containerName, err)
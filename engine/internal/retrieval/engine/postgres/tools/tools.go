@@ -615,6 +615,55 @@ func processAttachResponse(ctx context.Context, reader io.Reader) ([]byte, error
 	return bytes.TrimSpace(outBuf.Bytes()), nil
 }
 
+// ExecCommandWithStreams runs command in Docker container, copies its stdout and stderr to the provided writers
+// while the command is running and returns the exit code of the command.
+func ExecCommandWithStreams(ctx context.Context, docker *client.Client, containerID string, execCfg types.ExecConfig,
+	stdout, stderr io.Writer) (int, error) {
+	execCfg.AttachStdout = true
+	execCfg.AttachStderr = true
+
+	execCommand, err := docker.ContainerExecCreate(ctx, containerID, execCfg)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to create an exec command")
+	}
+
+	attachResponse, err := docker.ContainerExecAttach(ctx, execCommand.ID, types.ExecStartCheck{})
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to attach to exec command")
+	}
+
+	defer attachResponse.Close()
+
+	outputDone := make(chan error, 1)
+
+	go func() {
+		// StdCopy de-multiplexes the stream into two writers.
+		_, err := stdcopy.StdCopy(stdout, stderr, attachResponse.Reader)
+		outputDone <- err
+	}()
+
+	select {
+	case err := <-outputDone:
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to copy output")
+		}
+
+	case <-ctx.Done():
+		// Close the connection and wait for copying to stop, so the writers are not used after returning.
+		attachResponse.Close()
+		<-outputDone
+
+		return 0, ctx.Err()
+	}
+
+	inspection, err := docker.ContainerExecInspect(ctx, execCommand.ID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to inspect an exec process: %w", err)
+	}
+
+	return inspection.ExitCode, nil
+}
+
 // CreateContainerIfMissing create a new container if there is no other container with the same name, if the container
 // exits returns existing container id.
 func CreateContainerIfMissing(ctx context.Context, docker *client.Client, containerName string,
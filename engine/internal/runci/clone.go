@@ -15,6 +15,7 @@ import (
 
 	"gitlab.com/postgres-ai/database-lab/v3/pkg/client/dblabapi"
 	"gitlab.com/postgres-ai/database-lab/v3/pkg/client/dblabapi/types"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
 	dblabmodels "gitlab.com/postgres-ai/database-lab/v3/pkg/models"
 )
 
@@ -54,6 +55,13 @@ func createDBLabClone(ctx context.Context, dle *dblabapi.Client, opts cloneOpts)
 
 	clone, err := dle.CreateClone(ctx, clientRequest)
 	if err != nil {
+		if clone != nil && clone.ID != "" {
+			// The clone has not become ready, so it cannot be used for the migration check.
+			if destroyErr := dle.DestroyClone(context.Background(), clone.ID); destroyErr != nil {
+				log.Err("failed to destroy the clone that has not become ready: ", destroyErr)
+			}
+		}
+
 		return nil, errors.Wrap(err, "failed to create a new clone")
 	}
 
@@ -0,0 +1,157 @@
+package runci
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/pkg/errors"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/tools"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+)
+
+// Command describes a migration command executed in the runner container.
+// A plain JSON string is decoded as a command with default options.
+type Command struct {
+	Command         string `json:"command"`
+	Timeout         uint   `json:"timeout"`
+	ContinueOnError bool   `json:"continue_on_error"`
+}
+
+// UnmarshalJSON decodes a command defined either as a string or as an object.
+func (c *Command) UnmarshalJSON(data []byte) error {
+	var command string
+
+	if err := json.Unmarshal(data, &command); err == nil {
+		*c = Command{Command: command}
+		return nil
+	}
+
+	type commandOptions Command
+
+	options := commandOptions{}
+
+	if err := json.Unmarshal(data, &options); err != nil {
+		return err
+	}
+
+	*c = Command(options)
+
+	return nil
+}
+
+// CommandOutput contains the result of a migration command.
+type CommandOutput struct {
+	Command    string    `json:"command"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	ExitCode   int       `json:"exit_code"`
+	Stdout     string    `json:"stdout"`
+	Stderr     string    `json:"stderr"`
+	Error      string    `json:"error,omitempty"`
+	TimedOut   bool      `json:"timed_out,omitempty"`
+}
+
+// Failed checks if the command has not been completed successfully.
+func (o CommandOutput) Failed() bool {
+	return o.ExitCode != 0 || o.Error != ""
+}
+
+// commandExecutor runs a shell command in the runner container and copies its output to the writers.
+type commandExecutor func(ctx context.Context, cmd []string, stdout, stderr io.Writer) (int, error)
+
+func (s *Server) containerExecutor(containerID string) commandExecutor {
+	return func(ctx context.Context, cmd []string, stdout, stderr io.Writer) (int, error) {
+		return tools.ExecCommandWithStreams(ctx, s.docker, containerID, types.ExecConfig{Cmd: cmd}, stdout, stderr)
+	}
+}
+
+// execCommands runs migration commands one by one. Outputs of all executed commands are returned
+// even if the execution stops because a command fails.
+func execCommands(ctx context.Context, execute commandExecutor, commands []Command, output io.Writer) ([]CommandOutput, error) {
+	outputs := make([]CommandOutput, 0, len(commands))
+
+	for _, command := range commands {
+		log.Msg("Running command: ", command.Command)
+
+		commandOutput := execCommand(ctx, execute, command, output)
+		outputs = append(outputs, commandOutput)
+
+		if !commandOutput.Failed() {
+			log.Msg("Command has been executed: ", command.Command)
+			continue
+		}
+
+		if ctx.Err() != nil {
+			return outputs, ctx.Err()
+		}
+
+		if !command.ContinueOnError {
+			return outputs, errors.Errorf("failed to execute command %q: %s", command.Command, describeResult(commandOutput))
+		}
+
+		log.Msg(fmt.Sprintf("Command %q failed (%s), continue", command.Command, describeResult(commandOutput)))
+	}
+
+	return outputs, nil
+}
+
+func execCommand(ctx context.Context, execute commandExecutor, command Command, output io.Writer) CommandOutput {
+	var stdout, stderr bytes.Buffer
+
+	commandCtx := ctx
+
+	if command.Timeout > 0 {
+		var cancel context.CancelFunc
+
+		commandCtx, cancel = context.WithTimeout(ctx, time.Duration(command.Timeout)*time.Second)
+		defer cancel()
+	}
+
+	writeOutput(output, "$ %s\n", command.Command)
+
+	commandOutput := CommandOutput{
+		Command:   command.Command,
+		StartedAt: time.Now(),
+	}
+
+	exitCode, err := execute(commandCtx, []string{"/bin/sh", "-c", command.Command},
+		io.MultiWriter(&stdout, output), io.MultiWriter(&stderr, output))
+
+	commandOutput.FinishedAt = time.Now()
+	commandOutput.ExitCode = exitCode
+	commandOutput.Stdout = stdout.String()
+	commandOutput.Stderr = stderr.String()
+
+	if err != nil {
+		commandOutput.Error = err.Error()
+
+		if ctx.Err() == nil && errors.Is(commandCtx.Err(), context.DeadlineExceeded) {
+			commandOutput.TimedOut = true
+			commandOutput.Error = fmt.Sprintf("command timed out after %ds", command.Timeout)
+		}
+	}
+
+	writeOutput(output, "[%s] %s\n", commandOutput.FinishedAt.Format(time.RFC3339), describeResult(commandOutput))
+
+	return commandOutput
+}
+
+func describeResult(commandOutput CommandOutput) string {
+	if commandOutput.Error != "" {
+		return commandOutput.Error
+	}
+
+	return fmt.Sprintf("exit code: %d", commandOutput.ExitCode)
+}
+
+func writeOutput(output io.Writer, format string, args ...interface{}) {
+	if _, err := fmt.Fprintf(output, format, args...); err != nil {
+		log.Err("failed to write run output: ", err)
+	}
+}
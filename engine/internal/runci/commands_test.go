@@ -0,0 +1,84 @@
+package runci
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommandUnmarshal(t *testing.T) {
+	commands := []Command{}
+
+	err := json.Unmarshal([]byte(`["sqitch deploy", {"command": "sqitch verify", "timeout": 30, "continue_on_error": true}]`), &commands)
+	require.NoError(t, err)
+
+	assert.Equal(t, []Command{
+		{Command: "sqitch deploy"},
+		{Command: "sqitch verify", Timeout: 30, ContinueOnError: true},
+	}, commands)
+
+	err = json.Unmarshal([]byte(`[1]`), &commands)
+	assert.Error(t, err)
+}
+
+// shellMock emulates a shell: "fail" exits with code 1, "hang" runs until the context is canceled.
+func shellMock(ctx context.Context, cmd []string, stdout, stderr io.Writer) (int, error) {
+	switch command := cmd[len(cmd)-1]; command {
+	case "fail":
+		_, _ = fmt.Fprint(stderr, "error")
+		return 1, nil
+
+	case "hang":
+		<-ctx.Done()
+		return 0, ctx.Err()
+
+	default:
+		_, _ = fmt.Fprint(stdout, command)
+		return 0, nil
+	}
+}
+
+func TestExecCommands(t *testing.T) {
+	output := &bytes.Buffer{}
+
+	outputs, err := execCommands(context.Background(), shellMock, []Command{
+		{Command: "first"},
+		{Command: "fail", ContinueOnError: true},
+		{Command: "hang", Timeout: 1, ContinueOnError: true},
+		{Command: "fail"},
+		{Command: "skipped"},
+	}, output)
+	require.EqualError(t, err, `failed to execute command "fail": exit code: 1`)
+	require.Len(t, outputs, 4)
+
+	assert.Equal(t, "first", outputs[0].Stdout)
+	assert.False(t, outputs[0].Failed())
+	assert.False(t, outputs[0].FinishedAt.Before(outputs[0].StartedAt))
+
+	assert.Equal(t, 1, outputs[1].ExitCode)
+	assert.Equal(t, "error", outputs[1].Stderr)
+	assert.True(t, outputs[1].Failed())
+
+	assert.True(t, outputs[2].TimedOut)
+	assert.Equal(t, "command timed out after 1s", outputs[2].Error)
+
+	assert.Contains(t, output.String(), "$ first\nfirst")
+	assert.Contains(t, output.String(), "exit code: 1\n")
+	assert.NotContains(t, output.String(), "skipped")
+}
+
+func TestExecCommandsCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	outputs, err := execCommands(ctx, shellMock, []Command{{Command: "hang", ContinueOnError: true}, {Command: "next"}}, io.Discard)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Len(t, outputs, 1)
+	assert.False(t, outputs[0].TimedOut)
+}
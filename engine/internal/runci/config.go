@@ -27,6 +27,8 @@ type Config struct {
 	Platform platform.Config `yaml:"platform"`
 	Source   source.Config   `yaml:"source"`
 	Runner   Runner          `yaml:"runner"`
+	Report   Report          `yaml:"report"`
+	Queue    Queue           `yaml:"queue"`
 }
 
 // App defines a general configuration of the application.
@@ -50,6 +52,29 @@ type Runner struct {
 	Image string `yaml:"image"`
 }
 
+// Queue defines options of the migration run queue.
+type Queue struct {
+	Concurrency           int    `yaml:"concurrency"`
+	RepositoryConcurrency int    `yaml:"repositoryConcurrency"`
+	HistoryDir            string `yaml:"historyDir"`
+	HistoryLimit          int    `yaml:"historyLimit"`
+}
+
+// Report defines how migration results are posted back to the code provider.
+type Report struct {
+	Comment       bool            `yaml:"comment"`
+	CommitStatus  bool            `yaml:"commitStatus"`
+	StatusContext string          `yaml:"statusContext"`
+	ArtifactsURL  string          `yaml:"artifactsURL"`
+	Templates     ReportTemplates `yaml:"templates"`
+}
+
+// ReportTemplates contains custom templates of report messages.
+type ReportTemplates struct {
+	Comment           string `yaml:"comment"`
+	StatusDescription string `yaml:"statusDescription"`
+}
+
 // LoadConfiguration loads configuration of DB Migration Checker.
 func LoadConfiguration() (*Config, error) {
 	configPath, err := util.GetConfigPath(configFilename)
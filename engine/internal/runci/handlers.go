@@ -5,6 +5,7 @@
 package runci
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -12,11 +13,13 @@ import (
 	"net/http"
 	"os"
 	"path"
+	"strconv"
+	"time"
 
-	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/mount"
 	"github.com/docker/docker/api/types/network"
+	"github.com/gorilla/mux"
 	"github.com/pkg/errors"
 	"github.com/rs/xid"
 
@@ -37,6 +40,8 @@ import (
 const (
 	repoDirInRunner    = "/repo"
 	outputFileTemplate = "repo_%s.zip"
+
+	logsPollingInterval = time.Second
 )
 
 // StartMigrationRequest defines a request to start migration check.
@@ -46,7 +51,7 @@ type StartMigrationRequest struct {
 	UsernameFull      string             `json:"username_full"`
 	UsernameLink      string             `json:"username_link"`
 	DBName            string             `json:"db_name"`
-	Commands          []string           `json:"commands"`
+	Commands          []Command          `json:"commands"`
 	MigrationEnvs     []string           `json:"migration_envs"`
 	ObservationConfig dblab_types.Config `json:"observation_config"`
 	KeepClone         bool               `json:"keep_clone"`
@@ -54,11 +59,13 @@ type StartMigrationRequest struct {
 
 // MigrationResult provides the results of the executed migration.
 type MigrationResult struct {
-	CloneID string            `json:"clone_id"`
-	Session *observer.Session `json:"session"`
+	CloneID      string                    `json:"clone_id"`
+	Session      *observer.Session         `json:"session"`
+	RunnerOutput []CommandOutput           `json:"runner_output"`
+	Summary      *observer.SummaryArtifact `json:"summary,omitempty"`
 }
 
-// runMigration runs database migration.
+// runMigration runs database migration and waits for its completion.
 func (s *Server) runMigration(w http.ResponseWriter, r *http.Request) {
 	request := StartMigrationRequest{}
 
@@ -69,16 +76,196 @@ func (s *Server) runMigration(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	runID := xid.New().String()
-	outputFile := path.Join(source.RepoDir, fmt.Sprintf(outputFileTemplate, runID))
+	j, err := s.queue.enqueue(xid.New().String(), request)
+	if err != nil {
+		api.SendError(w, r, err)
+		return
+	}
 
-	if err := s.codeProvider.Download(context.Background(), request.Source, outputFile); err != nil {
-		log.Errf("failed to download: %v", err)
+	<-j.done
+
+	run, ok := s.runs.get(j.runID)
+	if !ok {
+		api.SendError(w, r, errRunNotFound)
+		return
+	}
+
+	if run.State != RunStateCompleted {
+		api.SendError(w, r, errors.Errorf("migration run %s %s: %s", run.ID, run.State, run.Error))
+		return
+	}
+
+	migrationResponse, err := json.Marshal(run.Result)
+	if err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(migrationResponse)
+}
+
+// startMigration queues a migration run and returns its ID immediately.
+func (s *Server) startMigration(w http.ResponseWriter, r *http.Request) {
+	request := StartMigrationRequest{}
+
+	if err := readJSON(r, &request); err != nil {
+		log.Errf("failed to read request: %v", err)
 		api.SendBadRequestError(w, r, err.Error())
 
 		return
 	}
 
+	j, err := s.queue.enqueue(xid.New().String(), request)
+	if err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+
+	run, ok := s.runs.get(j.runID)
+	if !ok {
+		api.SendError(w, r, errRunNotFound)
+		return
+	}
+
+	if err := api.WriteJSON(w, http.StatusAccepted, run); err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+}
+
+// listMigrations returns the history of migration runs.
+func (s *Server) listMigrations(w http.ResponseWriter, r *http.Request) {
+	runs := s.runs.list(r.URL.Query().Get("repository"))
+
+	if err := api.WriteJSON(w, http.StatusOK, runs); err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+}
+
+// getMigration reports the state and output of the migration run.
+func (s *Server) getMigration(w http.ResponseWriter, r *http.Request) {
+	run, ok := s.runs.get(mux.Vars(r)["id"])
+	if !ok {
+		api.SendNotFoundError(w, r)
+		return
+	}
+
+	output, err := os.ReadFile(s.runs.outputPath(run.ID))
+	if err != nil && !os.IsNotExist(err) {
+		api.SendError(w, r, errors.Wrap(err, "failed to read run output"))
+		return
+	}
+
+	run.Output = string(output)
+
+	if err := api.WriteJSON(w, http.StatusOK, run); err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+}
+
+// cancelMigration cancels a queued or running migration run.
+func (s *Server) cancelMigration(w http.ResponseWriter, r *http.Request) {
+	runID := mux.Vars(r)["id"]
+
+	if err := s.queue.cancel(runID); err != nil {
+		switch {
+		case errors.Is(err, errRunNotFound):
+			api.SendNotFoundError(w, r)
+
+		case errors.Is(err, errRunFinished):
+			api.SendBadRequestError(w, r, err.Error())
+
+		default:
+			api.SendError(w, r, err)
+		}
+
+		return
+	}
+
+	log.Dbg(fmt.Sprintf("Migration run %s is being canceled", runID))
+}
+
+// streamMigrationLogs streams the runner output until the migration run finishes.
+func (s *Server) streamMigrationLogs(w http.ResponseWriter, r *http.Request) {
+	runID := mux.Vars(r)["id"]
+
+	if _, ok := s.runs.get(runID); !ok {
+		api.SendNotFoundError(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+
+	flusher, _ := w.(http.Flusher)
+	ticker := time.NewTicker(logsPollingInterval)
+
+	defer ticker.Stop()
+
+	var offset int64
+
+	for {
+		// Check the state before reading to not miss the output written right before the run finishes.
+		active := s.queue.isActive(runID)
+
+		written, err := copyOutput(w, s.runs.outputPath(runID), offset)
+		if err != nil {
+			log.Err("failed to stream run output: ", err)
+			return
+		}
+
+		offset += written
+
+		if flusher != nil {
+			flusher.Flush()
+		}
+
+		if !active {
+			return
+		}
+
+		select {
+		case <-ticker.C:
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// copyOutput copies the content of the output file starting from the offset.
+func copyOutput(w io.Writer, outputPath string, offset int64) (int64, error) {
+	outputFile, err := os.Open(outputPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+
+		return 0, err
+	}
+
+	defer func() { _ = outputFile.Close() }()
+
+	if _, err := outputFile.Seek(offset, io.SeekStart); err != nil {
+		return 0, err
+	}
+
+	return io.Copy(w, outputFile)
+}
+
+// executeRun downloads the source code and checks the migration.
+func (s *Server) executeRun(ctx context.Context, runID string, request StartMigrationRequest,
+	output io.Writer) (*MigrationResult, error) {
+	outputFile := path.Join(source.RepoDir, fmt.Sprintf(outputFileTemplate, runID))
+
+	if err := s.codeProvider.Download(ctx, request.Source, outputFile); err != nil {
+		log.Errf("failed to download: %v", err)
+		return nil, errors.Wrap(err, "failed to download source code")
+	}
+
 	defer func() {
 		if err := os.Remove(outputFile); err != nil {
 			log.Dbg("failed to remove file: ", err)
@@ -87,8 +274,7 @@ func (s *Server) runMigration(w http.ResponseWriter, r *http.Request) {
 
 	sourceCodeDir, err := s.codeProvider.Extract(outputFile)
 	if err != nil {
-		api.SendError(w, r, err)
-		return
+		return nil, errors.Wrap(err, "failed to extract source code")
 	}
 
 	defer func() {
@@ -97,27 +283,47 @@ func (s *Server) runMigration(w http.ResponseWriter, r *http.Request) {
 		}
 	}()
 
+	return s.checkMigration(ctx, request, runID, sourceCodeDir, output)
+}
+
+// checkMigration runs migration commands against a new clone and reports the result if reporting is enabled.
+func (s *Server) checkMigration(ctx context.Context, request StartMigrationRequest, runID, sourceCodeDir string,
+	output io.Writer) (*MigrationResult, error) {
+	if s.reporter != nil {
+		s.reporter.ReportStart(ctx, request.Source)
+	}
+
+	migrationResult, err := s.migrate(ctx, request, runID, sourceCodeDir, output)
+
+	if s.reporter != nil {
+		// The run context may be canceled, so report using a separate one.
+		s.reportResult(context.Background(), request, migrationResult, err)
+	}
+
+	return migrationResult, err
+}
+
+func (s *Server) migrate(ctx context.Context, request StartMigrationRequest, runID, sourceCodeDir string,
+	output io.Writer) (*MigrationResult, error) {
 	volumes := map[string]string{
 		sourceCodeDir: repoDirInRunner,
 	}
 
 	log.Dbg(volumes)
 
-	clone, err := createDBLabClone(context.Background(), s.dle, cloneOpts{
+	clone, err := createDBLabClone(ctx, s.dle, cloneOpts{
 		username: request.Username,
 		dbname:   request.DBName,
 	})
 	if err != nil {
-		api.SendError(w, r, err)
-		return
+		return nil, err
 	}
 
 	log.Dbg("Clone: ", clone)
 
-	dleHealth, err := s.dle.Health(context.Background())
+	dleHealth, err := s.dle.Health(ctx)
 	if err != nil {
-		api.SendError(w, r, err)
-		return
+		return nil, err
 	}
 
 	tags := map[string]string{
@@ -134,38 +340,82 @@ func (s *Server) runMigration(w http.ResponseWriter, r *http.Request) {
 		"dle_version":   dleHealth.Version,
 	}
 
-	session, err := s.runCommands(context.Background(), clone, runID, volumes, tags, request.Commands, request.MigrationEnvs,
-		request.ObservationConfig)
-	if err != nil {
-		api.SendError(w, r, err)
-		return
+	session, runnerOutput, err := s.runCommands(ctx, clone, runID, volumes, tags, request.Commands, request.MigrationEnvs,
+		request.ObservationConfig, output)
+
+	migrationResult := &MigrationResult{
+		CloneID:      clone.ID,
+		Session:      session,
+		RunnerOutput: runnerOutput,
 	}
 
-	if !request.KeepClone {
-		if err := s.dle.DestroyClone(context.Background(), clone.ID); err != nil {
-			log.Errf("failed to destroy clone: %v", err)
+	// Clones of failed runs are kept for investigation unless the run has been canceled.
+	destroyClone := !request.KeepClone && (err == nil || ctx.Err() != nil)
+
+	// The run context may be canceled, so the clone is released using a separate one.
+	s.releaseClone(context.Background(), migrationResult, destroyClone)
+
+	return migrationResult, err
+}
+
+// releaseClone collects the observation summary of the run and destroys the clone if requested.
+// The summary is collected first, because observation artifacts are removed together with the clone.
+func (s *Server) releaseClone(ctx context.Context, result *MigrationResult, destroyClone bool) {
+	if result.Session != nil {
+		summary, err := s.dle.SummaryObservation(ctx, result.CloneID, strconv.FormatUint(result.Session.SessionID, 10))
+		if err != nil {
+			log.Err("failed to get observation summary: ", err)
+		} else {
+			result.Summary = summary
 		}
 	}
 
-	migrationResult := MigrationResult{
-		CloneID: clone.ID,
-		Session: session,
+	if !destroyClone {
+		return
 	}
 
-	migrationResponse, err := json.Marshal(migrationResult)
-	if err != nil {
-		api.SendError(w, r, err)
-		return
+	if err := s.dle.DestroyClone(ctx, result.CloneID); err != nil {
+		log.Errf("failed to destroy clone: %v", err)
 	}
+}
 
-	w.WriteHeader(http.StatusOK)
-	_, _ = w.Write(migrationResponse)
+// reportResult posts the migration result to the pull/merge request.
+func (s *Server) reportResult(ctx context.Context, request StartMigrationRequest, result *MigrationResult, migrationErr error) {
+	data := ReportData{
+		Status: statusFailed,
+	}
+
+	if migrationErr != nil {
+		data.Error = migrationErr.Error()
+	}
+
+	if result != nil {
+		data.CloneID = result.CloneID
+	}
+
+	if result != nil && result.Session != nil {
+		session := result.Session
+		data.SessionID = session.SessionID
+
+		if session.Result != nil {
+			data.Status = session.Result.Status
+		}
+
+		data.Summary = result.Summary
+
+		// Artifacts are available only while the clone exists, so links are posted only for kept clones.
+		if request.KeepClone {
+			data.Artifacts = s.reporter.ArtifactLinks(result.CloneID, session.SessionID, session.Artifacts)
+		}
+	}
+
+	s.reporter.ReportResult(ctx, request.Source, data)
 }
 
 func (s *Server) runCommands(ctx context.Context, clone *models.Clone, runID string, volumes, tags map[string]string,
-	commands, migrationEnvs []string, cfg dblab_types.Config) (*observer.Session, error) {
+	commands []Command, migrationEnvs []string, cfg dblab_types.Config, output io.Writer) (*observer.Session, []CommandOutput, error) {
 	if err := tools.PullImage(ctx, s.docker, s.config.Runner.Image); err != nil {
-		return nil, errors.Wrap(err, "failed to scan pulling image response")
+		return nil, nil, errors.Wrap(err, "failed to scan pulling image response")
 	}
 
 	containerCfg := s.buildContainerConfig(clone, migrationEnvs)
@@ -194,17 +444,18 @@ func (s *Server) runCommands(ctx context.Context, clone *models.Clone, runID str
 	contRunner, err := s.docker.ContainerCreate(ctx, containerCfg, hostConfig, networkConfig, nil, containerName)
 
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to create container")
+		return nil, nil, errors.Wrap(err, "failed to create container")
 	}
 
-	defer tools.RemoveContainer(ctx, s.docker, contRunner.ID, cont.StopPhysicalTimeout)
+	// The run context may be canceled, so clean up using a separate one.
+	defer tools.RemoveContainer(context.Background(), s.docker, contRunner.ID, cont.StopPhysicalTimeout)
 
 	log.Dbg("ContainerID: ", contRunner.ID)
 
 	log.Msg(fmt.Sprintf("Running container: %s. ID: %v", containerName, contRunner.ID))
 
 	if err := s.docker.ContainerStart(ctx, contRunner.ID, container.StartOptions{}); err != nil {
-		return nil, errors.Wrapf(err, "failed to start container %q", containerName)
+		return nil, nil, errors.Wrapf(err, "failed to start container %q", containerName)
 	}
 
 	session, err := s.dle.StartObservation(ctx,
@@ -216,37 +467,27 @@ func (s *Server) runCommands(ctx context.Context, clone *models.Clone, runID str
 	)
 
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to start observation session")
+		return nil, nil, errors.Wrap(err, "failed to start observation session")
 	}
 
 	defer func() {
 		if !session.IsFinished() {
 			log.Msg("Session has not been finished properly. Stop observation")
 
-			if _, stopErr := s.dle.StopObservation(ctx, dblab_types.StopObservationRequest{CloneID: clone.ID, OverallError: true}); stopErr != nil {
+			if _, stopErr := s.dle.StopObservation(context.Background(),
+				dblab_types.StopObservationRequest{CloneID: clone.ID, OverallError: true}); stopErr != nil {
 				log.Err(errors.Wrap(stopErr, "failed to stop observation session"))
 			}
 		}
 	}()
 
-	for _, command := range commands {
-		cmd := []string{"/bin/sh", "-c", command}
-
-		log.Msg("Running command: ", cmd)
-
-		output, err := tools.ExecCommandWithOutput(ctx, s.docker, contRunner.ID, types.ExecConfig{
-			Cmd: cmd,
-		})
-		if err != nil {
-			return nil, errors.Wrap(err, "failed to execute command")
-		}
-
-		log.Msg("Command output: ", output)
+	runnerOutput, commandErr := execCommands(ctx, s.containerExecutor(contRunner.ID), commands, output)
 
-		log.Msg("Command has been executed: ", cmd)
-	}
+	// The run context may be canceled, so finish the observation session using a separate one.
+	s.uploadRunnerOutput(context.Background(), clone.ID, runnerOutput)
 
-	session, err = s.dle.StopObservation(ctx, dblab_types.StopObservationRequest{CloneID: clone.ID})
+	session, err = s.dle.StopObservation(context.Background(),
+		dblab_types.StopObservationRequest{CloneID: clone.ID, OverallError: commandErr != nil})
 	if err != nil {
 		log.Err(errors.Wrap(err, "failed to stop observation session"))
 	}
@@ -255,12 +496,25 @@ func (s *Server) runCommands(ctx context.Context, clone *models.Clone, runID str
 
 	sessionResponse, err := json.MarshalIndent(session, "", "    ")
 	if err != nil {
-		return nil, err
+		return nil, runnerOutput, err
 	}
 
 	log.Msg("Observation session output: ", string(sessionResponse))
 
-	return session, nil
+	return session, runnerOutput, commandErr
+}
+
+// uploadRunnerOutput stores the output of migration commands as an artifact of the observation session.
+func (s *Server) uploadRunnerOutput(ctx context.Context, cloneID string, runnerOutput []CommandOutput) {
+	data, err := json.Marshal(runnerOutput)
+	if err != nil {
+		log.Err("failed to encode runner output: ", err)
+		return
+	}
+
+	if err := s.dle.UploadArtifact(ctx, cloneID, observer.RunnerOutputType, bytes.NewReader(data)); err != nil {
+		log.Err("failed to upload runner output: ", err)
+	}
 }
 
 func (s *Server) buildContainerConfig(clone *models.Clone, migrationEnvs []string) *container.Config {
@@ -0,0 +1,112 @@
+package runci
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/observer"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/runci/source"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/client/dblabapi"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+func TestRunsResponseWithoutMigrationEnvs(t *testing.T) {
+	store, err := newRunStore(t.TempDir())
+	require.NoError(t, err)
+
+	require.NoError(t, store.add(&Run{ID: "run1", State: RunStateRunning, Request: migrationRequest("repo")}))
+
+	s := &Server{runs: store}
+
+	r := mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/migration/run1", nil), map[string]string{"id": "run1"})
+	w := httptest.NewRecorder()
+	s.getMigration(w, r)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "SECRET")
+
+	run := Run{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &run))
+	assert.Equal(t, "run1", run.ID)
+	assert.Empty(t, run.Request.MigrationEnvs)
+
+	w = httptest.NewRecorder()
+	s.listMigrations(w, httptest.NewRequest(http.MethodGet, "/migration", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "SECRET")
+
+	runs := []Run{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &runs))
+	require.Len(t, runs, 1)
+	assert.Empty(t, runs[0].Request.MigrationEnvs)
+}
+
+// dleMock serves observation summaries of the clone until the clone is destroyed.
+func dleMock(t *testing.T) *dblabapi.Client {
+	t.Helper()
+
+	var destroyed atomic.Bool
+
+	r := mux.NewRouter()
+	r.HandleFunc("/observation/summary/{clone}/{session}", func(w http.ResponseWriter, r *http.Request) {
+		if destroyed.Load() {
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(models.Error{Code: models.ErrCodeNotFound, Message: "clone not found"})
+
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(observer.SummaryArtifact{CloneID: mux.Vars(r)["clone"], Duration: observer.Duration{Total: "5s"}})
+	})
+	r.HandleFunc("/clone/{clone}", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			destroyed.Store(true)
+			return
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(models.Error{Code: models.ErrCodeNotFound, Message: "clone not found"})
+	})
+
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+
+	dle, err := dblabapi.NewClient(dblabapi.Options{Host: server.URL, VerificationToken: "token"})
+	require.NoError(t, err)
+
+	return dle
+}
+
+func TestReportResultOfDestroyedClone(t *testing.T) {
+	provider := &providerMock{}
+
+	reporter, err := NewReporter(Report{CommitStatus: true}, provider)
+	require.NoError(t, err)
+
+	s := &Server{dle: dleMock(t), reporter: reporter}
+
+	result := &MigrationResult{
+		CloneID: "ci_clone",
+		Session: &observer.Session{SessionID: 42, Result: &models.ObservationResult{Status: statusPassed}},
+	}
+
+	s.releaseClone(context.Background(), result, true)
+
+	require.NotNil(t, result.Summary)
+	assert.Equal(t, "5s", result.Summary.Duration.Total)
+
+	s.reportResult(context.Background(), StartMigrationRequest{KeepClone: false}, result, nil)
+
+	require.Len(t, provider.statuses, 1)
+	assert.Equal(t, source.StateSuccess, provider.statuses[0].State)
+	assert.Equal(t, "Migration check passed in 5s", provider.statuses[0].Description)
+}
@@ -0,0 +1,242 @@
+package runci
+
+import (
+	"context"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+)
+
+const (
+	defaultConcurrency           = 4
+	defaultRepositoryConcurrency = 1
+	defaultHistoryLimit          = 100
+)
+
+var (
+	errRunNotFound = errors.New("run not found")
+	errRunFinished = errors.New("run has already finished")
+)
+
+// job contains a queued migration run and the data which are not persisted.
+type job struct {
+	runID      string
+	repository string
+	request    StartMigrationRequest
+	cancel     context.CancelFunc
+	done       chan struct{}
+}
+
+// runExecutor executes a migration run, writing the runner output to the provided file.
+type runExecutor func(ctx context.Context, runID string, request StartMigrationRequest, output io.Writer) (*MigrationResult, error)
+
+// runQueue schedules migration runs respecting concurrency limits.
+type runQueue struct {
+	cfg     Queue
+	store   *runStore
+	execute runExecutor
+
+	mu             sync.Mutex
+	pending        []*job
+	active         map[string]*job
+	activePerRepos map[string]int
+}
+
+func newRunQueue(cfg Queue, store *runStore, execute runExecutor) *runQueue {
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = defaultConcurrency
+	}
+
+	if cfg.RepositoryConcurrency <= 0 {
+		cfg.RepositoryConcurrency = defaultRepositoryConcurrency
+	}
+
+	if cfg.HistoryLimit == 0 {
+		cfg.HistoryLimit = defaultHistoryLimit
+	}
+
+	return &runQueue{
+		cfg:            cfg,
+		store:          store,
+		execute:        execute,
+		active:         make(map[string]*job),
+		activePerRepos: make(map[string]int),
+	}
+}
+
+// enqueue registers a new run and schedules it.
+func (q *runQueue) enqueue(runID string, request StartMigrationRequest) (*job, error) {
+	repository := request.Source.Owner + "/" + request.Source.Repo
+
+	run := &Run{
+		ID:         runID,
+		Repository: repository,
+		State:      RunStateQueued,
+		Request:    request,
+		CreatedAt:  time.Now(),
+	}
+
+	if err := q.store.add(run); err != nil {
+		return nil, errors.Wrap(err, "failed to register run")
+	}
+
+	j := &job{
+		runID:      runID,
+		repository: repository,
+		request:    request,
+		done:       make(chan struct{}),
+	}
+
+	q.mu.Lock()
+	q.pending = append(q.pending, j)
+	q.mu.Unlock()
+
+	q.dispatch()
+
+	return j, nil
+}
+
+// dispatch starts pending jobs while there are free slots.
+func (q *runQueue) dispatch() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for i := 0; i < len(q.pending) && len(q.active) < q.cfg.Concurrency; {
+		j := q.pending[i]
+
+		if q.activePerRepos[j.repository] >= q.cfg.RepositoryConcurrency {
+			i++
+			continue
+		}
+
+		q.pending = append(q.pending[:i], q.pending[i+1:]...)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		j.cancel = cancel
+		q.active[j.runID] = j
+		q.activePerRepos[j.repository]++
+
+		go q.run(ctx, j)
+	}
+}
+
+func (q *runQueue) run(ctx context.Context, j *job) {
+	defer q.finish(j)
+
+	startedAt := time.Now()
+
+	if err := q.store.update(j.runID, func(run *Run) {
+		run.State = RunStateRunning
+		run.StartedAt = &startedAt
+	}); err != nil {
+		log.Err("failed to update run state: ", err)
+	}
+
+	output, err := os.OpenFile(q.store.outputPath(j.runID), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		q.complete(j.runID, nil, errors.Wrap(err, "failed to create the output file"), false)
+		return
+	}
+
+	defer func() {
+		if err := output.Close(); err != nil {
+			log.Err("failed to close the output file: ", err)
+		}
+	}()
+
+	result, err := q.execute(ctx, j.runID, j.request, output)
+
+	q.complete(j.runID, result, err, ctx.Err() != nil)
+}
+
+func (q *runQueue) complete(runID string, result *MigrationResult, runErr error, canceled bool) {
+	finishedAt := time.Now()
+
+	if err := q.store.update(runID, func(run *Run) {
+		run.FinishedAt = &finishedAt
+		run.Result = result
+
+		switch {
+		case canceled:
+			run.State = RunStateCanceled
+
+		case runErr != nil:
+			run.State = RunStateFailed
+			run.Error = runErr.Error()
+
+		default:
+			run.State = RunStateCompleted
+		}
+	}); err != nil {
+		log.Err("failed to update run state: ", err)
+	}
+}
+
+func (q *runQueue) finish(j *job) {
+	q.mu.Lock()
+	delete(q.active, j.runID)
+	q.activePerRepos[j.repository]--
+
+	if q.activePerRepos[j.repository] <= 0 {
+		delete(q.activePerRepos, j.repository)
+	}
+
+	q.mu.Unlock()
+
+	j.cancel()
+	close(j.done)
+
+	q.store.prune(q.cfg.HistoryLimit)
+	q.dispatch()
+}
+
+// cancel cancels a queued or running migration run.
+func (q *runQueue) cancel(runID string) error {
+	q.mu.Lock()
+
+	if j, ok := q.active[runID]; ok {
+		q.mu.Unlock()
+		j.cancel()
+
+		return nil
+	}
+
+	for i, j := range q.pending {
+		if j.runID != runID {
+			continue
+		}
+
+		q.pending = append(q.pending[:i], q.pending[i+1:]...)
+		q.mu.Unlock()
+
+		q.complete(runID, nil, nil, true)
+		close(j.done)
+
+		return nil
+	}
+
+	q.mu.Unlock()
+
+	run, ok := q.store.get(runID)
+	if !ok {
+		return errRunNotFound
+	}
+
+	if run.State.IsFinal() {
+		return errRunFinished
+	}
+
+	return nil
+}
+
+// isActive checks if the run is queued or running.
+func (q *runQueue) isActive(runID string) bool {
+	run, ok := q.store.get(runID)
+
+	return ok && !run.State.IsFinal()
+}
@@ -0,0 +1,169 @@
+package runci
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/runci/source"
+)
+
+func migrationRequest(repo string) StartMigrationRequest {
+	return StartMigrationRequest{
+		Source:        source.Opts{Owner: "owner", Repo: repo},
+		MigrationEnvs: []string{"SECRET=value"},
+	}
+}
+
+func waitState(t *testing.T, store *runStore, runID string, state RunState) {
+	t.Helper()
+
+	require.Eventually(t, func() bool {
+		run, ok := store.get(runID)
+		return ok && run.State == state
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestQueueRepositoryConcurrency(t *testing.T) {
+	store, err := newRunStore(t.TempDir())
+	require.NoError(t, err)
+
+	release := make(chan struct{})
+	started := make(chan string, 3)
+
+	queue := newRunQueue(Queue{Concurrency: 2}, store,
+		func(ctx context.Context, runID string, _ StartMigrationRequest, output io.Writer) (*MigrationResult, error) {
+			started <- runID
+			_, _ = fmt.Fprintf(output, "output of %s", runID)
+			<-release
+
+			return &MigrationResult{CloneID: "clone_" + runID}, nil
+		})
+
+	first, err := queue.enqueue("first", migrationRequest("repo"))
+	require.NoError(t, err)
+
+	second, err := queue.enqueue("second", migrationRequest("repo"))
+	require.NoError(t, err)
+
+	third, err := queue.enqueue("third", migrationRequest("another"))
+	require.NoError(t, err)
+
+	waitState(t, store, "first", RunStateRunning)
+	waitState(t, store, "third", RunStateRunning)
+
+	// The second run waits for the first one because they check the same repository.
+	run, ok := store.get("second")
+	require.True(t, ok)
+	assert.Equal(t, RunStateQueued, run.State)
+
+	close(release)
+
+	for _, j := range []*job{first, second, third} {
+		<-j.done
+	}
+
+	for _, runID := range []string{"first", "second", "third"} {
+		run, ok := store.get(runID)
+		require.True(t, ok)
+		assert.Equal(t, RunStateCompleted, run.State)
+		assert.Equal(t, "clone_"+runID, run.Result.CloneID)
+		assert.NotNil(t, run.FinishedAt)
+
+		output, err := os.ReadFile(store.outputPath(runID))
+		require.NoError(t, err)
+		assert.Equal(t, "output of "+runID, string(output))
+	}
+}
+
+func TestQueueCancel(t *testing.T) {
+	store, err := newRunStore(t.TempDir())
+	require.NoError(t, err)
+
+	queue := newRunQueue(Queue{}, store,
+		func(ctx context.Context, _ string, _ StartMigrationRequest, _ io.Writer) (*MigrationResult, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+
+	running, err := queue.enqueue("running", migrationRequest("repo"))
+	require.NoError(t, err)
+
+	queued, err := queue.enqueue("queued", migrationRequest("repo"))
+	require.NoError(t, err)
+
+	waitState(t, store, "running", RunStateRunning)
+
+	require.NoError(t, queue.cancel("queued"))
+	<-queued.done
+
+	require.NoError(t, queue.cancel("running"))
+	<-running.done
+
+	waitState(t, store, "running", RunStateCanceled)
+	waitState(t, store, "queued", RunStateCanceled)
+
+	assert.ErrorIs(t, queue.cancel("running"), errRunFinished)
+	assert.ErrorIs(t, queue.cancel("unknown"), errRunNotFound)
+}
+
+func TestRunStoreRestore(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := newRunStore(dir)
+	require.NoError(t, err)
+
+	require.NoError(t, store.add(&Run{ID: "interrupted", State: RunStateRunning, Request: migrationRequest("repo")}))
+	require.NoError(t, store.add(&Run{ID: "completed", State: RunStateCompleted}))
+
+	persisted, err := os.ReadFile(path.Join(dir, "interrupted", runFilename))
+	require.NoError(t, err)
+	assert.NotContains(t, string(persisted), "SECRET")
+
+	restored, err := newRunStore(dir)
+	require.NoError(t, err)
+
+	run, ok := restored.get("interrupted")
+	require.True(t, ok)
+	assert.Equal(t, RunStateFailed, run.State)
+	assert.Equal(t, interruptedRunMessage, run.Error)
+
+	run, ok = restored.get("completed")
+	require.True(t, ok)
+	assert.Equal(t, RunStateCompleted, run.State)
+}
+
+func TestRunStorePrune(t *testing.T) {
+	store, err := newRunStore(t.TempDir())
+	require.NoError(t, err)
+
+	createdAt := time.Now()
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, store.add(&Run{
+			ID:        fmt.Sprintf("run%d", i),
+			State:     RunStateCompleted,
+			CreatedAt: createdAt.Add(time.Duration(i) * time.Minute),
+		}))
+	}
+
+	require.NoError(t, store.add(&Run{ID: "active", State: RunStateRunning, CreatedAt: createdAt}))
+
+	store.prune(2)
+
+	runs := store.list("")
+	require.Len(t, runs, 3)
+	assert.Equal(t, "run2", runs[0].ID)
+	assert.Equal(t, "run1", runs[1].ID)
+	assert.Equal(t, "active", runs[2].ID)
+
+	_, err = os.Stat(store.runDir("run0"))
+	assert.True(t, os.IsNotExist(err))
+}
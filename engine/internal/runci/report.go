@@ -0,0 +1,241 @@
+package runci
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/observer"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/runci/source"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+)
+
+const (
+	defaultStatusContext = "DB Migration Checker"
+
+	// maxStatusDescriptionLength defines the maximum length of a commit status description accepted by code providers.
+	maxStatusDescriptionLength = 140
+
+	statusPassed = "passed"
+	statusFailed = "failed"
+)
+
+const defaultCommentTemplate = `### DB Migration Checker: {{ if eq .Status "passed" }}:white_check_mark: passed{{ else }}:x: failed{{ end }}
+
+{{ if .Commit }}Commit: {{ if .CommitLink }}[{{ .Commit }}]({{ .CommitLink }}){{ else }}{{ .Commit }}{{ end }}
+{{ end }}
+{{- if .Error }}
+**Error:** {{ .Error }}
+{{ end }}
+{{- if .Summary }}
+| | |
+|---|---|
+| Duration | {{ .Summary.Duration.Total }} |
+| Longest query | {{ .Summary.Duration.MaxQueryDuration }} |
+| Database size | {{ .Summary.DBSize.Total }} (diff: {{ .Summary.DBSize.Diff }}) |
+| Intervals with dangerous locks | {{ .Summary.Locks.WarningInterval }} of {{ .Summary.Locks.TotalInterval }} |
+| Errors in logs | {{ .Summary.LogErrors.Count }} |
+{{ if .Summary.LogErrors.Message }}
+<details><summary>Log errors</summary>
+
+` + "```" + `
+{{ .Summary.LogErrors.Message }}
+` + "```" + `
+</details>
+{{ end }}
+{{- end }}
+{{- if .Artifacts }}
+Artifacts ({{ .CloneID }}, session {{ .SessionID }}):
+{{ range .Artifacts }}- [{{ .Type }}]({{ .Link }})
+{{ end }}
+{{- end }}`
+
+const defaultStatusDescriptionTemplate = `{{ if .Error }}Migration check failed: {{ .Error }}` +
+	`{{ else if .Summary }}Migration check {{ .Status }} in {{ .Summary.Duration.Total }}` +
+	`{{ else }}Migration check {{ .Status }}{{ end }}`
+
+// Reporter posts results of migration checks to pull/merge requests.
+type Reporter struct {
+	cfg             Report
+	provider        source.Provider
+	commentTmpl     *template.Template
+	descriptionTmpl *template.Template
+}
+
+// ReportData contains data available in report templates.
+type ReportData struct {
+	Status      string
+	Error       string
+	CloneID     string
+	SessionID   uint64
+	Commit      string
+	CommitLink  string
+	RequestLink string
+	Branch      string
+	Summary     *observer.SummaryArtifact
+	Artifacts   []ArtifactLink
+}
+
+// ArtifactLink describes a link to download an observation artifact.
+type ArtifactLink struct {
+	Type string
+	Link string
+}
+
+// NewReporter creates a new reporter. It returns nil if reporting is not enabled.
+func NewReporter(cfg Report, provider source.Provider) (*Reporter, error) {
+	if !cfg.Comment && !cfg.CommitStatus {
+		return nil, nil
+	}
+
+	if cfg.StatusContext == "" {
+		cfg.StatusContext = defaultStatusContext
+	}
+
+	commentTmpl, err := parseTemplate("comment", cfg.Templates.Comment, defaultCommentTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	descriptionTmpl, err := parseTemplate("statusDescription", cfg.Templates.StatusDescription, defaultStatusDescriptionTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Reporter{
+		cfg:             cfg,
+		provider:        provider,
+		commentTmpl:     commentTmpl,
+		descriptionTmpl: descriptionTmpl,
+	}, nil
+}
+
+func parseTemplate(name, text, defaultText string) (*template.Template, error) {
+	if text == "" {
+		text = defaultText
+	}
+
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the %s template", name)
+	}
+
+	return tmpl, nil
+}
+
+// ReportStart marks the checked commit as pending.
+func (r *Reporter) ReportStart(ctx context.Context, opts source.Opts) {
+	if !r.cfg.CommitStatus {
+		return
+	}
+
+	status := source.CommitStatus{
+		State:       source.StatePending,
+		Context:     r.cfg.StatusContext,
+		Description: "Migration check is running",
+	}
+
+	if err := r.provider.SetCommitStatus(ctx, opts, status); err != nil {
+		log.Err("failed to set pending commit status: ", err)
+	}
+}
+
+// ReportResult posts the result of the migration check.
+func (r *Reporter) ReportResult(ctx context.Context, opts source.Opts, data ReportData) {
+	data.Commit = opts.Commit
+	data.CommitLink = opts.CommitLink
+	data.RequestLink = opts.RequestLink
+	data.Branch = opts.Branch
+
+	if r.cfg.CommitStatus {
+		if err := r.setResultStatus(ctx, opts, data); err != nil {
+			log.Err("failed to set commit status: ", err)
+		}
+	}
+
+	if r.cfg.Comment && opts.RequestLink != "" {
+		comment, err := render(r.commentTmpl, data)
+		if err != nil {
+			log.Err(err)
+			return
+		}
+
+		if err := r.provider.PostComment(ctx, opts, comment); err != nil {
+			log.Err("failed to post comment: ", err)
+		}
+	}
+}
+
+func (r *Reporter) setResultStatus(ctx context.Context, opts source.Opts, data ReportData) error {
+	description, err := render(r.descriptionTmpl, data)
+	if err != nil {
+		return err
+	}
+
+	state := source.StateSuccess
+
+	switch {
+	case data.Error != "":
+		state = source.StateError
+
+	case data.Status != statusPassed:
+		state = source.StateFailure
+	}
+
+	status := source.CommitStatus{
+		State:       state,
+		Context:     r.cfg.StatusContext,
+		Description: truncate(strings.TrimSpace(description), maxStatusDescriptionLength),
+		TargetURL:   opts.RequestLink,
+	}
+
+	return r.provider.SetCommitStatus(ctx, opts, status)
+}
+
+// ArtifactLinks builds download links of the session artifacts.
+func (r *Reporter) ArtifactLinks(cloneID string, sessionID uint64, artifactTypes []string) []ArtifactLink {
+	if r.cfg.ArtifactsURL == "" {
+		return nil
+	}
+
+	links := make([]ArtifactLink, 0, len(artifactTypes))
+
+	for _, artifactType := range artifactTypes {
+		values := url.Values{}
+		values.Add("artifact_type", artifactType)
+		values.Add("clone_id", cloneID)
+		values.Add("session_id", strconv.FormatUint(sessionID, 10))
+
+		links = append(links, ArtifactLink{
+			Type: artifactType,
+			Link: fmt.Sprintf("%s/artifact/download?%s", strings.TrimRight(r.cfg.ArtifactsURL, "/"), values.Encode()),
+		})
+	}
+
+	return links
+}
+
+func render(tmpl *template.Template, data ReportData) (string, error) {
+	buf := &bytes.Buffer{}
+
+	if err := tmpl.Execute(buf, data); err != nil {
+		return "", errors.Wrapf(err, "failed to render the %s template", tmpl.Name())
+	}
+
+	return buf.String(), nil
+}
+
+func truncate(s string, maxLength int) string {
+	runes := []rune(s)
+	if len(runes) <= maxLength {
+		return s
+	}
+
+	return string(runes[:maxLength-3]) + "..."
+}
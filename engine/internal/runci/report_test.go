@@ -0,0 +1,119 @@
+package runci
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/observer"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/runci/source"
+)
+
+type providerMock struct {
+	source.Provider
+	comments []string
+	statuses []source.CommitStatus
+}
+
+func (p *providerMock) PostComment(_ context.Context, _ source.Opts, body string) error {
+	p.comments = append(p.comments, body)
+	return nil
+}
+
+func (p *providerMock) SetCommitStatus(_ context.Context, _ source.Opts, status source.CommitStatus) error {
+	p.statuses = append(p.statuses, status)
+	return nil
+}
+
+func TestReporterDisabled(t *testing.T) {
+	reporter, err := NewReporter(Report{}, &providerMock{})
+	require.NoError(t, err)
+	assert.Nil(t, reporter)
+}
+
+func TestReporterInvalidTemplate(t *testing.T) {
+	_, err := NewReporter(Report{Comment: true, Templates: ReportTemplates{Comment: "{{ .Status "}}, &providerMock{})
+	require.Error(t, err)
+}
+
+func TestReportResult(t *testing.T) {
+	provider := &providerMock{}
+	opts := source.Opts{Commit: "abc123", RequestLink: "https://github.com/owner/repo/pull/7"}
+
+	reporter, err := NewReporter(Report{Comment: true, CommitStatus: true, ArtifactsURL: "https://ci.example.com/"}, provider)
+	require.NoError(t, err)
+
+	reporter.ReportStart(context.Background(), opts)
+	reporter.ReportResult(context.Background(), opts, ReportData{
+		Status:    statusPassed,
+		CloneID:   "ci_clone",
+		SessionID: 42,
+		Summary: &observer.SummaryArtifact{
+			Duration:  observer.Duration{Total: "5s"},
+			Locks:     observer.Locks{TotalInterval: 3, WarningInterval: 1},
+			LogErrors: observer.LogErrors{Count: 2},
+		},
+		Artifacts: reporter.ArtifactLinks("ci_clone", 42, []string{"log_errors"}),
+	})
+
+	require.Len(t, provider.statuses, 2)
+	assert.Equal(t, source.StatePending, provider.statuses[0].State)
+	assert.Equal(t, source.StateSuccess, provider.statuses[1].State)
+	assert.Equal(t, defaultStatusContext, provider.statuses[1].Context)
+	assert.Equal(t, "Migration check passed in 5s", provider.statuses[1].Description)
+
+	require.Len(t, provider.comments, 1)
+	assert.Contains(t, provider.comments[0], "passed")
+	assert.Contains(t, provider.comments[0], "| Intervals with dangerous locks | 1 of 3 |")
+	assert.Contains(t, provider.comments[0],
+		"- [log_errors](https://ci.example.com/artifact/download?artifact_type=log_errors&clone_id=ci_clone&session_id=42)")
+}
+
+func TestReportResultWithError(t *testing.T) {
+	provider := &providerMock{}
+	opts := source.Opts{Commit: "abc123"}
+
+	reporter, err := NewReporter(Report{
+		Comment:      true,
+		CommitStatus: true,
+		Templates:    ReportTemplates{StatusDescription: "Custom: {{ .Error }}"},
+	}, provider)
+	require.NoError(t, err)
+
+	reporter.ReportResult(context.Background(), opts, ReportData{Status: statusFailed, Error: strings.Repeat("x", 200)})
+
+	require.Len(t, provider.statuses, 1)
+	assert.Equal(t, source.StateError, provider.statuses[0].State)
+	assert.Len(t, provider.statuses[0].Description, maxStatusDescriptionLength)
+	assert.True(t, strings.HasPrefix(provider.statuses[0].Description, "Custom: xxx"))
+
+	// No request link, so no comment is posted.
+	assert.Empty(t, provider.comments)
+}
+
+func TestRequestNumber(t *testing.T) {
+	testCases := []struct {
+		link     string
+		number   int
+		hasError bool
+	}{
+		{link: "https://github.com/owner/repo/pull/15", number: 15},
+		{link: "https://gitlab.com/group/project/-/merge_requests/8/", number: 8},
+		{link: "https://github.com/owner/repo/pull/new", hasError: true},
+		{link: "", hasError: true},
+	}
+
+	for _, tc := range testCases {
+		number, err := source.Opts{RequestLink: tc.link}.RequestNumber()
+		if tc.hasError {
+			assert.Error(t, err)
+			continue
+		}
+
+		require.NoError(t, err)
+		assert.Equal(t, tc.number, number)
+	}
+}
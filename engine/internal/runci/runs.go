@@ -0,0 +1,262 @@
+package runci
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/util"
+)
+
+const (
+	defaultHistoryDirName = "ci_checker_runs"
+	runFilename           = "run.json"
+	outputFilename        = "output.log"
+
+	interruptedRunMessage = "run was interrupted by a restart of DB Migration Checker"
+)
+
+// RunState defines the state of a migration run.
+type RunState string
+
+// Available states of migration runs.
+const (
+	RunStateQueued    RunState = "queued"
+	RunStateRunning   RunState = "running"
+	RunStateCompleted RunState = "completed"
+	RunStateFailed    RunState = "failed"
+	RunStateCanceled  RunState = "canceled"
+)
+
+// IsFinal checks if the run has finished.
+func (s RunState) IsFinal() bool {
+	return s == RunStateCompleted || s == RunStateFailed || s == RunStateCanceled
+}
+
+// Run describes a migration run.
+type Run struct {
+	ID         string                `json:"id"`
+	Repository string                `json:"repository"`
+	State      RunState              `json:"state"`
+	Request    StartMigrationRequest `json:"request"`
+	Error      string                `json:"error,omitempty"`
+	Result     *MigrationResult      `json:"result,omitempty"`
+	Output     string                `json:"output,omitempty"`
+	CreatedAt  time.Time             `json:"created_at"`
+	StartedAt  *time.Time            `json:"started_at,omitempty"`
+	FinishedAt *time.Time            `json:"finished_at,omitempty"`
+}
+
+// redacted returns a copy of the run without migration environment variables.
+// The variables may contain secrets, so they are neither kept on disk nor returned by the API.
+func (r *Run) redacted() Run {
+	run := *r
+	run.Request.MigrationEnvs = nil
+
+	return run
+}
+
+// runStore keeps migration runs and persists their history on disk.
+type runStore struct {
+	dir  string
+	mu   sync.RWMutex
+	runs map[string]*Run
+}
+
+func newRunStore(dir string) (*runStore, error) {
+	if dir == "" {
+		metaDir, err := util.GetMetaPath(defaultHistoryDirName)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get path of the run history directory")
+		}
+
+		dir = metaDir
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, errors.Wrap(err, "failed to create the run history directory")
+	}
+
+	store := &runStore{
+		dir:  dir,
+		runs: make(map[string]*Run),
+	}
+
+	if err := store.load(); err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+// load reads the run history from disk. Runs which had not finished before a restart are marked as failed
+// because neither the source code nor migration environment variables are kept on disk.
+func (rs *runStore) load() error {
+	entries, err := os.ReadDir(rs.dir)
+	if err != nil {
+		return errors.Wrap(err, "failed to read the run history directory")
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		data, err := os.ReadFile(path.Join(rs.dir, entry.Name(), runFilename))
+		if err != nil {
+			log.Dbg(fmt.Sprintf("Skip run directory %s: %v", entry.Name(), err))
+			continue
+		}
+
+		run := &Run{}
+		if err := json.Unmarshal(data, run); err != nil {
+			log.Errf("failed to decode run %s: %v", entry.Name(), err)
+			continue
+		}
+
+		if !run.State.IsFinal() {
+			finishedAt := time.Now()
+			run.State = RunStateFailed
+			run.Error = interruptedRunMessage
+			run.FinishedAt = &finishedAt
+
+			if err := rs.write(run); err != nil {
+				log.Err("failed to save interrupted run: ", err)
+			}
+		}
+
+		rs.runs[run.ID] = run
+	}
+
+	return nil
+}
+
+// add registers a new run and creates its directory.
+func (rs *runStore) add(run *Run) error {
+	if err := os.MkdirAll(rs.runDir(run.ID), 0755); err != nil {
+		return errors.Wrap(err, "failed to create the run directory")
+	}
+
+	rs.mu.Lock()
+	rs.runs[run.ID] = run
+	rs.mu.Unlock()
+
+	return rs.save(run.ID)
+}
+
+// get returns a redacted copy of the run.
+func (rs *runStore) get(runID string) (Run, bool) {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	run, ok := rs.runs[runID]
+	if !ok {
+		return Run{}, false
+	}
+
+	return run.redacted(), true
+}
+
+// list returns redacted copies of runs sorted from the newest to the oldest.
+func (rs *runStore) list(repository string) []Run {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	runs := make([]Run, 0, len(rs.runs))
+
+	for _, run := range rs.runs {
+		if repository != "" && run.Repository != repository {
+			continue
+		}
+
+		runs = append(runs, run.redacted())
+	}
+
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].CreatedAt.After(runs[j].CreatedAt)
+	})
+
+	return runs
+}
+
+// update applies changes to the run and persists it.
+func (rs *runStore) update(runID string, fn func(run *Run)) error {
+	rs.mu.Lock()
+
+	run, ok := rs.runs[runID]
+	if !ok {
+		rs.mu.Unlock()
+		return errors.Errorf("run %s not found", runID)
+	}
+
+	fn(run)
+	rs.mu.Unlock()
+
+	return rs.save(runID)
+}
+
+func (rs *runStore) save(runID string) error {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	run, ok := rs.runs[runID]
+	if !ok {
+		return errors.Errorf("run %s not found", runID)
+	}
+
+	return rs.write(run)
+}
+
+func (rs *runStore) write(run *Run) error {
+	data, err := json.Marshal(run.redacted())
+	if err != nil {
+		return errors.Wrap(err, "failed to encode run")
+	}
+
+	return os.WriteFile(path.Join(rs.runDir(run.ID), runFilename), data, 0600)
+}
+
+// prune removes the oldest finished runs exceeding the history limit.
+func (rs *runStore) prune(limit int) {
+	if limit <= 0 {
+		return
+	}
+
+	finished := []Run{}
+
+	for _, run := range rs.list("") {
+		if run.State.IsFinal() {
+			finished = append(finished, run)
+		}
+	}
+
+	if len(finished) <= limit {
+		return
+	}
+
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	for _, run := range finished[limit:] {
+		delete(rs.runs, run.ID)
+
+		if err := os.RemoveAll(rs.runDir(run.ID)); err != nil {
+			log.Errf("failed to remove the directory of run %s: %v", run.ID, err)
+		}
+	}
+}
+
+func (rs *runStore) runDir(runID string) string {
+	return path.Join(rs.dir, runID)
+}
+
+func (rs *runStore) outputPath(runID string) string {
+	return path.Join(rs.runDir(runID), outputFilename)
+}
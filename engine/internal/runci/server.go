@@ -12,6 +12,7 @@ import (
 	"github.com/docker/docker/client"
 	"github.com/gorilla/mux"
 	"github.com/gorilla/websocket"
+	"github.com/pkg/errors"
 
 	"gitlab.com/postgres-ai/database-lab/v3/internal/runci/source"
 
@@ -27,6 +28,9 @@ type Server struct {
 	config       *Config
 	dle          *dblabapi.Client
 	codeProvider source.Provider
+	reporter     *Reporter
+	runs         *runStore
+	queue        *runQueue
 	platform     *platform.Service
 	upgrader     websocket.Upgrader
 	httpServer   *http.Server
@@ -35,19 +39,28 @@ type Server struct {
 }
 
 // NewServer initializes a new runner Server instance.
-func NewServer(cfg *Config, dle *dblabapi.Client, platform *platform.Service, code source.Provider, docker *client.Client,
-	networkID string) *Server {
+func NewServer(cfg *Config, dle *dblabapi.Client, platform *platform.Service, code source.Provider, reporter *Reporter,
+	docker *client.Client, networkID string) (*Server, error) {
 	server := &Server{
 		config:       cfg,
 		dle:          dle,
 		platform:     platform,
 		codeProvider: code,
+		reporter:     reporter,
 		upgrader:     websocket.Upgrader{},
 		docker:       docker,
 		networkID:    networkID,
 	}
 
-	return server
+	runs, err := newRunStore(cfg.Queue.HistoryDir)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load the run history")
+	}
+
+	server.runs = runs
+	server.queue = newRunQueue(cfg.Queue, runs, server.executeRun)
+
+	return server, nil
 }
 
 // Run starts HTTP server on specified port in configuration.
@@ -57,6 +70,11 @@ func (s *Server) Run() error {
 	authMW := mw.NewAuth(s.config.App.VerificationToken, s.platform)
 
 	r.HandleFunc("/migration/run", authMW.Authorized(s.runMigration)).Methods(http.MethodPost)
+	r.HandleFunc("/migration", authMW.Authorized(s.startMigration)).Methods(http.MethodPost)
+	r.HandleFunc("/migration", authMW.Authorized(s.listMigrations)).Methods(http.MethodGet)
+	r.HandleFunc("/migration/{id}", authMW.Authorized(s.getMigration)).Methods(http.MethodGet)
+	r.HandleFunc("/migration/{id}/logs", authMW.Authorized(s.streamMigrationLogs)).Methods(http.MethodGet)
+	r.HandleFunc("/migration/{id}/cancel", authMW.Authorized(s.cancelMigration)).Methods(http.MethodPost)
 	r.HandleFunc("/artifact/download", authMW.Authorized(s.downloadArtifact)).Methods(http.MethodGet)
 	r.HandleFunc("/artifact/stop", authMW.Authorized(s.destroyClone)).Methods(http.MethodGet)
 	r.HandleFunc("/healthz", s.healthCheck).Methods(http.MethodGet)
@@ -112,3 +112,41 @@ func (cp *GHProvider) Extract(file string) (string, error) {
 
 	return source, nil
 }
+
+// PostComment posts a comment to the pull request.
+func (cp *GHProvider) PostComment(ctx context.Context, opts Opts, body string) error {
+	number, err := opts.RequestNumber()
+	if err != nil {
+		return err
+	}
+
+	if _, _, err := cp.client.Issues.CreateComment(ctx, opts.Owner, opts.Repo, number, &github.IssueComment{Body: &body}); err != nil {
+		return errors.Wrap(err, "failed to create comment")
+	}
+
+	return nil
+}
+
+// SetCommitStatus sets a status of the checked commit.
+func (cp *GHProvider) SetCommitStatus(ctx context.Context, opts Opts, status CommitStatus) error {
+	ref := getRunRef(opts)
+	if ref == "" {
+		return errors.New("commit reference is empty")
+	}
+
+	repoStatus := &github.RepoStatus{
+		State:       &status.State,
+		Context:     &status.Context,
+		Description: &status.Description,
+	}
+
+	if status.TargetURL != "" {
+		repoStatus.TargetURL = &status.TargetURL
+	}
+
+	if _, _, err := cp.client.Repositories.CreateStatus(ctx, opts.Owner, opts.Repo, ref, repoStatus); err != nil {
+		return errors.Wrap(err, "failed to create commit status")
+	}
+
+	return nil
+}
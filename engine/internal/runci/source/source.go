@@ -7,6 +7,11 @@ package source
 
 import (
 	"context"
+	"net/url"
+	"path"
+	"strconv"
+
+	"github.com/pkg/errors"
 )
 
 const (
@@ -14,6 +19,14 @@ const (
 	RepoDir = "/tmp/ci_checker"
 )
 
+// Commit status states supported by code providers.
+const (
+	StatePending = "pending"
+	StateSuccess = "success"
+	StateFailure = "failure"
+	StateError   = "error"
+)
+
 // Config describes the configuration of the plugged version control system.
 type Config struct {
 	Type  string `yaml:"type"`
@@ -24,6 +37,16 @@ type Config struct {
 type Provider interface {
 	Download(ctx context.Context, opts Opts, output string) error
 	Extract(file string) (sourceCodeDir string, err error)
+	PostComment(ctx context.Context, opts Opts, body string) error
+	SetCommitStatus(ctx context.Context, opts Opts, status CommitStatus) error
+}
+
+// CommitStatus describes a status of the checked commit.
+type CommitStatus struct {
+	State       string
+	Context     string
+	Description string
+	TargetURL   string
 }
 
 // Opts declares repository options.
@@ -38,3 +61,22 @@ type Opts struct {
 	RequestLink string `json:"request_link"`
 	DiffLink    string `json:"diff_link"`
 }
+
+// RequestNumber extracts the number of a pull/merge request from the request link.
+func (o Opts) RequestNumber() (int, error) {
+	if o.RequestLink == "" {
+		return 0, errors.New("request link is empty")
+	}
+
+	requestURL, err := url.Parse(o.RequestLink)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to parse request link")
+	}
+
+	number, err := strconv.Atoi(path.Base(requestURL.Path))
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to find request number in %q", o.RequestLink)
+	}
+
+	return number, nil
+}
@@ -20,7 +20,7 @@ import (
 func SendError(w http.ResponseWriter, r *http.Request, err error) {
 	log.Err(errDetailsMsg(r, err))
 
-	errorInternalServer, ok := errors.Cause(err).(models.Error)
+	errorInternalServer, ok := toModelError(err)
 	if !ok {
 		errorInternalServer = models.Error{
 			Code:    models.ErrCodeInternal,
@@ -28,9 +28,37 @@ func SendError(w http.ResponseWriter, r *http.Request, err error) {
 		}
 	}
 
+	// API v1 keeps reporting the generic error codes.
+	if !IsV2(r) {
+		errorInternalServer.Code = errorInternalServer.Code.Legacy()
+	}
+
 	_ = WriteJSON(w, toStatusCode(errorInternalServer), errorInternalServer)
 }
 
+// SendValidationError sends an error of request validation.
+func SendValidationError(w http.ResponseWriter, r *http.Request, message string) {
+	SendError(w, r, models.Error{
+		Code:    models.ErrCodeValidationFailed,
+		Message: message,
+	})
+}
+
+// toModelError extracts the model error. Errors of the engine are created with models.New.
+// Note that API v1 reported them as internal errors before API v2 was introduced,
+// now both API versions report their statuses, API v1 with the generic codes.
+func toModelError(err error) (models.Error, bool) {
+	switch modelErr := errors.Cause(err).(type) {
+	case models.Error:
+		return modelErr, true
+
+	case *models.Error:
+		return *modelErr, true
+	}
+
+	return models.Error{}, false
+}
+
 // SendBadRequestError sends a bad request error.
 func SendBadRequestError(w http.ResponseWriter, r *http.Request, message string) {
 	errorBadRequest := models.Error{
@@ -74,15 +102,23 @@ func errDetailsMsg(r *http.Request, err error) string {
 // toStatusCode converts an error to an HTTP status code.
 func toStatusCode(err models.Error) int {
 	switch err.Code {
-	case models.ErrCodeBadRequest:
+	case models.ErrCodeBadRequest, models.ErrCodeValidationFailed:
 		return http.StatusBadRequest
 
 	case models.ErrCodeUnauthorized:
 		return http.StatusUnauthorized
 
-	case models.ErrCodeNotFound:
+	case models.ErrCodeNotFound, models.ErrCodeCloneNotFound, models.ErrCodeSnapshotNotFound, models.ErrCodeSavepointNotFound,
+		models.ErrCodeUserNotFound:
 		return http.StatusNotFound
 
+	case models.ErrCodeCloneAlreadyExists, models.ErrCodeCloneProtected, models.ErrCodeCloneBusy, models.ErrCodeCloneNotStarted,
+		models.ErrCodeSavepointExists, models.ErrCodeUserExists, models.ErrCodeIdempotencyConflict:
+		return http.StatusConflict
+
+	case models.ErrCodePoolFull:
+		return http.StatusServiceUnavailable
+
 	case models.ErrCodeInternal:
 		return http.StatusInternalServerError
 
@@ -5,9 +5,15 @@
 package api
 
 import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
+	"github.com/pkg/errors"
+
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
 )
@@ -37,6 +43,30 @@ func TestErrorCodeStatuses(t *testing.T) {
 			error: "UNKNOWN_ERROR",
 			code:  500,
 		},
+		{
+			error: "CLONE_NOT_FOUND",
+			code:  404,
+		},
+		{
+			error: "CLONE_PROTECTED",
+			code:  409,
+		},
+		{
+			error: "CLONE_NOT_STARTED",
+			code:  409,
+		},
+		{
+			error: "SAVEPOINT_NOT_FOUND",
+			code:  404,
+		},
+		{
+			error: "SAVEPOINT_ALREADY_EXISTS",
+			code:  409,
+		},
+		{
+			error: "POOL_FULL",
+			code:  503,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -45,3 +75,83 @@ func TestErrorCodeStatuses(t *testing.T) {
 		assert.Equal(t, tc.code, errorCode)
 	}
 }
+
+func TestSendTypedError(t *testing.T) {
+	err := errors.Wrap(models.New(models.ErrCodeCloneProtected, "clone is protected"), "failed to destroy clone")
+
+	testCases := []struct {
+		name    string
+		handler http.Handler
+		code    models.ErrorCode
+		status  int
+	}{
+		{
+			name: "v1",
+			handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				SendError(w, r, err)
+			}),
+			code:   models.ErrCodeBadRequest,
+			status: http.StatusBadRequest,
+		},
+		{
+			name: "v2",
+			handler: V2(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				SendError(w, r, err)
+			})),
+			code:   models.ErrCodeCloneProtected,
+			status: http.StatusConflict,
+		},
+	}
+
+	for _, tc := range testCases {
+		recorder := httptest.NewRecorder()
+		tc.handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodDelete, "/clone/test", nil))
+
+		var responseError models.Error
+		require.NoError(t, json.NewDecoder(recorder.Body).Decode(&responseError), tc.name)
+
+		assert.Equal(t, tc.status, recorder.Code, tc.name)
+		assert.Equal(t, tc.code, responseError.Code, tc.name)
+		assert.Equal(t, "clone is protected", responseError.Message, tc.name)
+	}
+}
+
+func TestSendTypedErrorV1Compatibility(t *testing.T) {
+	testCases := []struct {
+		error  models.ErrorCode
+		code   models.ErrorCode
+		status int
+	}{
+		{
+			error:  models.ErrCodeCloneNotStarted,
+			code:   models.ErrCodeNotFound,
+			status: http.StatusNotFound,
+		},
+		{
+			error:  models.ErrCodeSnapshotNotFound,
+			code:   models.ErrCodeNotFound,
+			status: http.StatusNotFound,
+		},
+		{
+			error:  models.ErrCodeCloneProtected,
+			code:   models.ErrCodeBadRequest,
+			status: http.StatusBadRequest,
+		},
+		{
+			error:  models.ErrCodeCloneBusy,
+			code:   models.ErrCodeBadRequest,
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		recorder := httptest.NewRecorder()
+		SendError(recorder, httptest.NewRequest(http.MethodPost, "/clone/test/reset", nil), models.New(tc.error, "message"))
+
+		var responseError models.Error
+		require.NoError(t, json.NewDecoder(recorder.Body).Decode(&responseError), tc.error)
+
+		assert.Equal(t, tc.status, recorder.Code, tc.error)
+		assert.Equal(t, tc.code, responseError.Code, tc.error)
+	}
+}
@@ -20,6 +20,9 @@ const YamlContentType = "application/yaml; charset=utf-8"
 // JSONContentType is the content type header for JSON.
 const JSONContentType = "application/json; charset=utf-8"
 
+// PEMContentType is the content type header for PEM-encoded certificates.
+const PEMContentType = "application/x-pem-file"
+
 // WriteJSON responds with JSON.
 func WriteJSON(w http.ResponseWriter, httpStatusCode int, v interface{}) error {
 	b, err := json.MarshalIndent(v, "", "  ")
@@ -0,0 +1,25 @@
+package api
+
+import (
+	"context"
+	"net/http"
+)
+
+// V2PathPrefix defines the path prefix of API v2 routes.
+const V2PathPrefix = "/api/v2"
+
+type versionKey struct{}
+
+// V2 marks requests as API v2 requests. Errors of API v2 requests are reported with typed error codes.
+func V2(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), versionKey{}, true)))
+	})
+}
+
+// IsV2 checks if the request is an API v2 request.
+func IsV2(r *http.Request) bool {
+	isV2, _ := r.Context().Value(versionKey{}).(bool)
+
+	return isV2
+}
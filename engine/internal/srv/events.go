@@ -0,0 +1,186 @@
+package srv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/events"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/srv/api"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+const (
+	eventsKeepAliveInterval = 15 * time.Second
+	stateWatchInterval      = 5 * time.Second
+
+	cloneIDQueryParam   = "clone_id"
+	eventTypeQueryParam = "type"
+)
+
+// streamEvents streams state changes of the instance as server-sent events.
+func (s *Server) streamEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		api.SendError(w, r, errors.New("streaming is not supported"))
+		return
+	}
+
+	filter := events.Filter{CloneID: r.URL.Query().Get(cloneIDQueryParam)}
+
+	for _, eventType := range r.URL.Query()[eventTypeQueryParam] {
+		filter.Types = append(filter.Types, models.EventType(eventType))
+	}
+
+	subscription := s.events.Subscribe()
+	defer subscription.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	// Disable response buffering of reverse proxies.
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(eventsKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case event, ok := <-subscription.Events():
+			if !ok {
+				return
+			}
+
+			if !filter.Match(event) {
+				continue
+			}
+
+			if err := writeEvent(w, event); err != nil {
+				log.Dbg("Failed to write event: ", err)
+				return
+			}
+
+			flusher.Flush()
+
+		case <-keepAlive.C:
+			if _, err := io.WriteString(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+
+			flusher.Flush()
+
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func writeEvent(w io.Writer, event models.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode event")
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+
+	return err
+}
+
+// instanceState contains the watched state of pools and the retrieval service.
+type instanceState struct {
+	pools     map[string]models.PoolEntry
+	retrieval models.RetrievalStatus
+}
+
+// WatchInstanceState publishes changes of pools, snapshots and the retrieval status to event subscribers.
+// Clone changes are published by the cloning service.
+func (s *Server) WatchInstanceState(ctx context.Context) {
+	ticker := time.NewTicker(stateWatchInterval)
+	defer ticker.Stop()
+
+	state := s.collectInstanceState()
+
+	for {
+		select {
+		case <-ticker.C:
+			current := s.collectInstanceState()
+			s.publishStateChanges(state, current)
+			state = current
+
+			if s.events.HasSubscribers() {
+				// Snapshot changes are published by the cloning service when snapshots are refreshed.
+				if _, err := s.Cloning.GetSnapshots(); err != nil {
+					log.Dbg("Failed to refresh snapshots: ", err)
+				}
+			}
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *Server) collectInstanceState() instanceState {
+	state := instanceState{
+		pools:     make(map[string]models.PoolEntry),
+		retrieval: s.Retrieval.State.Status,
+	}
+
+	for _, fsManager := range s.pm.GetFSManagerOrderedList() {
+		fsmPool := fsManager.Pool()
+		if fsmPool == nil {
+			continue
+		}
+
+		var dataStateAt *models.LocalTime
+		if !fsmPool.DSA.IsZero() {
+			dataStateAt = models.NewLocalTime(fsmPool.DSA)
+		}
+
+		state.pools[fsmPool.Name] = models.PoolEntry{
+			Name:        fsmPool.Name,
+			Mode:        fsmPool.Mode,
+			DataStateAt: dataStateAt,
+			Status:      fsmPool.Status(),
+		}
+	}
+
+	return state
+}
+
+func (s *Server) publishStateChanges(previous, current instanceState) {
+	for name, pool := range current.pools {
+		previousPool, ok := previous.pools[name]
+		if ok && previousPool.Status == pool.Status && equalTime(previousPool.DataStateAt, pool.DataStateAt) {
+			continue
+		}
+
+		poolEntry := pool
+		s.events.Publish(models.Event{Type: models.EventPoolStatus, Pool: &poolEntry})
+	}
+
+	if previous.retrieval != current.retrieval {
+		s.events.Publish(models.Event{Type: models.EventRetrievalStatus, Retrieval: &models.Retrieving{
+			Mode:        s.Retrieval.State.Mode,
+			Status:      current.retrieval,
+			Alerts:      s.Retrieval.State.Alerts(),
+			LastRefresh: s.Retrieval.State.LastRefresh,
+		}})
+	}
+}
+
+func equalTime(a, b *models.LocalTime) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return a.Equal(b.Time)
+}
@@ -0,0 +1,89 @@
+package srv
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/events"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+func TestStreamEvents(t *testing.T) {
+	s := &Server{events: events.NewHub()}
+
+	ts := httptest.NewServer(http.HandlerFunc(s.streamEvents))
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+"?clone_id=clone1&type=clone_status", nil)
+	require.NoError(t, err)
+
+	response, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+
+	defer func() { _ = response.Body.Close() }()
+
+	assert.Equal(t, "text/event-stream", response.Header.Get("Content-Type"))
+	require.Eventually(t, s.events.HasSubscribers, time.Second, 10*time.Millisecond)
+
+	s.events.Publish(models.Event{Type: models.EventCloneStatus, Clone: &models.Clone{ID: "clone2"}})
+	s.events.Publish(models.Event{Type: models.EventCloneDeleted, Clone: &models.Clone{ID: "clone1"}})
+	s.events.Publish(models.Event{Type: models.EventCloneStatus, Clone: &models.Clone{ID: "clone1"}})
+
+	reader := bufio.NewReader(response.Body)
+	lines := make([]string, 0, 3)
+
+	for i := 0; i < 3; i++ {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+
+		lines = append(lines, strings.TrimSuffix(line, "\n"))
+	}
+
+	assert.Equal(t, "id: 3", lines[0])
+	assert.Equal(t, "event: clone_status", lines[1])
+	assert.True(t, strings.HasPrefix(lines[2], `data: {"id":3,"type":"clone_status"`))
+	assert.Contains(t, lines[2], `"clone":{"id":"clone1"`)
+}
+
+func TestPublishStateChanges(t *testing.T) {
+	s := &Server{events: events.NewHub()}
+
+	subscription := s.events.Subscribe()
+	defer subscription.Close()
+
+	dataStateAt := models.NewLocalTime(time.Date(2022, 5, 5, 0, 0, 0, 0, time.UTC))
+
+	previous := instanceState{
+		pools: map[string]models.PoolEntry{
+			"pool1": {Name: "pool1", Status: "active", DataStateAt: dataStateAt},
+			"pool2": {Name: "pool2", Status: "active"},
+		},
+		retrieval: models.Finished,
+	}
+
+	current := instanceState{
+		pools: map[string]models.PoolEntry{
+			"pool1": {Name: "pool1", Status: "active", DataStateAt: models.NewLocalTime(dataStateAt.Time)},
+			"pool2": {Name: "pool2", Status: "refreshing"},
+		},
+		retrieval: models.Finished,
+	}
+
+	s.publishStateChanges(previous, current)
+
+	event := <-subscription.Events()
+	assert.Equal(t, models.EventPoolStatus, event.Type)
+	assert.Equal(t, "pool2", event.Pool.Name)
+	assert.Len(t, subscription.Events(), 0)
+}
@@ -0,0 +1,114 @@
+package srv
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+
+	"github.com/pkg/errors"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/srv/api"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+)
+
+const (
+	// idempotencyKeyHeader defines the header containing a client-supplied key of the request.
+	idempotencyKeyHeader = "Idempotency-Key"
+
+	// idempotentReplayedHeader marks responses replayed from results of previous requests.
+	idempotentReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+)
+
+// idempotent makes repeated requests with the same Idempotency-Key header return the result of the first request.
+func (s *Server) idempotent(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(idempotencyKeyHeader)
+		if key == "" {
+			h(w, r)
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			api.SendValidationError(w, r, "Idempotency-Key header is too long")
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			api.SendBadRequestError(w, r, errors.Wrap(err, "failed to read a request body").Error())
+			return
+		}
+
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		record, err := s.Cloning.BeginIdempotentRequest(key, requestHash(r, body))
+		if err != nil {
+			api.SendError(w, r, err)
+			return
+		}
+
+		if record != nil {
+			log.Dbg("Replay the result of the request with Idempotency-Key: ", key)
+
+			w.Header().Set(idempotentReplayedHeader, "true")
+
+			if len(record.Response) > 0 {
+				if err := api.WriteData(w, record.StatusCode, record.Response); err != nil {
+					log.Err("Failed to write the replayed response: ", err)
+				}
+
+				return
+			}
+
+			w.WriteHeader(record.StatusCode)
+
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+		statusCode := http.StatusInternalServerError
+
+		// The key is released even if the handler panics, so the request can be retried.
+		defer func() {
+			s.Cloning.CompleteIdempotentRequest(key, statusCode, recorder.body.Bytes())
+		}()
+
+		h(recorder, r)
+
+		statusCode = recorder.statusCode
+	}
+}
+
+// requestHash identifies the request to detect reusing the idempotency key with another request.
+func requestHash(r *http.Request, body []byte) string {
+	hash := sha256.New()
+
+	_, _ = io.WriteString(hash, r.Method+" "+r.URL.Path+"\n")
+	_, _ = hash.Write(body)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// responseRecorder captures the status code and body of the response while writing it.
+type responseRecorder struct {
+	http.ResponseWriter
+	statusCode int
+	body       bytes.Buffer
+}
+
+// WriteHeader captures the status code.
+func (r *responseRecorder) WriteHeader(statusCode int) {
+	r.statusCode = statusCode
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write captures the body.
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.body.Write(data)
+
+	return r.ResponseWriter.Write(data)
+}
@@ -0,0 +1,86 @@
+package srv
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/cloning"
+)
+
+func TestIdempotentHandler(t *testing.T) {
+	s := &Server{Cloning: cloning.NewBase(&cloning.Config{}, nil, nil, nil, nil)}
+
+	calls := 0
+	handler := s.idempotent(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":"clone1"}`))
+	})
+
+	send := func(key, body string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodPost, "/clone", strings.NewReader(body))
+		if key != "" {
+			request.Header.Set(idempotencyKeyHeader, key)
+		}
+
+		recorder := httptest.NewRecorder()
+		handler(recorder, request)
+
+		return recorder
+	}
+
+	first := send("key1", `{"id":"clone1"}`)
+	assert.Equal(t, http.StatusCreated, first.Code)
+	assert.Empty(t, first.Header().Get(idempotentReplayedHeader))
+
+	replayed := send("key1", `{"id":"clone1"}`)
+	assert.Equal(t, http.StatusCreated, replayed.Code)
+	assert.Equal(t, "true", replayed.Header().Get(idempotentReplayedHeader))
+	assert.JSONEq(t, `{"id":"clone1"}`, replayed.Body.String())
+	assert.Equal(t, 1, calls)
+
+	conflict := send("key1", `{"id":"clone2"}`)
+	assert.Equal(t, http.StatusBadRequest, conflict.Code)
+	assert.Equal(t, 1, calls)
+
+	send("", `{"id":"clone1"}`)
+	assert.Equal(t, 2, calls)
+}
+
+func TestIdempotentHandlerPanic(t *testing.T) {
+	s := &Server{Cloning: cloning.NewBase(&cloning.Config{}, nil, nil, nil, nil)}
+
+	calls := 0
+	handler := s.idempotent(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+
+		if calls == 1 {
+			panic("handler failed")
+		}
+
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	send := func() *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodPost, "/clone", strings.NewReader(`{"id":"clone1"}`))
+		request.Header.Set(idempotencyKeyHeader, "key1")
+
+		recorder := httptest.NewRecorder()
+		handler(recorder, request)
+
+		return recorder
+	}
+
+	assert.Panics(t, func() { send() })
+
+	// The key is released, so the request is retried instead of being rejected as a request in progress.
+	retried := send()
+	assert.Equal(t, http.StatusCreated, retried.Code)
+	assert.Empty(t, retried.Header().Get(idempotentReplayedHeader))
+	assert.Equal(t, 2, calls)
+}
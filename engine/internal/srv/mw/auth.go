@@ -101,3 +101,20 @@ func (a *Auth) WebSocketsMW(holder *ws.TokenKeeper, h http.HandlerFunc) http.Han
 		h(w, r)
 	}
 }
+
+// StreamMW checks if the user has permission to access streaming handlers.
+// Browsers cannot set headers of event stream requests, so a web-socket token passed in query string is accepted as well.
+func (a *Auth) StreamMW(holder *ws.TokenKeeper, h http.HandlerFunc) http.HandlerFunc {
+	tokenHandler := a.WebSocketsMW(holder, h)
+	headerHandler := a.Authorized(h)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get(wsTokenKey) != "" {
+			tokenHandler(w, r)
+
+			return
+		}
+
+		headerHandler(w, r)
+	}
+}
@@ -6,9 +6,14 @@ package mw
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/srv/ws"
 )
 
 // Test constants.
@@ -58,3 +63,47 @@ func TestAccess(t *testing.T) {
 		assert.Equal(t, tc.result, isAllowed)
 	}
 }
+
+func TestStreamMW(t *testing.T) {
+	tokenKeeper, err := ws.NewTokenKeeper()
+	require.NoError(t, err)
+
+	wsToken, err := tokenKeeper.IssueToken()
+	require.NoError(t, err)
+
+	mw := Auth{verificationToken: testVerificationToken}
+
+	handler := mw.StreamMW(tokenKeeper, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	testCases := []struct {
+		name   string
+		header string
+		query  string
+		status int
+	}{
+		{name: "no tokens", status: http.StatusUnauthorized},
+		{name: "wrong verification token", header: "WrongToken", status: http.StatusUnauthorized},
+		{name: "correct verification token", header: testVerificationToken, status: http.StatusOK},
+		{name: "wrong web-socket token", query: "WrongToken", status: http.StatusUnauthorized},
+		{name: "correct web-socket token", query: wsToken, status: http.StatusOK},
+		{name: "expended web-socket token", query: wsToken, status: http.StatusUnauthorized},
+	}
+
+	for _, tc := range testCases {
+		t.Log(tc.name)
+
+		r := httptest.NewRequest(http.MethodGet, "/events", nil)
+		r.Header.Set(VerificationTokenHeader, tc.header)
+
+		if tc.query != "" {
+			r.URL.RawQuery = wsTokenKey + "=" + tc.query
+		}
+
+		w := httptest.NewRecorder()
+		handler(w, r)
+
+		assert.Equal(t, tc.status, w.Code)
+	}
+}
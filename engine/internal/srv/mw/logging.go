@@ -10,10 +10,19 @@ import (
 	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
 )
 
+// requestIDHeader defines the header with the request ID supplied by clients.
+const requestIDHeader = "X-Request-ID"
+
 // Logging logs the incoming request.
 func Logging(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		log.Msg("-> ", r.Method, r.RequestURI)
+		if requestID := r.Header.Get(requestIDHeader); requestID != "" {
+			w.Header().Set(requestIDHeader, requestID)
+			log.Msg("-> ", r.Method, r.RequestURI, "[request ID: "+requestID+"]")
+		} else {
+			log.Msg("-> ", r.Method, r.RequestURI)
+		}
+
 		next.ServeHTTP(w, r)
 	})
 }
@@ -0,0 +1,72 @@
+package mw
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/srv/api"
+)
+
+// maxRequestBodySize limits the size of validated request bodies.
+const maxRequestBodySize = 10 * 1024 * 1024
+
+// validator is implemented by request types checking their own constraints.
+type validator interface {
+	Validate() error
+}
+
+// ValidateJSON checks that the request body is a JSON document of the request type without unknown fields
+// and passes the request validation of the type. An empty body is treated as an empty object.
+func ValidateJSON(newRequest func() interface{}, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestBodySize+1))
+		if err != nil {
+			api.SendBadRequestError(w, r, fmt.Sprintf("failed to read a request body: %v", err))
+			return
+		}
+
+		if len(body) > maxRequestBodySize {
+			api.SendValidationError(w, r, fmt.Sprintf("request body exceeds %d bytes", maxRequestBodySize))
+			return
+		}
+
+		if len(bytes.TrimSpace(body)) == 0 {
+			body = []byte("{}")
+		} else if contentType := r.Header.Get("Content-Type"); contentType != "" {
+			if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType != "application/json" {
+				api.SendValidationError(w, r, fmt.Sprintf("unsupported content type: %q", contentType))
+				return
+			}
+		}
+
+		request := newRequest()
+
+		decoder := json.NewDecoder(bytes.NewReader(body))
+		decoder.DisallowUnknownFields()
+
+		if err := decoder.Decode(request); err != nil {
+			api.SendValidationError(w, r, fmt.Sprintf("invalid request body: %v", err))
+			return
+		}
+
+		if decoder.More() {
+			api.SendValidationError(w, r, "invalid request body: unexpected data after the JSON document")
+			return
+		}
+
+		if requestValidator, ok := request.(validator); ok {
+			if err := requestValidator.Validate(); err != nil {
+				api.SendValidationError(w, r, err.Error())
+				return
+			}
+		}
+
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		next(w, r)
+	}
+}
@@ -0,0 +1,54 @@
+package mw
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/client/dblabapi/types"
+)
+
+func TestValidateJSON(t *testing.T) {
+	testCases := []struct {
+		name        string
+		body        string
+		contentType string
+		status      int
+		passedBody  string
+	}{
+		{name: "valid", body: `{"latest": true}`, contentType: "application/json", status: http.StatusOK, passedBody: `{"latest": true}`},
+		{name: "empty body", status: http.StatusOK, passedBody: "{}"},
+		{name: "unknown field", body: `{"last": true}`, status: http.StatusBadRequest},
+		{name: "wrong type", body: `{"latest": "yes"}`, status: http.StatusBadRequest},
+		{name: "trailing data", body: `{"latest": true} {}`, status: http.StatusBadRequest},
+		{name: "content type", body: `{"latest": true}`, contentType: "text/plain", status: http.StatusBadRequest},
+		{name: "request validation", body: `{"latest": true, "snapshotID": "snapshot1"}`, status: http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		var passedBody string
+
+		handler := ValidateJSON(func() interface{} { return &types.ResetCloneRequest{} }, func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+
+			passedBody = string(body)
+		})
+
+		request := httptest.NewRequest(http.MethodPost, "/clone/test/reset", strings.NewReader(tc.body))
+		if tc.contentType != "" {
+			request.Header.Set("Content-Type", tc.contentType)
+		}
+
+		recorder := httptest.NewRecorder()
+		handler(recorder, request)
+
+		assert.Equal(t, tc.status, recorder.Code, tc.name)
+		assert.Equal(t, tc.passedBody, passedBody, tc.name)
+	}
+}
@@ -4,9 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gorilla/mux"
@@ -107,6 +110,91 @@ func (s *Server) getSnapshots(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (s *Server) listClones(w http.ResponseWriter, r *http.Request) {
+	listRequest, err := parseCloneListRequest(r.URL.Query())
+	if err != nil {
+		api.SendBadRequestError(w, r, err.Error())
+		return
+	}
+
+	page, err := s.Cloning.ListClones(listRequest)
+	if err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+
+	if err := api.WriteJSON(w, http.StatusOK, page); err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+}
+
+// parseCloneListRequest parses query parameters of the clone list request. Statuses can be passed
+// as repeated parameters or as a comma-separated list, ages are passed as durations, for example: 36h.
+func parseCloneListRequest(values url.Values) (types.CloneListRequest, error) {
+	listRequest := types.CloneListRequest{
+		Selector: values.Get("selector"),
+		Snapshot: values.Get("snapshot"),
+		Pool:     values.Get("pool"),
+		Creator:  values.Get("creator"),
+		Sort:     values.Get("sort"),
+	}
+
+	for _, status := range values["status"] {
+		for _, code := range strings.Split(status, ",") {
+			if code = strings.TrimSpace(code); code != "" {
+				listRequest.Status = append(listRequest.Status, code)
+			}
+		}
+	}
+
+	var err error
+
+	if listRequest.MinAge, err = parseDurationParam(values, "min_age"); err != nil {
+		return listRequest, err
+	}
+
+	if listRequest.MaxAge, err = parseDurationParam(values, "max_age"); err != nil {
+		return listRequest, err
+	}
+
+	if listRequest.Limit, err = parseIntParam(values, "limit"); err != nil {
+		return listRequest, err
+	}
+
+	if listRequest.Offset, err = parseIntParam(values, "offset"); err != nil {
+		return listRequest, err
+	}
+
+	return listRequest, nil
+}
+
+func parseDurationParam(values url.Values, param string) (time.Duration, error) {
+	if values.Get(param) == "" {
+		return 0, nil
+	}
+
+	value, err := time.ParseDuration(values.Get(param))
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", param, err)
+	}
+
+	return value, nil
+}
+
+func parseIntParam(values url.Values, param string) (int, error) {
+	if values.Get(param) == "" {
+		return 0, nil
+	}
+
+	value, err := strconv.Atoi(values.Get(param))
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", param, err)
+	}
+
+	return value, nil
+}
+
 func (s *Server) createClone(w http.ResponseWriter, r *http.Request) {
 	if s.engProps.GetEdition() == global.StandardEdition {
 		if err := s.engProps.CheckBilling(); err != nil {
@@ -122,20 +210,13 @@ func (s *Server) createClone(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := s.validator.ValidateCloneRequest(cloneRequest); err != nil {
-		api.SendBadRequestError(w, r, err.Error())
+		api.SendValidationError(w, r, err.Error())
 		return
 	}
 
 	newClone, err := s.Cloning.CreateClone(cloneRequest)
 	if err != nil {
-		var reqErr *models.Error
-		if errors.As(err, &reqErr) {
-			api.SendBadRequestError(w, r, reqErr.Error())
-			return
-		}
-
 		api.SendError(w, r, errors.Wrap(err, "failed to create clone"))
-
 		return
 	}
 
@@ -181,13 +262,19 @@ func (s *Server) patchClone(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var patchClone types.CloneUpdateRequest
+	var patchClone types.ClonePatchRequest
 	if err := api.ReadJSON(r, &patchClone); err != nil {
 		api.SendBadRequestError(w, r, err.Error())
 
 		return
 	}
 
+	if err := s.validator.ValidateLabels(patchClone.Labels); err != nil {
+		api.SendValidationError(w, r, err.Error())
+
+		return
+	}
+
 	updatedClone, err := s.Cloning.UpdateClone(cloneID, patchClone)
 	if err != nil {
 		api.SendError(w, r, errors.Wrap(err, "failed to update clone"))
@@ -200,6 +287,56 @@ func (s *Server) patchClone(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (s *Server) destroyClones(w http.ResponseWriter, r *http.Request) {
+	var bulkRequest types.BulkCloneRequest
+	if err := api.ReadJSON(r, &bulkRequest); err != nil {
+		api.SendBadRequestError(w, r, err.Error())
+		return
+	}
+
+	result, err := s.Cloning.DestroyClones(bulkRequest)
+	if err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+
+	for _, cloneResult := range result.Clones {
+		if cloneResult.Result == models.BulkResultSucceeded {
+			s.tm.SendEvent(context.Background(), telemetry.CloneDestroyedEvent, telemetry.CloneDestroyed{
+				ID: util.HashID(cloneResult.CloneID),
+			})
+		}
+	}
+
+	if err := api.WriteJSON(w, http.StatusOK, result); err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+
+	log.Dbg(fmt.Sprintf("Bulk destroy: %d succeeded, %d failed, %d skipped", result.Succeeded, result.Failed, result.Skipped))
+}
+
+func (s *Server) resetClones(w http.ResponseWriter, r *http.Request) {
+	var bulkRequest types.BulkResetRequest
+	if err := api.ReadJSON(r, &bulkRequest); err != nil {
+		api.SendBadRequestError(w, r, err.Error())
+		return
+	}
+
+	result, err := s.Cloning.ResetClones(bulkRequest)
+	if err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+
+	if err := api.WriteJSON(w, http.StatusOK, result); err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+
+	log.Dbg(fmt.Sprintf("Bulk reset: %d succeeded, %d failed, %d skipped", result.Succeeded, result.Failed, result.Skipped))
+}
+
 func (s *Server) getClone(w http.ResponseWriter, r *http.Request) {
 	cloneID := mux.Vars(r)["id"]
 
@@ -210,7 +347,14 @@ func (s *Server) getClone(w http.ResponseWriter, r *http.Request) {
 
 	clone, err := s.Cloning.GetClone(cloneID)
 	if err != nil {
-		api.SendNotFoundError(w, r)
+		// API v1 reports the generic error of missing objects.
+		if !api.IsV2(r) {
+			api.SendNotFoundError(w, r)
+			return
+		}
+
+		api.SendError(w, r, err)
+
 		return
 	}
 
@@ -237,8 +381,8 @@ func (s *Server) resetClone(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	if resetOptions.Latest && resetOptions.SnapshotID != "" {
-		api.SendBadRequestError(w, r, "parameters `latest` and `snapshot ID` must not be specified together")
+	if err := resetOptions.Validate(); err != nil {
+		api.SendValidationError(w, r, err.Error())
 		return
 	}
 
@@ -250,6 +394,238 @@ func (s *Server) resetClone(w http.ResponseWriter, r *http.Request) {
 	log.Dbg(fmt.Sprintf("Clone ID=%s is being reset", cloneID))
 }
 
+func (s *Server) stopClone(w http.ResponseWriter, r *http.Request) {
+	cloneID := mux.Vars(r)["id"]
+
+	if cloneID == "" {
+		api.SendBadRequestError(w, r, "ID must not be empty")
+		return
+	}
+
+	if err := s.Cloning.StopClone(cloneID); err != nil {
+		api.SendError(w, r, errors.Wrap(err, "failed to stop clone"))
+		return
+	}
+
+	log.Dbg(fmt.Sprintf("Clone ID=%s has been stopped", cloneID))
+}
+
+func (s *Server) startClone(w http.ResponseWriter, r *http.Request) {
+	cloneID := mux.Vars(r)["id"]
+
+	if cloneID == "" {
+		api.SendBadRequestError(w, r, "ID must not be empty")
+		return
+	}
+
+	if err := s.Cloning.StartClone(cloneID); err != nil {
+		api.SendError(w, r, errors.Wrap(err, "failed to start clone"))
+		return
+	}
+
+	log.Dbg(fmt.Sprintf("Clone ID=%s is being started", cloneID))
+}
+
+func (s *Server) createSavepoint(w http.ResponseWriter, r *http.Request) {
+	cloneID := mux.Vars(r)["id"]
+
+	if cloneID == "" {
+		api.SendBadRequestError(w, r, "ID must not be empty")
+		return
+	}
+
+	var savepointRequest types.SavepointCreateRequest
+
+	if r.Body != http.NoBody {
+		if err := json.NewDecoder(r.Body).Decode(&savepointRequest); err != nil {
+			api.SendError(w, r, errors.Wrap(err, "failed to parse request parameters"))
+			return
+		}
+	}
+
+	if err := savepointRequest.Validate(); err != nil {
+		api.SendValidationError(w, r, err.Error())
+		return
+	}
+
+	savepoint, err := s.Cloning.CreateSavepoint(cloneID, savepointRequest)
+	if err != nil {
+		api.SendError(w, r, errors.Wrap(err, "failed to create savepoint"))
+		return
+	}
+
+	if err := api.WriteJSON(w, http.StatusCreated, savepoint); err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+
+	log.Dbg(fmt.Sprintf("Savepoint %s of clone ID=%s has been created", savepoint.Name, cloneID))
+}
+
+func (s *Server) rollbackSavepoint(w http.ResponseWriter, r *http.Request) {
+	cloneID := mux.Vars(r)["id"]
+
+	if cloneID == "" {
+		api.SendBadRequestError(w, r, "ID must not be empty")
+		return
+	}
+
+	var rollbackRequest types.SavepointRollbackRequest
+	if err := api.ReadJSON(r, &rollbackRequest); err != nil {
+		api.SendBadRequestError(w, r, err.Error())
+		return
+	}
+
+	if err := rollbackRequest.Validate(); err != nil {
+		api.SendValidationError(w, r, err.Error())
+		return
+	}
+
+	if err := s.Cloning.RollbackSavepoint(cloneID, rollbackRequest); err != nil {
+		api.SendError(w, r, errors.Wrap(err, "failed to rollback clone"))
+		return
+	}
+
+	log.Dbg(fmt.Sprintf("Clone ID=%s is being rolled back to savepoint %s", cloneID, rollbackRequest.Name))
+}
+
+func (s *Server) listSavepoints(w http.ResponseWriter, r *http.Request) {
+	cloneID := mux.Vars(r)["id"]
+
+	if cloneID == "" {
+		api.SendBadRequestError(w, r, "ID must not be empty")
+		return
+	}
+
+	savepoints, err := s.Cloning.ListSavepoints(cloneID)
+	if err != nil {
+		api.SendError(w, r, errors.Wrap(err, "failed to list savepoints"))
+		return
+	}
+
+	if err := api.WriteJSON(w, http.StatusOK, savepoints); err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+}
+
+func (s *Server) upgradeClone(w http.ResponseWriter, r *http.Request) {
+	cloneID := mux.Vars(r)["id"]
+
+	if cloneID == "" {
+		api.SendBadRequestError(w, r, "ID must not be empty")
+		return
+	}
+
+	var upgradeRequest types.CloneUpgradeRequest
+	if err := api.ReadJSON(r, &upgradeRequest); err != nil {
+		api.SendBadRequestError(w, r, err.Error())
+		return
+	}
+
+	if err := upgradeRequest.Validate(); err != nil {
+		api.SendValidationError(w, r, err.Error())
+		return
+	}
+
+	if err := s.Cloning.UpgradeClone(cloneID, upgradeRequest); err != nil {
+		api.SendError(w, r, errors.Wrap(err, "failed to upgrade clone"))
+		return
+	}
+
+	log.Dbg(fmt.Sprintf("Clone ID=%s is being upgraded using image %s", cloneID, upgradeRequest.DockerImage))
+}
+
+func (s *Server) getCloneUpgrade(w http.ResponseWriter, r *http.Request) {
+	cloneID := mux.Vars(r)["id"]
+
+	if cloneID == "" {
+		api.SendBadRequestError(w, r, "ID must not be empty")
+		return
+	}
+
+	upgrade, err := s.Cloning.GetCloneUpgrade(cloneID)
+	if err != nil {
+		api.SendError(w, r, errors.Wrap(err, "failed to get clone upgrade"))
+		return
+	}
+
+	if err := api.WriteJSON(w, http.StatusOK, upgrade); err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+}
+
+func (s *Server) createCloneUser(w http.ResponseWriter, r *http.Request) {
+	cloneID := mux.Vars(r)["id"]
+
+	if cloneID == "" {
+		api.SendBadRequestError(w, r, "ID must not be empty")
+		return
+	}
+
+	var userRequest types.CloneUserRequest
+	if err := api.ReadJSON(r, &userRequest); err != nil {
+		api.SendBadRequestError(w, r, err.Error())
+		return
+	}
+
+	if err := userRequest.Validate(); err != nil {
+		api.SendValidationError(w, r, err.Error())
+		return
+	}
+
+	user, err := s.Cloning.CreateCloneUser(cloneID, userRequest)
+	if err != nil {
+		api.SendError(w, r, errors.Wrap(err, "failed to create clone user"))
+		return
+	}
+
+	if err := api.WriteJSON(w, http.StatusCreated, user); err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+
+	log.Dbg(fmt.Sprintf("User %s of clone ID=%s has been created", user.Name, cloneID))
+}
+
+func (s *Server) deleteCloneUser(w http.ResponseWriter, r *http.Request) {
+	cloneID := mux.Vars(r)["id"]
+	username := mux.Vars(r)["name"]
+
+	if cloneID == "" || username == "" {
+		api.SendBadRequestError(w, r, "clone ID and user name must not be empty")
+		return
+	}
+
+	if err := s.Cloning.DeleteCloneUser(cloneID, username); err != nil {
+		api.SendError(w, r, errors.Wrap(err, "failed to delete clone user"))
+		return
+	}
+
+	log.Dbg(fmt.Sprintf("User %s of clone ID=%s has been deleted", username, cloneID))
+}
+
+func (s *Server) listCloneUsers(w http.ResponseWriter, r *http.Request) {
+	cloneID := mux.Vars(r)["id"]
+
+	if cloneID == "" {
+		api.SendBadRequestError(w, r, "ID must not be empty")
+		return
+	}
+
+	users, err := s.Cloning.ListCloneUsers(cloneID)
+	if err != nil {
+		api.SendError(w, r, errors.Wrap(err, "failed to list clone users"))
+		return
+	}
+
+	if err := api.WriteJSON(w, http.StatusOK, users); err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+}
+
 func (s *Server) startObservation(w http.ResponseWriter, r *http.Request) {
 	if s.Platform.Client == nil {
 		api.SendBadRequestError(w, r, "cannot start the session observation because a Platform client is not configured")
@@ -262,9 +638,14 @@ func (s *Server) startObservation(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// API v2 passes the clone ID in the path.
+	if cloneID, ok := mux.Vars(r)["clone_id"]; ok {
+		observationRequest.CloneID = cloneID
+	}
+
 	clone, err := s.Cloning.GetClone(observationRequest.CloneID)
 	if err != nil {
-		api.SendNotFoundError(w, r)
+		api.SendError(w, r, err)
 		return
 	}
 
@@ -337,6 +718,11 @@ func (s *Server) stopObservation(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// API v2 passes the clone ID in the path.
+	if cloneID, ok := mux.Vars(r)["clone_id"]; ok {
+		observationRequest.CloneID = cloneID
+	}
+
 	observingClone, err := s.Observer.GetObservingClone(observationRequest.CloneID)
 	if err != nil {
 		api.SendNotFoundError(w, r)
@@ -420,6 +806,138 @@ func (s *Server) stopObservation(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (s *Server) uploadArtifact(w http.ResponseWriter, r *http.Request) {
+	artifactType := requestParam(r, "artifact_type")
+
+	if !observer.IsUploadableArtifactType(artifactType) {
+		api.SendBadRequestError(w, r, fmt.Sprintf("artifact %q is not available to upload", artifactType))
+		return
+	}
+
+	cloneID := requestParam(r, "clone_id")
+
+	observingClone, err := s.Observer.GetObservingClone(cloneID)
+	if err != nil {
+		api.SendNotFoundError(w, r)
+		return
+	}
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		api.SendBadRequestError(w, r, fmt.Sprintf("failed to read artifact: %v", err))
+		return
+	}
+
+	if !json.Valid(data) {
+		api.SendBadRequestError(w, r, "artifact must be a valid JSON document")
+		return
+	}
+
+	if err := observingClone.StoreArtifact(artifactType, data); err != nil {
+		api.SendBadRequestError(w, r, err.Error())
+		return
+	}
+
+	log.Dbg(fmt.Sprintf("Artifact %q has been uploaded for clone %s", artifactType, cloneID))
+}
+
+func (s *Server) checkPlans(w http.ResponseWriter, r *http.Request) {
+	var planCheckRequest types.PlanCheckRequest
+	if err := api.ReadJSON(r, &planCheckRequest); err != nil {
+		api.SendBadRequestError(w, r, err.Error())
+		return
+	}
+
+	report, err := s.planChecker.Check(r.Context(), &planCheckRequest)
+	if err != nil {
+		var reqErr *models.Error
+		if errors.As(err, &reqErr) {
+			api.SendBadRequestError(w, r, reqErr.Error())
+			return
+		}
+
+		api.SendError(w, r, errors.Wrap(err, "failed to check query plans"))
+
+		return
+	}
+
+	if err := api.WriteJSON(w, http.StatusOK, report); err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+}
+
+func (s *Server) startReplay(w http.ResponseWriter, r *http.Request) {
+	var replayRequest types.StartReplayRequest
+	if err := api.ReadJSON(r, &replayRequest); err != nil {
+		api.SendBadRequestError(w, r, err.Error())
+		return
+	}
+
+	// API v2 passes the clone ID in the path.
+	if cloneID, ok := mux.Vars(r)["clone_id"]; ok {
+		replayRequest.CloneID = cloneID
+	}
+
+	status, err := s.replayer.Start(&replayRequest)
+	if err != nil {
+		sendReplayError(w, r, err)
+		return
+	}
+
+	if err := api.WriteJSON(w, http.StatusOK, status); err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+}
+
+func (s *Server) stopReplay(w http.ResponseWriter, r *http.Request) {
+	var replayRequest types.StopReplayRequest
+	if err := api.ReadJSON(r, &replayRequest); err != nil {
+		api.SendBadRequestError(w, r, err.Error())
+		return
+	}
+
+	// API v2 passes the clone ID in the path.
+	if cloneID, ok := mux.Vars(r)["clone_id"]; ok {
+		replayRequest.CloneID = cloneID
+	}
+
+	status, err := s.replayer.Stop(replayRequest.CloneID)
+	if err != nil {
+		sendReplayError(w, r, err)
+		return
+	}
+
+	if err := api.WriteJSON(w, http.StatusOK, status); err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+}
+
+func (s *Server) replayStatus(w http.ResponseWriter, r *http.Request) {
+	status, err := s.replayer.Status(mux.Vars(r)["clone_id"])
+	if err != nil {
+		sendReplayError(w, r, err)
+		return
+	}
+
+	if err := api.WriteJSON(w, http.StatusOK, status); err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+}
+
+func sendReplayError(w http.ResponseWriter, r *http.Request, err error) {
+	var reqErr *models.Error
+	if errors.As(err, &reqErr) {
+		api.SendError(w, r, *reqErr)
+		return
+	}
+
+	api.SendError(w, r, errors.Wrap(err, "failed to replay workload"))
+}
+
 func (s *Server) sessionSummaryObservation(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 
@@ -450,21 +968,20 @@ func (s *Server) sessionSummaryObservation(w http.ResponseWriter, r *http.Reques
 }
 
 func (s *Server) downloadArtifact(w http.ResponseWriter, r *http.Request) {
-	values := r.URL.Query()
-	artifactType := values.Get("artifact_type")
+	artifactType := requestParam(r, "artifact_type")
 
 	if !observer.IsAvailableArtifactType(artifactType) {
 		api.SendBadRequestError(w, r, fmt.Sprintf("artifact %q is not available to download", artifactType))
 		return
 	}
 
-	sessionID, err := strconv.ParseUint(values.Get("session_id"), 10, 64)
+	sessionID, err := strconv.ParseUint(requestParam(r, "session_id"), 10, 64)
 	if err != nil {
 		api.SendBadRequestError(w, r, fmt.Sprintf("invalid session_id: %v", sessionID))
 		return
 	}
 
-	cloneID := values.Get("clone_id")
+	cloneID := requestParam(r, "clone_id")
 
 	observingClone, err := s.Observer.GetObservingClone(cloneID)
 	if err != nil || !observingClone.IsExistArtifacts(sessionID) {
@@ -476,7 +993,29 @@ func (s *Server) downloadArtifact(w http.ResponseWriter, r *http.Request) {
 	http.ServeFile(w, r, filePath)
 }
 
+// requestParam returns the path variable of API v2 routes or the query parameter of API v1 routes.
+func requestParam(r *http.Request, name string) string {
+	if value, ok := mux.Vars(r)[name]; ok {
+		return value
+	}
+
+	return r.URL.Query().Get(name)
+}
+
 // healthCheck provides a health check handler.
+func (s *Server) getCACert(w http.ResponseWriter, r *http.Request) {
+	caCert, err := s.Cloning.CACert()
+	if err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+
+	if err := api.WriteDataTyped(w, http.StatusOK, api.PEMContentType, caCert); err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+}
+
 func (s *Server) healthCheck(w http.ResponseWriter, _ *http.Request) {
 	w.Header().Set("Content-Type", api.JSONContentType)
 
@@ -0,0 +1,55 @@
+package srv
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/cloning"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/srv/mw"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+func TestV1Errors(t *testing.T) {
+	s := &Server{Cloning: cloning.NewBase(&cloning.Config{}, nil, nil, nil, nil)}
+	authMW := mw.NewAuth("token", nil)
+
+	r := mux.NewRouter()
+	r.HandleFunc("/clone/{id}", authMW.Authorized(s.destroyClone)).Methods(http.MethodDelete)
+	r.HandleFunc("/clone/{id}", authMW.Authorized(s.getClone)).Methods(http.MethodGet)
+	r.HandleFunc("/clone/{id}/reset", authMW.Authorized(s.resetClone)).Methods(http.MethodPost)
+	r.HandleFunc("/clone/{id}/savepoints", authMW.Authorized(s.listSavepoints)).Methods(http.MethodGet)
+
+	// API v1 reports errors of the engine with the generic codes.
+	testCases := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+	}{
+		{name: "destroy unknown clone", method: http.MethodDelete, path: "/clone/clone1"},
+		{name: "get unknown clone", method: http.MethodGet, path: "/clone/clone1"},
+		{name: "reset unknown clone", method: http.MethodPost, path: "/clone/clone1/reset", body: `{}`},
+		{name: "list savepoints of unknown clone", method: http.MethodGet, path: "/clone/clone1/savepoints"},
+	}
+
+	for _, tc := range testCases {
+		request := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+		request.Header.Set(mw.VerificationTokenHeader, "token")
+
+		recorder := httptest.NewRecorder()
+		r.ServeHTTP(recorder, request)
+
+		var responseError models.Error
+		require.NoError(t, json.NewDecoder(recorder.Body).Decode(&responseError), tc.name)
+
+		assert.Equal(t, http.StatusNotFound, recorder.Code, tc.name)
+		assert.Equal(t, models.ErrCodeNotFound, responseError.Code, tc.name)
+	}
+}
@@ -0,0 +1,81 @@
+package srv
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/srv/api"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/srv/mw"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/client/dblabapi/types"
+)
+
+// initV2Handlers registers API v2 routes described by api/swagger-spec/dblab_server_v2.yaml.
+// API v2 reuses handlers of API v1, but names resources consistently, validates request bodies
+// and reports typed error codes.
+func (s *Server) initV2Handlers(r *mux.Router, authMW *mw.Auth) {
+	v2 := r.PathPrefix(api.V2PathPrefix).Subrouter()
+	v2.Use(api.V2)
+
+	validate := func(newRequest func() interface{}, h http.HandlerFunc) http.HandlerFunc {
+		return authMW.Authorized(mw.ValidateJSON(newRequest, h))
+	}
+
+	v2.HandleFunc("/instance/status", authMW.Authorized(s.getInstanceStatus)).Methods(http.MethodGet)
+	v2.HandleFunc("/instance/retrieval", authMW.Authorized(s.retrievalState)).Methods(http.MethodGet)
+	v2.HandleFunc("/instance/events", authMW.StreamMW(s.wsService.tokenKeeper, s.streamEvents)).Methods(http.MethodGet)
+
+	v2.HandleFunc("/snapshots", authMW.Authorized(s.getSnapshots)).Methods(http.MethodGet)
+
+	v2.HandleFunc("/clones", authMW.Authorized(s.listClones)).Methods(http.MethodGet)
+	v2.HandleFunc("/clones", validate(func() interface{} { return &types.CloneCreateRequest{} }, s.idempotent(s.createClone))).
+		Methods(http.MethodPost)
+	v2.HandleFunc("/clones/bulk/destroy", validate(func() interface{} { return &types.BulkCloneRequest{} }, s.destroyClones)).
+		Methods(http.MethodPost)
+	v2.HandleFunc("/clones/bulk/reset", validate(func() interface{} { return &types.BulkResetRequest{} }, s.resetClones)).
+		Methods(http.MethodPost)
+	v2.HandleFunc("/clones/{id}", authMW.Authorized(s.getClone)).Methods(http.MethodGet)
+	v2.HandleFunc("/clones/{id}", validate(func() interface{} { return &types.ClonePatchRequest{} }, s.patchClone)).
+		Methods(http.MethodPatch)
+	v2.HandleFunc("/clones/{id}", authMW.Authorized(s.idempotent(s.destroyClone))).Methods(http.MethodDelete)
+	v2.HandleFunc("/clones/{id}/reset", validate(func() interface{} { return &types.ResetCloneRequest{} }, s.idempotent(s.resetClone))).
+		Methods(http.MethodPost)
+	v2.HandleFunc("/clones/{id}/stop", authMW.Authorized(s.idempotent(s.stopClone))).Methods(http.MethodPost)
+	v2.HandleFunc("/clones/{id}/start", authMW.Authorized(s.idempotent(s.startClone))).Methods(http.MethodPost)
+	v2.HandleFunc("/clones/{id}/savepoints", authMW.Authorized(s.listSavepoints)).Methods(http.MethodGet)
+	v2.HandleFunc("/clones/{id}/savepoints",
+		validate(func() interface{} { return &types.SavepointCreateRequest{} }, s.createSavepoint)).Methods(http.MethodPost)
+	v2.HandleFunc("/clones/{id}/rollback",
+		validate(func() interface{} { return &types.SavepointRollbackRequest{} }, s.idempotent(s.rollbackSavepoint))).
+		Methods(http.MethodPost)
+	v2.HandleFunc("/clones/{id}/upgrade",
+		validate(func() interface{} { return &types.CloneUpgradeRequest{} }, s.idempotent(s.upgradeClone))).Methods(http.MethodPost)
+	v2.HandleFunc("/clones/{id}/upgrade", authMW.Authorized(s.getCloneUpgrade)).Methods(http.MethodGet)
+	v2.HandleFunc("/clones/{id}/users", authMW.Authorized(s.listCloneUsers)).Methods(http.MethodGet)
+	v2.HandleFunc("/clones/{id}/users",
+		validate(func() interface{} { return &types.CloneUserRequest{} }, s.createCloneUser)).Methods(http.MethodPost)
+	v2.HandleFunc("/clones/{id}/users/{name}", authMW.Authorized(s.deleteCloneUser)).Methods(http.MethodDelete)
+
+	v2.HandleFunc("/clones/{clone_id}/observations",
+		validate(func() interface{} { return &types.StartObservationRequest{} }, s.startObservation)).Methods(http.MethodPost)
+	v2.HandleFunc("/clones/{clone_id}/observations/stop",
+		validate(func() interface{} { return &types.StopObservationRequest{} }, s.stopObservation)).Methods(http.MethodPost)
+	v2.HandleFunc("/clones/{clone_id}/observations/{session_id}", authMW.Authorized(s.sessionSummaryObservation)).
+		Methods(http.MethodGet)
+	v2.HandleFunc("/clones/{clone_id}/observations/{session_id}/artifacts/{artifact_type}", authMW.Authorized(s.downloadArtifact)).
+		Methods(http.MethodGet)
+	v2.HandleFunc("/clones/{clone_id}/observations/artifacts/{artifact_type}", authMW.Authorized(s.uploadArtifact)).
+		Methods(http.MethodPost)
+
+	v2.HandleFunc("/clones/{clone_id}/replay", authMW.Authorized(s.replayStatus)).Methods(http.MethodGet)
+	v2.HandleFunc("/clones/{clone_id}/replay",
+		validate(func() interface{} { return &types.StartReplayRequest{} }, s.startReplay)).Methods(http.MethodPost)
+	v2.HandleFunc("/clones/{clone_id}/replay/stop",
+		validate(func() interface{} { return &types.StopReplayRequest{} }, s.stopReplay)).Methods(http.MethodPost)
+
+	v2.HandleFunc("/plan-checks", validate(func() interface{} { return &types.PlanCheckRequest{} }, s.checkPlans)).
+		Methods(http.MethodPost)
+
+	// Router middlewares are not applied to the not found handler.
+	v2.NotFoundHandler = api.V2(http.HandlerFunc(api.SendNotFoundError))
+}
@@ -0,0 +1,180 @@
+package srv
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/cloning"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/srv/api"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/srv/mw"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+const v2SpecPath = "../../api/swagger-spec/dblab_server_v2.yaml"
+
+type v2Spec struct {
+	Paths      map[string]map[string]interface{} `yaml:"paths"`
+	Components struct {
+		Schemas struct {
+			ErrorCode struct {
+				Enum []string `yaml:"enum"`
+			} `yaml:"ErrorCode"`
+		} `yaml:"schemas"`
+	} `yaml:"components"`
+}
+
+func loadV2Spec(t *testing.T) v2Spec {
+	data, err := os.ReadFile(v2SpecPath)
+	require.NoError(t, err)
+
+	var spec v2Spec
+	require.NoError(t, yaml.Unmarshal(data, &spec))
+
+	return spec
+}
+
+func newV2Router(s *Server) *mux.Router {
+	r := mux.NewRouter().StrictSlash(true).UseEncodedPath()
+	s.initV2Handlers(r, mw.NewAuth("token", nil))
+
+	return r
+}
+
+func TestV2RoutesMatchSpec(t *testing.T) {
+	spec := loadV2Spec(t)
+
+	specOperations := []string{}
+
+	for path, operations := range spec.Paths {
+		for method := range operations {
+			if method == "parameters" {
+				continue
+			}
+
+			specOperations = append(specOperations, strings.ToUpper(method)+" "+path)
+		}
+	}
+
+	routeOperations := []string{}
+
+	err := newV2Router(&Server{}).Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return err
+		}
+
+		methods, err := route.GetMethods()
+		if err != nil {
+			// Subrouters have no methods.
+			return nil
+		}
+
+		for _, method := range methods {
+			routeOperations = append(routeOperations, method+" "+strings.TrimPrefix(path, api.V2PathPrefix))
+		}
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	sort.Strings(specOperations)
+	sort.Strings(routeOperations)
+
+	assert.Equal(t, specOperations, routeOperations)
+}
+
+func TestV2ErrorCodesMatchSpec(t *testing.T) {
+	spec := loadV2Spec(t)
+
+	codes := []string{}
+
+	for _, code := range []models.ErrorCode{
+		models.ErrCodeInternal, models.ErrCodeBadRequest, models.ErrCodeUnauthorized, models.ErrCodeNotFound,
+		models.ErrCodeValidationFailed, models.ErrCodeCloneNotFound, models.ErrCodeCloneAlreadyExists, models.ErrCodeCloneProtected,
+		models.ErrCodeCloneBusy, models.ErrCodeCloneNotStarted, models.ErrCodeSnapshotNotFound, models.ErrCodePoolFull,
+		models.ErrCodeIdempotencyConflict, models.ErrCodeSavepointNotFound,
+		models.ErrCodeSavepointExists, models.ErrCodeUserNotFound, models.ErrCodeUserExists,
+	} {
+		codes = append(codes, string(code))
+	}
+
+	assert.ElementsMatch(t, spec.Components.Schemas.ErrorCode.Enum, codes)
+}
+
+func TestV2Errors(t *testing.T) {
+	s := &Server{Cloning: cloning.NewBase(&cloning.Config{}, nil, nil, nil, nil)}
+	r := newV2Router(s)
+
+	testCases := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+		code   models.ErrorCode
+	}{
+		{
+			name:   "unauthorized",
+			method: http.MethodGet,
+			path:   "/api/v2/clones/clone1",
+			status: http.StatusUnauthorized,
+			code:   models.ErrCodeUnauthorized,
+		},
+		{
+			name:   "clone not found",
+			method: http.MethodGet,
+			path:   "/api/v2/clones/clone1",
+			status: http.StatusNotFound,
+			code:   models.ErrCodeCloneNotFound,
+		},
+		{
+			name:   "unknown field",
+			method: http.MethodPatch,
+			path:   "/api/v2/clones/clone1",
+			body:   `{"protect": true}`,
+			status: http.StatusBadRequest,
+			code:   models.ErrCodeValidationFailed,
+		},
+		{
+			name:   "invalid reset options",
+			method: http.MethodPost,
+			path:   "/api/v2/clones/clone1/reset",
+			body:   `{"snapshotID": "snapshot1", "latest": true}`,
+			status: http.StatusBadRequest,
+			code:   models.ErrCodeValidationFailed,
+		},
+		{
+			name:   "unknown route",
+			method: http.MethodGet,
+			path:   "/api/v2/clone/clone1",
+			status: http.StatusNotFound,
+			code:   models.ErrCodeNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		request := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+		if tc.code != models.ErrCodeUnauthorized {
+			request.Header.Set(mw.VerificationTokenHeader, "token")
+		}
+
+		recorder := httptest.NewRecorder()
+		r.ServeHTTP(recorder, request)
+
+		var responseError models.Error
+		require.NoError(t, json.NewDecoder(recorder.Body).Decode(&responseError), tc.name)
+
+		assert.Equal(t, tc.status, recorder.Code, tc.name)
+		assert.Equal(t, tc.code, responseError.Code, tc.name)
+	}
+}
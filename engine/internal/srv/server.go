@@ -21,10 +21,13 @@ import (
 	"gitlab.com/postgres-ai/database-lab/v3/internal/billing"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/cloning"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/embeddedui"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/events"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/observer"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/plancheck"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/platform"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/provision"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/pool"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/replay"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/srv/api"
 	srvCfg "gitlab.com/postgres-ai/database-lab/v3/internal/srv/config"
@@ -50,6 +53,9 @@ type Server struct {
 	Retrieval   *retrieval.Retrieval
 	Platform    *platform.Service
 	Observer    *observer.Observer
+	planChecker *plancheck.Checker
+	replayer    *replay.Replayer
+	events      *events.Hub
 	billingSvc  *billing.Billing
 	wsService   WSService
 	httpSrv     *http.Server
@@ -72,7 +78,7 @@ type WSService struct {
 func NewServer(cfg *srvCfg.Config, globalCfg *global.Config, engineProps *global.EngineProps,
 	dockerClient *client.Client, cloning *cloning.Base, provisioner *provision.Provisioner,
 	retrievalSvc *retrieval.Retrieval, platform *platform.Service, billingSvc *billing.Billing, observer *observer.Observer,
-	pm *pool.Manager, tm *telemetry.Agent, tokenKeeper *ws.TokenKeeper,
+	pm *pool.Manager, tm *telemetry.Agent, tokenKeeper *ws.TokenKeeper, eventHub *events.Hub,
 	filtering *log.Filtering, uiManager *embeddedui.UIManager, reloadConfigFn func(server *Server) error) *Server {
 	server := &Server{
 		Config:      cfg,
@@ -83,6 +89,9 @@ func NewServer(cfg *srvCfg.Config, globalCfg *global.Config, engineProps *global
 		Retrieval:   retrievalSvc,
 		Platform:    platform,
 		Observer:    observer,
+		planChecker: plancheck.NewChecker(cloning, observer),
+		replayer:    replay.NewReplayer(cloning, observer),
+		events:      eventHub,
 		wsService: WSService{
 			upgrader:    websocket.Upgrader{},
 			tokenKeeper: tokenKeeper,
@@ -193,16 +202,37 @@ func (s *Server) InitHandlers() {
 
 	r.HandleFunc("/status", authMW.Authorized(s.getInstanceStatus)).Methods(http.MethodGet)
 	r.HandleFunc("/snapshots", authMW.Authorized(s.getSnapshots)).Methods(http.MethodGet)
-	r.HandleFunc("/clone", authMW.Authorized(s.createClone)).Methods(http.MethodPost)
-	r.HandleFunc("/clone/{id}", authMW.Authorized(s.destroyClone)).Methods(http.MethodDelete)
+	r.HandleFunc("/clones", authMW.Authorized(s.listClones)).Methods(http.MethodGet)
+	r.HandleFunc("/clones/destroy", authMW.Authorized(s.destroyClones)).Methods(http.MethodPost)
+	r.HandleFunc("/clones/reset", authMW.Authorized(s.resetClones)).Methods(http.MethodPost)
+	r.HandleFunc("/clone", authMW.Authorized(s.idempotent(s.createClone))).Methods(http.MethodPost)
+	r.HandleFunc("/clone/{id}", authMW.Authorized(s.idempotent(s.destroyClone))).Methods(http.MethodDelete)
 	r.HandleFunc("/clone/{id}", authMW.Authorized(s.patchClone)).Methods(http.MethodPatch)
 	r.HandleFunc("/clone/{id}", authMW.Authorized(s.getClone)).Methods(http.MethodGet)
-	r.HandleFunc("/clone/{id}/reset", authMW.Authorized(s.resetClone)).Methods(http.MethodPost)
+	r.HandleFunc("/clone/{id}/reset", authMW.Authorized(s.idempotent(s.resetClone))).Methods(http.MethodPost)
+	r.HandleFunc("/clone/{id}/stop", authMW.Authorized(s.idempotent(s.stopClone))).Methods(http.MethodPost)
+	r.HandleFunc("/clone/{id}/start", authMW.Authorized(s.idempotent(s.startClone))).Methods(http.MethodPost)
+	r.HandleFunc("/clone/{id}/savepoint", authMW.Authorized(s.createSavepoint)).Methods(http.MethodPost)
+	r.HandleFunc("/clone/{id}/rollback", authMW.Authorized(s.idempotent(s.rollbackSavepoint))).Methods(http.MethodPost)
+	r.HandleFunc("/clone/{id}/savepoints", authMW.Authorized(s.listSavepoints)).Methods(http.MethodGet)
+	r.HandleFunc("/clone/{id}/upgrade", authMW.Authorized(s.idempotent(s.upgradeClone))).Methods(http.MethodPost)
+	r.HandleFunc("/clone/{id}/upgrade", authMW.Authorized(s.getCloneUpgrade)).Methods(http.MethodGet)
+	r.HandleFunc("/clone/{id}/users", authMW.Authorized(s.createCloneUser)).Methods(http.MethodPost)
+	r.HandleFunc("/clone/{id}/users", authMW.Authorized(s.listCloneUsers)).Methods(http.MethodGet)
+	r.HandleFunc("/clone/{id}/users/{name}", authMW.Authorized(s.deleteCloneUser)).Methods(http.MethodDelete)
 	r.HandleFunc("/observation/start", authMW.Authorized(s.startObservation)).Methods(http.MethodPost)
 	r.HandleFunc("/observation/stop", authMW.Authorized(s.stopObservation)).Methods(http.MethodPost)
 	r.HandleFunc("/observation/summary/{clone_id}/{session_id}", authMW.Authorized(s.sessionSummaryObservation)).Methods(http.MethodGet)
 	r.HandleFunc("/observation/download", authMW.Authorized(s.downloadArtifact)).Methods(http.MethodGet)
+	r.HandleFunc("/observation/artifact", authMW.Authorized(s.uploadArtifact)).Methods(http.MethodPost)
+	r.HandleFunc("/plan-check", authMW.Authorized(s.checkPlans)).Methods(http.MethodPost)
+	r.HandleFunc("/replay/start", authMW.Authorized(s.startReplay)).Methods(http.MethodPost)
+	r.HandleFunc("/replay/stop", authMW.Authorized(s.stopReplay)).Methods(http.MethodPost)
+	r.HandleFunc("/replay/status/{clone_id}", authMW.Authorized(s.replayStatus)).Methods(http.MethodGet)
 	r.HandleFunc("/instance/retrieval", authMW.Authorized(s.retrievalState)).Methods(http.MethodGet)
+	r.HandleFunc("/events", authMW.StreamMW(s.wsService.tokenKeeper, s.streamEvents)).Methods(http.MethodGet)
+
+	s.initV2Handlers(r, authMW)
 
 	// Sub-route /admin
 	adminR := r.PathPrefix("/admin").Subrouter()
@@ -220,6 +250,9 @@ func (s *Server) InitHandlers() {
 	// Health check.
 	r.HandleFunc("/healthz", s.healthCheck).Methods(http.MethodGet)
 
+	// CA bundle to verify clone certificates.
+	r.HandleFunc("/ca.pem", s.getCACert).Methods(http.MethodGet)
+
 	// Show Swagger UI on index page.
 	if err := attachAPI(r); err != nil {
 		log.Err("Cannot load API description.")
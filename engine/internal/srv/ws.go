@@ -35,6 +35,11 @@ func (s *Server) instanceLogs(w http.ResponseWriter, r *http.Request) {
 	s.wsService.upgrader.CheckOrigin = func(r *http.Request) bool {
 		requestOrigin := r.Header.Get("Origin")
 
+		// Non-browser clients, such as CLI, do not send the Origin header.
+		if requestOrigin == "" {
+			return true
+		}
+
 		var uiURL string
 
 		if s.wsService.uiManager.IsEnabled() {
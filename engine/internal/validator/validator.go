@@ -7,6 +7,7 @@ package validator
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -15,7 +16,15 @@ import (
 	"gitlab.com/postgres-ai/database-lab/v3/pkg/client/dblabapi/types"
 )
 
-const minEntropyBits = 60
+const (
+	minEntropyBits = 60
+
+	maxLabels           = 64
+	maxLabelValueLength = 256
+)
+
+// labelKeyRe defines allowed label keys: alphanumeric characters, dots, dashes, underscores and slashes.
+var labelKeyRe = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9._/-]{0,62})$`)
 
 // Service provides a validation service.
 type Service struct {
@@ -43,5 +52,29 @@ func (v Service) ValidateCloneRequest(cloneRequest *types.CloneCreateRequest) er
 		return fmt.Errorf("password validation: %w", err)
 	}
 
+	return v.ValidateLabels(cloneRequest.Labels)
+}
+
+// ValidateLabels validates clone labels.
+func (v Service) ValidateLabels(labels map[string]string) error {
+	if len(labels) > maxLabels {
+		return errors.Errorf("too many labels: %d, maximum: %d", len(labels), maxLabels)
+	}
+
+	for key, value := range labels {
+		if !labelKeyRe.MatchString(key) {
+			return errors.Errorf("invalid label key %q: must be up to 63 characters, "+
+				"start with an alphanumeric character and contain only alphanumeric characters, '.', '_', '-' or '/'", key)
+		}
+
+		if len(value) > maxLabelValueLength {
+			return errors.Errorf("value of label %q is too long, maximum: %d characters", key, maxLabelValueLength)
+		}
+
+		if strings.Contains(value, ",") {
+			return errors.Errorf("value of label %q must not contain commas", key)
+		}
+	}
+
 	return nil
 }
@@ -5,6 +5,7 @@
 package validator
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -70,3 +71,16 @@ func TestValidationCloneRequestErrors(t *testing.T) {
 		assert.EqualError(t, err, tc.error)
 	}
 }
+
+func TestValidateLabels(t *testing.T) {
+	validator := Service{}
+
+	assert.NoError(t, validator.ValidateLabels(nil))
+	assert.NoError(t, validator.ValidateLabels(map[string]string{"team": "billing", "postgres.ai/ci": "", "env_1": "staging"}))
+
+	assert.Error(t, validator.ValidateLabels(map[string]string{"": "billing"}))
+	assert.Error(t, validator.ValidateLabels(map[string]string{"-team": "billing"}))
+	assert.Error(t, validator.ValidateLabels(map[string]string{"team=": "billing"}))
+	assert.Error(t, validator.ValidateLabels(map[string]string{"team": "billing,search"}))
+	assert.Error(t, validator.ValidateLabels(map[string]string{"team": strings.Repeat("a", maxLabelValueLength+1)}))
+}
@@ -0,0 +1,187 @@
+package dblabapi
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/gorilla/websocket"
+	"github.com/pkg/errors"
+
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+const wsTokenParam = "token"
+
+// AdminConfig provides the projected configuration of the instance.
+func (c *Client) AdminConfig(ctx context.Context) (map[string]interface{}, error) {
+	cfg := map[string]interface{}{}
+
+	if err := c.get(ctx, c.URL("/admin/config"), &cfg); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// AdminConfigYaml provides the full configuration of the instance with masked sensitive values.
+func (c *Client) AdminConfigYaml(ctx context.Context) ([]byte, error) {
+	request, err := http.NewRequest(http.MethodGet, c.URL("/admin/config.yaml").String(), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to make a request")
+	}
+
+	response, err := c.Do(ctx, request)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get response")
+	}
+
+	defer func() { _ = response.Body.Close() }()
+
+	cfg, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read response")
+	}
+
+	return cfg, nil
+}
+
+// SetAdminConfig applies the projected configuration and returns the updated one.
+// The instance validates the configuration before applying, validation errors are returned as models.Error.
+func (c *Client) SetAdminConfig(ctx context.Context, cfg map[string]interface{}) (map[string]interface{}, error) {
+	applied := map[string]interface{}{}
+
+	if err := c.request(ctx, c.URL("/admin/config"), cfg, &applied); err != nil {
+		return nil, err
+	}
+
+	return applied, nil
+}
+
+// TestDBSource checks the connection to the source database. The configured password is used if the password is empty.
+func (c *Client) TestDBSource(ctx context.Context, connection models.ConnectionTest) (*models.DBSource, error) {
+	var dbSource models.DBSource
+
+	if err := c.request(ctx, c.URL("/admin/test-db-source"), connection, &dbSource); err != nil {
+		return nil, err
+	}
+
+	return &dbSource, nil
+}
+
+// WSToken issues a one-time token to access web-socket handlers.
+func (c *Client) WSToken(ctx context.Context) (string, error) {
+	var wsToken models.WSToken
+
+	if err := c.get(ctx, c.URL("/admin/ws-auth"), &wsToken); err != nil {
+		return "", err
+	}
+
+	return wsToken.Token, nil
+}
+
+// StreamLogs streams log lines of the instance starting from recent ones.
+// The channel is closed when the context is canceled or the connection is closed.
+func (c *Client) StreamLogs(ctx context.Context) (<-chan string, error) {
+	token, err := c.WSToken(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get a web-socket token")
+	}
+
+	u := c.URL("/instance/logs")
+
+	u.Scheme = "ws"
+	if c.url.Scheme == "https" {
+		u.Scheme = "wss"
+	}
+
+	values := url.Values{}
+	values.Set(wsTokenParam, token)
+	u.RawQuery = values.Encode()
+
+	header := http.Header{}
+	header.Set("User-Agent", c.userAgent)
+	header.Set(requestIDHeader, requestID(ctx))
+
+	conn, response, err := c.websocketDialer().DialContext(ctx, u.String(), header)
+	if response != nil && response.Body != nil {
+		_ = response.Body.Close()
+	}
+
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to connect to the log stream")
+	}
+
+	lines := make(chan string)
+
+	go func() {
+		defer close(lines)
+		defer func() { _ = conn.Close() }()
+
+		stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+		defer stop()
+
+		for {
+			_, message, err := conn.ReadMessage()
+			if err != nil {
+				if ctx.Err() == nil && !websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+					log.Dbg("Log stream has been interrupted: ", err)
+				}
+
+				return
+			}
+
+			// Log lines are encoded, but errors of the stream are sent as plain text.
+			line, err := base64.StdEncoding.DecodeString(string(message))
+			if err != nil {
+				line = message
+			}
+
+			select {
+			case lines <- string(line):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return lines, nil
+}
+
+// websocketDialer creates a web-socket dialer using settings of the HTTP transport.
+func (c *Client) websocketDialer() *websocket.Dialer {
+	dialer := &websocket.Dialer{Proxy: http.ProxyFromEnvironment}
+
+	if tr, ok := c.client.Transport.(*http.Transport); ok {
+		dialer.Proxy = tr.Proxy
+		dialer.NetDialContext = tr.DialContext
+		dialer.TLSClientConfig = tr.TLSClientConfig
+	}
+
+	return dialer
+}
+
+// get requests the URL and decodes the JSON response.
+func (c *Client) get(ctx context.Context, u *url.URL, responseObject interface{}) error {
+	request, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to make a request")
+	}
+
+	response, err := c.Do(ctx, request)
+	if err != nil {
+		return errors.Wrap(err, "failed to get response")
+	}
+
+	defer func() { _ = response.Body.Close() }()
+
+	if err := json.NewDecoder(response.Body).Decode(responseObject); err != nil {
+		return errors.Wrap(err, "failed to decode a response body")
+	}
+
+	return nil
+}
@@ -0,0 +1,123 @@
+package dblabapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+func TestClientSetAdminConfig(t *testing.T) {
+	cfg := map[string]interface{}{
+		"global": map[string]interface{}{"debug": true},
+	}
+
+	mockClient := NewTestClient(func(req *http.Request) *http.Response {
+		assert.Equal(t, "https://example.com/admin/config", req.URL.String())
+		assert.Equal(t, http.MethodPost, req.Method)
+
+		var requested map[string]interface{}
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&requested))
+
+		if requested["global"].(map[string]interface{})["debug"] != true {
+			body, err := json.Marshal(models.New(models.ErrCodeBadRequest, "invalid debug value"))
+			require.NoError(t, err)
+
+			return &http.Response{
+				StatusCode: http.StatusBadRequest,
+				Body:       io.NopCloser(bytes.NewBuffer(body)),
+				Header:     make(http.Header),
+			}
+		}
+
+		body, err := json.Marshal(requested)
+		require.NoError(t, err)
+
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(bytes.NewBuffer(body)),
+			Header:     make(http.Header),
+		}
+	})
+
+	c, err := NewClient(Options{
+		Host:              "https://example.com/",
+		VerificationToken: "testVerify",
+	})
+	require.NoError(t, err)
+
+	c.client = mockClient
+
+	applied, err := c.SetAdminConfig(context.Background(), cfg)
+	require.NoError(t, err)
+	assert.Equal(t, cfg, applied)
+
+	_, err = c.SetAdminConfig(context.Background(), map[string]interface{}{
+		"global": map[string]interface{}{"debug": "yes"},
+	})
+	require.Error(t, err)
+
+	var apiErr models.Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "invalid debug value", apiErr.Message)
+}
+
+func TestClientStreamLogs(t *testing.T) {
+	const wsToken = "one-time-token"
+
+	upgrader := websocket.Upgrader{}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/admin/ws-auth", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "testVerify", r.Header.Get(verificationHeader))
+		require.NoError(t, json.NewEncoder(w).Encode(models.WSToken{Token: wsToken}))
+	})
+	mux.HandleFunc("/instance/logs", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, wsToken, r.URL.Query().Get("token"))
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		require.NoError(t, err)
+
+		defer func() { _ = conn.Close() }()
+
+		for _, message := range []string{
+			base64.StdEncoding.EncodeToString([]byte("first line\n")),
+			"plain error",
+		} {
+			require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(message)))
+		}
+
+		require.NoError(t, conn.WriteMessage(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")))
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c, err := NewClient(Options{
+		Host:              server.URL,
+		VerificationToken: "testVerify",
+	})
+	require.NoError(t, err)
+
+	lines, err := c.StreamLogs(context.Background())
+	require.NoError(t, err)
+
+	received := []string{}
+
+	for line := range lines {
+		received = append(received, line)
+	}
+
+	assert.Equal(t, []string{"first line\n", "plain error"}, received)
+}
@@ -18,14 +18,17 @@ import (
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/pkg/errors"
 
 	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
 	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+	"gitlab.com/postgres-ai/database-lab/v3/version"
 )
 
 const (
 	verificationHeader = "Verification-Token"
+	requestIDHeader    = "X-Request-ID"
 
 	urlKey          = "url"
 	requestDumpKey  = "request-dump"
@@ -39,14 +42,32 @@ type Client struct {
 	client            *http.Client
 	requestTimeout    time.Duration
 	pollingInterval   time.Duration
+	retryPolicy       RetryPolicy
+	attemptTimeout    time.Duration
+	userAgent         string
+	apiVersion        APIVersion
 }
 
 // Options describes options of a Database Lab API client.
 type Options struct {
 	Host              string
 	VerificationToken string
-	Insecure          bool
-	RequestTimeout    time.Duration
+	// Insecure skips verification of server certificates. It is ignored if Transport is set.
+	Insecure bool
+	// RequestTimeout limits waiting for clone operations if the context of the call has no deadline.
+	RequestTimeout time.Duration
+	// AttemptTimeout limits a single attempt of a request. Deadlines of contexts limit whole calls including retries.
+	// Event streams are limited only until the response headers are received.
+	// Use WithAttemptTimeout to change the limit for a single call.
+	AttemptTimeout time.Duration
+	// RetryPolicy defines retries of failed requests. DefaultRetryPolicy is used if the policy is not set.
+	RetryPolicy RetryPolicy
+	// Transport allows replacing the HTTP transport, for example, to add tracing or use a custom proxy.
+	Transport http.RoundTripper
+	// UserAgent identifies the application using the client.
+	UserAgent string
+	// APIVersion defines the version of the API used by the client. APIVersionV1 is used if the version is not set.
+	APIVersion APIVersion
 }
 
 const (
@@ -63,20 +84,47 @@ func NewClient(options Options) (*Client, error) {
 
 	u.Path = strings.TrimRight(u.Path, "/")
 
-	tr := &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: options.Insecure},
+	tr := options.Transport
+	if tr == nil {
+		tr = &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: options.Insecure},
+		}
 	}
 
 	if options.RequestTimeout == 0 {
 		options.RequestTimeout = defaultPollingTimeout
 	}
 
+	if options.RetryPolicy == nil {
+		options.RetryPolicy = DefaultRetryPolicy()
+	}
+
+	switch options.APIVersion {
+	case "":
+		options.APIVersion = APIVersionV1
+
+	case APIVersionV1, APIVersionV2:
+
+	default:
+		return nil, errors.Errorf("unsupported API version: %q", options.APIVersion)
+	}
+
+	userAgent := "dblab-client/" + version.GetVersion()
+	if options.UserAgent != "" {
+		userAgent = options.UserAgent + " " + userAgent
+	}
+
 	return &Client{
 		url:               u,
 		verificationToken: options.VerificationToken,
 		client:            &http.Client{Transport: tr},
 		requestTimeout:    options.RequestTimeout,
 		pollingInterval:   defaultPollingInterval,
+		retryPolicy:       options.RetryPolicy,
+		attemptTimeout:    options.AttemptTimeout,
+		userAgent:         userAgent,
+		apiVersion:        options.APIVersion,
 	}, nil
 }
 
@@ -117,10 +165,13 @@ func (c *Client) Do(ctx context.Context, request *http.Request) (response *http.
 		}
 	}()
 
-	request.Header.Add(verificationHeader, c.verificationToken)
+	request.Header.Set(verificationHeader, c.verificationToken)
+	request.Header.Set("User-Agent", c.userAgent)
+	request.Header.Set(requestIDHeader, requestID(ctx))
+	setIdempotencyKey(ctx, request)
 	request = request.WithContext(ctx)
 
-	response, err = c.client.Do(request)
+	response, err = c.doWithRetries(ctx, request)
 	if err != nil {
 		return nil, err
 	}
@@ -144,3 +195,124 @@ func (c *Client) Do(ctx context.Context, request *http.Request) (response *http.
 
 	return response, nil
 }
+
+// doWithRetries sends the request and retries failed attempts according to the retry policy.
+func (c *Client) doWithRetries(ctx context.Context, request *http.Request) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		response, err := c.doAttempt(ctx, request, attempt)
+
+		delay, retry := c.retryPolicy.Retry(attempt, request, response, err)
+		if !retry || ctx.Err() != nil || (request.Body != nil && request.GetBody == nil) {
+			return response, err
+		}
+
+		if response != nil {
+			_, _ = io.Copy(io.Discard, response.Body)
+			_ = response.Body.Close()
+		}
+
+		log.Dbg(fmt.Sprintf("Attempt %d of request %s %s failed, retry in %v", attempt, request.Method, request.URL, delay))
+
+		timer := time.NewTimer(delay)
+
+		select {
+		case <-timer.C:
+
+		case <-ctx.Done():
+			timer.Stop()
+
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// doAttempt makes a single attempt of the request.
+func (c *Client) doAttempt(ctx context.Context, request *http.Request, attempt int) (*http.Response, error) {
+	attemptRequest := request
+
+	if attempt > 1 {
+		attemptRequest = request.Clone(ctx)
+
+		if request.GetBody != nil {
+			body, err := request.GetBody()
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to get a request body")
+			}
+
+			attemptRequest.Body = body
+		}
+	}
+
+	attemptTimeout := c.attemptTimeout
+	if timeout, ok := ctx.Value(attemptTimeoutCtxKey{}).(time.Duration); ok {
+		attemptTimeout = timeout
+	}
+
+	if attemptTimeout <= 0 {
+		return c.client.Do(attemptRequest)
+	}
+
+	attemptCtx, cancel := context.WithCancel(ctx)
+	timer := time.AfterFunc(attemptTimeout, cancel)
+
+	response, err := c.client.Do(attemptRequest.WithContext(attemptCtx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	// Streams stay open as long as the caller needs them, so the timeout limits only waiting for the response headers.
+	if isStream, _ := ctx.Value(streamCtxKey{}).(bool); isStream {
+		timer.Stop()
+	}
+
+	// The attempt context must live until the response body is read.
+	response.Body = &cancelOnCloseBody{ReadCloser: response.Body, cancel: cancel}
+
+	return response, nil
+}
+
+// cancelOnCloseBody cancels the context of the request when the response body is closed.
+type cancelOnCloseBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// Close closes the body and cancels the request context.
+func (b *cancelOnCloseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+
+	return err
+}
+
+type attemptTimeoutCtxKey struct{}
+
+// WithAttemptTimeout returns a context making requests use the timeout of a single attempt instead of the one of the client.
+// A zero timeout does not limit attempts.
+func WithAttemptTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, attemptTimeoutCtxKey{}, timeout)
+}
+
+type streamCtxKey struct{}
+
+// withStream returns a context marking the request as a long-lived stream.
+func withStream(ctx context.Context) context.Context {
+	return context.WithValue(ctx, streamCtxKey{}, true)
+}
+
+type requestIDCtxKey struct{}
+
+// WithRequestID returns a context making requests carry the ID in the X-Request-ID header,
+// so they can be correlated with logs of the server. Requests without a supplied ID get a random ID.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDCtxKey{}, id)
+}
+
+func requestID(ctx context.Context) string {
+	if id, _ := ctx.Value(requestIDCtxKey{}).(string); id != "" {
+		return id
+	}
+
+	return uuid.NewString()
+}
@@ -8,16 +8,17 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
-	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"time"
 
 	"github.com/pkg/errors"
 
 	"gitlab.com/postgres-ai/database-lab/v3/internal/observer"
 	"gitlab.com/postgres-ai/database-lab/v3/pkg/client/dblabapi/types"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
 	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
 )
 
@@ -41,7 +42,10 @@ func (c *Client) ListClones(ctx context.Context) ([]*models.Clone, error) {
 
 // ListClonesRaw provides a raw list of Database Lab clones.
 func (c *Client) ListClonesRaw(ctx context.Context) (io.ReadCloser, error) {
-	u := c.URL("/status")
+	u, err := c.operationURL("getInstanceStatus", nil)
+	if err != nil {
+		return nil, err
+	}
 
 	request, err := http.NewRequest(http.MethodGet, u.String(), nil)
 	if err != nil {
@@ -56,6 +60,75 @@ func (c *Client) ListClonesRaw(ctx context.Context) (io.ReadCloser, error) {
 	return response.Body, nil
 }
 
+// ListClonesPage provides a page of Database Lab clones filtered and sorted according to the request.
+func (c *Client) ListClonesPage(ctx context.Context, listRequest types.CloneListRequest) (*models.ClonePage, error) {
+	u, err := c.operationURL("listClones", nil)
+	if err != nil {
+		return nil, err
+	}
+	u.RawQuery = cloneListQuery(listRequest).Encode()
+
+	request, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to make a request")
+	}
+
+	response, err := c.Do(ctx, request)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get response")
+	}
+
+	defer func() { _ = response.Body.Close() }()
+
+	var page models.ClonePage
+
+	if err := json.NewDecoder(response.Body).Decode(&page); err != nil {
+		return nil, errors.Wrap(err, "failed to decode a response body")
+	}
+
+	return &page, nil
+}
+
+func cloneListQuery(listRequest types.CloneListRequest) url.Values {
+	values := url.Values{}
+
+	params := map[string]string{
+		"selector": listRequest.Selector,
+		"snapshot": listRequest.Snapshot,
+		"pool":     listRequest.Pool,
+		"creator":  listRequest.Creator,
+		"sort":     listRequest.Sort,
+	}
+
+	for param, value := range params {
+		if value != "" {
+			values.Set(param, value)
+		}
+	}
+
+	for _, status := range listRequest.Status {
+		values.Add("status", status)
+	}
+
+	if listRequest.MinAge > 0 {
+		values.Set("min_age", listRequest.MinAge.String())
+	}
+
+	if listRequest.MaxAge > 0 {
+		values.Set("max_age", listRequest.MaxAge.String())
+	}
+
+	if listRequest.Limit > 0 {
+		values.Set("limit", strconv.Itoa(listRequest.Limit))
+	}
+
+	if listRequest.Offset > 0 {
+		values.Set("offset", strconv.Itoa(listRequest.Offset))
+	}
+
+	return values
+}
+
 // GetClone returns info about a Database Lab clone.
 func (c *Client) GetClone(ctx context.Context, cloneID string) (*models.Clone, error) {
 	body, err := c.GetCloneRaw(ctx, cloneID)
@@ -76,7 +149,10 @@ func (c *Client) GetClone(ctx context.Context, cloneID string) (*models.Clone, e
 
 // GetCloneRaw returns raw info about a Database Lab clone.
 func (c *Client) GetCloneRaw(ctx context.Context, cloneID string) (io.ReadCloser, error) {
-	u := c.URL(fmt.Sprintf("/clone/%s", cloneID))
+	u, err := c.operationURL("getClone", map[string]string{"id": cloneID})
+	if err != nil {
+		return nil, err
+	}
 
 	request, err := http.NewRequest(http.MethodGet, u.String(), nil)
 	if err != nil {
@@ -92,8 +168,15 @@ func (c *Client) GetCloneRaw(ctx context.Context, cloneID string) (io.ReadCloser
 }
 
 // CreateClone creates a new Database Lab clone.
+// If the clone fails to start, for example, because its init profile fails, the clone is returned along with the error,
+// so the caller can inspect or destroy it.
 func (c *Client) CreateClone(ctx context.Context, cloneRequest types.CloneCreateRequest) (*models.Clone, error) {
-	u := c.URL("/clone")
+	ctx = ensureIdempotencyKey(ctx)
+
+	u, err := c.operationURL("createClone", nil)
+	if err != nil {
+		return nil, err
+	}
 
 	body := bytes.NewBuffer(nil)
 	if err := json.NewEncoder(body).Encode(cloneRequest); err != nil {
@@ -127,26 +210,26 @@ func (c *Client) CreateClone(ctx context.Context, cloneRequest types.CloneCreate
 	}
 
 	if clone.Status.Code != models.StatusCreating {
-		return nil, errors.Errorf("unexpected clone status given: %v", clone.Status)
+		return clone, errors.Errorf("unexpected clone status given: %v", clone.Status)
 	}
 
-	clone, err = c.watchCloneStatus(ctx, clone.ID, clone.Status.Code)
+	createdClone, err := c.watchCloneStatus(ctx, clone.ID, clone.Status.Code)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to watch the clone status")
+		return clone, errors.Wrap(err, "failed to watch the clone status")
 	}
 
+	clone = createdClone
+
 	if clone.Status.Code != models.StatusOK {
-		return nil, errors.Errorf("failed to create clone, unexpected status given. %v: %s", clone.Status.Code, clone.Status.Message)
+		return clone, errors.Errorf("failed to create clone, unexpected status given. %v: %s", clone.Status.Code, clone.Status.Message)
 	}
 
 	return clone, nil
 }
 
-// watchCloneStatus checks the clone status for changing.
+// watchCloneStatus waits for the clone status to change. It uses the event stream of the instance
+// and falls back to polling if the stream is not available.
 func (c *Client) watchCloneStatus(ctx context.Context, cloneID string, initialStatusCode models.StatusCode) (*models.Clone, error) {
-	pollingTimer := time.NewTimer(c.pollingInterval)
-	defer pollingTimer.Stop()
-
 	var cancel context.CancelFunc
 
 	if _, ok := ctx.Deadline(); !ok {
@@ -154,6 +237,61 @@ func (c *Client) watchCloneStatus(ctx context.Context, cloneID string, initialSt
 		defer cancel()
 	}
 
+	streamCtx, streamCancel := context.WithCancel(ctx)
+	defer streamCancel()
+
+	eventCh, err := c.WatchEvents(streamCtx, types.EventsFilter{
+		CloneID: cloneID,
+		Types:   []models.EventType{models.EventCloneStatus, models.EventCloneDeleted},
+	})
+	if err != nil {
+		log.Dbg("Event stream is not available, fall back to polling: ", err)
+
+		return c.pollCloneStatus(ctx, cloneID, initialStatusCode)
+	}
+
+	// The status might have changed before the subscription.
+	clone, err := c.GetClone(ctx, cloneID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get clone info")
+	}
+
+	if clone.Status.Code != initialStatusCode {
+		return clone, nil
+	}
+
+	for {
+		select {
+		case event, ok := <-eventCh:
+			if !ok {
+				if ctx.Err() != nil {
+					return nil, ctx.Err()
+				}
+
+				log.Dbg("Event stream has been closed, fall back to polling")
+
+				return c.pollCloneStatus(ctx, cloneID, initialStatusCode)
+			}
+
+			if event.Type == models.EventCloneDeleted {
+				return nil, models.Error{Code: models.ErrCodeNotFound, Message: "clone not found"}
+			}
+
+			if event.Clone != nil && event.Clone.Status.Code != initialStatusCode {
+				return event.Clone, nil
+			}
+
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// pollCloneStatus checks the clone status for changing.
+func (c *Client) pollCloneStatus(ctx context.Context, cloneID string, initialStatusCode models.StatusCode) (*models.Clone, error) {
+	pollingTimer := time.NewTimer(c.pollingInterval)
+	defer pollingTimer.Stop()
+
 	for {
 		select {
 		case <-pollingTimer.C:
@@ -176,22 +314,35 @@ func (c *Client) watchCloneStatus(ctx context.Context, cloneID string, initialSt
 
 // CreateCloneAsync asynchronously creates a new Database Lab clone.
 func (c *Client) CreateCloneAsync(ctx context.Context, cloneRequest types.CloneCreateRequest) (*models.Clone, error) {
-	u := c.URL("/clone")
+	ctx = ensureIdempotencyKey(ctx)
+
+	u, err := c.operationURL("createClone", nil)
+	if err != nil {
+		return nil, err
+	}
 
 	var clone models.Clone
 
-	err := c.request(ctx, u, cloneRequest, &clone)
+	err = c.request(ctx, u, cloneRequest, &clone)
 
 	return &clone, err
 }
 
 // UpdateClone updates an existing Database Lab clone.
 func (c *Client) UpdateClone(ctx context.Context, cloneID string, updateRequest types.CloneUpdateRequest) (*models.Clone, error) {
-	u := c.URL(fmt.Sprintf("/clone/%s", cloneID))
+	return c.PatchClone(ctx, cloneID, types.ClonePatchRequest{Protected: &updateRequest.Protected})
+}
+
+// PatchClone changes the specified fields of an existing Database Lab clone.
+func (c *Client) PatchClone(ctx context.Context, cloneID string, patchRequest types.ClonePatchRequest) (*models.Clone, error) {
+	u, err := c.operationURL("updateClone", map[string]string{"id": cloneID})
+	if err != nil {
+		return nil, err
+	}
 
 	body := bytes.NewBuffer(nil)
-	if err := json.NewEncoder(body).Encode(updateRequest); err != nil {
-		return nil, errors.Wrap(err, "failed to encode CloneUpdateRequest")
+	if err := json.NewEncoder(body).Encode(patchRequest); err != nil {
+		return nil, errors.Wrap(err, "failed to encode ClonePatchRequest")
 	}
 
 	request, err := http.NewRequest(http.MethodPatch, u.String(), body)
@@ -217,7 +368,12 @@ func (c *Client) UpdateClone(ctx context.Context, cloneID string, updateRequest
 
 // ResetClone resets a Database Lab clone session.
 func (c *Client) ResetClone(ctx context.Context, cloneID string, params types.ResetCloneRequest) error {
-	u := c.URL(fmt.Sprintf("/clone/%s/reset", cloneID))
+	ctx = ensureIdempotencyKey(ctx)
+
+	u, err := c.operationURL("resetClone", map[string]string{"id": cloneID})
+	if err != nil {
+		return err
+	}
 
 	body := bytes.NewBuffer(nil)
 	if err := json.NewEncoder(body).Encode(params); err != nil {
@@ -250,7 +406,12 @@ func (c *Client) ResetClone(ctx context.Context, cloneID string, params types.Re
 
 // ResetCloneAsync asynchronously resets a Database Lab clone session.
 func (c *Client) ResetCloneAsync(ctx context.Context, cloneID string, params types.ResetCloneRequest) error {
-	u := c.URL(fmt.Sprintf("/clone/%s/reset", cloneID))
+	ctx = ensureIdempotencyKey(ctx)
+
+	u, err := c.operationURL("resetClone", map[string]string{"id": cloneID})
+	if err != nil {
+		return err
+	}
 
 	body := bytes.NewBuffer(nil)
 	if err := json.NewEncoder(body).Encode(params); err != nil {
@@ -274,7 +435,12 @@ func (c *Client) ResetCloneAsync(ctx context.Context, cloneID string, params typ
 
 // DestroyClone destroys a Database Lab clone.
 func (c *Client) DestroyClone(ctx context.Context, cloneID string) error {
-	u := c.URL(fmt.Sprintf("/clone/%s", cloneID))
+	ctx = ensureIdempotencyKey(ctx)
+
+	u, err := c.operationURL("destroyClone", map[string]string{"id": cloneID})
+	if err != nil {
+		return err
+	}
 
 	request, err := http.NewRequest(http.MethodDelete, u.String(), nil)
 	if err != nil {
@@ -290,7 +456,7 @@ func (c *Client) DestroyClone(ctx context.Context, cloneID string) error {
 
 	clone, err := c.watchCloneStatus(ctx, cloneID, models.StatusDeleting)
 	if err != nil {
-		if err, ok := errors.Cause(err).(models.Error); ok && err.Code == models.ErrCodeNotFound {
+		if err, ok := errors.Cause(err).(models.Error); ok && err.Code.Legacy() == models.ErrCodeNotFound {
 			return nil
 		}
 
@@ -306,7 +472,12 @@ func (c *Client) DestroyClone(ctx context.Context, cloneID string) error {
 
 // DestroyCloneAsync asynchronously destroys a Database Lab clone.
 func (c *Client) DestroyCloneAsync(ctx context.Context, cloneID string) error {
-	u := c.URL(fmt.Sprintf("/clone/%s", cloneID))
+	ctx = ensureIdempotencyKey(ctx)
+
+	u, err := c.operationURL("destroyClone", map[string]string{"id": cloneID})
+	if err != nil {
+		return err
+	}
 
 	request, err := http.NewRequest(http.MethodDelete, u.String(), nil)
 	if err != nil {
@@ -323,31 +494,106 @@ func (c *Client) DestroyCloneAsync(ctx context.Context, cloneID string) error {
 	return nil
 }
 
+// StopClone stops the container of a Database Lab clone keeping its data.
+func (c *Client) StopClone(ctx context.Context, cloneID string) error {
+	ctx = ensureIdempotencyKey(ctx)
+
+	u, err := c.operationURL("stopClone", map[string]string{"id": cloneID})
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequest(http.MethodPost, u.String(), nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to make a request")
+	}
+
+	response, err := c.Do(ctx, request)
+	if err != nil {
+		return errors.Wrap(err, "failed to get response")
+	}
+
+	defer func() { _ = response.Body.Close() }()
+
+	return nil
+}
+
+// StartClone starts a stopped Database Lab clone and waits until it is ready.
+func (c *Client) StartClone(ctx context.Context, cloneID string) (*models.Clone, error) {
+	if err := c.StartCloneAsync(ctx, cloneID); err != nil {
+		return nil, err
+	}
+
+	clone, err := c.watchCloneStatus(ctx, cloneID, models.StatusStarting)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to watch the clone status")
+	}
+
+	if clone.Status.Code != models.StatusOK {
+		return nil, errors.Errorf("failed to start clone, unexpected status given. %v: %s", clone.Status.Code, clone.Status.Message)
+	}
+
+	return clone, nil
+}
+
+// StartCloneAsync asynchronously starts a stopped Database Lab clone.
+func (c *Client) StartCloneAsync(ctx context.Context, cloneID string) error {
+	ctx = ensureIdempotencyKey(ctx)
+
+	u, err := c.operationURL("startClone", map[string]string{"id": cloneID})
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequest(http.MethodPost, u.String(), nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to make a request")
+	}
+
+	response, err := c.Do(ctx, request)
+	if err != nil {
+		return errors.Wrap(err, "failed to get response")
+	}
+
+	defer func() { _ = response.Body.Close() }()
+
+	return nil
+}
+
 // StartObservation starts a new clone observation.
 func (c *Client) StartObservation(ctx context.Context, startRequest types.StartObservationRequest) (*observer.Session, error) {
-	u := c.URL("/observation/start")
+	u, err := c.operationURL("startObservation", map[string]string{"clone_id": startRequest.CloneID})
+	if err != nil {
+		return nil, err
+	}
 
 	var session observer.Session
 
-	err := c.request(ctx, u, startRequest, &session)
+	err = c.request(ctx, u, startRequest, &session)
 
 	return &session, err
 }
 
 // StopObservation stops the clone observation.
 func (c *Client) StopObservation(ctx context.Context, stopRequest types.StopObservationRequest) (*observer.Session, error) {
-	u := c.URL("/observation/stop")
+	u, err := c.operationURL("stopObservation", map[string]string{"clone_id": stopRequest.CloneID})
+	if err != nil {
+		return nil, err
+	}
 
 	var observerSession observer.Session
 
-	err := c.request(ctx, u, stopRequest, &observerSession)
+	err = c.request(ctx, u, stopRequest, &observerSession)
 
 	return &observerSession, err
 }
 
 // SummaryObservation returns the summary of clone observation.
 func (c *Client) SummaryObservation(ctx context.Context, cloneID, sessionID string) (*observer.SummaryArtifact, error) {
-	u := c.URL(fmt.Sprintf("/observation/summary/%s/%s", cloneID, sessionID))
+	u, err := c.operationURL("getObservationSummary", map[string]string{"clone_id": cloneID, "session_id": sessionID})
+	if err != nil {
+		return nil, err
+	}
 
 	request, err := http.NewRequest(http.MethodGet, u.String(), nil)
 	if err != nil {
@@ -372,13 +618,14 @@ func (c *Client) SummaryObservation(ctx context.Context, cloneID, sessionID stri
 
 // DownloadArtifact downloads clone observation artifacts.
 func (c *Client) DownloadArtifact(ctx context.Context, cloneID, sessionID, artifactType string) (io.ReadCloser, error) {
-	u := c.URL("/observation/download")
-
-	values := url.Values{}
-	values.Add("clone_id", cloneID)
-	values.Add("session_id", sessionID)
-	values.Add("artifact_type", artifactType)
-	u.RawQuery = values.Encode()
+	u, err := c.operationURL("downloadObservationArtifact", map[string]string{
+		"clone_id":      cloneID,
+		"session_id":    sessionID,
+		"artifact_type": artifactType,
+	})
+	if err != nil {
+		return nil, err
+	}
 
 	request, err := http.NewRequest(http.MethodGet, u.String(), nil)
 	if err != nil {
@@ -402,6 +649,60 @@ func (c *Client) DownloadArtifact(ctx context.Context, cloneID, sessionID, artif
 	return response.Body, err
 }
 
+// DestroyClones destroys clones selected by the request and waits until the clones are destroyed.
+func (c *Client) DestroyClones(ctx context.Context, bulkRequest types.BulkCloneRequest) (*models.BulkResult, error) {
+	u, err := c.operationURL("destroyClones", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var result models.BulkResult
+
+	if err := c.request(ctx, u, bulkRequest, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// ResetClones resets clones selected by the request and waits until the clones are reset.
+func (c *Client) ResetClones(ctx context.Context, bulkRequest types.BulkResetRequest) (*models.BulkResult, error) {
+	u, err := c.operationURL("resetClones", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var result models.BulkResult
+
+	if err := c.request(ctx, u, bulkRequest, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// UploadArtifact uploads an artifact to the current observation session of the clone.
+func (c *Client) UploadArtifact(ctx context.Context, cloneID, artifactType string, data io.Reader) error {
+	u, err := c.operationURL("uploadObservationArtifact", map[string]string{"clone_id": cloneID, "artifact_type": artifactType})
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequest(http.MethodPost, u.String(), data)
+	if err != nil {
+		return errors.Wrap(err, "failed to make a request")
+	}
+
+	response, err := c.Do(ctx, request)
+	if err != nil {
+		return errors.Wrap(err, "failed to get response")
+	}
+
+	defer func() { _ = response.Body.Close() }()
+
+	return nil
+}
+
 func (c *Client) request(ctx context.Context, u *url.URL, requestObject, responseObject interface{}) error {
 	body := bytes.NewBuffer(nil)
 	if err := json.NewEncoder(body).Encode(requestObject); err != nil {
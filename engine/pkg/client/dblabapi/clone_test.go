@@ -67,6 +67,102 @@ func TestClientListClones(t *testing.T) {
 	require.EqualValues(t, expectedClones, cloneList)
 }
 
+func TestClientListClonesPage(t *testing.T) {
+	expectedPage := &models.ClonePage{
+		Clones: []*models.Clone{{
+			ID:     "testCloneID",
+			Labels: map[string]string{"team": "billing"},
+		}},
+		Total:  3,
+		Limit:  1,
+		Offset: 2,
+	}
+
+	mockClient := NewTestClient(func(req *http.Request) *http.Response {
+		assert.Equal(t, "/clones", req.URL.Path)
+		assert.Equal(t, "team=billing", req.URL.Query().Get("selector"))
+		assert.Equal(t, []string{"OK", "FATAL"}, req.URL.Query()["status"])
+		assert.Equal(t, "1h0m0s", req.URL.Query().Get("min_age"))
+		assert.Equal(t, "-created_at", req.URL.Query().Get("sort"))
+		assert.Equal(t, "1", req.URL.Query().Get("limit"))
+		assert.Equal(t, "2", req.URL.Query().Get("offset"))
+		assert.False(t, req.URL.Query().Has("pool"))
+
+		body, err := json.Marshal(expectedPage)
+		require.NoError(t, err)
+
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(bytes.NewBuffer(body)),
+			Header:     make(http.Header),
+		}
+	})
+
+	c, err := NewClient(Options{
+		Host:              "https://example.com/",
+		VerificationToken: "token",
+	})
+	require.NoError(t, err)
+
+	c.client = mockClient
+
+	page, err := c.ListClonesPage(context.Background(), types.CloneListRequest{
+		Selector: "team=billing",
+		Status:   []string{"OK", "FATAL"},
+		MinAge:   time.Hour,
+		Sort:     "-created_at",
+		Limit:    1,
+		Offset:   2,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, expectedPage, page)
+}
+
+func TestClientDestroyClones(t *testing.T) {
+	expectedResult := &models.BulkResult{
+		DryRun:  true,
+		Skipped: 1,
+		Clones: []models.CloneOperationResult{
+			{CloneID: "clone1", Result: models.BulkResultPlanned},
+			{CloneID: "clone2", Result: models.BulkResultSkipped, Message: "clone is protected"},
+		},
+	}
+
+	mockClient := NewTestClient(func(req *http.Request) *http.Response {
+		assert.Equal(t, http.MethodPost, req.Method)
+		assert.Equal(t, "/clones/destroy", req.URL.Path)
+
+		request := types.BulkCloneRequest{}
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&request))
+		assert.Equal(t, types.BulkCloneRequest{Selector: "team=billing", OlderThan: "2h0m0s", DryRun: true}, request)
+
+		body, err := json.Marshal(expectedResult)
+		require.NoError(t, err)
+
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(bytes.NewBuffer(body)),
+			Header:     make(http.Header),
+		}
+	})
+
+	c, err := NewClient(Options{
+		Host:              "https://example.com/",
+		VerificationToken: "token",
+	})
+	require.NoError(t, err)
+
+	c.client = mockClient
+
+	result, err := c.DestroyClones(context.Background(), types.BulkCloneRequest{
+		Selector:  "team=billing",
+		OlderThan: "2h0m0s",
+		DryRun:    true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, expectedResult, result)
+}
+
 func TestClientListClonesWithFailedRequest(t *testing.T) {
 	mockClient := NewTestClient(func(r *http.Request) *http.Response {
 		return &http.Response{
@@ -169,6 +265,53 @@ func TestClientCreateClone(t *testing.T) {
 	assert.EqualValues(t, expectedClone, *newClone)
 }
 
+func TestClientCreateCloneWithFailedInit(t *testing.T) {
+	mockClient := NewTestClient(func(r *http.Request) *http.Response {
+		if r.URL.Path == "/events" {
+			return eventStreamNotFoundResponse()
+		}
+
+		clone := models.Clone{
+			ID:     "testCloneID",
+			Status: models.Status{Code: models.StatusFatal, Message: "init profile \"billing\" failed"},
+		}
+
+		if r.Method == http.MethodPost {
+			clone.Status = models.Status{Code: models.StatusCreating, Message: models.CloneMessageCreating}
+		}
+
+		responseBody, err := json.Marshal(clone)
+		require.NoError(t, err)
+
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(bytes.NewBuffer(responseBody)),
+			Header:     make(http.Header),
+		}
+	})
+
+	c, err := NewClient(Options{
+		Host:              "https://example.com/",
+		VerificationToken: "token",
+	})
+	require.NoError(t, err)
+
+	c.client = mockClient
+	c.pollingInterval = time.Millisecond
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	clone, err := c.CreateClone(ctx, types.CloneCreateRequest{ID: "testCloneID"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "FATAL")
+
+	// The clone is returned, so the caller can destroy it.
+	require.NotNil(t, clone)
+	assert.Equal(t, "testCloneID", clone.ID)
+	assert.Equal(t, models.StatusFatal, clone.Status.Code)
+}
+
 func TestClientCreateCloneAsync(t *testing.T) {
 	time.Local = time.UTC
 
@@ -396,6 +539,42 @@ func TestClientUpdateClone(t *testing.T) {
 	assert.EqualValues(t, cloneModel, newClone)
 }
 
+func TestClientPatchClone(t *testing.T) {
+	mockClient := NewTestClient(func(r *http.Request) *http.Response {
+		assert.Equal(t, http.MethodPatch, r.Method)
+
+		requestBody, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		defer func() { _ = r.Body.Close() }()
+
+		// The protection mode is kept if it is not specified.
+		assert.JSONEq(t, `{"labels": {"team": "billing"}, "allowedCIDRs": null}`, string(requestBody))
+
+		responseBody, err := json.Marshal(models.Clone{ID: "testCloneID", Protected: true, Labels: map[string]string{"team": "billing"}})
+		require.NoError(t, err)
+
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(bytes.NewBuffer(responseBody)),
+			Header:     make(http.Header),
+		}
+	})
+
+	c, err := NewClient(Options{
+		Host:              "https://example.com/",
+		VerificationToken: "token",
+	})
+	require.NoError(t, err)
+
+	c.client = mockClient
+
+	clone, err := c.PatchClone(context.Background(), "testCloneID", types.ClonePatchRequest{
+		Labels: map[string]string{"team": "billing"},
+	})
+	require.NoError(t, err)
+	assert.True(t, clone.Protected)
+}
+
 func TestClientUpdateCloneWithFailedRequest(t *testing.T) {
 	mockClient := NewTestClient(func(req *http.Request) *http.Response {
 		errorBadRequest := models.Error{
@@ -428,6 +607,10 @@ func TestClientUpdateCloneWithFailedRequest(t *testing.T) {
 
 func TestClientDestroyClone(t *testing.T) {
 	mockClient := NewTestClient(func(r *http.Request) *http.Response {
+		if r.URL.Path == "/events" {
+			return eventStreamNotFoundResponse()
+		}
+
 		assert.Equal(t, r.URL.String(), "https://example.com/clone/testCloneID")
 
 		var responseBody []byte
@@ -524,6 +707,10 @@ func TestClientDestroyCloneWithFailedRequest(t *testing.T) {
 
 func TestClientResetClone(t *testing.T) {
 	mockClient := NewTestClient(func(r *http.Request) *http.Response {
+		if r.URL.Path == "/events" {
+			return eventStreamNotFoundResponse()
+		}
+
 		var responseBody []byte
 
 		if r.Method == http.MethodPost {
@@ -619,3 +806,12 @@ func TestClientResetCloneWithFailedRequest(t *testing.T) {
 	err = c.ResetClone(context.Background(), "testCloneID", types.ResetCloneRequest{Latest: true, SnapshotID: "test"})
 	assert.EqualError(t, err, `failed to get response: Check your verification token.`)
 }
+
+// eventStreamNotFoundResponse imitates an instance without the event stream, so clone statuses are polled.
+func eventStreamNotFoundResponse() *http.Response {
+	return &http.Response{
+		StatusCode: http.StatusNotFound,
+		Body:       io.NopCloser(bytes.NewBuffer(nil)),
+		Header:     make(http.Header),
+	}
+}
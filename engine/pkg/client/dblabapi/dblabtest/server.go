@@ -0,0 +1,648 @@
+// Package dblabtest provides an in-memory Database Lab API server to test applications using the API client.
+package dblabtest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"time"
+
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/client/dblabapi/types"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+const (
+	verificationHeader   = "Verification-Token"
+	idempotencyKeyHeader = "Idempotency-Key"
+)
+
+// Server is a mock of the Database Lab API. Clones are ready as soon as they are created.
+type Server struct {
+	*httptest.Server
+
+	mu              sync.Mutex
+	token           string
+	snapshots       []*models.Snapshot
+	clones          map[string]*models.Clone
+	savepoints      map[string][]models.Savepoint
+	upgrades        map[string]*models.CloneUpgrade
+	users           map[string][]models.CloneUser
+	idempotencyKeys map[string]string
+	failures        []int
+	requests        []*http.Request
+	cloneCounter    int
+}
+
+// NewServer starts a new mock server checking the verification token of requests. An empty token disables the check.
+func NewServer(token string) *Server {
+	s := &Server{
+		token:           token,
+		clones:          make(map[string]*models.Clone),
+		savepoints:      make(map[string][]models.Savepoint),
+		upgrades:        make(map[string]*models.CloneUpgrade),
+		users:           make(map[string][]models.CloneUser),
+		idempotencyKeys: make(map[string]string),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", s.healthCheck)
+	mux.HandleFunc("GET /status", s.getStatus)
+	mux.HandleFunc("GET /snapshots", s.getSnapshots)
+	mux.HandleFunc("POST /clone", s.createClone)
+	mux.HandleFunc("GET /clone/{id}", s.getClone)
+	mux.HandleFunc("PATCH /clone/{id}", s.patchClone)
+	mux.HandleFunc("DELETE /clone/{id}", s.destroyClone)
+	mux.HandleFunc("POST /clone/{id}/reset", s.resetClone)
+	mux.HandleFunc("POST /clone/{id}/stop", s.stopClone)
+	mux.HandleFunc("POST /clone/{id}/start", s.startClone)
+	mux.HandleFunc("POST /clone/{id}/savepoint", s.createSavepoint)
+	mux.HandleFunc("POST /clone/{id}/rollback", s.rollbackSavepoint)
+	mux.HandleFunc("GET /clone/{id}/savepoints", s.listSavepoints)
+	mux.HandleFunc("POST /clone/{id}/upgrade", s.upgradeClone)
+	mux.HandleFunc("GET /clone/{id}/upgrade", s.getCloneUpgrade)
+	mux.HandleFunc("POST /clone/{id}/users", s.createCloneUser)
+	mux.HandleFunc("GET /clone/{id}/users", s.listCloneUsers)
+	mux.HandleFunc("DELETE /clone/{id}/users/{name}", s.deleteCloneUser)
+
+	s.Server = httptest.NewServer(s.middleware(mux))
+
+	return s
+}
+
+// AddSnapshot adds a snapshot available for cloning.
+func (s *Server) AddSnapshot(snapshot models.Snapshot) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.snapshots = append(s.snapshots, &snapshot)
+}
+
+// AddClone adds a clone to the server.
+func (s *Server) AddClone(clone models.Clone) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.clones[clone.ID] = &clone
+}
+
+// Clones returns clones of the server ordered by ID.
+func (s *Server) Clones() []models.Clone {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	clones := make([]models.Clone, 0, len(s.clones))
+	for _, clone := range s.clones {
+		clones = append(clones, *clone)
+	}
+
+	sort.Slice(clones, func(i, j int) bool {
+		return clones[i].ID < clones[j].ID
+	})
+
+	return clones
+}
+
+// FailNext makes the next requests fail with the given HTTP status codes, one status per request.
+func (s *Server) FailNext(statusCodes ...int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failures = append(s.failures, statusCodes...)
+}
+
+// Requests returns requests received by the server.
+func (s *Server) Requests() []*http.Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]*http.Request{}, s.requests...)
+}
+
+func (s *Server) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests = append(s.requests, r.Clone(r.Context()))
+
+		var failure int
+		if len(s.failures) > 0 {
+			failure, s.failures = s.failures[0], s.failures[1:]
+		}
+		s.mu.Unlock()
+
+		if failure != 0 {
+			writeError(w, failure, models.ErrCodeInternal, "injected failure")
+			return
+		}
+
+		if s.token != "" && r.Header.Get(verificationHeader) != s.token {
+			writeError(w, http.StatusUnauthorized, models.ErrCodeUnauthorized, "Check your verification token.")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) healthCheck(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, models.Engine{Version: "dblabtest"})
+}
+
+func (s *Server) getStatus(w http.ResponseWriter, _ *http.Request) {
+	clones := s.Clones()
+
+	status := models.InstanceStatus{
+		Status: &models.Status{Code: models.StatusOK, Message: models.InstanceMessageOK},
+		Engine: models.Engine{Version: "dblabtest"},
+		Cloning: models.Cloning{
+			NumClones: uint64(len(clones)),
+			Clones:    make([]*models.Clone, 0, len(clones)),
+		},
+	}
+
+	for i := range clones {
+		status.Cloning.Clones = append(status.Cloning.Clones, &clones[i])
+	}
+
+	writeJSON(w, http.StatusOK, status)
+}
+
+func (s *Server) getSnapshots(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	snapshots := append([]*models.Snapshot{}, s.snapshots...)
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, snapshots)
+}
+
+func (s *Server) createClone(w http.ResponseWriter, r *http.Request) {
+	var cloneRequest types.CloneCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&cloneRequest); err != nil {
+		writeError(w, http.StatusBadRequest, models.ErrCodeBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := r.Header.Get(idempotencyKeyHeader)
+
+	if cloneID, ok := s.idempotencyKeys[key]; ok && key != "" {
+		if clone, ok := s.clones[cloneID]; ok {
+			writeJSON(w, http.StatusCreated, clone)
+			return
+		}
+	}
+
+	if len(s.snapshots) == 0 {
+		writeError(w, http.StatusNotFound, models.ErrCodeNotFound, "no snapshot found")
+		return
+	}
+
+	snapshot := s.snapshots[len(s.snapshots)-1]
+
+	if cloneRequest.Snapshot != nil && cloneRequest.Snapshot.ID != "" {
+		snapshot = s.findSnapshot(cloneRequest.Snapshot.ID)
+		if snapshot == nil {
+			writeError(w, http.StatusNotFound, models.ErrCodeNotFound, "no snapshot found")
+			return
+		}
+	}
+
+	if cloneRequest.ID == "" {
+		s.cloneCounter++
+		cloneRequest.ID = fmt.Sprintf("clone%d", s.cloneCounter)
+	}
+
+	if _, ok := s.clones[cloneRequest.ID]; ok {
+		writeError(w, http.StatusBadRequest, models.ErrCodeBadRequest, fmt.Sprintf("clone with ID %q already exists", cloneRequest.ID))
+		return
+	}
+
+	clone := &models.Clone{
+		ID:           cloneRequest.ID,
+		Snapshot:     snapshot,
+		Protected:    cloneRequest.Protected,
+		CreatedAt:    models.NewLocalTime(time.Now()),
+		Status:       models.Status{Code: models.StatusOK, Message: models.CloneMessageOK},
+		Labels:       cloneRequest.Labels,
+		DockerImage:  cloneRequest.DockerImage,
+		AllowedCIDRs: cloneRequest.AllowedCIDRs,
+	}
+
+	if cloneRequest.DB != nil {
+		clone.DB = models.Database{
+			Host:     "localhost",
+			Port:     "6000",
+			Username: cloneRequest.DB.Username,
+			DBName:   cloneRequest.DB.DBName,
+		}
+
+		role := models.UserRoleSuperuser
+		if cloneRequest.DB.Restricted {
+			role = models.UserRoleRestricted
+		}
+
+		s.users[cloneRequest.ID] = []models.CloneUser{
+			{Name: cloneRequest.DB.Username, Role: role, DBName: cloneRequest.DB.DBName, Primary: true},
+		}
+	}
+
+	s.clones[clone.ID] = clone
+
+	if key != "" {
+		s.idempotencyKeys[key] = clone.ID
+	}
+
+	writeJSON(w, http.StatusCreated, clone)
+}
+
+func (s *Server) getClone(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	clone, ok := s.clones[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, models.ErrCodeNotFound, "clone not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, clone)
+}
+
+func (s *Server) patchClone(w http.ResponseWriter, r *http.Request) {
+	var patch types.ClonePatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		writeError(w, http.StatusBadRequest, models.ErrCodeBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	clone, ok := s.clones[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, models.ErrCodeNotFound, "clone not found")
+		return
+	}
+
+	if patch.Protected != nil {
+		clone.Protected = *patch.Protected
+	}
+
+	if patch.Labels != nil {
+		clone.Labels = patch.Labels
+	}
+
+	if patch.AllowedCIDRs != nil {
+		clone.AllowedCIDRs = patch.AllowedCIDRs
+	}
+
+	writeJSON(w, http.StatusOK, clone)
+}
+
+func (s *Server) destroyClone(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	clone, ok := s.clones[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, models.ErrCodeNotFound, "clone not found")
+		return
+	}
+
+	if clone.Protected {
+		writeError(w, http.StatusBadRequest, models.ErrCodeBadRequest, "clone is protected")
+		return
+	}
+
+	delete(s.clones, clone.ID)
+	delete(s.savepoints, clone.ID)
+	delete(s.upgrades, clone.ID)
+	delete(s.users, clone.ID)
+}
+
+func (s *Server) resetClone(w http.ResponseWriter, r *http.Request) {
+	var resetRequest types.ResetCloneRequest
+
+	// The request body is optional.
+	if err := json.NewDecoder(r.Body).Decode(&resetRequest); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, models.ErrCodeBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	clone, ok := s.clones[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, models.ErrCodeNotFound, "clone not found")
+		return
+	}
+
+	if resetRequest.SnapshotID != "" {
+		snapshot := s.findSnapshot(resetRequest.SnapshotID)
+		if snapshot == nil {
+			writeError(w, http.StatusNotFound, models.ErrCodeNotFound, "no snapshot found")
+			return
+		}
+
+		clone.Snapshot = snapshot
+	}
+
+	clone.Status = models.Status{Code: models.StatusOK, Message: models.CloneMessageOK}
+}
+
+func (s *Server) stopClone(w http.ResponseWriter, r *http.Request) {
+	s.setCloneStatus(w, r.PathValue("id"), models.StatusOK,
+		models.Status{Code: models.StatusStopped, Message: models.CloneMessageStopped})
+}
+
+// startClone makes the clone ready at once, the real instance reports the STARTING status first.
+func (s *Server) startClone(w http.ResponseWriter, r *http.Request) {
+	s.setCloneStatus(w, r.PathValue("id"), models.StatusStopped,
+		models.Status{Code: models.StatusOK, Message: models.CloneMessageOK})
+}
+
+func (s *Server) setCloneStatus(w http.ResponseWriter, cloneID string, expected models.StatusCode, status models.Status) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	clone, ok := s.clones[cloneID]
+	if !ok {
+		writeError(w, http.StatusNotFound, models.ErrCodeNotFound, "clone not found")
+		return
+	}
+
+	if clone.Status.Code != expected {
+		writeError(w, http.StatusConflict, models.ErrCodeCloneBusy, fmt.Sprintf("clone status is %s", clone.Status.Code))
+		return
+	}
+
+	clone.Status = status
+}
+
+func (s *Server) createSavepoint(w http.ResponseWriter, r *http.Request) {
+	var savepointRequest types.SavepointCreateRequest
+
+	// The request body is optional.
+	if err := json.NewDecoder(r.Body).Decode(&savepointRequest); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, models.ErrCodeBadRequest, err.Error())
+		return
+	}
+
+	if err := savepointRequest.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, models.ErrCodeBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cloneID := r.PathValue("id")
+
+	if _, ok := s.clones[cloneID]; !ok {
+		writeError(w, http.StatusNotFound, models.ErrCodeNotFound, "clone not found")
+		return
+	}
+
+	savepoint := models.Savepoint{
+		Name:      savepointRequest.Name,
+		CreatedAt: models.NewLocalTime(time.Now()),
+	}
+
+	if savepoint.Name == "" {
+		savepoint.Name = fmt.Sprintf("savepoint%d", len(s.savepoints[cloneID])+1)
+	}
+
+	if findSavepoint(s.savepoints[cloneID], savepoint.Name) != -1 {
+		writeError(w, http.StatusConflict, models.ErrCodeSavepointExists, fmt.Sprintf("savepoint %q already exists", savepoint.Name))
+		return
+	}
+
+	s.savepoints[cloneID] = append(s.savepoints[cloneID], savepoint)
+
+	writeJSON(w, http.StatusCreated, savepoint)
+}
+
+// rollbackSavepoint destroys savepoints created after the requested one as the real instance does.
+func (s *Server) rollbackSavepoint(w http.ResponseWriter, r *http.Request) {
+	var rollbackRequest types.SavepointRollbackRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&rollbackRequest); err != nil {
+		writeError(w, http.StatusBadRequest, models.ErrCodeBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cloneID := r.PathValue("id")
+
+	if _, ok := s.clones[cloneID]; !ok {
+		writeError(w, http.StatusNotFound, models.ErrCodeNotFound, "clone not found")
+		return
+	}
+
+	idx := findSavepoint(s.savepoints[cloneID], rollbackRequest.Name)
+	if idx == -1 {
+		writeError(w, http.StatusNotFound, models.ErrCodeNotFound, fmt.Sprintf("savepoint %q not found", rollbackRequest.Name))
+		return
+	}
+
+	s.savepoints[cloneID] = s.savepoints[cloneID][:idx+1]
+}
+
+func (s *Server) listSavepoints(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cloneID := r.PathValue("id")
+
+	if _, ok := s.clones[cloneID]; !ok {
+		writeError(w, http.StatusNotFound, models.ErrCodeNotFound, "clone not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, append([]models.Savepoint{}, s.savepoints[cloneID]...))
+}
+
+// upgradeClone finishes the upgrade at once, the real instance reports the UPGRADING status first.
+func (s *Server) upgradeClone(w http.ResponseWriter, r *http.Request) {
+	var upgradeRequest types.CloneUpgradeRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&upgradeRequest); err != nil {
+		writeError(w, http.StatusBadRequest, models.ErrCodeBadRequest, err.Error())
+		return
+	}
+
+	if err := upgradeRequest.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, models.ErrCodeBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cloneID := r.PathValue("id")
+
+	clone, ok := s.clones[cloneID]
+	if !ok {
+		writeError(w, http.StatusNotFound, models.ErrCodeNotFound, "clone not found")
+		return
+	}
+
+	now := models.NewLocalTime(time.Now())
+
+	clone.DockerImage = upgradeRequest.DockerImage
+	s.upgrades[cloneID] = &models.CloneUpgrade{
+		Status:      models.UpgradeStatusFinished,
+		DockerImage: upgradeRequest.DockerImage,
+		StartedAt:   now,
+		FinishedAt:  now,
+	}
+}
+
+func (s *Server) getCloneUpgrade(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cloneID := r.PathValue("id")
+
+	if _, ok := s.clones[cloneID]; !ok {
+		writeError(w, http.StatusNotFound, models.ErrCodeNotFound, "clone not found")
+		return
+	}
+
+	upgrade, ok := s.upgrades[cloneID]
+	if !ok {
+		writeError(w, http.StatusNotFound, models.ErrCodeNotFound, "clone has not been upgraded")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, upgrade)
+}
+
+func (s *Server) createCloneUser(w http.ResponseWriter, r *http.Request) {
+	var userRequest types.CloneUserRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&userRequest); err != nil {
+		writeError(w, http.StatusBadRequest, models.ErrCodeBadRequest, err.Error())
+		return
+	}
+
+	if err := userRequest.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, models.ErrCodeBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cloneID := r.PathValue("id")
+
+	if _, ok := s.clones[cloneID]; !ok {
+		writeError(w, http.StatusNotFound, models.ErrCodeNotFound, "clone not found")
+		return
+	}
+
+	if findUser(s.users[cloneID], userRequest.Username) != -1 {
+		writeError(w, http.StatusConflict, models.ErrCodeUserExists, fmt.Sprintf("user %q already exists", userRequest.Username))
+		return
+	}
+
+	user := models.CloneUser{
+		Name:   userRequest.Username,
+		Role:   userRequest.Role,
+		DBName: userRequest.DBName,
+		Grants: userRequest.Grants,
+	}
+
+	s.users[cloneID] = append(s.users[cloneID], user)
+
+	writeJSON(w, http.StatusCreated, user)
+}
+
+func (s *Server) listCloneUsers(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cloneID := r.PathValue("id")
+
+	if _, ok := s.clones[cloneID]; !ok {
+		writeError(w, http.StatusNotFound, models.ErrCodeNotFound, "clone not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, append([]models.CloneUser{}, s.users[cloneID]...))
+}
+
+func (s *Server) deleteCloneUser(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cloneID := r.PathValue("id")
+
+	if _, ok := s.clones[cloneID]; !ok {
+		writeError(w, http.StatusNotFound, models.ErrCodeNotFound, "clone not found")
+		return
+	}
+
+	idx := findUser(s.users[cloneID], r.PathValue("name"))
+	if idx == -1 {
+		writeError(w, http.StatusNotFound, models.ErrCodeNotFound, fmt.Sprintf("user %q not found", r.PathValue("name")))
+		return
+	}
+
+	if s.users[cloneID][idx].Primary {
+		writeError(w, http.StatusBadRequest, models.ErrCodeBadRequest, "the user defined on clone creation cannot be deleted")
+		return
+	}
+
+	s.users[cloneID] = append(s.users[cloneID][:idx], s.users[cloneID][idx+1:]...)
+}
+
+func findUser(users []models.CloneUser, name string) int {
+	for i, user := range users {
+		if user.Name == name {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func findSavepoint(savepoints []models.Savepoint, name string) int {
+	for i, savepoint := range savepoints {
+		if savepoint.Name == name {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (s *Server) findSnapshot(snapshotID string) *models.Snapshot {
+	for _, snapshot := range s.snapshots {
+		if snapshot.ID == snapshotID {
+			return snapshot
+		}
+	}
+
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(statusCode)
+
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, statusCode int, code models.ErrorCode, message string) {
+	writeJSON(w, statusCode, models.Error{Code: code, Message: message})
+}
@@ -0,0 +1,238 @@
+package dblabtest_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/client/dblabapi"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/client/dblabapi/dblabtest"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/client/dblabapi/types"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+func newClient(t *testing.T, server *dblabtest.Server, token string) *dblabapi.Client {
+	t.Helper()
+
+	client, err := dblabapi.NewClient(dblabapi.Options{
+		Host:              server.URL,
+		VerificationToken: token,
+		RetryPolicy:       &dblabapi.ExponentialBackoff{MaxAttempts: 3, InitialDelay: time.Millisecond},
+	})
+	require.NoError(t, err)
+
+	return client
+}
+
+func TestServerClones(t *testing.T) {
+	server := dblabtest.NewServer("secret")
+	defer server.Close()
+
+	server.AddSnapshot(models.Snapshot{ID: "snapshot1"})
+	server.AddSnapshot(models.Snapshot{ID: "snapshot2"})
+
+	client := newClient(t, server, "secret")
+	ctx := context.Background()
+
+	clone, err := client.CreateCloneAsync(ctx, types.CloneCreateRequest{
+		Snapshot: &types.SnapshotCloneFieldRequest{ID: "snapshot1"},
+		DB:       &types.DatabaseRequest{Username: "john", Password: "secret"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "clone1", clone.ID)
+	assert.Equal(t, "snapshot1", clone.Snapshot.ID)
+	assert.Equal(t, models.StatusOK, clone.Status.Code)
+
+	require.NoError(t, client.ResetCloneAsync(ctx, clone.ID, types.ResetCloneRequest{SnapshotID: "snapshot2"}))
+
+	clone, err = client.GetClone(ctx, clone.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "snapshot2", clone.Snapshot.ID)
+
+	require.NoError(t, client.DestroyCloneAsync(ctx, clone.ID))
+	assert.Empty(t, server.Clones())
+
+	_, err = client.GetClone(ctx, clone.ID)
+	require.Error(t, err)
+}
+
+func TestServerFailures(t *testing.T) {
+	server := dblabtest.NewServer("secret")
+	defer server.Close()
+
+	server.AddSnapshot(models.Snapshot{ID: "snapshot1"})
+
+	_, err := newClient(t, server, "wrong").ListSnapshots(context.Background())
+	require.Error(t, err)
+
+	client := newClient(t, server, "secret")
+
+	// Clone creation is retried with the same idempotency key, so only one clone is created.
+	server.FailNext(http.StatusServiceUnavailable, http.StatusBadGateway)
+
+	clone, err := client.CreateCloneAsync(context.Background(), types.CloneCreateRequest{})
+	require.NoError(t, err)
+	assert.Len(t, server.Clones(), 1)
+	assert.Equal(t, server.Clones()[0].ID, clone.ID)
+
+	requests := server.Requests()
+	require.Len(t, requests, 4)
+
+	for _, request := range requests[1:] {
+		assert.Equal(t, requests[1].Header.Get("Idempotency-Key"), request.Header.Get("Idempotency-Key"))
+		assert.Equal(t, requests[1].Header.Get("X-Request-ID"), request.Header.Get("X-Request-ID"))
+	}
+
+	server.FailNext(http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError)
+
+	_, err = client.Status(context.Background())
+	require.Error(t, err)
+}
+
+func TestServerCloneHibernation(t *testing.T) {
+	server := dblabtest.NewServer("secret")
+	defer server.Close()
+
+	server.AddSnapshot(models.Snapshot{ID: "snapshot1"})
+
+	client := newClient(t, server, "secret")
+	ctx := context.Background()
+
+	clone, err := client.CreateCloneAsync(ctx, types.CloneCreateRequest{
+		DB: &types.DatabaseRequest{Username: "john", Password: "secret"},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, client.StopClone(ctx, clone.ID))
+
+	clone, err = client.GetClone(ctx, clone.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusStopped, clone.Status.Code)
+
+	err = client.StopClone(ctx, clone.ID)
+	require.Error(t, err)
+
+	var apiErr models.Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, models.ErrCodeCloneBusy, apiErr.Code)
+
+	require.NoError(t, client.StartCloneAsync(ctx, clone.ID))
+
+	clone, err = client.GetClone(ctx, clone.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusOK, clone.Status.Code)
+}
+
+func TestServerCloneSavepoints(t *testing.T) {
+	server := dblabtest.NewServer("secret")
+	defer server.Close()
+
+	server.AddSnapshot(models.Snapshot{ID: "snapshot1"})
+
+	client := newClient(t, server, "secret")
+	ctx := context.Background()
+
+	clone, err := client.CreateCloneAsync(ctx, types.CloneCreateRequest{
+		DB: &types.DatabaseRequest{Username: "john", Password: "secret"},
+	})
+	require.NoError(t, err)
+
+	for _, name := range []string{"before_migration", "after_migration"} {
+		savepoint, err := client.CreateSavepoint(ctx, clone.ID, types.SavepointCreateRequest{Name: name})
+		require.NoError(t, err)
+		assert.Equal(t, name, savepoint.Name)
+	}
+
+	_, err = client.CreateSavepoint(ctx, clone.ID, types.SavepointCreateRequest{Name: "before_migration"})
+	require.Error(t, err)
+
+	var apiErr models.Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, models.ErrCodeSavepointExists, apiErr.Code)
+
+	_, err = client.RollbackSavepoint(ctx, clone.ID, types.SavepointRollbackRequest{Name: "before_migration"})
+	require.NoError(t, err)
+
+	savepoints, err := client.ListSavepoints(ctx, clone.ID)
+	require.NoError(t, err)
+	require.Len(t, savepoints, 1)
+	assert.Equal(t, "before_migration", savepoints[0].Name)
+}
+
+func TestServerCloneUpgrade(t *testing.T) {
+	server := dblabtest.NewServer("secret")
+	defer server.Close()
+
+	server.AddSnapshot(models.Snapshot{ID: "snapshot1"})
+
+	client := newClient(t, server, "secret")
+	ctx := context.Background()
+
+	clone, err := client.CreateCloneAsync(ctx, types.CloneCreateRequest{
+		DB: &types.DatabaseRequest{Username: "john", Password: "secret"},
+	})
+	require.NoError(t, err)
+
+	_, err = client.GetCloneUpgrade(ctx, clone.ID)
+	require.Error(t, err)
+
+	upgrade, err := client.UpgradeClone(ctx, clone.ID, types.CloneUpgradeRequest{DockerImage: "postgresai/extended-postgres:17"})
+	require.NoError(t, err)
+	assert.Equal(t, models.UpgradeStatusFinished, upgrade.Status)
+
+	clone, err = client.GetClone(ctx, clone.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "postgresai/extended-postgres:17", clone.DockerImage)
+}
+
+func TestServerCloneUsers(t *testing.T) {
+	server := dblabtest.NewServer("secret")
+	defer server.Close()
+
+	server.AddSnapshot(models.Snapshot{ID: "snapshot1"})
+
+	client := newClient(t, server, "secret")
+	ctx := context.Background()
+
+	clone, err := client.CreateCloneAsync(ctx, types.CloneCreateRequest{
+		DB: &types.DatabaseRequest{Username: "app", Password: "secret", Restricted: true},
+	})
+	require.NoError(t, err)
+
+	user, err := client.CreateCloneUser(ctx, clone.ID, types.CloneUserRequest{
+		Username: "analyst",
+		Password: "secret",
+		Role:     models.UserRoleReadOnly,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, models.UserRoleReadOnly, user.Role)
+
+	_, err = client.CreateCloneUser(ctx, clone.ID, types.CloneUserRequest{
+		Username: "analyst",
+		Password: "other",
+		Role:     models.UserRoleReadOnly,
+	})
+	require.Error(t, err)
+
+	var apiErr models.Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, models.ErrCodeUserExists, apiErr.Code)
+
+	users, err := client.ListCloneUsers(ctx, clone.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []models.CloneUser{
+		{Name: "app", Role: models.UserRoleRestricted, Primary: true},
+		{Name: "analyst", Role: models.UserRoleReadOnly},
+	}, users)
+
+	require.Error(t, client.DeleteCloneUser(ctx, clone.ID, "app"))
+	require.NoError(t, client.DeleteCloneUser(ctx, clone.ID, "analyst"))
+
+	users, err = client.ListCloneUsers(ctx, clone.ID)
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+}
@@ -0,0 +1,126 @@
+package dblabapi
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/client/dblabapi/types"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+const (
+	eventStreamContentType = "text/event-stream"
+	eventDataPrefix        = "data:"
+
+	// maxEventSize limits the size of a single event in the stream.
+	maxEventSize = 1024 * 1024
+)
+
+// WatchEvents subscribes to state changes of the instance.
+// The channel is closed when the context is canceled or the stream is interrupted.
+// The attempt timeout of the client limits only opening the stream.
+func (c *Client) WatchEvents(ctx context.Context, filter types.EventsFilter) (<-chan models.Event, error) {
+	u, err := c.operationURL("streamEvents", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	values := u.Query()
+
+	if filter.CloneID != "" {
+		values.Set("clone_id", filter.CloneID)
+	}
+
+	for _, eventType := range filter.Types {
+		values.Add("type", string(eventType))
+	}
+
+	u.RawQuery = values.Encode()
+
+	request, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to make a request")
+	}
+
+	request.Header.Set("Accept", eventStreamContentType)
+
+	response, err := c.Do(withStream(ctx), request)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get response")
+	}
+
+	if mediaType, _, _ := mime.ParseMediaType(response.Header.Get("Content-Type")); mediaType != eventStreamContentType {
+		_ = response.Body.Close()
+
+		return nil, errors.Errorf("unexpected content type of event stream: %q", response.Header.Get("Content-Type"))
+	}
+
+	eventCh := make(chan models.Event)
+
+	go func() {
+		defer close(eventCh)
+		defer func() { _ = response.Body.Close() }()
+
+		if err := readEvents(ctx, response.Body, eventCh); err != nil && ctx.Err() == nil {
+			log.Dbg("Event stream has been interrupted: ", err)
+		}
+	}()
+
+	return eventCh, nil
+}
+
+// readEvents parses server-sent events. Only data fields are used because events contain their types.
+func readEvents(ctx context.Context, body io.Reader, eventCh chan<- models.Event) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxEventSize)
+
+	data := strings.Builder{}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if strings.HasPrefix(line, eventDataPrefix) {
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, eventDataPrefix), " "))
+
+			continue
+		}
+
+		// Other fields and comments are skipped, an empty line dispatches the event.
+		if line != "" || data.Len() == 0 {
+			continue
+		}
+
+		var event models.Event
+
+		if err := json.Unmarshal([]byte(data.String()), &event); err != nil {
+			return errors.Wrap(err, "failed to decode event")
+		}
+
+		data.Reset()
+
+		select {
+		case eventCh <- event:
+
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	return io.ErrUnexpectedEOF
+}
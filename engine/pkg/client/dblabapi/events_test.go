@@ -0,0 +1,172 @@
+package dblabapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/client/dblabapi/types"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+const testEventStream = `: keep-alive
+
+id: 1
+event: clone_status
+data: {"id":1,"type":"clone_status","clone":{"id":"testCloneID","status":{"code":"CREATING"}}}
+
+id: 2
+event: clone_status
+data: {"id":2,"type":"clone_status",
+data: "clone":{"id":"testCloneID","status":{"code":"OK"}}}
+
+`
+
+func eventStreamResponse(stream string) *http.Response {
+	header := make(http.Header)
+	header.Set("Content-Type", "text/event-stream")
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader(stream)),
+		Header:     header,
+	}
+}
+
+func TestClientWatchEvents(t *testing.T) {
+	mockClient := NewTestClient(func(r *http.Request) *http.Response {
+		assert.Equal(t, "/events", r.URL.Path)
+		assert.Equal(t, "testCloneID", r.URL.Query().Get("clone_id"))
+		assert.Equal(t, []string{"clone_status"}, r.URL.Query()["type"])
+
+		return eventStreamResponse(testEventStream)
+	})
+
+	c, err := NewClient(Options{
+		Host:              "https://example.com/",
+		VerificationToken: "testVerify",
+	})
+	require.NoError(t, err)
+
+	c.client = mockClient
+
+	eventCh, err := c.WatchEvents(context.Background(), types.EventsFilter{
+		CloneID: "testCloneID",
+		Types:   []models.EventType{models.EventCloneStatus},
+	})
+	require.NoError(t, err)
+
+	received := []models.Event{}
+	for event := range eventCh {
+		received = append(received, event)
+	}
+
+	require.Len(t, received, 2)
+	assert.Equal(t, uint64(1), received[0].ID)
+	assert.Equal(t, models.StatusCreating, received[0].Clone.Status.Code)
+	assert.Equal(t, uint64(2), received[1].ID)
+	assert.Equal(t, models.StatusOK, received[1].Clone.Status.Code)
+}
+
+func TestClientWatchEventsWithUnexpectedResponse(t *testing.T) {
+	mockClient := NewTestClient(func(r *http.Request) *http.Response {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(bytes.NewBufferString("{}")),
+			Header:     make(http.Header),
+		}
+	})
+
+	c, err := NewClient(Options{
+		Host:              "https://example.com/",
+		VerificationToken: "testVerify",
+	})
+	require.NoError(t, err)
+
+	c.client = mockClient
+
+	_, err = c.WatchEvents(context.Background(), types.EventsFilter{})
+	require.Error(t, err)
+}
+
+func TestClientCreateCloneWithEventStream(t *testing.T) {
+	mockClient := NewTestClient(func(r *http.Request) *http.Response {
+		if r.URL.Path == "/events" {
+			return eventStreamResponse(testEventStream)
+		}
+
+		if r.Method == http.MethodGet {
+			assert.Equal(t, "/clone/testCloneID", r.URL.Path)
+		}
+
+		clone := models.Clone{ID: "testCloneID", Status: models.Status{Code: models.StatusCreating}}
+
+		responseBody, err := json.Marshal(clone)
+		require.NoError(t, err)
+
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(bytes.NewBuffer(responseBody)),
+			Header:     make(http.Header),
+		}
+	})
+
+	c, err := NewClient(Options{
+		Host:              "https://example.com/",
+		VerificationToken: "testVerify",
+	})
+	require.NoError(t, err)
+
+	c.client = mockClient
+	// Polling must not be used while the event stream is available.
+	c.pollingInterval = time.Hour
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	clone, err := c.CreateClone(ctx, types.CloneCreateRequest{ID: "testCloneID"})
+	require.NoError(t, err)
+
+	assert.Equal(t, models.StatusOK, clone.Status.Code)
+}
+
+func TestClientDestroyCloneWithEventStream(t *testing.T) {
+	mockClient := NewTestClient(func(r *http.Request) *http.Response {
+		if r.URL.Path == "/events" {
+			return eventStreamResponse(`data: {"id":1,"type":"clone_deleted","clone":{"id":"testCloneID"}}` + "\n\n")
+		}
+
+		clone := models.Clone{ID: "testCloneID", Status: models.Status{Code: models.StatusDeleting}}
+
+		responseBody, err := json.Marshal(clone)
+		require.NoError(t, err)
+
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(bytes.NewBuffer(responseBody)),
+			Header:     make(http.Header),
+		}
+	})
+
+	c, err := NewClient(Options{
+		Host:              "https://example.com/",
+		VerificationToken: "testVerify",
+	})
+	require.NoError(t, err)
+
+	c.client = mockClient
+	c.pollingInterval = time.Hour
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	require.NoError(t, c.DestroyClone(ctx, "testCloneID"))
+}
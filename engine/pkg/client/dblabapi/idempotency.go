@@ -0,0 +1,40 @@
+package dblabapi
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// idempotencyKeyHeader defines the header making repeated requests return the result of the first one.
+const idempotencyKeyHeader = "Idempotency-Key"
+
+type idempotencyKeyCtxKey struct{}
+
+// WithIdempotencyKey returns a context making clone creation, reset and destruction requests idempotent with the key.
+// Use the same key when the whole operation is retried, for example, when a CI job is restarted.
+// Requests without a supplied key get a random key, so only retries of a single call are deduplicated.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyCtxKey{}, key)
+}
+
+// ensureIdempotencyKey returns a context containing an idempotency key, generating a new key if there is none.
+func ensureIdempotencyKey(ctx context.Context) context.Context {
+	if key, _ := ctx.Value(idempotencyKeyCtxKey{}).(string); key != "" {
+		return ctx
+	}
+
+	return WithIdempotencyKey(ctx, uuid.NewString())
+}
+
+// setIdempotencyKey sets the idempotency key of the context to the modifying request.
+func setIdempotencyKey(ctx context.Context, request *http.Request) {
+	if request.Method == http.MethodGet {
+		return
+	}
+
+	if key, _ := ctx.Value(idempotencyKeyCtxKey{}).(string); key != "" {
+		request.Header.Set(idempotencyKeyHeader, key)
+	}
+}
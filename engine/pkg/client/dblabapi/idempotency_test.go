@@ -0,0 +1,44 @@
+package dblabapi
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotencyKeys(t *testing.T) {
+	keys := []string{}
+
+	mockClient := NewTestClient(func(r *http.Request) *http.Response {
+		assert.Equal(t, http.MethodDelete, r.Method)
+		keys = append(keys, r.Header.Get(idempotencyKeyHeader))
+
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(strings.NewReader("")),
+			Header:     make(http.Header),
+		}
+	})
+
+	c, err := NewClient(Options{
+		Host:              "https://example.com/",
+		VerificationToken: "token",
+	})
+	require.NoError(t, err)
+
+	c.client = mockClient
+
+	require.NoError(t, c.DestroyCloneAsync(context.Background(), "clone1"))
+	require.NoError(t, c.DestroyCloneAsync(context.Background(), "clone1"))
+	require.NoError(t, c.DestroyCloneAsync(WithIdempotencyKey(context.Background(), "ci-job-1"), "clone1"))
+
+	require.Len(t, keys, 3)
+	assert.NotEmpty(t, keys[0])
+	assert.NotEqual(t, keys[0], keys[1])
+	assert.Equal(t, "ci-job-1", keys[2])
+}
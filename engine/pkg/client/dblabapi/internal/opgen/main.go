@@ -0,0 +1,132 @@
+// Command opgen generates the table of DBLab API v2 operations from the OpenAPI spec.
+// The table maps operation IDs to HTTP methods and paths, it is the only generated part of the API client,
+// the client methods, request and response types are written by hand.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"go/format"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+var httpMethods = map[string]bool{
+	"get": true, "put": true, "post": true, "delete": true, "options": true, "head": true, "patch": true, "trace": true,
+}
+
+var operationsTemplate = template.Must(template.New("operations").Parse(`// Code generated by opgen from {{ .Source }}. DO NOT EDIT.
+
+package dblabapi
+
+import "net/http"
+
+// v2Operations defines methods and paths of API v2 operations by operation IDs.
+var v2Operations = map[string]operation{
+{{- range .Operations }}
+	"{{ .ID }}": {method: http.Method{{ .Method }}, path: "{{ .Path }}"},
+{{- end }}
+}
+`))
+
+type specOperation struct {
+	ID     string
+	Method string
+	Path   string
+}
+
+func main() {
+	specPath := flag.String("spec", "", "path to the OpenAPI spec of API v2")
+	outPath := flag.String("out", "", "path to the generated file")
+	flag.Parse()
+
+	spec, err := os.ReadFile(*specPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	code, err := generate(spec, specSource(*specPath))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := os.WriteFile(*outPath, code, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// specSource returns the path of the spec relative to the engine root to mention it in the generated file.
+func specSource(specPath string) string {
+	if i := strings.Index(specPath, "api/swagger-spec/"); i >= 0 {
+		return specPath[i:]
+	}
+
+	return specPath
+}
+
+// generate builds the Go source defining operations of the spec sorted by operation IDs.
+func generate(spec []byte, source string) ([]byte, error) {
+	var doc struct {
+		Paths map[string]map[string]yaml.Node `yaml:"paths"`
+	}
+
+	if err := yaml.Unmarshal(spec, &doc); err != nil {
+		return nil, errors.Wrap(err, "failed to parse the spec")
+	}
+
+	operations := []specOperation{}
+	ids := make(map[string]bool)
+
+	for path, pathItem := range doc.Paths {
+		for method, node := range pathItem {
+			if !httpMethods[method] {
+				continue
+			}
+
+			var op struct {
+				OperationID string `yaml:"operationId"`
+			}
+
+			if err := node.Decode(&op); err != nil {
+				return nil, errors.Wrapf(err, "failed to parse operation %s %s", method, path)
+			}
+
+			if op.OperationID == "" {
+				return nil, errors.Errorf("operation %s %s has no operationId", method, path)
+			}
+
+			if ids[op.OperationID] {
+				return nil, errors.Errorf("duplicate operationId %q", op.OperationID)
+			}
+
+			ids[op.OperationID] = true
+
+			operations = append(operations, specOperation{
+				ID:     op.OperationID,
+				Method: strings.ToUpper(method[:1]) + method[1:],
+				Path:   path,
+			})
+		}
+	}
+
+	sort.Slice(operations, func(i, j int) bool {
+		return operations[i].ID < operations[j].ID
+	})
+
+	buf := &bytes.Buffer{}
+
+	if err := operationsTemplate.Execute(buf, struct {
+		Source     string
+		Operations []specOperation
+	}{Source: source, Operations: operations}); err != nil {
+		return nil, errors.Wrap(err, "failed to render operations")
+	}
+
+	return format.Source(buf.Bytes())
+}
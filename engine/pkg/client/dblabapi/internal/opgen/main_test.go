@@ -0,0 +1,32 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	specPath      = "../../../../../api/swagger-spec/dblab_server_v2.yaml"
+	generatedPath = "../../operations_v2_gen.go"
+)
+
+func TestGeneratedOperationsUpToDate(t *testing.T) {
+	spec, err := os.ReadFile(specPath)
+	require.NoError(t, err)
+
+	code, err := generate(spec, specSource(specPath))
+	require.NoError(t, err)
+
+	generated, err := os.ReadFile(generatedPath)
+	require.NoError(t, err)
+
+	assert.Equal(t, string(code), string(generated), "run go generate ./pkg/client/dblabapi")
+}
+
+func TestGenerateRequiresOperationID(t *testing.T) {
+	_, err := generate([]byte("paths:\n  /clones:\n    get:\n      summary: List clones\n"), "spec.yaml")
+	assert.EqualError(t, err, "operation get /clones has no operationId")
+}
@@ -0,0 +1,117 @@
+package dblabapi
+
+//go:generate go run ./internal/opgen -spec ../../../api/swagger-spec/dblab_server_v2.yaml -out operations_v2_gen.go
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// APIVersion defines the version of the DBLab API used by the client.
+type APIVersion string
+
+const (
+	// APIVersionV1 is the default version of the API.
+	APIVersionV1 APIVersion = "v1"
+	// APIVersionV2 is the version of the API described by api/swagger-spec/dblab_server_v2.yaml.
+	// Requests of admin, instance logs and health check endpoints are sent to API v1 anyway, since API v2 does not serve them.
+	APIVersionV2 APIVersion = "v2"
+
+	v2PathPrefix = "/api/v2"
+)
+
+// operation describes an endpoint of the API.
+// The client methods are written by hand and refer to endpoints by operation IDs of the API v2 spec,
+// so only the table of API v2 operations is generated from the spec, see operations_v2_gen.go.
+type operation struct {
+	method string
+	path   string
+	// query lists parameters passed in the query string instead of the path.
+	query []string
+}
+
+// v1Operations defines operations of API v1 by operation IDs of API v2.
+var v1Operations = map[string]operation{
+	"getInstanceStatus": {method: http.MethodGet, path: "/status"},
+	"getRetrievalState": {method: http.MethodGet, path: "/instance/retrieval"},
+	"streamEvents":      {method: http.MethodGet, path: "/events"},
+	"listSnapshots":     {method: http.MethodGet, path: "/snapshots"},
+
+	"listClones":        {method: http.MethodGet, path: "/clones"},
+	"createClone":       {method: http.MethodPost, path: "/clone"},
+	"destroyClones":     {method: http.MethodPost, path: "/clones/destroy"},
+	"resetClones":       {method: http.MethodPost, path: "/clones/reset"},
+	"getClone":          {method: http.MethodGet, path: "/clone/{id}"},
+	"updateClone":       {method: http.MethodPatch, path: "/clone/{id}"},
+	"destroyClone":      {method: http.MethodDelete, path: "/clone/{id}"},
+	"resetClone":        {method: http.MethodPost, path: "/clone/{id}/reset"},
+	"stopClone":         {method: http.MethodPost, path: "/clone/{id}/stop"},
+	"startClone":        {method: http.MethodPost, path: "/clone/{id}/start"},
+	"listSavepoints":    {method: http.MethodGet, path: "/clone/{id}/savepoints"},
+	"createSavepoint":   {method: http.MethodPost, path: "/clone/{id}/savepoint"},
+	"rollbackSavepoint": {method: http.MethodPost, path: "/clone/{id}/rollback"},
+	"getCloneUpgrade":   {method: http.MethodGet, path: "/clone/{id}/upgrade"},
+	"upgradeClone":      {method: http.MethodPost, path: "/clone/{id}/upgrade"},
+	"listCloneUsers":    {method: http.MethodGet, path: "/clone/{id}/users"},
+	"createCloneUser":   {method: http.MethodPost, path: "/clone/{id}/users"},
+	"deleteCloneUser":   {method: http.MethodDelete, path: "/clone/{id}/users/{name}"},
+
+	"startObservation":      {method: http.MethodPost, path: "/observation/start"},
+	"stopObservation":       {method: http.MethodPost, path: "/observation/stop"},
+	"getObservationSummary": {method: http.MethodGet, path: "/observation/summary/{clone_id}/{session_id}"},
+	"downloadObservationArtifact": {
+		method: http.MethodGet,
+		path:   "/observation/download",
+		query:  []string{"clone_id", "session_id", "artifact_type"},
+	},
+	"uploadObservationArtifact": {
+		method: http.MethodPost,
+		path:   "/observation/artifact",
+		query:  []string{"clone_id", "artifact_type"},
+	},
+
+	"getReplayStatus": {method: http.MethodGet, path: "/replay/status/{clone_id}"},
+	"startReplay":     {method: http.MethodPost, path: "/replay/start"},
+	"stopReplay":      {method: http.MethodPost, path: "/replay/stop"},
+
+	"checkPlans": {method: http.MethodPost, path: "/plan-check"},
+}
+
+// operationURL builds URL of the operation for the API version of the client.
+// Parameters are substituted into the path or added to the query string as the operation requires,
+// parameters not used by the operation are ignored.
+func (c *Client) operationURL(operationID string, params map[string]string) (*url.URL, error) {
+	operations, prefix := v1Operations, ""
+
+	if c.apiVersion == APIVersionV2 {
+		operations, prefix = v2Operations, v2PathPrefix
+	}
+
+	op, ok := operations[operationID]
+	if !ok {
+		return nil, errors.Errorf("unknown API operation: %s", operationID)
+	}
+
+	p := op.path
+
+	for name, value := range params {
+		p = strings.ReplaceAll(p, "{"+name+"}", value)
+	}
+
+	u := c.URL(prefix + p)
+
+	if len(op.query) > 0 {
+		values := url.Values{}
+
+		for _, name := range op.query {
+			values.Set(name, params[name])
+		}
+
+		u.RawQuery = values.Encode()
+	}
+
+	return u, nil
+}
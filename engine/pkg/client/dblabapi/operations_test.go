@@ -0,0 +1,151 @@
+package dblabapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+func TestOperationsOfAPIVersions(t *testing.T) {
+	require.Len(t, v1Operations, len(v2Operations))
+
+	for operationID, v2Operation := range v2Operations {
+		v1Operation, ok := v1Operations[operationID]
+		if assert.True(t, ok, "operation %s is not defined for API v1", operationID) {
+			assert.Equal(t, v2Operation.method, v1Operation.method, operationID)
+		}
+	}
+}
+
+func TestV1OperationsMatchSpec(t *testing.T) {
+	spec, err := os.ReadFile("../../../api/swagger-spec/dblab_server_swagger.yaml")
+	require.NoError(t, err)
+
+	var doc struct {
+		Paths map[string]map[string]yaml.Node `yaml:"paths"`
+	}
+
+	require.NoError(t, yaml.Unmarshal(spec, &doc))
+
+	for operationID, op := range v1Operations {
+		_, ok := doc.Paths[op.path][strings.ToLower(op.method)]
+		assert.True(t, ok, "operation %s: %s %s is not described by the API v1 spec", operationID, op.method, op.path)
+	}
+}
+
+func TestOperationURL(t *testing.T) {
+	testCases := []struct {
+		apiVersion  APIVersion
+		operationID string
+		params      map[string]string
+		expected    string
+	}{
+		{
+			apiVersion:  APIVersionV1,
+			operationID: "getClone",
+			params:      map[string]string{"id": "clone1"},
+			expected:    "https://example.com/dblab/clone/clone1",
+		},
+		{
+			apiVersion:  APIVersionV2,
+			operationID: "getClone",
+			params:      map[string]string{"id": "clone1"},
+			expected:    "https://example.com/dblab/api/v2/clones/clone1",
+		},
+		{
+			apiVersion:  APIVersionV1,
+			operationID: "downloadObservationArtifact",
+			params:      map[string]string{"clone_id": "clone1", "session_id": "2", "artifact_type": "log"},
+			expected:    "https://example.com/dblab/observation/download?artifact_type=log&clone_id=clone1&session_id=2",
+		},
+		{
+			apiVersion:  APIVersionV2,
+			operationID: "downloadObservationArtifact",
+			params:      map[string]string{"clone_id": "clone1", "session_id": "2", "artifact_type": "log"},
+			expected:    "https://example.com/dblab/api/v2/clones/clone1/observations/2/artifacts/log",
+		},
+		{
+			apiVersion:  APIVersionV2,
+			operationID: "startObservation",
+			params:      map[string]string{"clone_id": "clone1"},
+			expected:    "https://example.com/dblab/api/v2/clones/clone1/observations",
+		},
+		{
+			apiVersion:  APIVersionV1,
+			operationID: "startObservation",
+			params:      map[string]string{"clone_id": "clone1"},
+			expected:    "https://example.com/dblab/observation/start",
+		},
+	}
+
+	for _, tc := range testCases {
+		c, err := NewClient(Options{Host: "https://example.com/dblab", APIVersion: tc.apiVersion})
+		require.NoError(t, err)
+
+		u, err := c.operationURL(tc.operationID, tc.params)
+		require.NoError(t, err)
+
+		assert.Equal(t, tc.expected, u.String())
+	}
+}
+
+func TestOperationURLUnknownOperation(t *testing.T) {
+	for _, apiVersion := range []APIVersion{APIVersionV1, APIVersionV2} {
+		c, err := NewClient(Options{Host: "https://example.com/dblab", APIVersion: apiVersion})
+		require.NoError(t, err)
+
+		_, err = c.operationURL("unknownOperation", nil)
+		assert.EqualError(t, err, "unknown API operation: unknownOperation")
+	}
+}
+
+func TestNewClientWithUnsupportedAPIVersion(t *testing.T) {
+	_, err := NewClient(Options{Host: "https://example.com", APIVersion: "v3"})
+	assert.EqualError(t, err, `unsupported API version: "v3"`)
+}
+
+func TestClientAPIV2(t *testing.T) {
+	var requests []string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v2/clones/clone1":
+			_ = json.NewEncoder(w).Encode(models.Clone{ID: "clone1", Status: models.Status{Code: models.StatusOK}})
+
+		case r.Method == http.MethodDelete && r.URL.Path == "/api/v2/clones/missing":
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(models.Error{Code: models.ErrCodeCloneNotFound, Message: "clone not found"})
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(models.Error{Code: models.ErrCodeNotFound, Message: "not found"})
+		}
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(Options{Host: srv.URL, APIVersion: APIVersionV2})
+	require.NoError(t, err)
+
+	clone, err := c.GetClone(context.Background(), "clone1")
+	require.NoError(t, err)
+	assert.Equal(t, "clone1", clone.ID)
+
+	err = c.DestroyClone(context.Background(), "missing")
+	require.Error(t, err)
+	assert.Equal(t, models.ErrCodeCloneNotFound, errors.Cause(err).(models.Error).Code)
+
+	assert.Equal(t, []string{"GET /api/v2/clones/clone1", "DELETE /api/v2/clones/missing"}, requests)
+}
@@ -0,0 +1,40 @@
+// Code generated by opgen from api/swagger-spec/dblab_server_v2.yaml. DO NOT EDIT.
+
+package dblabapi
+
+import "net/http"
+
+// v2Operations defines methods and paths of API v2 operations by operation IDs.
+var v2Operations = map[string]operation{
+	"checkPlans":                  {method: http.MethodPost, path: "/plan-checks"},
+	"createClone":                 {method: http.MethodPost, path: "/clones"},
+	"createCloneUser":             {method: http.MethodPost, path: "/clones/{id}/users"},
+	"createSavepoint":             {method: http.MethodPost, path: "/clones/{id}/savepoints"},
+	"deleteCloneUser":             {method: http.MethodDelete, path: "/clones/{id}/users/{name}"},
+	"destroyClone":                {method: http.MethodDelete, path: "/clones/{id}"},
+	"destroyClones":               {method: http.MethodPost, path: "/clones/bulk/destroy"},
+	"downloadObservationArtifact": {method: http.MethodGet, path: "/clones/{clone_id}/observations/{session_id}/artifacts/{artifact_type}"},
+	"getClone":                    {method: http.MethodGet, path: "/clones/{id}"},
+	"getCloneUpgrade":             {method: http.MethodGet, path: "/clones/{id}/upgrade"},
+	"getInstanceStatus":           {method: http.MethodGet, path: "/instance/status"},
+	"getObservationSummary":       {method: http.MethodGet, path: "/clones/{clone_id}/observations/{session_id}"},
+	"getReplayStatus":             {method: http.MethodGet, path: "/clones/{clone_id}/replay"},
+	"getRetrievalState":           {method: http.MethodGet, path: "/instance/retrieval"},
+	"listCloneUsers":              {method: http.MethodGet, path: "/clones/{id}/users"},
+	"listClones":                  {method: http.MethodGet, path: "/clones"},
+	"listSavepoints":              {method: http.MethodGet, path: "/clones/{id}/savepoints"},
+	"listSnapshots":               {method: http.MethodGet, path: "/snapshots"},
+	"resetClone":                  {method: http.MethodPost, path: "/clones/{id}/reset"},
+	"resetClones":                 {method: http.MethodPost, path: "/clones/bulk/reset"},
+	"rollbackSavepoint":           {method: http.MethodPost, path: "/clones/{id}/rollback"},
+	"startClone":                  {method: http.MethodPost, path: "/clones/{id}/start"},
+	"startObservation":            {method: http.MethodPost, path: "/clones/{clone_id}/observations"},
+	"startReplay":                 {method: http.MethodPost, path: "/clones/{clone_id}/replay"},
+	"stopClone":                   {method: http.MethodPost, path: "/clones/{id}/stop"},
+	"stopObservation":             {method: http.MethodPost, path: "/clones/{clone_id}/observations/stop"},
+	"stopReplay":                  {method: http.MethodPost, path: "/clones/{clone_id}/replay/stop"},
+	"streamEvents":                {method: http.MethodGet, path: "/instance/events"},
+	"updateClone":                 {method: http.MethodPatch, path: "/clones/{id}"},
+	"upgradeClone":                {method: http.MethodPost, path: "/clones/{id}/upgrade"},
+	"uploadObservationArtifact":   {method: http.MethodPost, path: "/clones/{clone_id}/observations/artifacts/{artifact_type}"},
+}
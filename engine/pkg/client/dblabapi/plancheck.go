@@ -0,0 +1,24 @@
+package dblabapi
+
+import (
+	"context"
+
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/client/dblabapi/types"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+// CheckPlans runs a query plan regression check and returns its report.
+func (c *Client) CheckPlans(ctx context.Context, planCheckRequest types.PlanCheckRequest) (*models.PlanCheckReport, error) {
+	u, err := c.operationURL("checkPlans", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var report models.PlanCheckReport
+
+	if err := c.request(ctx, u, planCheckRequest, &report); err != nil {
+		return nil, err
+	}
+
+	return &report, nil
+}
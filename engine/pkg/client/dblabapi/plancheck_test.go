@@ -0,0 +1,68 @@
+package dblabapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/client/dblabapi/types"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+func TestClientCheckPlans(t *testing.T) {
+	planCheckRequest := types.PlanCheckRequest{
+		Queries:   []types.PlanQuery{{Name: "user", Query: "select * from users where id = $1", Params: []interface{}{float64(1)}}},
+		SnapshotA: "snapshot_a",
+		SnapshotB: "snapshot_b",
+	}
+
+	expectedReport := &models.PlanCheckReport{
+		Status:   models.PlanCheckFailed,
+		Baseline: "snapshot snapshot_a",
+		Target:   "snapshot snapshot_b",
+		Queries: []models.QueryPlanReport{{
+			Name:        "user",
+			Query:       "select * from users where id = $1",
+			PlanChanged: true,
+			PlanDiff:    []string{"- Index Scan using users_pkey on users", "+ Seq Scan on users"},
+			Regressions: []string{"buffers grew 2.00x (10 -> 20)"},
+		}},
+	}
+
+	mockClient := NewTestClient(func(req *http.Request) *http.Response {
+		assert.Equal(t, "https://example.com/plan-check", req.URL.String())
+		assert.Equal(t, http.MethodPost, req.Method)
+
+		var received types.PlanCheckRequest
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&received))
+		assert.Equal(t, planCheckRequest, received)
+
+		body, err := json.Marshal(expectedReport)
+		require.NoError(t, err)
+
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(bytes.NewBuffer(body)),
+			Header:     make(http.Header),
+		}
+	})
+
+	c, err := NewClient(Options{
+		Host:              "https://example.com/",
+		VerificationToken: "testVerify",
+	})
+	require.NoError(t, err)
+
+	c.client = mockClient
+
+	report, err := c.CheckPlans(context.Background(), planCheckRequest)
+	require.NoError(t, err)
+
+	assert.Equal(t, expectedReport, report)
+}
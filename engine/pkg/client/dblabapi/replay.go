@@ -0,0 +1,72 @@
+package dblabapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/pkg/errors"
+
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/client/dblabapi/types"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+// StartReplay starts replaying a workload against the clone.
+func (c *Client) StartReplay(ctx context.Context, startRequest types.StartReplayRequest) (*models.ReplayStatus, error) {
+	u, err := c.operationURL("startReplay", map[string]string{"clone_id": startRequest.CloneID})
+	if err != nil {
+		return nil, err
+	}
+
+	var status models.ReplayStatus
+
+	if err := c.request(ctx, u, startRequest, &status); err != nil {
+		return nil, err
+	}
+
+	return &status, nil
+}
+
+// StopReplay stops the workload replay on the clone.
+func (c *Client) StopReplay(ctx context.Context, stopRequest types.StopReplayRequest) (*models.ReplayStatus, error) {
+	u, err := c.operationURL("stopReplay", map[string]string{"clone_id": stopRequest.CloneID})
+	if err != nil {
+		return nil, err
+	}
+
+	var status models.ReplayStatus
+
+	if err := c.request(ctx, u, stopRequest, &status); err != nil {
+		return nil, err
+	}
+
+	return &status, nil
+}
+
+// ReplayStatus returns the state and progress of the workload replay on the clone.
+func (c *Client) ReplayStatus(ctx context.Context, cloneID string) (*models.ReplayStatus, error) {
+	u, err := c.operationURL("getReplayStatus", map[string]string{"clone_id": cloneID})
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to make a request")
+	}
+
+	response, err := c.Do(ctx, request)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get response")
+	}
+
+	defer func() { _ = response.Body.Close() }()
+
+	var status models.ReplayStatus
+
+	if err := json.NewDecoder(response.Body).Decode(&status); err != nil {
+		return nil, errors.Wrap(err, "failed to decode a response body")
+	}
+
+	return &status, nil
+}
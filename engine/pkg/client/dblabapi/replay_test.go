@@ -0,0 +1,73 @@
+package dblabapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/client/dblabapi/types"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+func TestClientReplay(t *testing.T) {
+	expectedStatus := &models.ReplayStatus{
+		CloneID:     "testCloneID",
+		SessionID:   1,
+		State:       models.ReplayRunning,
+		Speed:       2,
+		Concurrency: 4,
+		Progress:    models.ReplayProgress{Sessions: 4, Statements: 100, Executed: 25, Percent: 25},
+	}
+
+	mockClient := NewTestClient(func(req *http.Request) *http.Response {
+		switch req.URL.Path {
+		case "/replay/start":
+			assert.Equal(t, http.MethodPost, req.Method)
+
+			var startRequest types.StartReplayRequest
+			require.NoError(t, json.NewDecoder(req.Body).Decode(&startRequest))
+			assert.Equal(t, types.ReplayFormatStatements, startRequest.Format)
+
+		case "/replay/status/testCloneID":
+			assert.Equal(t, http.MethodGet, req.Method)
+
+		default:
+			t.Fatalf("unexpected request: %s", req.URL.Path)
+		}
+
+		body, err := json.Marshal(expectedStatus)
+		require.NoError(t, err)
+
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(bytes.NewBuffer(body)),
+			Header:     make(http.Header),
+		}
+	})
+
+	c, err := NewClient(Options{
+		Host:              "https://example.com/",
+		VerificationToken: "testVerify",
+	})
+	require.NoError(t, err)
+
+	c.client = mockClient
+
+	status, err := c.StartReplay(context.Background(), types.StartReplayRequest{
+		CloneID:    "testCloneID",
+		Format:     types.ReplayFormatStatements,
+		Statements: []types.ReplayStatement{{Query: "select 1", Calls: 100}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, expectedStatus, status)
+
+	status, err = c.ReplayStatus(context.Background(), "testCloneID")
+	require.NoError(t, err)
+	assert.Equal(t, expectedStatus, status)
+}
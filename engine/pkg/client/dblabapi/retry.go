@@ -0,0 +1,120 @@
+package dblabapi
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultMaxAttempts       = 4
+	defaultInitialRetryDelay = 250 * time.Millisecond
+	defaultMaxRetryDelay     = 5 * time.Second
+	defaultRetryJitter       = 0.2
+
+	// maxDelayShift prevents overflows of exponential delays.
+	maxDelayShift = 30
+)
+
+// RetryPolicy decides whether a failed attempt of a request should be retried.
+type RetryPolicy interface {
+	// Retry returns the delay before the next attempt and false if the request must not be retried.
+	// The attempt number starts from 1. Either the response or the error of the attempt is not nil.
+	Retry(attempt int, request *http.Request, response *http.Response, err error) (time.Duration, bool)
+}
+
+// NoRetry is a retry policy making a single attempt of each request.
+type NoRetry struct{}
+
+// Retry never retries requests.
+func (NoRetry) Retry(int, *http.Request, *http.Response, error) (time.Duration, bool) {
+	return 0, false
+}
+
+// ExponentialBackoff retries idempotent requests failed with connection errors or retryable statuses.
+// Requests are considered idempotent if their methods are idempotent or they have an Idempotency-Key header.
+type ExponentialBackoff struct {
+	// MaxAttempts limits the number of attempts including the first one.
+	MaxAttempts int
+	// InitialDelay defines the delay after the first attempt. It is doubled after each next attempt.
+	InitialDelay time.Duration
+	// MaxDelay limits the delay between attempts.
+	MaxDelay time.Duration
+	// Jitter defines the random fraction of the delay (from 0 to 1) subtracted to spread out retries of clients.
+	Jitter float64
+}
+
+// DefaultRetryPolicy returns the retry policy used by clients without a configured policy.
+func DefaultRetryPolicy() *ExponentialBackoff {
+	return &ExponentialBackoff{
+		MaxAttempts:  defaultMaxAttempts,
+		InitialDelay: defaultInitialRetryDelay,
+		MaxDelay:     defaultMaxRetryDelay,
+		Jitter:       defaultRetryJitter,
+	}
+}
+
+// Retry implements the RetryPolicy interface.
+func (b *ExponentialBackoff) Retry(attempt int, request *http.Request, response *http.Response, err error) (time.Duration, bool) {
+	if attempt >= b.MaxAttempts || !isIdempotent(request) || !isRetryable(response, err) {
+		return 0, false
+	}
+
+	delay := b.MaxDelay
+
+	if shift := attempt - 1; shift < maxDelayShift {
+		if exponentialDelay := b.InitialDelay << shift; b.MaxDelay == 0 || exponentialDelay < b.MaxDelay {
+			delay = exponentialDelay
+		}
+	}
+
+	if b.Jitter > 0 {
+		delay -= time.Duration(rand.Float64() * b.Jitter * float64(delay))
+	}
+
+	// Respect the delay requested by the server.
+	if retryAfter := retryAfterDelay(response); retryAfter > delay && (b.MaxDelay == 0 || retryAfter <= b.MaxDelay) {
+		delay = retryAfter
+	}
+
+	return delay, true
+}
+
+func isIdempotent(request *http.Request) bool {
+	switch request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+
+	return request.Header.Get(idempotencyKeyHeader) != ""
+}
+
+func isRetryable(response *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled)
+	}
+
+	switch response.StatusCode {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+
+	return false
+}
+
+func retryAfterDelay(response *http.Response) time.Duration {
+	if response == nil {
+		return 0
+	}
+
+	seconds, err := strconv.Atoi(response.Header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0
+	}
+
+	return time.Duration(seconds) * time.Second
+}
@@ -0,0 +1,161 @@
+package dblabapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/client/dblabapi/types"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+func TestExponentialBackoff(t *testing.T) {
+	policy := &ExponentialBackoff{MaxAttempts: 4, InitialDelay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond}
+
+	get := &http.Request{Method: http.MethodGet, Header: make(http.Header)}
+	post := &http.Request{Method: http.MethodPost, Header: make(http.Header)}
+	idempotentPost := &http.Request{Method: http.MethodPost, Header: http.Header{idempotencyKeyHeader: []string{"key"}}}
+
+	unavailable := &http.Response{StatusCode: http.StatusServiceUnavailable, Header: make(http.Header)}
+	badRequest := &http.Response{StatusCode: http.StatusBadRequest, Header: make(http.Header)}
+	connectionRefused := errors.New("connection refused")
+
+	testCases := []struct {
+		name     string
+		attempt  int
+		request  *http.Request
+		response *http.Response
+		err      error
+		delay    time.Duration
+		retry    bool
+	}{
+		{name: "first retry", attempt: 1, request: get, response: unavailable, delay: 100 * time.Millisecond, retry: true},
+		{name: "second retry", attempt: 2, request: get, err: connectionRefused, delay: 200 * time.Millisecond, retry: true},
+		{name: "max delay", attempt: 3, request: get, response: unavailable, delay: 300 * time.Millisecond, retry: true},
+		{name: "max attempts", attempt: 4, request: get, response: unavailable},
+		{name: "client error", attempt: 1, request: get, response: badRequest},
+		{name: "canceled", attempt: 1, request: get, err: context.Canceled},
+		{name: "not idempotent", attempt: 1, request: post, response: unavailable},
+		{name: "idempotency key", attempt: 1, request: idempotentPost, response: unavailable, delay: 100 * time.Millisecond, retry: true},
+	}
+
+	for _, tc := range testCases {
+		delay, retry := policy.Retry(tc.attempt, tc.request, tc.response, tc.err)
+
+		assert.Equal(t, tc.retry, retry, tc.name)
+		assert.Equal(t, tc.delay, delay, tc.name)
+	}
+
+	retryAfter := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": []string{"0"}}}
+	delay, retry := (&ExponentialBackoff{MaxAttempts: 2, InitialDelay: time.Second, Jitter: 0.5}).Retry(1, get, retryAfter, nil)
+	assert.True(t, retry)
+	assert.True(t, delay > 500*time.Millisecond && delay <= time.Second, delay)
+}
+
+func TestClientRetries(t *testing.T) {
+	attempts, failures := 0, 2
+	headers := []http.Header{}
+
+	transport := roundTripFunc(func(r *http.Request) *http.Response {
+		attempts++
+		headers = append(headers, r.Header.Clone())
+
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		assert.Equal(t, `{"snapshotID":"","latest":true}`+"\n", string(body))
+
+		if attempts <= failures {
+			return &http.Response{
+				StatusCode: http.StatusBadGateway,
+				Body:       io.NopCloser(strings.NewReader(`{"code":"INTERNAL_ERROR","message":"bad gateway"}`)),
+				Header:     make(http.Header),
+			}
+		}
+
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("")), Header: make(http.Header)}
+	})
+
+	c, err := NewClient(Options{
+		Host:              "https://example.com/",
+		VerificationToken: "token",
+		Transport:         transport,
+		RetryPolicy:       &ExponentialBackoff{MaxAttempts: 3, InitialDelay: time.Millisecond},
+		UserAgent:         "ci-runner/1.0",
+	})
+	require.NoError(t, err)
+
+	ctx := WithRequestID(context.Background(), "request-1")
+	require.NoError(t, c.ResetCloneAsync(ctx, "clone1", types.ResetCloneRequest{Latest: true}))
+
+	require.Equal(t, 3, attempts)
+
+	for _, header := range headers {
+		assert.Equal(t, "request-1", header.Get(requestIDHeader))
+		assert.Equal(t, headers[0].Get(idempotencyKeyHeader), header.Get(idempotencyKeyHeader))
+		assert.True(t, strings.HasPrefix(header.Get("User-Agent"), "ci-runner/1.0 dblab-client/"))
+	}
+
+	assert.NotEmpty(t, headers[0].Get(idempotencyKeyHeader))
+
+	// The error is returned when attempts are exhausted.
+	attempts, failures = 0, 3
+
+	err = c.ResetCloneAsync(context.Background(), "clone1", types.ResetCloneRequest{Latest: true})
+	require.Error(t, err)
+	assert.Equal(t, 3, attempts)
+}
+
+func TestClientAttemptTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(Options{Host: srv.URL, RetryPolicy: NoRetry{}, AttemptTimeout: 20 * time.Millisecond})
+	require.NoError(t, err)
+
+	_, err = c.ListSnapshots(context.Background())
+	require.ErrorIs(t, err, context.Canceled)
+
+	// The timeout of the call replaces the one of the client.
+	_, err = c.ListSnapshots(WithAttemptTimeout(context.Background(), 0))
+	require.NoError(t, err)
+}
+
+func TestClientAttemptTimeoutOfEventStream(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", eventStreamContentType)
+		w.(http.Flusher).Flush()
+
+		for _, status := range []models.StatusCode{models.StatusCreating, models.StatusOK} {
+			time.Sleep(50 * time.Millisecond)
+
+			_, _ = fmt.Fprintf(w, "data: {\"type\":\"clone_status\",\"clone\":{\"status\":{\"code\":%q}}}\n\n", status)
+			w.(http.Flusher).Flush()
+		}
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(Options{Host: srv.URL, RetryPolicy: NoRetry{}, AttemptTimeout: 20 * time.Millisecond})
+	require.NoError(t, err)
+
+	eventCh, err := c.WatchEvents(context.Background(), types.EventsFilter{})
+	require.NoError(t, err)
+
+	received := []models.StatusCode{}
+	for event := range eventCh {
+		received = append(received, event.Clone.Status.Code)
+	}
+
+	assert.Equal(t, []models.StatusCode{models.StatusCreating, models.StatusOK}, received)
+}
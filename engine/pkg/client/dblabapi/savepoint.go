@@ -0,0 +1,92 @@
+package dblabapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/pkg/errors"
+
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/client/dblabapi/types"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+// CreateSavepoint saves the current state of a Database Lab clone.
+func (c *Client) CreateSavepoint(ctx context.Context, cloneID string, params types.SavepointCreateRequest) (*models.Savepoint, error) {
+	u, err := c.operationURL("createSavepoint", map[string]string{"id": cloneID})
+	if err != nil {
+		return nil, err
+	}
+
+	var savepoint models.Savepoint
+
+	if err := c.request(ctx, u, params, &savepoint); err != nil {
+		return nil, err
+	}
+
+	return &savepoint, nil
+}
+
+// ListSavepoints lists savepoints of a Database Lab clone.
+func (c *Client) ListSavepoints(ctx context.Context, cloneID string) ([]models.Savepoint, error) {
+	var savepoints []models.Savepoint
+
+	u, err := c.operationURL("listSavepoints", map[string]string{"id": cloneID})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = c.get(ctx, u, &savepoints); err != nil {
+		return nil, err
+	}
+
+	return savepoints, nil
+}
+
+// RollbackSavepoint rolls a Database Lab clone back to the savepoint and waits until the clone is ready.
+func (c *Client) RollbackSavepoint(ctx context.Context, cloneID string, params types.SavepointRollbackRequest) (*models.Clone, error) {
+	if err := c.RollbackSavepointAsync(ctx, cloneID, params); err != nil {
+		return nil, err
+	}
+
+	clone, err := c.watchCloneStatus(ctx, cloneID, models.StatusResetting)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to watch the clone status")
+	}
+
+	if clone.Status.Code != models.StatusOK {
+		return nil, errors.Errorf("failed to rollback clone, unexpected status given. %v: %s", clone.Status.Code, clone.Status.Message)
+	}
+
+	return clone, nil
+}
+
+// RollbackSavepointAsync asynchronously rolls a Database Lab clone back to the savepoint.
+func (c *Client) RollbackSavepointAsync(ctx context.Context, cloneID string, params types.SavepointRollbackRequest) error {
+	ctx = ensureIdempotencyKey(ctx)
+
+	body := bytes.NewBuffer(nil)
+	if err := json.NewEncoder(body).Encode(params); err != nil {
+		return errors.Wrap(err, "failed to encode RollbackSavepoint parameters to JSON")
+	}
+
+	u, err := c.operationURL("rollbackSavepoint", map[string]string{"id": cloneID})
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequest(http.MethodPost, u.String(), body)
+	if err != nil {
+		return errors.Wrap(err, "failed to make a request")
+	}
+
+	response, err := c.Do(ctx, request)
+	if err != nil {
+		return errors.Wrap(err, "failed to get response")
+	}
+
+	defer func() { _ = response.Body.Close() }()
+
+	return nil
+}
@@ -35,7 +35,10 @@ func (c *Client) ListSnapshots(ctx context.Context) ([]*models.Snapshot, error)
 
 // ListSnapshotsRaw provides a snapshot list in raw format.
 func (c *Client) ListSnapshotsRaw(ctx context.Context) (io.ReadCloser, error) {
-	u := c.URL("/snapshots")
+	u, err := c.operationURL("listSnapshots", nil)
+	if err != nil {
+		return nil, err
+	}
 
 	request, err := http.NewRequest(http.MethodGet, u.String(), nil)
 	if err != nil {
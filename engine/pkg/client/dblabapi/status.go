@@ -35,7 +35,10 @@ func (c *Client) Status(ctx context.Context) (*models.InstanceStatus, error) {
 
 // StatusRaw provides a raw instance status.
 func (c *Client) StatusRaw(ctx context.Context) (io.ReadCloser, error) {
-	u := c.URL("/status")
+	u, err := c.operationURL("getInstanceStatus", nil)
+	if err != nil {
+		return nil, err
+	}
 
 	request, err := http.NewRequest(http.MethodGet, u.String(), nil)
 	if err != nil {
@@ -72,3 +75,19 @@ func (c *Client) Health(ctx context.Context) (*models.Engine, error) {
 
 	return &engine, nil
 }
+
+// RetrievalStatus provides the state of the data retrieval.
+func (c *Client) RetrievalStatus(ctx context.Context) (*models.Retrieving, error) {
+	var retrieving models.Retrieving
+
+	u, err := c.operationURL("getRetrievalState", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = c.get(ctx, u, &retrieving); err != nil {
+		return nil, err
+	}
+
+	return &retrieving, nil
+}
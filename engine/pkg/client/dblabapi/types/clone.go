@@ -5,6 +5,11 @@
 // Package types provides request structures for Database Lab HTTP API.
 package types
 
+import (
+	"errors"
+	"time"
+)
+
 // CloneCreateRequest represents clone params of a create request.
 type CloneCreateRequest struct {
 	ID        string                     `json:"id"`
@@ -12,13 +17,47 @@ type CloneCreateRequest struct {
 	DB        *DatabaseRequest           `json:"db"`
 	Snapshot  *SnapshotCloneFieldRequest `json:"snapshot"`
 	ExtraConf map[string]string          `json:"extra_conf"`
+	Labels    map[string]string          `json:"labels,omitempty"`
+	// DockerImage overrides the default Docker image of clones, the image must be allowed by the server configuration.
+	DockerImage string `json:"dockerImage,omitempty"`
+	// AllowedCIDRs restricts client addresses allowed to connect to the clone. Only loopback CIDRs are supported, for example, 127.0.0.1.
+	AllowedCIDRs []string `json:"allowedCIDRs,omitempty"`
+	// InitProfile names the init profile of the server configuration run after the clone starts and after each reset.
+	InitProfile string `json:"initProfile,omitempty"`
 }
 
-// CloneUpdateRequest represents params of an update request.
+// CloneUpdateRequest represents params of an update request. The protection mode of the clone is always changed.
 type CloneUpdateRequest struct {
 	Protected bool `json:"protected"`
 }
 
+// ClonePatchRequest represents params of an update request changing only the specified fields of the clone.
+type ClonePatchRequest struct {
+	// Protected changes the protection mode of the clone if specified.
+	Protected *bool `json:"protected,omitempty"`
+	// Labels replace all labels of the clone if specified, an empty map removes the labels.
+	Labels map[string]string `json:"labels"`
+	// AllowedCIDRs replace client CIDRs allowed to connect to the clone if specified, an empty list allows all clients.
+	AllowedCIDRs []string `json:"allowedCIDRs"`
+}
+
+// CloneListRequest represents filtering, sorting and pagination params of a clone list request.
+type CloneListRequest struct {
+	// Selector filters clones by labels, for example: team=billing,env!=production,owner.
+	Selector string
+	Status   []string
+	Snapshot string
+	Pool     string
+	// Creator filters clones by the database user.
+	Creator string
+	MinAge  time.Duration
+	MaxAge  time.Duration
+	// Sort defines the sort field, prefix it with "-" for descending order.
+	Sort   string
+	Limit  int
+	Offset int
+}
+
 // DatabaseRequest represents database params of a clone request.
 type DatabaseRequest struct {
 	Username   string `json:"username"`
@@ -36,4 +75,68 @@ type SnapshotCloneFieldRequest struct {
 type ResetCloneRequest struct {
 	SnapshotID string `json:"snapshotID"`
 	Latest     bool   `json:"latest"`
+	// InitProfile replaces the init profile of the clone if specified, an empty name removes the profile of the clone.
+	// The current profile of the clone runs if it is not specified.
+	InitProfile *string `json:"initProfile,omitempty"`
+}
+
+// Validate checks the reset request.
+func (r ResetCloneRequest) Validate() error {
+	if r.Latest && r.SnapshotID != "" {
+		return errors.New("parameters `latest` and `snapshot ID` must not be specified together")
+	}
+
+	return nil
+}
+
+// CloneUpgradeRequest represents params of a major-version upgrade request.
+type CloneUpgradeRequest struct {
+	// DockerImage defines the image providing the new Postgres major version, it must be allowed by the server configuration.
+	DockerImage string `json:"dockerImage"`
+}
+
+// Validate checks the upgrade request.
+func (r CloneUpgradeRequest) Validate() error {
+	if r.DockerImage == "" {
+		return errors.New("parameter `dockerImage` must be specified")
+	}
+
+	return nil
+}
+
+// BulkCloneRequest represents params selecting clones of a bulk operation.
+type BulkCloneRequest struct {
+	// Selector filters clones by labels, for example: team=billing,env!=production.
+	Selector string   `json:"selector"`
+	Status   []string `json:"status"`
+	// OlderThan selects clones created earlier than the duration ago, for example: 2h.
+	OlderThan string `json:"older_than"`
+	// Force allows operations on protected clones.
+	Force bool `json:"force"`
+	// DryRun reports selected clones without running the operation.
+	DryRun bool `json:"dry_run"`
+}
+
+// Validate checks that the request selects clones by at least one filter to prevent operations on all clones by mistake.
+func (r BulkCloneRequest) Validate() error {
+	if r.Selector == "" && len(r.Status) == 0 && r.OlderThan == "" {
+		return errors.New("at least one of selector, status or older_than must be specified")
+	}
+
+	return nil
+}
+
+// BulkResetRequest represents params of a bulk reset.
+type BulkResetRequest struct {
+	BulkCloneRequest
+	ResetCloneRequest
+}
+
+// Validate checks the bulk reset request.
+func (r BulkResetRequest) Validate() error {
+	if err := r.BulkCloneRequest.Validate(); err != nil {
+		return err
+	}
+
+	return r.ResetCloneRequest.Validate()
 }
@@ -0,0 +1,11 @@
+package types
+
+import (
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+// EventsFilter selects instance events by clone and type. Empty fields match any event.
+type EventsFilter struct {
+	CloneID string
+	Types   []models.EventType
+}
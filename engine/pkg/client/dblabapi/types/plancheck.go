@@ -0,0 +1,35 @@
+package types
+
+// PlanCheckRequest represents a request to check query plans for regressions.
+//
+// Plans are compared either between clones created from SnapshotA and SnapshotB
+// or between the stages of the observation session running on the clone CloneID.
+type PlanCheckRequest struct {
+	Queries    []PlanQuery         `json:"queries"`
+	SnapshotA  string              `json:"snapshot_a,omitempty"`
+	SnapshotB  string              `json:"snapshot_b,omitempty"`
+	CloneID    string              `json:"clone_id,omitempty"`
+	Stage      string              `json:"stage,omitempty"`
+	DBName     string              `json:"db_name,omitempty"`
+	Thresholds PlanCheckThresholds `json:"thresholds"`
+}
+
+// Stages of the plan check running in an observation session.
+const (
+	PlanCheckStageBefore = "before"
+	PlanCheckStageAfter  = "after"
+)
+
+// PlanQuery describes a query to explain.
+type PlanQuery struct {
+	Name   string        `json:"name"`
+	Query  string        `json:"query"`
+	Params []interface{} `json:"params,omitempty"`
+}
+
+// PlanCheckThresholds defines the allowed growth of query metrics.
+// A value is a ratio of the new metric to the baseline one, zero values enable defaults.
+type PlanCheckThresholds struct {
+	ExecutionTime float64 `json:"execution_time"`
+	Buffers       float64 `json:"buffers"`
+}
@@ -0,0 +1,29 @@
+package types
+
+// Formats of replayed workloads.
+const (
+	ReplayFormatCSVLog     = "csvlog"
+	ReplayFormatStatements = "statements"
+)
+
+// StartReplayRequest represents a request to replay a workload against a clone.
+type StartReplayRequest struct {
+	CloneID     string            `json:"clone_id"`
+	Format      string            `json:"format"`
+	CSVLog      string            `json:"csv_log,omitempty"`
+	Statements  []ReplayStatement `json:"statements,omitempty"`
+	Speed       float64           `json:"speed"`
+	Concurrency int               `json:"concurrency"`
+}
+
+// ReplayStatement describes a statement sample, for example, taken from pg_stat_statements.
+type ReplayStatement struct {
+	Query  string        `json:"query"`
+	Params []interface{} `json:"params,omitempty"`
+	Calls  int           `json:"calls"`
+}
+
+// StopReplayRequest represents a request to stop a workload replay.
+type StopReplayRequest struct {
+	CloneID string `json:"clone_id"`
+}
@@ -0,0 +1,46 @@
+package types
+
+import (
+	"errors"
+	"regexp"
+)
+
+// savepointNamePattern limits savepoint names to symbols allowed in file system snapshot names.
+var savepointNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,64}$`)
+
+// SavepointCreateRequest represents params of a savepoint creation request.
+type SavepointCreateRequest struct {
+	// Name is generated from the current time if it is empty.
+	Name string `json:"name"`
+}
+
+// Validate checks the savepoint creation request.
+func (r SavepointCreateRequest) Validate() error {
+	if r.Name == "" {
+		return nil
+	}
+
+	return validateSavepointName(r.Name)
+}
+
+// SavepointRollbackRequest represents params of a savepoint rollback request.
+type SavepointRollbackRequest struct {
+	Name string `json:"name"`
+}
+
+// Validate checks the savepoint rollback request.
+func (r SavepointRollbackRequest) Validate() error {
+	if r.Name == "" {
+		return errors.New("savepoint name must be specified")
+	}
+
+	return validateSavepointName(r.Name)
+}
+
+func validateSavepointName(name string) error {
+	if !savepointNamePattern.MatchString(name) {
+		return errors.New("savepoint name must contain up to 64 letters, digits, dots, dashes and underscores")
+	}
+
+	return nil
+}
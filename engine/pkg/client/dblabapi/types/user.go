@@ -0,0 +1,43 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+// CloneUserRequest represents params of a request adding a database user to a clone.
+type CloneUserRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	// Role defines the role preset of the user: superuser, restricted, readonly or custom.
+	Role models.UserRole `json:"role"`
+	// DBName defines the database the user connects to, grants of the custom role are executed in it.
+	DBName string `json:"dbName,omitempty"`
+	// Grants defines SQL statements granting privileges to the user with the custom role,
+	// for example: grant select on table orders to analyst.
+	Grants []string `json:"grants,omitempty"`
+}
+
+// Validate checks the clone user request.
+func (r CloneUserRequest) Validate() error {
+	if r.Username == "" || r.Password == "" {
+		return errors.New("username and password must be specified")
+	}
+
+	if !r.Role.IsValid() {
+		return fmt.Errorf("role must be one of: %s, %s, %s, %s",
+			models.UserRoleSuperuser, models.UserRoleRestricted, models.UserRoleReadOnly, models.UserRoleCustom)
+	}
+
+	if r.Role == models.UserRoleCustom && len(r.Grants) == 0 {
+		return errors.New("grants must be specified for the custom role")
+	}
+
+	if r.Role != models.UserRoleCustom && len(r.Grants) > 0 {
+		return errors.New("grants can be specified only for the custom role")
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package dblabapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/pkg/errors"
+
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/client/dblabapi/types"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+// UpgradeClone upgrades a Database Lab clone to a new Postgres major version and waits until the upgrade is finished.
+func (c *Client) UpgradeClone(ctx context.Context, cloneID string, params types.CloneUpgradeRequest) (*models.CloneUpgrade, error) {
+	if err := c.UpgradeCloneAsync(ctx, cloneID, params); err != nil {
+		return nil, err
+	}
+
+	clone, err := c.watchCloneStatus(ctx, cloneID, models.StatusUpgrading)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to watch the clone status")
+	}
+
+	upgrade, err := c.GetCloneUpgrade(ctx, cloneID)
+	if err != nil {
+		return nil, err
+	}
+
+	if clone.Status.Code != models.StatusOK {
+		return upgrade, errors.Errorf("failed to upgrade clone, unexpected status given. %v: %s", clone.Status.Code, clone.Status.Message)
+	}
+
+	return upgrade, nil
+}
+
+// UpgradeCloneAsync asynchronously upgrades a Database Lab clone to a new Postgres major version.
+func (c *Client) UpgradeCloneAsync(ctx context.Context, cloneID string, params types.CloneUpgradeRequest) error {
+	ctx = ensureIdempotencyKey(ctx)
+
+	body := bytes.NewBuffer(nil)
+	if err := json.NewEncoder(body).Encode(params); err != nil {
+		return errors.Wrap(err, "failed to encode UpgradeClone parameters to JSON")
+	}
+
+	u, err := c.operationURL("upgradeClone", map[string]string{"id": cloneID})
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequest(http.MethodPost, u.String(), body)
+	if err != nil {
+		return errors.Wrap(err, "failed to make a request")
+	}
+
+	response, err := c.Do(ctx, request)
+	if err != nil {
+		return errors.Wrap(err, "failed to get response")
+	}
+
+	defer func() { _ = response.Body.Close() }()
+
+	return nil
+}
+
+// GetCloneUpgrade gets the report of the last major-version upgrade of a Database Lab clone.
+func (c *Client) GetCloneUpgrade(ctx context.Context, cloneID string) (*models.CloneUpgrade, error) {
+	var upgrade models.CloneUpgrade
+
+	u, err := c.operationURL("getCloneUpgrade", map[string]string{"id": cloneID})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = c.get(ctx, u, &upgrade); err != nil {
+		return nil, err
+	}
+
+	return &upgrade, nil
+}
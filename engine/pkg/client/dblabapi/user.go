@@ -0,0 +1,65 @@
+package dblabapi
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/pkg/errors"
+
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/client/dblabapi/types"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+// CreateCloneUser adds a database user to a running Database Lab clone.
+func (c *Client) CreateCloneUser(ctx context.Context, cloneID string, params types.CloneUserRequest) (*models.CloneUser, error) {
+	u, err := c.operationURL("createCloneUser", map[string]string{"id": cloneID})
+	if err != nil {
+		return nil, err
+	}
+
+	var user models.CloneUser
+
+	if err := c.request(ctx, u, params, &user); err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// ListCloneUsers lists database users of a Database Lab clone.
+func (c *Client) ListCloneUsers(ctx context.Context, cloneID string) ([]models.CloneUser, error) {
+	var users []models.CloneUser
+
+	u, err := c.operationURL("listCloneUsers", map[string]string{"id": cloneID})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = c.get(ctx, u, &users); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// DeleteCloneUser drops a database user added to a Database Lab clone.
+func (c *Client) DeleteCloneUser(ctx context.Context, cloneID, username string) error {
+	u, err := c.operationURL("deleteCloneUser", map[string]string{"id": cloneID, "name": username})
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequest(http.MethodDelete, u.String(), nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to make a request")
+	}
+
+	response, err := c.Do(ctx, request)
+	if err != nil {
+		return errors.Wrap(err, "failed to get response")
+	}
+
+	defer func() { _ = response.Body.Close() }()
+
+	return nil
+}
@@ -0,0 +1,25 @@
+package models
+
+// Results of clone operations in a bulk.
+const (
+	BulkResultSucceeded = "succeeded"
+	BulkResultFailed    = "failed"
+	BulkResultSkipped   = "skipped"
+	BulkResultPlanned   = "planned"
+)
+
+// BulkResult represents results of a bulk clone operation.
+type BulkResult struct {
+	DryRun    bool                   `json:"dry_run"`
+	Succeeded int                    `json:"succeeded"`
+	Failed    int                    `json:"failed"`
+	Skipped   int                    `json:"skipped"`
+	Clones    []CloneOperationResult `json:"clones"`
+}
+
+// CloneOperationResult represents the result of an operation on a clone.
+type CloneOperationResult struct {
+	CloneID string `json:"clone_id"`
+	Result  string `json:"result"`
+	Message string `json:"message,omitempty"`
+}
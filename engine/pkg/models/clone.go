@@ -6,14 +6,29 @@ package models
 
 // Clone defines a clone model.
 type Clone struct {
-	ID        string        `json:"id"`
-	Snapshot  *Snapshot     `json:"snapshot"`
-	Protected bool          `json:"protected"`
-	DeleteAt  *LocalTime    `json:"deleteAt"`
-	CreatedAt *LocalTime    `json:"createdAt"`
-	Status    Status        `json:"status"`
-	DB        Database      `json:"db"`
-	Metadata  CloneMetadata `json:"metadata"`
+	ID        string            `json:"id"`
+	Snapshot  *Snapshot         `json:"snapshot"`
+	Protected bool              `json:"protected"`
+	DeleteAt  *LocalTime        `json:"deleteAt"`
+	CreatedAt *LocalTime        `json:"createdAt"`
+	Status    Status            `json:"status"`
+	DB        Database          `json:"db"`
+	Metadata  CloneMetadata     `json:"metadata"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	// DockerImage defines the Docker image of the clone container.
+	DockerImage string `json:"dockerImage,omitempty"`
+	// AllowedCIDRs restricts client addresses allowed to connect to the clone, all clients are allowed if it is empty.
+	AllowedCIDRs []string `json:"allowedCIDRs,omitempty"`
+	// Init describes the last run of the init profile of the clone.
+	Init *CloneInit `json:"init,omitempty"`
+}
+
+// ClonePage represents a page of the filtered clone list.
+type ClonePage struct {
+	Clones []*Clone `json:"clones"`
+	Total  int      `json:"total"`
+	Limit  int      `json:"limit"`
+	Offset int      `json:"offset"`
 }
 
 // CloneMetadata contains fields describing a clone model.
@@ -0,0 +1,18 @@
+package models
+
+// CloneInit describes the last run of the init profile in a clone.
+type CloneInit struct {
+	Profile    string          `json:"profile"`
+	StartedAt  *LocalTime      `json:"startedAt"`
+	FinishedAt *LocalTime      `json:"finishedAt"`
+	Steps      []CloneInitStep `json:"steps"`
+	// Error describes the failed step, the following steps are not run.
+	Error string `json:"error,omitempty"`
+}
+
+// CloneInitStep describes a step of the init profile run. Only the end of long outputs is kept.
+type CloneInitStep struct {
+	Name   string `json:"name"`
+	Output string `json:"output,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
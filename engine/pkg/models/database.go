@@ -6,10 +6,11 @@ package models
 
 // Database defines clone database parameters.
 type Database struct {
-	ConnStr  string `json:"connStr"`
-	Host     string `json:"host"`
-	Port     string `json:"port"`
-	Username string `json:"username"`
-	Password string `json:"password"`
-	DBName   string `json:"dbName"`
+	ConnStr       string `json:"connStr"`
+	RouterConnStr string `json:"routerConnStr,omitempty"`
+	Host          string `json:"host"`
+	Port          string `json:"port"`
+	Username      string `json:"username"`
+	Password      string `json:"password"`
+	DBName        string `json:"dbName"`
 }
@@ -16,6 +16,38 @@ const (
 	ErrCodeNotFound     ErrorCode = "NOT_FOUND"
 )
 
+// Typed error codes. API v1 reports them with the generic codes above, see Legacy.
+const (
+	ErrCodeValidationFailed    ErrorCode = "VALIDATION_FAILED"
+	ErrCodeCloneNotFound       ErrorCode = "CLONE_NOT_FOUND"
+	ErrCodeCloneAlreadyExists  ErrorCode = "CLONE_ALREADY_EXISTS"
+	ErrCodeCloneProtected      ErrorCode = "CLONE_PROTECTED"
+	ErrCodeCloneBusy           ErrorCode = "CLONE_BUSY"
+	ErrCodeCloneNotStarted     ErrorCode = "CLONE_NOT_STARTED"
+	ErrCodeSnapshotNotFound    ErrorCode = "SNAPSHOT_NOT_FOUND"
+	ErrCodeSavepointNotFound   ErrorCode = "SAVEPOINT_NOT_FOUND"
+	ErrCodeSavepointExists     ErrorCode = "SAVEPOINT_ALREADY_EXISTS"
+	ErrCodeUserNotFound        ErrorCode = "USER_NOT_FOUND"
+	ErrCodeUserExists          ErrorCode = "USER_ALREADY_EXISTS"
+	ErrCodePoolFull            ErrorCode = "POOL_FULL"
+	ErrCodeIdempotencyConflict ErrorCode = "IDEMPOTENCY_CONFLICT"
+)
+
+// Legacy returns the generic error code reported by API v1 for the typed one.
+func (c ErrorCode) Legacy() ErrorCode {
+	switch c {
+	case ErrCodeCloneNotFound, ErrCodeCloneNotStarted, ErrCodeSnapshotNotFound, ErrCodeSavepointNotFound, ErrCodeUserNotFound:
+		return ErrCodeNotFound
+
+	case ErrCodeValidationFailed, ErrCodeCloneAlreadyExists, ErrCodeCloneProtected, ErrCodeCloneBusy,
+		ErrCodeSavepointExists, ErrCodeUserExists, ErrCodePoolFull, ErrCodeIdempotencyConflict:
+		return ErrCodeBadRequest
+
+	default:
+		return c
+	}
+}
+
 // Error struct represents a response error.
 type Error struct {
 	Code    ErrorCode `json:"code"`
@@ -0,0 +1,34 @@
+package models
+
+// EventType defines the type of instance event.
+type EventType string
+
+// Instance event types.
+const (
+	EventCloneStatus     EventType = "clone_status"
+	EventCloneDeleted    EventType = "clone_deleted"
+	EventSnapshotCreated EventType = "snapshot_created"
+	EventSnapshotDeleted EventType = "snapshot_deleted"
+	EventPoolStatus      EventType = "pool_status"
+	EventRetrievalStatus EventType = "retrieval_status"
+)
+
+// Event describes a state change of the instance.
+type Event struct {
+	ID        uint64      `json:"id"`
+	Type      EventType   `json:"type"`
+	Time      *LocalTime  `json:"time"`
+	Clone     *Clone      `json:"clone,omitempty"`
+	Snapshot  *Snapshot   `json:"snapshot,omitempty"`
+	Pool      *PoolEntry  `json:"pool,omitempty"`
+	Retrieval *Retrieving `json:"retrieval,omitempty"`
+}
+
+// CloneID returns the ID of the clone the event relates to.
+func (e Event) CloneID() string {
+	if e.Clone == nil {
+		return ""
+	}
+
+	return e.Clone.ID
+}
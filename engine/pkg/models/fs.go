@@ -39,6 +39,10 @@ type Size uint64
 
 // MarshalJSON marshals the Size struct.
 func (s Size) MarshalJSON() ([]byte, error) {
-	humanReadableSize := humanize.BigIBytes(big.NewInt(int64(s)))
-	return []byte(fmt.Sprintf("%q", humanReadableSize)), nil
+	return []byte(fmt.Sprintf("%q", s.String())), nil
+}
+
+// String returns the human-readable size.
+func (s Size) String() string {
+	return humanize.BigIBytes(big.NewInt(int64(s)))
 }
@@ -37,9 +37,19 @@ type ContainerOptions struct {
 
 // Cloning represents info about the cloning process.
 type Cloning struct {
-	ExpectedCloningTime float64  `json:"expectedCloningTime"`
-	NumClones           uint64   `json:"numClones"`
-	Clones              []*Clone `json:"clones"`
+	ExpectedCloningTime float64   `json:"expectedCloningTime"`
+	NumClones           uint64    `json:"numClones"`
+	Clones              []*Clone  `json:"clones"`
+	WarmPool            *WarmPool `json:"warmPool,omitempty"`
+}
+
+// WarmPool represents the state of the pool of pre-created clones.
+type WarmPool struct {
+	Size       uint   `json:"size"`
+	Ready      uint   `json:"ready"`
+	SnapshotID string `json:"snapshotID,omitempty"`
+	Hits       uint64 `json:"hits"`
+	Misses     uint64 `json:"misses"`
 }
 
 // Engine represents info about Database Lab Engine instance.
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Plan check statuses.
+const (
+	PlanCheckPassed   = "passed"
+	PlanCheckFailed   = "failed"
+	PlanCheckBaseline = "baseline"
+)
+
+// PlanCheckReport represents a report of the query plan regression check.
+type PlanCheckReport struct {
+	Status     string            `json:"status"`
+	Baseline   string            `json:"baseline"`
+	Target     string            `json:"target"`
+	StartedAt  time.Time         `json:"started_at"`
+	FinishedAt time.Time         `json:"finished_at"`
+	Queries    []QueryPlanReport `json:"queries"`
+}
+
+// QueryPlanReport contains the comparison of query plans.
+type QueryPlanReport struct {
+	Name         string          `json:"name"`
+	Query        string          `json:"query"`
+	Baseline     *QueryPlanStats `json:"baseline,omitempty"`
+	Target       *QueryPlanStats `json:"target,omitempty"`
+	PlanChanged  bool            `json:"plan_changed"`
+	PlanDiff     []string        `json:"plan_diff,omitempty"`
+	TimeRatio    float64         `json:"execution_time_ratio,omitempty"`
+	BuffersRatio float64         `json:"buffers_ratio,omitempty"`
+	Regressions  []string        `json:"regressions,omitempty"`
+	Error        string          `json:"error,omitempty"`
+}
+
+// QueryPlanStats contains the key metrics of an executed query plan.
+type QueryPlanStats struct {
+	Nodes         []string        `json:"nodes"`
+	PlanningTime  float64         `json:"planning_time"`
+	ExecutionTime float64         `json:"execution_time"`
+	SharedHit     int64           `json:"shared_hit_blocks"`
+	SharedRead    int64           `json:"shared_read_blocks"`
+	Plan          json.RawMessage `json:"plan,omitempty"`
+}
+
+// Buffers returns the total number of shared buffers touched by the query.
+func (s QueryPlanStats) Buffers() int64 {
+	return s.SharedHit + s.SharedRead
+}
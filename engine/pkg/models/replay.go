@@ -0,0 +1,70 @@
+package models
+
+import (
+	"time"
+)
+
+// Replay states.
+const (
+	ReplayRunning   = "running"
+	ReplayCompleted = "completed"
+	ReplayStopped   = "stopped"
+	ReplayFailed    = "failed"
+)
+
+// ReplayStatus represents the state of a workload replay.
+type ReplayStatus struct {
+	CloneID     string         `json:"clone_id"`
+	SessionID   uint64         `json:"session_id"`
+	State       string         `json:"state"`
+	Error       string         `json:"error,omitempty"`
+	Speed       float64        `json:"speed"`
+	Concurrency int            `json:"concurrency"`
+	StartedAt   time.Time      `json:"started_at"`
+	FinishedAt  *time.Time     `json:"finished_at,omitempty"`
+	Progress    ReplayProgress `json:"progress"`
+}
+
+// ReplayProgress contains the progress of a workload replay.
+type ReplayProgress struct {
+	Sessions   int     `json:"sessions"`
+	Statements int     `json:"statements"`
+	Executed   int     `json:"executed"`
+	Errors     int     `json:"errors"`
+	Percent    float64 `json:"percent"`
+}
+
+// ReplayReport represents latency distributions of a replayed workload.
+type ReplayReport struct {
+	ReplayStatus
+	Latency LatencyHistogram `json:"latency"`
+	Queries []QueryLatency   `json:"queries"`
+}
+
+// QueryLatency contains the latency distribution of a query.
+type QueryLatency struct {
+	Query   string           `json:"query"`
+	Latency LatencyHistogram `json:"latency"`
+}
+
+// LatencyHistogram describes a latency distribution. Latencies are measured in milliseconds.
+type LatencyHistogram struct {
+	Count   int             `json:"count"`
+	Errors  int             `json:"errors"`
+	Total   float64         `json:"total"`
+	Min     float64         `json:"min"`
+	Max     float64         `json:"max"`
+	Mean    float64         `json:"mean"`
+	P50     float64         `json:"p50"`
+	P90     float64         `json:"p90"`
+	P95     float64         `json:"p95"`
+	P99     float64         `json:"p99"`
+	Buckets []LatencyBucket `json:"buckets,omitempty"`
+}
+
+// LatencyBucket contains the number of statements with latency greater than the upper bound of the previous bucket
+// and less than or equal to the upper bound. The upper bound of the last bucket is omitted, the bucket counts all slower statements.
+type LatencyBucket struct {
+	UpperBound float64 `json:"le,omitempty"`
+	Count      int     `json:"count"`
+}
@@ -0,0 +1,10 @@
+package models
+
+// Savepoint describes a saved state of a clone that the clone can be rolled back to.
+type Savepoint struct {
+	Name      string     `json:"name"`
+	CreatedAt *LocalTime `json:"createdAt"`
+	// PhysicalSize is the disk space used only by the savepoint, it grows as the clone data changes.
+	PhysicalSize uint64 `json:"physicalSize"`
+	LogicalSize  uint64 `json:"logicalSize"`
+}
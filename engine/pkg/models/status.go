@@ -22,12 +22,20 @@ const (
 	StatusExporting StatusCode = "EXPORTING"
 	StatusFatal     StatusCode = "FATAL"
 	StatusWarning   StatusCode = "WARNING"
+	StatusStopped   StatusCode = "STOPPED"
+	StatusStarting  StatusCode = "STARTING"
+	StatusUpgrading StatusCode = "UPGRADING"
 
 	CloneMessageOK        = "Clone is ready to accept Postgres connections."
 	CloneMessageCreating  = "Clone is being created."
 	CloneMessageResetting = "Clone is being reset."
 	CloneMessageDeleting  = "Clone is being deleted."
 	CloneMessageFatal     = "Cloning failure."
+	CloneMessageStopped   = "Clone is stopped. Data is kept, start the clone to accept Postgres connections."
+	CloneMessageStarting  = "Clone is being started."
+	CloneMessageUpgrading = "Clone is being upgraded to a new Postgres major version."
+	CloneMessageAccess    = "Client allowlist of the clone is being changed."
+	CloneMessageSavepoint = "Savepoint of the clone is being created."
 
 	InstanceMessageOK      = "Instance is ready"
 	InstanceMessageWarning = "Subsystems that need attention"
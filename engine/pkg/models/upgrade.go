@@ -0,0 +1,35 @@
+package models
+
+// UpgradeStatus defines the state of a clone upgrade.
+type UpgradeStatus string
+
+const (
+	// UpgradeStatusRunning means that the upgrade is in progress.
+	UpgradeStatusRunning UpgradeStatus = "running"
+	// UpgradeStatusFinished means that the clone has been upgraded and restarted on the new image.
+	UpgradeStatusFinished UpgradeStatus = "finished"
+	// UpgradeStatusFailed means that the upgrade has not been completed, see the error and the check output.
+	UpgradeStatusFailed UpgradeStatus = "failed"
+)
+
+// CloneUpgrade describes a major-version upgrade of a clone with pg_upgrade.
+type CloneUpgrade struct {
+	Status      UpgradeStatus `json:"status"`
+	DockerImage string        `json:"dockerImage"`
+	FromVersion string        `json:"fromVersion,omitempty"`
+	ToVersion   string        `json:"toVersion,omitempty"`
+	StartedAt   *LocalTime    `json:"startedAt"`
+	FinishedAt  *LocalTime    `json:"finishedAt,omitempty"`
+	// CheckOutput keeps the output of pg_upgrade --check.
+	CheckOutput string         `json:"checkOutput,omitempty"`
+	Timings     UpgradeTimings `json:"timings"`
+	Error       string         `json:"error,omitempty"`
+}
+
+// UpgradeTimings contains durations of the upgrade steps in seconds.
+type UpgradeTimings struct {
+	CheckTime   float64 `json:"checkTime"`
+	UpgradeTime float64 `json:"upgradeTime"`
+	AnalyzeTime float64 `json:"analyzeTime"`
+	TotalTime   float64 `json:"totalTime"`
+}
@@ -0,0 +1,36 @@
+package models
+
+// UserRole defines a preset of privileges of a clone database user.
+type UserRole string
+
+// UserRole constants define role presets of clone database users.
+const (
+	// UserRoleSuperuser defines a superuser.
+	UserRoleSuperuser UserRole = "superuser"
+	// UserRoleRestricted defines a user owning all databases and their objects without superuser privileges.
+	UserRoleRestricted UserRole = "restricted"
+	// UserRoleReadOnly defines a user reading all tables and sequences, including the ones created later.
+	UserRoleReadOnly UserRole = "readonly"
+	// UserRoleCustom defines a user getting privileges with custom SQL grants.
+	UserRoleCustom UserRole = "custom"
+)
+
+// IsValid checks if the role preset is supported.
+func (r UserRole) IsValid() bool {
+	switch r {
+	case UserRoleSuperuser, UserRoleRestricted, UserRoleReadOnly, UserRoleCustom:
+		return true
+	}
+
+	return false
+}
+
+// CloneUser describes a database user of a clone.
+type CloneUser struct {
+	Name   string   `json:"name"`
+	Role   UserRole `json:"role"`
+	DBName string   `json:"dbName,omitempty"`
+	Grants []string `json:"grants,omitempty"`
+	// Primary marks the user defined on clone creation, the user cannot be deleted.
+	Primary bool `json:"primary"`
+}